@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/vmware/govmomi/vapi/rest"
@@ -42,8 +43,37 @@ type VSphereClient struct {
 	// The REST client used for tags and content library.
 	restClient *rest.Client
 
-	// The vAPI REST client
+	// The vAPI REST client. This is connected on first use; see
+	// VApiConnector.
 	vApiConnector client.Connector
+
+	// Guards the lazy connection of vApiConnector.
+	vApiMutex sync.Mutex
+
+	// The configuration used to connect vApiConnector.
+	config *Config
+}
+
+// VApiConnector returns the vAPI connector used for compute policies,
+// connecting it on first use. The connection is deferred so that endpoints
+// that do not serve the vAPI, such as the vCenter simulator used in tests, can
+// still be used for everything else.
+func (c *VSphereClient) VApiConnector() (client.Connector, error) {
+	c.vApiMutex.Lock()
+	defer c.vApiMutex.Unlock()
+	if c.vApiConnector != nil {
+		return c.vApiConnector, nil
+	}
+	if c.restClient == nil {
+		return nil, fmt.Errorf("compute policies require %s or higher", tagsMinVersion)
+	}
+	connector, err := utils.NewVsphereConnector(c.config.VSphereServer, c.config.User, c.config.Password)
+	if err != nil {
+		return nil, err
+	}
+	log.Println("[DEBUG] vAPI client configuration successful")
+	c.vApiConnector = connector
+	return connector, nil
 }
 
 // TagsManager returns the embedded tags manager used for tags, after determining
@@ -137,7 +167,7 @@ func (c *Config) vimURL() (*url.URL, error) {
 
 // Client returns a new client for accessing VMWare vSphere.
 func (c *Config) Client() (*VSphereClient, error) {
-	client := new(VSphereClient)
+	client := &VSphereClient{config: c}
 
 	u, err := c.vimURL()
 	if err != nil {
@@ -169,13 +199,6 @@ func (c *Config) Client() (*VSphereClient, error) {
 		if err != nil {
 			return nil, err
 		}
-
-		// Connect to vapi go endpoint
-		// TODO will replace restClient with the vapi client in the future
-		client.vApiConnector, err = utils.NewVsphereConnector(c.VSphereServer, c.User, c.Password)
-		if err != nil {
-			return nil, err
-		}
 		log.Println("[DEBUG] CIS REST client configuration successful")
 	} else {
 		// Just print a log message so that we know that tags are not available on
@@ -44,7 +44,10 @@ func dataSourceVSphereComputePolicy() *schema.Resource {
 
 func dataSourceVSphereComputePolicyRead(d *schema.ResourceData, meta interface{}) error {
 	policyName := d.Get("name").(string)
-	connector := meta.(*VSphereClient).vApiConnector
+	connector, err := meta.(*VSphereClient).VApiConnector()
+	if err != nil {
+		return err
+	}
 	policyClient := compute.NewDefaultPoliciesClient(connector)
 	policySummaries, err := policyClient.List()
 	if err != nil {
@@ -0,0 +1,34 @@
+package vsphere
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/contentlibrary"
+)
+
+func dataSourceVSphereContentLibrary() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereContentLibraryRead,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name of the content library.",
+				Required:    true,
+			},
+		},
+	}
+}
+
+func dataSourceVSphereContentLibraryRead(d *schema.ResourceData, meta interface{}) error {
+	rc, err := contentLibraryClient(meta)
+	if err != nil {
+		return err
+	}
+
+	lib, err := contentlibrary.FromName(rc, d.Get("name").(string))
+	if err != nil {
+		return err
+	}
+
+	d.SetId(lib.ID)
+	return nil
+}
@@ -0,0 +1,45 @@
+package vsphere
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/contentlibrary"
+)
+
+func dataSourceVSphereContentLibraryItem() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereContentLibraryItemRead,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name of the content library item.",
+				Required:    true,
+			},
+			"library_id": {
+				Type:        schema.TypeString,
+				Description: "The ID of the content library that contains the item.",
+				Required:    true,
+			},
+			"type": {
+				Type:        schema.TypeString,
+				Description: "The type of the content library item.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func dataSourceVSphereContentLibraryItemRead(d *schema.ResourceData, meta interface{}) error {
+	rc, err := contentLibraryClient(meta)
+	if err != nil {
+		return err
+	}
+
+	item, err := contentlibrary.ItemFromName(rc, d.Get("library_id").(string), d.Get("name").(string))
+	if err != nil {
+		return err
+	}
+
+	d.SetId(item.ID)
+	d.Set("type", item.Type)
+	return nil
+}
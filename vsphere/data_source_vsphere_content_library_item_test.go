@@ -0,0 +1,45 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataSourceVSphereContentLibraryItem_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccSkipIfEsxi(t)
+			testAccCheckEnvVariables(t, []string{"VSPHERE_OVF_URL"})
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceVSphereContentLibraryItemConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(
+						"data.vsphere_content_library_item.item", "id",
+						"vsphere_content_library_item.item", "id",
+					),
+					resource.TestCheckResourceAttr("data.vsphere_content_library_item.item", "type", "ovf"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceVSphereContentLibraryItemConfig() string {
+	return fmt.Sprintf(`
+%s
+
+data "vsphere_content_library_item" "item" {
+  name       = "${vsphere_content_library_item.item.name}"
+  library_id = "${vsphere_content_library.library.id}"
+}
+`,
+		testAccResourceVSphereContentLibraryItemConfig(os.Getenv("VSPHERE_OVF_URL")),
+	)
+}
@@ -0,0 +1,116 @@
+package vsphere
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/dvportgroup"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi/object"
+)
+
+func dataSourceVSphereDistributedPortGroup() *schema.Resource {
+	s := schemaDVPortgroupConfigSpec()
+	structure.SchemaToComputed(s)
+
+	// The name is the only item of the port group configuration that is used
+	// to look up the port group.
+	delete(s, "name")
+	structure.MergeSchema(s, map[string]*schema.Schema{
+		"name": {
+			Type:        schema.TypeString,
+			Description: "The name or path of the port group.",
+			Required:    true,
+		},
+		"datacenter_id": {
+			Type:        schema.TypeString,
+			Description: "The managed object ID of the datacenter the port group is in. This is required if the supplied path is not an absolute path containing a datacenter and there are multiple datacenters in your infrastructure.",
+			Optional:    true,
+		},
+		"distributed_virtual_switch_uuid": {
+			Type:        schema.TypeString,
+			Description: "The UUID of the DVS the port group is on. When set, the port group is looked up by name on this DVS only.",
+			Optional:    true,
+			Computed:    true,
+		},
+		"key": {
+			Type:        schema.TypeString,
+			Description: "The generated UUID of the port group.",
+			Computed:    true,
+		},
+		"port_keys": {
+			Type:        schema.TypeList,
+			Description: "The keys of the ports in the port group.",
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+	})
+
+	return &schema.Resource{
+		Read:   dataSourceVSphereDistributedPortGroupRead,
+		Schema: s,
+	}
+}
+
+func dataSourceVSphereDistributedPortGroupRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return err
+	}
+
+	name := d.Get("name").(string)
+	var pg *object.DistributedVirtualPortgroup
+	if dvsUUID, ok := d.GetOk("distributed_virtual_switch_uuid"); ok {
+		dvs, err := dvsFromUUID(client, dvsUUID.(string))
+		if err != nil {
+			return fmt.Errorf("cannot locate distributed virtual switch: %s", err)
+		}
+		pg, err = dvsPortgroupFromName(client, dvs, name)
+		if err != nil {
+			return err
+		}
+	} else {
+		var dc *object.Datacenter
+		if dcID, ok := d.GetOk("datacenter_id"); ok {
+			var err error
+			dc, err = datacenterFromID(client, dcID.(string))
+			if err != nil {
+				return fmt.Errorf("cannot locate datacenter: %s", err)
+			}
+		}
+		var err error
+		pg, err = dvportgroup.FromPath(client, name, dc)
+		if err != nil {
+			return fmt.Errorf("error fetching port group: %s", err)
+		}
+	}
+
+	props, err := dvportgroup.Properties(pg)
+	if err != nil {
+		return fmt.Errorf("error fetching port group properties: %s", err)
+	}
+	dvs, err := dvsFromMOID(client, props.Config.DistributedVirtualSwitch.Value)
+	if err != nil {
+		return fmt.Errorf("cannot locate distributed virtual switch: %s", err)
+	}
+	dvsProps, err := dvsProperties(dvs)
+	if err != nil {
+		return fmt.Errorf("error fetching DVS properties: %s", err)
+	}
+
+	d.SetId(pg.Reference().Value)
+	d.Set("distributed_virtual_switch_uuid", dvsProps.Uuid)
+	d.Set("key", props.Key)
+	if err := d.Set("port_keys", props.PortKeys); err != nil {
+		return fmt.Errorf("error setting port_keys: %s", err)
+	}
+	if err := flattenDVPortgroupConfigInfo(d, props.Config); err != nil {
+		return err
+	}
+	// flattenDVPortgroupConfigInfo sets the name of the port group, which is
+	// set back to the name or path that was supplied.
+	d.Set("name", name)
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataSourceVSphereDistributedPortGroup_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccSkipIfEsxi(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceVSphereDistributedPortGroupConfig(false),
+				Check:  testAccDataSourceVSphereDistributedPortGroupCheck(),
+			},
+		},
+	})
+}
+
+func TestAccDataSourceVSphereDistributedPortGroup_byDVS(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccSkipIfEsxi(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceVSphereDistributedPortGroupConfig(true),
+				Check:  testAccDataSourceVSphereDistributedPortGroupCheck(),
+			},
+		},
+	})
+}
+
+func testAccDataSourceVSphereDistributedPortGroupCheck() resource.TestCheckFunc {
+	return resource.ComposeTestCheckFunc(
+		resource.TestCheckResourceAttrPair(
+			"data.vsphere_distributed_port_group.pg", "id",
+			"vsphere_distributed_port_group.pg", "id",
+		),
+		resource.TestCheckResourceAttrPair(
+			"data.vsphere_distributed_port_group.pg", "key",
+			"vsphere_distributed_port_group.pg", "key",
+		),
+		resource.TestCheckResourceAttrPair(
+			"data.vsphere_distributed_port_group.pg", "distributed_virtual_switch_uuid",
+			"vsphere_distributed_virtual_switch.dvs", "id",
+		),
+		resource.TestCheckResourceAttr("data.vsphere_distributed_port_group.pg", "vlan_range.#", "1"),
+		resource.TestCheckResourceAttr("data.vsphere_distributed_port_group.pg", "teaming_policy", "failover_explicit"),
+		resource.TestCheckResourceAttr("data.vsphere_distributed_port_group.pg", "active_uplinks.#", "1"),
+		resource.TestCheckResourceAttr("data.vsphere_distributed_port_group.pg", "active_uplinks.0", "tfup1"),
+		resource.TestCheckResourceAttr("data.vsphere_distributed_port_group.pg", "standby_uplinks.0", "tfup2"),
+		resource.TestCheckResourceAttr("data.vsphere_distributed_port_group.pg", "port_keys.#", "4"),
+	)
+}
+
+func testAccDataSourceVSphereDistributedPortGroupConfig(byDVS bool) string {
+	lookup := `datacenter_id = "${data.vsphere_datacenter.dc.id}"`
+	if byDVS {
+		lookup = `distributed_virtual_switch_uuid = "${vsphere_distributed_virtual_switch.dvs.id}"`
+	}
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_distributed_virtual_switch" "dvs" {
+  name          = "terraform-test-dvs"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+  uplinks       = ["tfup1", "tfup2"]
+}
+
+resource "vsphere_distributed_port_group" "pg" {
+  name                            = "terraform-test-pg"
+  distributed_virtual_switch_uuid = "${vsphere_distributed_virtual_switch.dvs.id}"
+  number_of_ports                 = 4
+  teaming_policy                  = "failover_explicit"
+  active_uplinks                  = ["tfup1"]
+  standby_uplinks                 = ["tfup2"]
+
+  vlan_range {
+    min_vlan = 100
+    max_vlan = 199
+  }
+}
+
+data "vsphere_distributed_port_group" "pg" {
+  name = "${vsphere_distributed_port_group.pg.name}"
+  %s
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		lookup,
+	)
+}
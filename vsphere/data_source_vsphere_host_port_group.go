@@ -0,0 +1,85 @@
+package vsphere
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
+)
+
+func dataSourceVSphereHostPortGroup() *schema.Resource {
+	s := schemaHostPortGroupSpec()
+	structure.SchemaToComputed(s)
+
+	// The name is used to look up the port group, along with the host.
+	delete(s, "name")
+	structure.MergeSchema(s, map[string]*schema.Schema{
+		"name": {
+			Type:        schema.TypeString,
+			Description: "The name of the port group.",
+			Required:    true,
+		},
+		"host_system_id": {
+			Type:        schema.TypeString,
+			Description: "The managed object ID of the host the port group is on.",
+			Required:    true,
+		},
+		"computed_policy": {
+			Type:        schema.TypeMap,
+			Description: "The effective network policy after inheritance.",
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+		"key": {
+			Type:        schema.TypeString,
+			Description: "The linkable identifier for this port group.",
+			Computed:    true,
+		},
+		"ports": {
+			Type:        schema.TypeSet,
+			Description: "The ports that currently exist and are used on this port group.",
+			Computed:    true,
+			Elem:        portGroupPortSchema(),
+		},
+	})
+
+	return &schema.Resource{
+		Read:   dataSourceVSphereHostPortGroupRead,
+		Schema: s,
+	}
+}
+
+func dataSourceVSphereHostPortGroupRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	name := d.Get("name").(string)
+	hsID := d.Get("host_system_id").(string)
+	ns, err := hostNetworkSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error loading host network system: %s", err)
+	}
+
+	pg, err := hostPortGroupFromName(client, ns, name)
+	if err != nil {
+		return fmt.Errorf("error fetching port group data: %s", err)
+	}
+
+	saveHostPortGroupID(d, hsID, name)
+	d.Set("virtual_switch_name", pg.Spec.VswitchName)
+	if err := flattenHostPortGroupSpec(d, &pg.Spec); err != nil {
+		return fmt.Errorf("error setting resource data: %s", err)
+	}
+
+	d.Set("key", pg.Key)
+	cpm, err := calculateComputedPolicy(pg.ComputedPolicy)
+	if err != nil {
+		return err
+	}
+	if err := d.Set("computed_policy", cpm); err != nil {
+		return fmt.Errorf("error saving effective policy to state: %s", err)
+	}
+	if err := d.Set("ports", calculatePorts(pg.Port)); err != nil {
+		return fmt.Errorf("error setting port list: %s", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataSourceVSphereHostPortGroup_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereHostPortGroupPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceVSphereHostPortGroupConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(
+						"data.vsphere_host_port_group.pg", "id",
+						"vsphere_host_port_group.pg", "id",
+					),
+					resource.TestCheckResourceAttrPair(
+						"data.vsphere_host_port_group.pg", "key",
+						"vsphere_host_port_group.pg", "key",
+					),
+					resource.TestCheckResourceAttr("data.vsphere_host_port_group.pg", "vlan_id", "1000"),
+					resource.TestCheckResourceAttr("data.vsphere_host_port_group.pg", "virtual_switch_name", "vSwitchTerraformTest"),
+					resource.TestCheckResourceAttr("data.vsphere_host_port_group.pg", "allow_promiscuous", "true"),
+					resource.TestCheckResourceAttr("data.vsphere_host_port_group.pg", "computed_policy.allow_promiscuous", "true"),
+					resource.TestCheckResourceAttr("data.vsphere_host_port_group.pg", "computed_policy.active_nics.#", "1"),
+					resource.TestCheckResourceAttr("data.vsphere_host_port_group.pg", "computed_policy.active_nics.0", os.Getenv("VSPHERE_HOST_NIC0")),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceVSphereHostPortGroupConfig() string {
+	return fmt.Sprintf(`
+variable "host_nic0" {
+  default = "%s"
+}
+
+variable "host_nic1" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_host_virtual_switch" "switch" {
+  name           = "vSwitchTerraformTest"
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+
+  network_adapters = ["${var.host_nic0}", "${var.host_nic1}"]
+  active_nics      = ["${var.host_nic0}", "${var.host_nic1}"]
+  standby_nics     = []
+}
+
+resource "vsphere_host_port_group" "pg" {
+  name                = "PGTerraformTest"
+  host_system_id      = "${data.vsphere_host.esxi_host.id}"
+  virtual_switch_name = "${vsphere_host_virtual_switch.switch.name}"
+
+  vlan_id           = 1000
+  active_nics       = ["${var.host_nic0}"]
+  standby_nics      = ["${var.host_nic1}"]
+  allow_promiscuous = true
+}
+
+data "vsphere_host_port_group" "pg" {
+  name           = "${vsphere_host_port_group.pg.name}"
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+}
+`, os.Getenv("VSPHERE_HOST_NIC0"), os.Getenv("VSPHERE_HOST_NIC1"), os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_ESXI_HOST"))
+}
@@ -10,7 +10,7 @@ import (
 )
 
 func TestAccDataSourceVSphereHost_basic(t *testing.T) {
-	resource.Test(t, resource.TestCase{
+	testAccResourceTest(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccDataSourceVSphereHostPreCheck(t)
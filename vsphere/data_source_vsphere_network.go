@@ -1,28 +1,54 @@
 package vsphere
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/network"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/nsx"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
 )
 
+// opaqueNetworkTypeNsxLogicalSwitch is the opaque network type of networks
+// that are backed by NSX-T logical switches.
+const opaqueNetworkTypeNsxLogicalSwitch = "nsx.LogicalSwitch"
+
 func dataSourceVSphereNetwork() *schema.Resource {
 	return &schema.Resource{
 		Read: dataSourceVSphereNetworkRead,
 
 		Schema: map[string]*schema.Schema{
 			"name": {
-				Type:        schema.TypeString,
-				Description: "The name or path of the network.",
-				Required:    true,
+				Type:          schema.TypeString,
+				Description:   "The name or path of the network.",
+				Optional:      true,
+				ConflictsWith: []string{"nsxt_segment_path", "opaque_network_id"},
 			},
 			"datacenter_id": {
 				Type:        schema.TypeString,
 				Description: "The managed object ID of the datacenter the network is in. This is required if the supplied path is not an absolute path containing a datacenter and there are multiple datacenters in your infrastructure.",
 				Optional:    true,
 			},
+			"nsxt_segment_path": {
+				Type:          schema.TypeString,
+				Description:   "The policy path of the NSX-T segment backing the network, ie: /infra/segments/web.",
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"opaque_network_id"},
+			},
+			"opaque_network_id": {
+				Type:        schema.TypeString,
+				Description: "The ID of the opaque network.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"logical_switch_uuid": {
+				Type:        schema.TypeString,
+				Description: "The UUID of the NSX-T logical switch backing the network, if any.",
+				Computed:    true,
+			},
 			"type": {
 				Type:        schema.TypeString,
 				Description: "The managed object type of the network.",
@@ -35,21 +61,53 @@ func dataSourceVSphereNetwork() *schema.Resource {
 func dataSourceVSphereNetworkRead(d *schema.ResourceData, meta interface{}) error {
 	client := meta.(*VSphereClient).vimClient
 
-	name := d.Get("name").(string)
-	var dc *object.Datacenter
-	if dcID, ok := d.GetOk("datacenter_id"); ok {
-		var err error
-		dc, err = datacenterFromID(client, dcID.(string))
-		if err != nil {
-			return fmt.Errorf("cannot locate datacenter: %s", err)
-		}
+	var net object.NetworkReference
+	var err error
+	switch {
+	case d.Get("name").(string) != "":
+		net, err = dataSourceVSphereNetworkFromPath(d, meta)
+	case d.Get("nsxt_segment_path").(string) != "":
+		net, err = nsx.OpaqueNetworkFromSegmentPath(client, d.Get("nsxt_segment_path").(string))
+	case d.Get("opaque_network_id").(string) != "":
+		net, err = nsx.OpaqueNetworkFromNetworkID(client, d.Get("opaque_network_id").(string))
+	default:
+		return errors.New("one of name, nsxt_segment_path, or opaque_network_id must be set")
 	}
-	net, err := network.FromPath(client, name, dc)
 	if err != nil {
 		return fmt.Errorf("error fetching network: %s", err)
 	}
 
 	d.SetId(net.Reference().Value)
 	d.Set("type", net.Reference().Type)
+
+	onet, ok := net.(*object.OpaqueNetwork)
+	if !ok {
+		return nil
+	}
+	props, err := nsx.OpaqueNetworkProperties(onet)
+	if err != nil {
+		return fmt.Errorf("error fetching opaque network properties: %s", err)
+	}
+	summary := props.Summary.(*types.OpaqueNetworkSummary)
+	d.Set("opaque_network_id", summary.OpaqueNetworkId)
+	d.Set("nsxt_segment_path", nsx.SegmentPath(props))
+	if summary.OpaqueNetworkType == opaqueNetworkTypeNsxLogicalSwitch {
+		d.Set("logical_switch_uuid", summary.OpaqueNetworkId)
+	}
 	return nil
 }
+
+// dataSourceVSphereNetworkFromPath looks up the network by the name or path
+// and the optional datacenter in the data source.
+func dataSourceVSphereNetworkFromPath(d *schema.ResourceData, meta interface{}) (object.NetworkReference, error) {
+	client := meta.(*VSphereClient).vimClient
+	var dc *object.Datacenter
+	if dcID, ok := d.GetOk("datacenter_id"); ok {
+		var err error
+		dc, err = datacenterFromID(client, dcID.(string))
+		if err != nil {
+			return nil, fmt.Errorf("cannot locate datacenter: %s", err)
+		}
+	}
+	return network.FromPath(client, d.Get("name").(string), dc)
+}
@@ -94,6 +94,38 @@ func TestAccDataSourceVSphereNetwork_absolutePathEndingInSameName(t *testing.T)
 	})
 }
 
+func TestAccDataSourceVSphereNetwork_nsxtSegment(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccSkipIfEsxi(t)
+			if os.Getenv("VSPHERE_NSXT_SEGMENT_PATH") == "" {
+				t.Skip("set VSPHERE_NSXT_SEGMENT_PATH to run vsphere_network NSX-T acceptance tests")
+			}
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceVSphereNetworkConfigNsxtSegment(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.vsphere_network.segment", "type", "OpaqueNetwork"),
+					resource.TestCheckResourceAttr("data.vsphere_network.segment", "nsxt_segment_path", os.Getenv("VSPHERE_NSXT_SEGMENT_PATH")),
+					resource.TestCheckResourceAttrSet("data.vsphere_network.segment", "logical_switch_uuid"),
+					resource.TestCheckResourceAttrPair(
+						"data.vsphere_network.segment", "logical_switch_uuid",
+						"data.vsphere_network.segment", "opaque_network_id",
+					),
+					resource.TestCheckResourceAttrPair(
+						"data.vsphere_network.opaque", "id",
+						"data.vsphere_network.segment", "id",
+					),
+					resource.TestCheckResourceAttr("data.vsphere_network.opaque", "nsxt_segment_path", os.Getenv("VSPHERE_NSXT_SEGMENT_PATH")),
+				),
+			},
+		},
+	})
+}
+
 func testAccDataSourceVSphereNetworkPreCheck(t *testing.T) {
 	if os.Getenv("VSPHERE_HOST_NIC0") == "" {
 		t.Skip("set VSPHERE_HOST_NIC0 to run vsphere_network acceptance tests")
@@ -288,3 +320,15 @@ data "vsphere_network" "net" {
 		os.Getenv("VSPHERE_HOST_NIC1"),
 	)
 }
+
+func testAccDataSourceVSphereNetworkConfigNsxtSegment() string {
+	return fmt.Sprintf(`
+data "vsphere_network" "segment" {
+  nsxt_segment_path = "%s"
+}
+
+data "vsphere_network" "opaque" {
+  opaque_network_id = "${data.vsphere_network.segment.opaque_network_id}"
+}
+`, os.Getenv("VSPHERE_NSXT_SEGMENT_PATH"))
+}
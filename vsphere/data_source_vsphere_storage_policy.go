@@ -0,0 +1,36 @@
+package vsphere
+
+import (
+	"errors"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/spbm"
+)
+
+func dataSourceVSphereStoragePolicy() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereStoragePolicyRead,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The display name of the storage policy.",
+				Required:    true,
+			},
+		},
+	}
+}
+
+func dataSourceVSphereStoragePolicyRead(d *schema.ResourceData, meta interface{}) error {
+	pc := meta.(*VSphereClient).pbmClient
+	if pc == nil {
+		return errors.New("storage policies require a vCenter connection")
+	}
+
+	id, err := spbm.PolicyIDByName(pc, d.Get("name").(string))
+	if err != nil {
+		return err
+	}
+
+	d.SetId(id)
+	return nil
+}
@@ -8,7 +8,7 @@ import (
 )
 
 func TestAccDataSourceVSphereTagCategory_basic(t *testing.T) {
-	resource.Test(t, resource.TestCase{
+	testAccResourceTest(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 		},
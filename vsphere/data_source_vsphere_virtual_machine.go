@@ -31,6 +31,24 @@ func dataSourceVSphereVirtualMachine() *schema.Resource {
 				Optional:    true,
 				Default:     1,
 			},
+			"sata_controller_scan_count": {
+				Type:        schema.TypeInt,
+				Description: "The number of SATA controllers to scan for disk sizes on.",
+				Optional:    true,
+				Default:     0,
+			},
+			"nvme_controller_scan_count": {
+				Type:        schema.TypeInt,
+				Description: "The number of NVMe controllers to scan for disk sizes on.",
+				Optional:    true,
+				Default:     0,
+			},
+			"ide_controller_scan_count": {
+				Type:        schema.TypeInt,
+				Description: "The number of IDE controllers to scan for disk sizes on.",
+				Optional:    true,
+				Default:     0,
+			},
 			"guest_id": {
 				Type:        schema.TypeString,
 				Description: "The guest ID of the virtual machine.",
@@ -58,7 +76,7 @@ func dataSourceVSphereVirtualMachine() *schema.Resource {
 			},
 			"disks": {
 				Type:        schema.TypeList,
-				Description: "Select configuration attributes from the disks on this virtual machine, sorted by bus and unit number.",
+				Description: "Select configuration attributes from the disks on this virtual machine, sorted by controller type, bus, and unit number.",
 				Computed:    true,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
@@ -74,6 +92,10 @@ func dataSourceVSphereVirtualMachine() *schema.Resource {
 							Type:     schema.TypeBool,
 							Computed: true,
 						},
+						"controller_type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
 					},
 				},
 			},
@@ -124,7 +146,12 @@ func dataSourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{
 	d.Set("scsi_type", virtualdevice.ReadSCSIBusType(object.VirtualDeviceList(props.Config.Hardware.Device), d.Get("scsi_controller_scan_count").(int)))
 	d.Set("scsi_bus_sharing", virtualdevice.ReadSCSIBusSharing(object.VirtualDeviceList(props.Config.Hardware.Device), d.Get("scsi_controller_scan_count").(int)))
 	d.Set("firmware", props.Config.Firmware)
-	disks, err := virtualdevice.ReadDiskAttrsForDataSource(object.VirtualDeviceList(props.Config.Hardware.Device), d.Get("scsi_controller_scan_count").(int))
+	disks, err := virtualdevice.ReadDiskAttrsForDataSource(object.VirtualDeviceList(props.Config.Hardware.Device), map[string]int{
+		virtualdevice.SubresourceControllerTypeSCSI: d.Get("scsi_controller_scan_count").(int),
+		virtualdevice.SubresourceControllerTypeSATA: d.Get("sata_controller_scan_count").(int),
+		virtualdevice.SubresourceControllerTypeNVMe: d.Get("nvme_controller_scan_count").(int),
+		virtualdevice.SubresourceControllerTypeIDE:  d.Get("ide_controller_scan_count").(int),
+	})
 	if err != nil {
 		return fmt.Errorf("error reading disk sizes: %s", err)
 	}
@@ -1,7 +1,13 @@
 package vsphere
 
 import (
+	"fmt"
 	"log"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
@@ -22,6 +28,32 @@ var vmwareUplinkPortTeamingPolicyModeAllowedValues = []string{
 	string(types.DistributedVirtualSwitchNicTeamingPolicyModeLoadbalance_loadbased),
 }
 
+// trafficFilterAgentName is the name of the filter agent that processes the
+// traffic filtering and marking rules of a distributed port.
+const trafficFilterAgentName = "dvfilter-generic-vmware"
+
+const (
+	trafficRuleActionAccept = "accept"
+	trafficRuleActionDrop   = "drop"
+	trafficRuleActionTag    = "tag"
+)
+
+var trafficRuleActionAllowedValues = []string{
+	trafficRuleActionAccept,
+	trafficRuleActionDrop,
+	trafficRuleActionTag,
+}
+
+var trafficRuleDirectionAllowedValues = []string{
+	string(types.DvsNetworkRuleDirectionTypeIncomingPackets),
+	string(types.DvsNetworkRuleDirectionTypeOutgoingPackets),
+	string(types.DvsNetworkRuleDirectionTypeBoth),
+}
+
+// trafficRulePortRegexp matches a single port, or a range of ports in the
+// form start-end.
+var trafficRulePortRegexp = regexp.MustCompile(`^([0-9]+)(?:-([0-9]+))?$`)
+
 // schemaVMwareDVSPortSetting returns schema items for resources that
 // need to work with a VMwareDVSPortSetting.
 func schemaVMwareDVSPortSetting() map[string]*schema.Schema {
@@ -84,7 +116,7 @@ func schemaVMwareDVSPortSetting() map[string]*schema.Schema {
 			Type:        schema.TypeList,
 			Optional:    true,
 			Computed:    true,
-			Description: "List of active uplinks used for load balancing, matching the names of the uplinks assigned in the DVS.",
+			Description: "List of active uplinks used for load balancing, matching the names of the uplinks or LAGs assigned in the DVS.",
 			Elem:        &schema.Schema{Type: schema.TypeString},
 		},
 		"standby_uplinks": {
@@ -151,6 +183,15 @@ func schemaVMwareDVSPortSetting() map[string]*schema.Schema {
 			ValidateFunc: validation.StringInSlice(vmwareUplinkLacpPolicyModeAllowedValues, false),
 		},
 
+		// DvsFilterPolicy/DvsTrafficRuleset
+		"traffic_filter": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			MaxItems:    1,
+			Description: "The traffic filtering and marking rules for the ports this policy applies to.",
+			Elem:        &schema.Resource{Schema: schemaDvsTrafficRuleset()},
+		},
+
 		// DVSTrafficShapingPolicy - ingress
 		"ingress_shaping_average_bandwidth": {
 			Type:        schema.TypeInt,
@@ -554,6 +595,335 @@ func flattenDVSTrafficShapingPolicyEgress(d *schema.ResourceData, obj *types.DVS
 	return nil
 }
 
+// schemaDvsTrafficRuleset returns the schema for the traffic_filter
+// sub-resource of a port setting.
+func schemaDvsTrafficRuleset() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"enabled": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "Whether or not the traffic filtering and marking rules are enabled.",
+		},
+		"rule": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "A traffic filtering or marking rule. Rules are evaluated in the order they are declared.",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"description": {
+						Type:        schema.TypeString,
+						Optional:    true,
+						Description: "The description of the rule.",
+					},
+					"action": {
+						Type:         schema.TypeString,
+						Required:     true,
+						Description:  "The action to take on matching packets. Can be one of accept, drop, or tag.",
+						ValidateFunc: validation.StringInSlice(trafficRuleActionAllowedValues, false),
+					},
+					"direction": {
+						Type:         schema.TypeString,
+						Optional:     true,
+						Default:      string(types.DvsNetworkRuleDirectionTypeBoth),
+						Description:  "The direction of the packets that the rule applies to. Can be one of incomingPackets, outgoingPackets, or both.",
+						ValidateFunc: validation.StringInSlice(trafficRuleDirectionAllowedValues, false),
+					},
+					"dscp_tag": {
+						Type:         schema.TypeInt,
+						Optional:     true,
+						Description:  "The DSCP value to tag matching packets with, when action is tag.",
+						ValidateFunc: validation.IntBetween(0, 63),
+					},
+					"cos_tag": {
+						Type:         schema.TypeInt,
+						Optional:     true,
+						Description:  "The CoS (802.1p) value to tag matching packets with, when action is tag.",
+						ValidateFunc: validation.IntBetween(0, 7),
+					},
+					"ip_qualifier": {
+						Type:        schema.TypeList,
+						Optional:    true,
+						MaxItems:    1,
+						Description: "Matches packets by their IP header.",
+						Elem: &schema.Resource{
+							Schema: map[string]*schema.Schema{
+								"protocol": {
+									Type:         schema.TypeInt,
+									Optional:     true,
+									Description:  "The IP protocol number to match. 0 matches any protocol.",
+									ValidateFunc: validation.IntBetween(0, 255),
+								},
+								"source_address": {
+									Type:         schema.TypeString,
+									Optional:     true,
+									Description:  "The source IP address or CIDR network to match.",
+									ValidateFunc: validateTrafficRuleIPAddress,
+								},
+								"destination_address": {
+									Type:         schema.TypeString,
+									Optional:     true,
+									Description:  "The destination IP address or CIDR network to match.",
+									ValidateFunc: validateTrafficRuleIPAddress,
+								},
+								"source_port": {
+									Type:         schema.TypeString,
+									Optional:     true,
+									Description:  "The source port, or range of ports in the form start-end, to match.",
+									ValidateFunc: validation.StringMatch(trafficRulePortRegexp, "must be a port, or a range of ports in the form start-end"),
+								},
+								"destination_port": {
+									Type:         schema.TypeString,
+									Optional:     true,
+									Description:  "The destination port, or range of ports in the form start-end, to match.",
+									ValidateFunc: validation.StringMatch(trafficRulePortRegexp, "must be a port, or a range of ports in the form start-end"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// validateTrafficRuleIPAddress validates an IP address or CIDR network in a
+// traffic rule qualifier.
+func validateTrafficRuleIPAddress(v interface{}, k string) ([]string, []error) {
+	s := v.(string)
+	if strings.Contains(s, "/") {
+		if _, _, err := net.ParseCIDR(s); err != nil {
+			return nil, []error{fmt.Errorf("%s: %q is not a valid CIDR network", k, s)}
+		}
+		return nil, nil
+	}
+	if net.ParseIP(s) == nil {
+		return nil, []error{fmt.Errorf("%s: %q is not a valid IP address", k, s)}
+	}
+	return nil, nil
+}
+
+// expandTrafficRuleIPAddress returns a SingleIp or IpRange for an IP address
+// or CIDR network string. nil is returned for an empty string.
+func expandTrafficRuleIPAddress(s string) types.BaseIpAddress {
+	if s == "" {
+		return nil
+	}
+	if strings.Contains(s, "/") {
+		ip, ipnet, _ := net.ParseCIDR(s)
+		ones, _ := ipnet.Mask.Size()
+		return &types.IpRange{
+			AddressPrefix: ip.String(),
+			PrefixLength:  int32(ones),
+		}
+	}
+	return &types.SingleIp{Address: s}
+}
+
+// flattenTrafficRuleIPAddress is the flatten counterpart to
+// expandTrafficRuleIPAddress.
+func flattenTrafficRuleIPAddress(obj types.BaseIpAddress) string {
+	switch t := obj.(type) {
+	case *types.SingleIp:
+		return t.Address
+	case *types.IpRange:
+		return fmt.Sprintf("%s/%d", t.AddressPrefix, t.PrefixLength)
+	}
+	return ""
+}
+
+// expandTrafficRuleIPPort returns a DvsSingleIpPort or DvsIpPortRange for a
+// port or port range string. nil is returned for an empty string.
+func expandTrafficRuleIPPort(s string) types.BaseDvsIpPort {
+	m := trafficRulePortRegexp.FindStringSubmatch(s)
+	if m == nil {
+		return nil
+	}
+	start, _ := strconv.Atoi(m[1])
+	if m[2] == "" {
+		return &types.DvsSingleIpPort{PortNumber: int32(start)}
+	}
+	end, _ := strconv.Atoi(m[2])
+	return &types.DvsIpPortRange{
+		StartPortNumber: int32(start),
+		EndPortNumber:   int32(end),
+	}
+}
+
+// flattenTrafficRuleIPPort is the flatten counterpart to
+// expandTrafficRuleIPPort.
+func flattenTrafficRuleIPPort(obj types.BaseDvsIpPort) string {
+	switch t := obj.(type) {
+	case *types.DvsSingleIpPort:
+		return strconv.Itoa(int(t.PortNumber))
+	case *types.DvsIpPortRange:
+		return fmt.Sprintf("%d-%d", t.StartPortNumber, t.EndPortNumber)
+	}
+	return ""
+}
+
+// expandTrafficRuleIntExpression returns an IntExpression for a non-zero
+// value. nil is returned for zero, which matches any value.
+func expandTrafficRuleIntExpression(v int) *types.IntExpression {
+	if v == 0 {
+		return nil
+	}
+	return &types.IntExpression{Value: int32(v)}
+}
+
+// flattenTrafficRuleIntExpression is the flatten counterpart to
+// expandTrafficRuleIntExpression.
+func flattenTrafficRuleIntExpression(obj *types.IntExpression) int {
+	if obj == nil {
+		return 0
+	}
+	return int(obj.Value)
+}
+
+// expandDvsTrafficRule reads certain keys from a list object map and returns
+// a DvsTrafficRule.
+func expandDvsTrafficRule(d map[string]interface{}, sequence int) types.DvsTrafficRule {
+	obj := types.DvsTrafficRule{
+		Description: d["description"].(string),
+		Sequence:    int32(sequence),
+		Direction:   d["direction"].(string),
+	}
+
+	switch d["action"].(string) {
+	case trafficRuleActionAccept:
+		obj.Action = &types.DvsAcceptNetworkRuleAction{}
+	case trafficRuleActionDrop:
+		obj.Action = &types.DvsDropNetworkRuleAction{}
+	case trafficRuleActionTag:
+		obj.Action = &types.DvsUpdateTagNetworkRuleAction{
+			DscpTag: int32(d["dscp_tag"].(int)),
+			QosTag:  int32(d["cos_tag"].(int)),
+		}
+	}
+
+	for _, v := range d["ip_qualifier"].([]interface{}) {
+		q := v.(map[string]interface{})
+		obj.Qualifier = append(obj.Qualifier, &types.DvsIpNetworkRuleQualifier{
+			Protocol:           expandTrafficRuleIntExpression(q["protocol"].(int)),
+			SourceAddress:      expandTrafficRuleIPAddress(q["source_address"].(string)),
+			DestinationAddress: expandTrafficRuleIPAddress(q["destination_address"].(string)),
+			SourceIpPort:       expandTrafficRuleIPPort(q["source_port"].(string)),
+			DestinationIpPort:  expandTrafficRuleIPPort(q["destination_port"].(string)),
+		})
+	}
+	return obj
+}
+
+// flattenDvsTrafficRule reads various fields from a DvsTrafficRule and
+// returns a list object map.
+//
+// This is the flatten counterpart to expandDvsTrafficRule.
+func flattenDvsTrafficRule(obj types.DvsTrafficRule) map[string]interface{} {
+	d := map[string]interface{}{
+		"description": obj.Description,
+		"direction":   obj.Direction,
+	}
+
+	switch t := obj.Action.(type) {
+	case *types.DvsAcceptNetworkRuleAction:
+		d["action"] = trafficRuleActionAccept
+	case *types.DvsDropNetworkRuleAction:
+		d["action"] = trafficRuleActionDrop
+	case *types.DvsUpdateTagNetworkRuleAction:
+		d["action"] = trafficRuleActionTag
+		d["dscp_tag"] = int(t.DscpTag)
+		d["cos_tag"] = int(t.QosTag)
+	default:
+		log.Printf("[WARN] flattenDvsTrafficRule: Unsupported action %T in rule %q", obj.Action, obj.Description)
+	}
+
+	var ipq []interface{}
+	for _, v := range obj.Qualifier {
+		switch q := v.(type) {
+		case *types.DvsIpNetworkRuleQualifier:
+			ipq = append(ipq, map[string]interface{}{
+				"protocol":            flattenTrafficRuleIntExpression(q.Protocol),
+				"source_address":      flattenTrafficRuleIPAddress(q.SourceAddress),
+				"destination_address": flattenTrafficRuleIPAddress(q.DestinationAddress),
+				"source_port":         flattenTrafficRuleIPPort(q.SourceIpPort),
+				"destination_port":    flattenTrafficRuleIPPort(q.DestinationIpPort),
+			})
+		}
+	}
+	d["ip_qualifier"] = ipq
+	return d
+}
+
+// expandDvsFilterPolicy reads the traffic_filter sub-resource and returns a
+// DvsFilterPolicy with the traffic filtering and marking rules for a port
+// setting. nil is returned if traffic_filter is not, and has not been,
+// configured. When traffic_filter is removed from configuration, a disabled
+// ruleset without any rules is returned to clear the rules.
+func expandDvsFilterPolicy(d *schema.ResourceData) *types.DvsFilterPolicy {
+	ruleset := &types.DvsTrafficRuleset{
+		Enabled: structure.BoolPtr(false),
+	}
+	if v := d.Get("traffic_filter").([]interface{}); len(v) > 0 && v[0] != nil {
+		tf := v[0].(map[string]interface{})
+		ruleset.Enabled = structure.BoolPtr(tf["enabled"].(bool))
+		for i, r := range tf["rule"].([]interface{}) {
+			ruleset.Rules = append(ruleset.Rules, expandDvsTrafficRule(r.(map[string]interface{}), (i+1)*10))
+		}
+	} else if o, _ := d.GetChange("traffic_filter"); len(o.([]interface{})) < 1 {
+		return nil
+	}
+
+	return &types.DvsFilterPolicy{
+		InheritablePolicy: types.InheritablePolicy{
+			Inherited: false,
+		},
+		FilterConfig: []types.BaseDvsFilterConfig{
+			&types.DvsTrafficFilterConfig{
+				DvsFilterConfig: types.DvsFilterConfig{
+					AgentName: trafficFilterAgentName,
+				},
+				TrafficRuleset: ruleset,
+			},
+		},
+	}
+}
+
+// flattenDvsFilterPolicy reads the traffic filtering and marking rules from a
+// DvsFilterPolicy into the traffic_filter sub-resource. Rules that are
+// inherited are not tracked, and neither is a disabled ruleset without any
+// rules, which is what is left after traffic_filter has been removed.
+func flattenDvsFilterPolicy(d *schema.ResourceData, obj *types.DvsFilterPolicy) error {
+	var result []interface{}
+	if obj != nil && !obj.Inherited {
+		for _, v := range obj.FilterConfig {
+			tfc, ok := v.(*types.DvsTrafficFilterConfig)
+			if !ok || tfc.TrafficRuleset == nil {
+				continue
+			}
+			ruleset := tfc.TrafficRuleset
+			enabled := ruleset.Enabled != nil && *ruleset.Enabled
+			if !enabled && len(ruleset.Rules) < 1 {
+				continue
+			}
+			rules := make([]types.DvsTrafficRule, len(ruleset.Rules))
+			copy(rules, ruleset.Rules)
+			sort.SliceStable(rules, func(i, j int) bool {
+				return rules[i].Sequence < rules[j].Sequence
+			})
+			var rl []interface{}
+			for _, rule := range rules {
+				rl = append(rl, flattenDvsTrafficRule(rule))
+			}
+			result = append(result, map[string]interface{}{
+				"enabled": enabled,
+				"rule":    rl,
+			})
+			break
+		}
+	}
+	return d.Set("traffic_filter", result)
+}
+
 // expandVMwareDVSPortSetting reads certain ResourceData keys and
 // returns a VMwareDVSPortSetting.
 func expandVMwareDVSPortSetting(d *schema.ResourceData) *types.VMwareDVSPortSetting {
@@ -563,6 +933,7 @@ func expandVMwareDVSPortSetting(d *schema.ResourceData) *types.VMwareDVSPortSett
 			InShapingPolicy:         expandDVSTrafficShapingPolicyIngress(d),
 			OutShapingPolicy:        expandDVSTrafficShapingPolicyEgress(d),
 			VmDirectPathGen2Allowed: structure.GetBoolPolicy(d, "directpath_gen2_allowed"),
+			FilterPolicy:            expandDvsFilterPolicy(d),
 		},
 		Vlan:                expandBaseVmwareDistributedVirtualSwitchVlanSpec(d),
 		UplinkTeamingPolicy: expandVmwareUplinkPortTeamingPolicy(d),
@@ -608,5 +979,34 @@ func flattenVMwareDVSPortSetting(d *schema.ResourceData, obj *types.VMwareDVSPor
 	if err := flattenVMwareUplinkLacpPolicy(d, obj.LacpPolicy); err != nil {
 		return err
 	}
+	if err := flattenDvsFilterPolicy(d, obj.FilterPolicy); err != nil {
+		return err
+	}
 	return nil
 }
+
+// inheritedVMwareDVSPortSetting returns a VMwareDVSPortSetting with all of
+// the policies that can be managed by expandVMwareDVSPortSetting set to be
+// inherited. This is used to reset the overrides on an individual port.
+func inheritedVMwareDVSPortSetting() *types.VMwareDVSPortSetting {
+	inherited := types.InheritablePolicy{Inherited: true}
+	return &types.VMwareDVSPortSetting{
+		DVPortSetting: types.DVPortSetting{
+			Blocked:                 &types.BoolPolicy{InheritablePolicy: inherited},
+			InShapingPolicy:         &types.DVSTrafficShapingPolicy{InheritablePolicy: inherited},
+			OutShapingPolicy:        &types.DVSTrafficShapingPolicy{InheritablePolicy: inherited},
+			VmDirectPathGen2Allowed: &types.BoolPolicy{InheritablePolicy: inherited},
+			FilterPolicy:            &types.DvsFilterPolicy{InheritablePolicy: inherited},
+		},
+		Vlan: &types.VmwareDistributedVirtualSwitchVlanIdSpec{
+			VmwareDistributedVirtualSwitchVlanSpec: types.VmwareDistributedVirtualSwitchVlanSpec{
+				InheritablePolicy: inherited,
+			},
+		},
+		UplinkTeamingPolicy: &types.VmwareUplinkPortTeamingPolicy{InheritablePolicy: inherited},
+		SecurityPolicy:      &types.DVSSecurityPolicy{InheritablePolicy: inherited},
+		IpfixEnabled:        &types.BoolPolicy{InheritablePolicy: inherited},
+		TxUplink:            &types.BoolPolicy{InheritablePolicy: inherited},
+		LacpPolicy:          &types.VMwareUplinkLacpPolicy{InheritablePolicy: inherited},
+	}
+}
@@ -2,9 +2,16 @@ package vsphere
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"strings"
 
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/dvportgroup"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/network"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
@@ -144,3 +151,245 @@ func enableDVSNetworkResourceManagement(client *govmomi.Client, dvs *object.Vmwa
 
 	return nil
 }
+
+// reconfigureDVSVmVnicNetworkResourcePool exposes the
+// DvsReconfigureVmVnicNetworkResourcePool_Task method of the
+// DistributedVirtualSwitch MO, which adds, edits, and removes the network
+// resource pools of a DVS with network I/O control version 3.
+func reconfigureDVSVmVnicNetworkResourcePool(client *govmomi.Client, dvs *object.VmwareDistributedVirtualSwitch, spec []types.DvsVmVnicResourcePoolConfigSpec) error {
+	req := &types.DvsReconfigureVmVnicNetworkResourcePool_Task{
+		This:       dvs.Reference(),
+		ConfigSpec: spec,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	resp, err := methods.DvsReconfigureVmVnicNetworkResourcePool_Task(ctx, client, req)
+	if err != nil {
+		return err
+	}
+	task := object.NewTask(client.Client, resp.Returnval)
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	return task.Wait(tctx)
+}
+
+// updateDVSLacpGroupConfig exposes the UpdateDVSLacpGroupConfig_Task method
+// of the VmwareDistributedVirtualSwitch MO, which adds, edits, and removes the
+// link aggregation groups of a DVS.
+func updateDVSLacpGroupConfig(client *govmomi.Client, dvs *object.VmwareDistributedVirtualSwitch, spec []types.VMwareDvsLacpGroupSpec) error {
+	req := &types.UpdateDVSLacpGroupConfig_Task{
+		This:          dvs.Reference(),
+		LacpGroupSpec: spec,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	resp, err := methods.UpdateDVSLacpGroupConfig_Task(ctx, client, req)
+	if err != nil {
+		return err
+	}
+	task := object.NewTask(client.Client, resp.Returnval)
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	return task.Wait(tctx)
+}
+
+// dvsPortFromKey fetches a single port from a DVS by its key. nil is returned
+// if the port cannot be found.
+func dvsPortFromKey(dvs *object.VmwareDistributedVirtualSwitch, key string) (*types.DistributedVirtualPort, error) {
+	criteria := &types.DistributedVirtualSwitchPortCriteria{
+		PortKey: []string{key},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	ports, err := dvs.FetchDVPorts(ctx, criteria)
+	if err != nil {
+		return nil, err
+	}
+	for _, port := range ports {
+		if port.Key == key {
+			port := port
+			return &port, nil
+		}
+	}
+	return nil, nil
+}
+
+// reconfigureDVSPort exposes the ReconfigureDVPort_Task method of the
+// DistributedVirtualSwitch MO, which edits the settings of individual ports
+// on a DVS.
+func reconfigureDVSPort(client *govmomi.Client, dvs *object.VmwareDistributedVirtualSwitch, spec []types.DVPortConfigSpec) error {
+	req := &types.ReconfigureDVPort_Task{
+		This: dvs.Reference(),
+		Port: spec,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	resp, err := methods.ReconfigureDVPort_Task(ctx, client, req)
+	if err != nil {
+		return err
+	}
+	task := object.NewTask(client.Client, resp.Returnval)
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	return task.Wait(tctx)
+}
+
+// dvsPortgroupFromName locates a port group on a DVS by name.
+func dvsPortgroupFromName(client *govmomi.Client, dvs *object.VmwareDistributedVirtualSwitch, name string) (*object.DistributedVirtualPortgroup, error) {
+	props, err := dvsProperties(dvs)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching DVS properties: %s", err)
+	}
+	for _, ref := range props.Portgroup {
+		pg, err := dvportgroup.FromMOID(client, ref.Value)
+		if err != nil {
+			return nil, fmt.Errorf("error locating port group %q: %s", ref.Value, err)
+		}
+		pgProps, err := dvportgroup.Properties(pg)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching port group properties: %s", err)
+		}
+		if pgProps.Config.Name == name {
+			return pg, nil
+		}
+	}
+	return nil, fmt.Errorf("no port group named %q found on DVS %q", name, props.Name)
+}
+
+// dvsEntryFlattenID makes an ID for a resource that manages an entry in the
+// configuration of a DVS, such as a network resource pool or a port
+// mirroring session. The ID is made up of the managed object ID of the DVS
+// and the key of the entry.
+func dvsEntryFlattenID(dvs *object.VmwareDistributedVirtualSwitch, key string) string {
+	return strings.Join([]string{dvs.Reference().Value, key}, ":")
+}
+
+// dvsEntryParseID parses an ID made by dvsEntryFlattenID and outputs its
+// parts.
+func dvsEntryParseID(id string) (string, string, error) {
+	parts := strings.SplitN(id, ":", 2)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("bad ID %q", id)
+	}
+	return parts[0], parts[1], nil
+}
+
+// dvsEntryObjects handles the fetching of the DVS and the entry key from the
+// ID of a resource that manages an entry in the configuration of a DVS.
+func dvsEntryObjects(d *schema.ResourceData, meta interface{}) (*object.VmwareDistributedVirtualSwitch, string, error) {
+	client := meta.(*VSphereClient).vimClient
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return nil, "", err
+	}
+	dvsID, key, err := dvsEntryParseID(d.Id())
+	if err != nil {
+		return nil, "", err
+	}
+	dvs, err := dvsFromMOID(client, dvsID)
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot locate distributed virtual switch: %s", err)
+	}
+	return dvs, key, nil
+}
+
+// dvsEntryImport parses the import ID of a resource that manages an entry in
+// the configuration of a DVS. The import ID is a JSON object with the path
+// of the DVS in distributed_virtual_switch_path and the name of the entry in
+// name. The DVS and the name of the entry are returned.
+func dvsEntryImport(d *schema.ResourceData, meta interface{}) (*object.VmwareDistributedVirtualSwitch, string, error) {
+	var data map[string]string
+	if err := json.Unmarshal([]byte(d.Id()), &data); err != nil {
+		return nil, "", err
+	}
+	dvsPath, ok := data["distributed_virtual_switch_path"]
+	if !ok {
+		return nil, "", errors.New("missing distributed_virtual_switch_path in input data")
+	}
+	name, ok := data["name"]
+	if !ok {
+		return nil, "", errors.New("missing name in input data")
+	}
+
+	client := meta.(*VSphereClient).vimClient
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return nil, "", err
+	}
+	dvs, err := dvsFromPath(client, dvsPath, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot locate distributed virtual switch %q: %s", dvsPath, err)
+	}
+	return dvs, name, nil
+}
+
+// dvsNetworkResourcePoolFromKey locates a network resource pool on a DVS by
+// key. nil is returned if the pool cannot be found.
+func dvsNetworkResourcePoolFromKey(dvs *object.VmwareDistributedVirtualSwitch, key string) (*types.DVSVmVnicNetworkResourcePool, error) {
+	props, err := dvsProperties(dvs)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching DVS properties: %s", err)
+	}
+	for _, pool := range props.Config.GetDVSConfigInfo().VmVnicNetworkResourcePool {
+		if pool.Key == key {
+			log.Printf("[DEBUG] Found network resource pool %q on DVS %q", key, dvs.Name())
+			pool := pool
+			return &pool, nil
+		}
+	}
+	log.Printf("[DEBUG] No network resource pool %q found on DVS %q", key, dvs.Name())
+	return nil, nil
+}
+
+// dvsNetworkResourcePoolFromName locates a network resource pool on a DVS by
+// name. Unlike dvsNetworkResourcePoolFromKey, it is an error if the pool is
+// not found.
+func dvsNetworkResourcePoolFromName(dvs *object.VmwareDistributedVirtualSwitch, name string) (*types.DVSVmVnicNetworkResourcePool, error) {
+	props, err := dvsProperties(dvs)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching DVS properties: %s", err)
+	}
+	for _, pool := range props.Config.GetDVSConfigInfo().VmVnicNetworkResourcePool {
+		if pool.Name == name {
+			pool := pool
+			return &pool, nil
+		}
+	}
+	return nil, fmt.Errorf("no network resource pool named %q found on DVS %q", name, dvs.Name())
+}
+
+// dvsVspanSessionFromKey locates a port mirroring session on a DVS by key.
+// nil is returned if the session cannot be found.
+func dvsVspanSessionFromKey(dvs *object.VmwareDistributedVirtualSwitch, key string) (*types.VMwareVspanSession, error) {
+	props, err := dvsProperties(dvs)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching DVS properties: %s", err)
+	}
+	for _, session := range props.Config.(*types.VMwareDVSConfigInfo).VspanSession {
+		if session.Key == key {
+			log.Printf("[DEBUG] Found port mirroring session %q on DVS %q", key, dvs.Name())
+			session := session
+			return &session, nil
+		}
+	}
+	log.Printf("[DEBUG] No port mirroring session %q found on DVS %q", key, dvs.Name())
+	return nil, nil
+}
+
+// dvsVspanSessionFromName locates a port mirroring session on a DVS by name.
+// Unlike dvsVspanSessionFromKey, it is an error if the session is not found.
+func dvsVspanSessionFromName(dvs *object.VmwareDistributedVirtualSwitch, name string) (*types.VMwareVspanSession, error) {
+	props, err := dvsProperties(dvs)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching DVS properties: %s", err)
+	}
+	for _, session := range props.Config.(*types.VMwareDVSConfigInfo).VspanSession {
+		if session.Name == name {
+			session := session
+			return &session, nil
+		}
+	}
+	return nil, fmt.Errorf("no port mirroring session named %q found on DVS %q", name, dvs.Name())
+}
@@ -2,6 +2,8 @@ package vsphere
 
 import (
 	"fmt"
+	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
@@ -15,6 +17,29 @@ var lacpAPIVersionAllowedValues = []string{
 	string(types.VMwareDvsLacpApiVersionMultipleLag),
 }
 
+var lacpGroupLoadBalanceAlgorithmAllowedValues = []string{
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmSrcMac),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmDestMac),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmSrcDestMac),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmDestIpVlan),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmSrcIpVlan),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmSrcDestIpVlan),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmDestTcpUdpPort),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmSrcTcpUdpPort),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmSrcDestTcpUdpPort),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmDestIpTcpUdpPort),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmSrcIpTcpUdpPort),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmSrcDestIpTcpUdpPort),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmDestIpTcpUdpPortVlan),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmSrcIpTcpUdpPortVlan),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmSrcDestIpTcpUdpPortVlan),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmDestIp),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmSrcIp),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmSrcDestIp),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmVlan),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmSrcPortId),
+}
+
 var multicastFilteringModeAllowedValues = []string{
 	string(types.VMwareDvsMulticastFilteringModeLegacyFiltering),
 	string(types.VMwareDvsMulticastFilteringModeSnooping),
@@ -87,6 +112,104 @@ func schemaVMwareDVSConfigSpec() map[string]*schema.Schema {
 						Description:  "The managed object ID of the host this specification applies to.",
 						ValidateFunc: validation.NoZeroValues,
 					},
+					"migrate_vmknics": {
+						Type:        schema.TypeList,
+						Optional:    true,
+						Description: "Virtual NICs of the host to migrate to this DVS together with the physical NICs in devices.",
+						Elem: &schema.Resource{
+							Schema: map[string]*schema.Schema{
+								"device": {
+									Type:         schema.TypeString,
+									Required:     true,
+									Description:  "The name of the virtual NIC to migrate, such as vmk0.",
+									ValidateFunc: validation.NoZeroValues,
+								},
+								"portgroup_name": {
+									Type:         schema.TypeString,
+									Required:     true,
+									Description:  "The name of the distributed port group on this DVS to migrate the virtual NIC to.",
+									ValidateFunc: validation.NoZeroValues,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+
+		// VMwareDVSPvlanConfigSpec
+		"pvlan_mapping": {
+			Type:        schema.TypeSet,
+			Optional:    true,
+			Description: "A private VLAN (PVLAN) mapping.",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"primary_vlan_id": {
+						Type:         schema.TypeInt,
+						Required:     true,
+						Description:  "The primary VLAN ID. The VLAN IDs of 0 and 4095 are reserved and cannot be used in this property.",
+						ValidateFunc: validation.IntBetween(1, 4094),
+					},
+					"secondary_vlan_id": {
+						Type:         schema.TypeInt,
+						Required:     true,
+						Description:  "The secondary VLAN ID. The VLAN IDs of 0 and 4095 are reserved and cannot be used in this property.",
+						ValidateFunc: validation.IntBetween(1, 4094),
+					},
+					"pvlan_type": {
+						Type:         schema.TypeString,
+						Required:     true,
+						Description:  "The private VLAN type. Valid values are promiscuous, community and isolated.",
+						ValidateFunc: validation.StringInSlice(privateVLANTypeAllowedValues, false),
+					},
+				},
+			},
+		},
+
+		// VMwareDvsLacpGroupConfig
+		"lacp_group": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "A link aggregation group (LAG). Requires lacp_api_version to be multipleLag.",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"name": {
+						Type:         schema.TypeString,
+						Required:     true,
+						Description:  "The name of the LAG. This can be used in active_uplinks and standby_uplinks to use the LAG for uplink teaming.",
+						ValidateFunc: validation.NoZeroValues,
+					},
+					"uplink_count": {
+						Type:         schema.TypeInt,
+						Required:     true,
+						Description:  "The number of uplink ports in the LAG.",
+						ValidateFunc: validation.IntBetween(1, 32),
+					},
+					"mode": {
+						Type:         schema.TypeString,
+						Optional:     true,
+						Default:      string(types.VMwareUplinkLacpModePassive),
+						Description:  "The LACP mode of the LAG. Valid values are active and passive.",
+						ValidateFunc: validation.StringInSlice(vmwareUplinkLacpPolicyModeAllowedValues, false),
+					},
+					"load_balancing_algorithm": {
+						Type:         schema.TypeString,
+						Optional:     true,
+						Default:      string(types.VMwareDvsLacpLoadBalanceAlgorithmSrcDestIpTcpUdpPortVlan),
+						Description:  "The load balancing algorithm of the LAG.",
+						ValidateFunc: validation.StringInSlice(lacpGroupLoadBalanceAlgorithmAllowedValues, false),
+					},
+					"key": {
+						Type:        schema.TypeString,
+						Computed:    true,
+						Description: "The generated key of the LAG.",
+					},
+					"uplinks": {
+						Type:        schema.TypeList,
+						Computed:    true,
+						Description: "The names of the uplink ports in the LAG.",
+						Elem:        &schema.Schema{Type: schema.TypeString},
+					},
 				},
 			},
 		},
@@ -333,6 +456,19 @@ func expandSliceOfDistributedVirtualSwitchHostMemberConfigSpec(d *schema.Resourc
 		} else {
 			spec.Operation = string(types.ConfigSpecOperationEdit)
 		}
+		// Physical NICs of hosts with virtual NICs to migrate are moved
+		// together with the virtual NICs after this update, so they are left
+		// as they were here.
+		if len(nm["migrate_vmknics"].([]interface{})) > 0 {
+			backing := &types.DistributedVirtualSwitchHostMemberPnicBacking{}
+			for _, oe := range os.List() {
+				om := oe.(map[string]interface{})
+				if om["host_system_id"] == nm["host_system_id"] {
+					backing = expandDistributedVirtualSwitchHostMemberConfigSpec(om).Backing.(*types.DistributedVirtualSwitchHostMemberPnicBacking)
+				}
+			}
+			spec.Backing = backing
+		}
 		specs = append(specs, spec)
 	}
 
@@ -340,15 +476,71 @@ func expandSliceOfDistributedVirtualSwitchHostMemberConfigSpec(d *schema.Resourc
 	return specs
 }
 
+// dvsHostNetworkMigration describes the physical and virtual NICs of a host
+// that are migrated to a DVS together.
+type dvsHostNetworkMigration struct {
+	// The managed object ID of the host.
+	HostSystemID string
+
+	// The physical NICs that the host should have on the DVS.
+	Devices []string
+
+	// The virtual NICs to migrate, mapped to the names of the distributed port
+	// groups that they are migrated to.
+	Vmknics map[string]string
+}
+
+// expandSliceOfDVSHostNetworkMigration returns the hosts that have been
+// added or changed in the host set and have virtual NICs to migrate. It takes
+// either a ResourceData or a ResourceDiff.
+func expandSliceOfDVSHostNetworkMigration(d interface {
+	GetChange(string) (interface{}, interface{})
+}) []dvsHostNetworkMigration {
+	var migrations []dvsHostNetworkMigration
+	o, n := d.GetChange("host")
+	ns := n.(*schema.Set).Difference(o.(*schema.Set))
+	for _, ne := range ns.List() {
+		nm := ne.(map[string]interface{})
+		vmknics := make(map[string]string)
+		for _, v := range nm["migrate_vmknics"].([]interface{}) {
+			vm := v.(map[string]interface{})
+			vmknics[vm["device"].(string)] = vm["portgroup_name"].(string)
+		}
+		if len(vmknics) < 1 {
+			continue
+		}
+		migrations = append(migrations, dvsHostNetworkMigration{
+			HostSystemID: nm["host_system_id"].(string),
+			Devices:      structure.SliceInterfacesToStrings(nm["devices"].([]interface{})),
+			Vmknics:      vmknics,
+		})
+	}
+	return migrations
+}
+
 // flattenSliceOfDistributedVirtualSwitchHostMember creates a set of all host
 // entries for a supplied slice of DistributedVirtualSwitchHostMember.
 //
 // This is the flatten counterpart to
 // expandSliceOfDistributedVirtualSwitchHostMemberConfigSpec.
 func flattenSliceOfDistributedVirtualSwitchHostMember(d *schema.ResourceData, members []types.DistributedVirtualSwitchHostMember) error {
+	// The virtual NIC migrations are not part of the host member
+	// configuration, so they are carried over from the existing state.
+	migrations := make(map[string]interface{})
+	if v, ok := d.Get("host").(*schema.Set); ok {
+		for _, e := range v.List() {
+			m := e.(map[string]interface{})
+			migrations[m["host_system_id"].(string)] = m["migrate_vmknics"]
+		}
+	}
+
 	var hosts []map[string]interface{}
 	for _, m := range members {
-		hosts = append(hosts, flattenDistributedVirtualSwitchHostMember(m))
+		host := flattenDistributedVirtualSwitchHostMember(m)
+		if v, ok := migrations[m.Config.Host.Value]; ok {
+			host["migrate_vmknics"] = v
+		}
+		hosts = append(hosts, host)
 	}
 	if err := d.Set("host", hosts); err != nil {
 		return err
@@ -356,6 +548,206 @@ func flattenSliceOfDistributedVirtualSwitchHostMember(d *schema.ResourceData, me
 	return nil
 }
 
+// expandVMwareDVSPvlanMapEntry reads certain keys from a Set object map and
+// returns a VMwareDVSPvlanMapEntry.
+func expandVMwareDVSPvlanMapEntry(d map[string]interface{}) types.VMwareDVSPvlanMapEntry {
+	obj := types.VMwareDVSPvlanMapEntry{
+		PrimaryVlanId:   int32(d["primary_vlan_id"].(int)),
+		SecondaryVlanId: int32(d["secondary_vlan_id"].(int)),
+		PvlanType:       d["pvlan_type"].(string),
+	}
+	return obj
+}
+
+// flattenVMwareDVSPvlanMapEntry reads various fields from a
+// VMwareDVSPvlanMapEntry and returns a Set object map.
+//
+// This is the flatten counterpart to expandVMwareDVSPvlanMapEntry.
+func flattenVMwareDVSPvlanMapEntry(obj types.VMwareDVSPvlanMapEntry) map[string]interface{} {
+	d := make(map[string]interface{})
+	d["primary_vlan_id"] = obj.PrimaryVlanId
+	d["secondary_vlan_id"] = obj.SecondaryVlanId
+	d["pvlan_type"] = obj.PvlanType
+	return d
+}
+
+// expandSliceOfVMwareDVSPvlanConfigSpec expands all PVLAN mapping entries for
+// a VMware DVS, detecting if an entry needs to be added or removed. There is
+// no edit operation for PVLAN entries, so a changed entry is removed and
+// re-added.
+//
+// Secondary PVLANs can only be added once their primary (promiscuous) PVLAN
+// exists, and a primary PVLAN can only be removed after its secondary PVLANs.
+// Removals are processed before additions, with entries ordered accordingly.
+func expandSliceOfVMwareDVSPvlanConfigSpec(d *schema.ResourceData) []types.VMwareDVSPvlanConfigSpec {
+	var specs []types.VMwareDVSPvlanConfigSpec
+	o, n := d.GetChange("pvlan_mapping")
+	os := o.(*schema.Set)
+	ns := n.(*schema.Set)
+
+	// Entries that are in both sets have not changed, so we don't bother with
+	// them.
+	is := os.Intersection(ns)
+	os = os.Difference(is)
+	ns = ns.Difference(is)
+
+	isPromiscuous := func(e types.VMwareDVSPvlanMapEntry) bool {
+		return e.PvlanType == string(types.VmwareDistributedVirtualSwitchPvlanPortTypePromiscuous)
+	}
+
+	for _, promiscuous := range []bool{false, true} {
+		for _, oe := range os.List() {
+			entry := expandVMwareDVSPvlanMapEntry(oe.(map[string]interface{}))
+			if isPromiscuous(entry) != promiscuous {
+				continue
+			}
+			specs = append(specs, types.VMwareDVSPvlanConfigSpec{
+				PvlanEntry: entry,
+				Operation:  string(types.ConfigSpecOperationRemove),
+			})
+		}
+	}
+	for _, promiscuous := range []bool{true, false} {
+		for _, ne := range ns.List() {
+			entry := expandVMwareDVSPvlanMapEntry(ne.(map[string]interface{}))
+			if isPromiscuous(entry) != promiscuous {
+				continue
+			}
+			specs = append(specs, types.VMwareDVSPvlanConfigSpec{
+				PvlanEntry: entry,
+				Operation:  string(types.ConfigSpecOperationAdd),
+			})
+		}
+	}
+
+	return specs
+}
+
+// flattenSliceOfVMwareDVSPvlanMapEntry creates a set of all PVLAN mapping
+// entries for a supplied slice of VMwareDVSPvlanMapEntry.
+//
+// This is the flatten counterpart to expandSliceOfVMwareDVSPvlanConfigSpec.
+func flattenSliceOfVMwareDVSPvlanMapEntry(d *schema.ResourceData, entries []types.VMwareDVSPvlanMapEntry) error {
+	var pvlans []map[string]interface{}
+	for _, e := range entries {
+		pvlans = append(pvlans, flattenVMwareDVSPvlanMapEntry(e))
+	}
+	if err := d.Set("pvlan_mapping", pvlans); err != nil {
+		return err
+	}
+	return nil
+}
+
+// expandVMwareDvsLacpGroupConfig reads certain keys from a list object map
+// and returns a VMwareDvsLacpGroupConfig.
+func expandVMwareDvsLacpGroupConfig(d map[string]interface{}) types.VMwareDvsLacpGroupConfig {
+	obj := types.VMwareDvsLacpGroupConfig{
+		Name:                 d["name"].(string),
+		UplinkNum:            int32(d["uplink_count"].(int)),
+		Mode:                 d["mode"].(string),
+		LoadbalanceAlgorithm: d["load_balancing_algorithm"].(string),
+	}
+	return obj
+}
+
+// flattenVMwareDvsLacpGroupConfig reads various fields from a
+// VMwareDvsLacpGroupConfig and returns a list object map.
+//
+// This is the flatten counterpart to expandVMwareDvsLacpGroupConfig.
+func flattenVMwareDvsLacpGroupConfig(obj types.VMwareDvsLacpGroupConfig) map[string]interface{} {
+	d := make(map[string]interface{})
+	d["name"] = obj.Name
+	d["uplink_count"] = int(obj.UplinkNum)
+	d["mode"] = obj.Mode
+	d["load_balancing_algorithm"] = obj.LoadbalanceAlgorithm
+	d["key"] = obj.Key
+	d["uplinks"] = obj.UplinkName
+	return d
+}
+
+// expandSliceOfVMwareDvsLacpGroupSpec expands all LAG entries for a VMware
+// DVS, matching old and new entries by name to detect if a LAG needs to be
+// added, edited, or removed.
+//
+// LAGs are managed outside of the DVS configuration spec, and need to exist
+// before they can be referenced in the uplink teaming policy of the DVS, and
+// be unreferenced before they can be removed. As such, additions and edits
+// are returned separately from removals, so that they can be processed before
+// and after the DVS configuration update, respectively.
+func expandSliceOfVMwareDvsLacpGroupSpec(d *schema.ResourceData) ([]types.VMwareDvsLacpGroupSpec, []types.VMwareDvsLacpGroupSpec) {
+	var addEditSpecs, removeSpecs []types.VMwareDvsLacpGroupSpec
+	o, n := d.GetChange("lacp_group")
+
+	old := make(map[string]map[string]interface{})
+	for _, v := range o.([]interface{}) {
+		m := v.(map[string]interface{})
+		old[m["name"].(string)] = m
+	}
+
+	for _, v := range n.([]interface{}) {
+		nm := v.(map[string]interface{})
+		config := expandVMwareDvsLacpGroupConfig(nm)
+		om, ok := old[config.Name]
+		if !ok {
+			addEditSpecs = append(addEditSpecs, types.VMwareDvsLacpGroupSpec{
+				LacpGroupConfig: config,
+				Operation:       string(types.ConfigSpecOperationAdd),
+			})
+			continue
+		}
+		delete(old, config.Name)
+		if reflect.DeepEqual(expandVMwareDvsLacpGroupConfig(om), config) {
+			continue
+		}
+		config.Key = om["key"].(string)
+		addEditSpecs = append(addEditSpecs, types.VMwareDvsLacpGroupSpec{
+			LacpGroupConfig: config,
+			Operation:       string(types.ConfigSpecOperationEdit),
+		})
+	}
+
+	for _, om := range old {
+		removeSpecs = append(removeSpecs, types.VMwareDvsLacpGroupSpec{
+			LacpGroupConfig: types.VMwareDvsLacpGroupConfig{
+				Key: om["key"].(string),
+			},
+			Operation: string(types.ConfigSpecOperationRemove),
+		})
+	}
+
+	return addEditSpecs, removeSpecs
+}
+
+// flattenSliceOfVMwareDvsLacpGroupConfig creates a list of all LAGs for a
+// supplied slice of VMwareDvsLacpGroupConfig. LAGs are returned in the order
+// they appear in configuration, followed by any LAGs that are not in
+// configuration.
+//
+// This is the flatten counterpart to expandSliceOfVMwareDvsLacpGroupSpec.
+func flattenSliceOfVMwareDvsLacpGroupConfig(d *schema.ResourceData, groups []types.VMwareDvsLacpGroupConfig) error {
+	order := make(map[string]int)
+	for i, v := range d.Get("lacp_group").([]interface{}) {
+		order[v.(map[string]interface{})["name"].(string)] = i
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		oi, iok := order[groups[i].Name]
+		oj, jok := order[groups[j].Name]
+		if iok && jok {
+			return oi < oj
+		}
+		return iok && !jok
+	})
+
+	var lags []map[string]interface{}
+	for _, g := range groups {
+		lags = append(lags, flattenVMwareDvsLacpGroupConfig(g))
+	}
+	if err := d.Set("lacp_group", lags); err != nil {
+		return err
+	}
+	return nil
+}
+
 // expandVMwareIpfixConfig reads certain ResourceData keys and
 // returns a VMwareIpfixConfig.
 func expandVMwareIpfixConfig(d *schema.ResourceData) *types.VMwareIpfixConfig {
@@ -551,6 +943,7 @@ func expandVMwareDVSConfigSpec(d *schema.ResourceData) *types.VMwareDVSConfigSpe
 		IpfixConfig:                 expandVMwareIpfixConfig(d),
 		LacpApiVersion:              d.Get("lacp_api_version").(string),
 		MulticastFilteringMode:      d.Get("multicast_filtering_mode").(string),
+		PvlanConfigSpec:             expandSliceOfVMwareDVSPvlanConfigSpec(d),
 	}
 	return obj
 }
@@ -587,6 +980,12 @@ func flattenVMwareDVSConfigInfo(d *schema.ResourceData, obj *types.VMwareDVSConf
 	if err := flattenSliceOfDistributedVirtualSwitchHostMember(d, obj.Host); err != nil {
 		return err
 	}
+	if err := flattenSliceOfVMwareDVSPvlanMapEntry(d, obj.PvlanConfig); err != nil {
+		return err
+	}
+	if err := flattenSliceOfVMwareDvsLacpGroupConfig(d, obj.LacpGroupConfig); err != nil {
+		return err
+	}
 	if err := flattenSliceOfDvsHostInfrastructureTrafficResource(d, obj.InfrastructureTrafficResourceConfig); err != nil {
 		return err
 	}
@@ -15,6 +15,7 @@ import (
 	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/clustercomputeresource"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/cryptomanager"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/datastore"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/dvportgroup"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/folder"
@@ -654,6 +655,78 @@ func testGetDVPortgroupProperties(s *terraform.State, resourceName string) (*mo.
 	return dvportgroup.Properties(dvs)
 }
 
+// testGetDistributedNetworkResourcePool is a convenience method to fetch a
+// network resource pool by resource name.
+func testGetDistributedNetworkResourcePool(s *terraform.State, resourceName string) (*types.DVSVmVnicNetworkResourcePool, error) {
+	vars, err := testClientVariablesForResource(
+		s,
+		fmt.Sprintf("%s.%s", resourceVSphereDistributedNetworkResourcePoolName, resourceName),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	dvsID, key, err := dvsEntryParseID(vars.resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	dvs, err := dvsFromMOID(vars.client, dvsID)
+	if err != nil {
+		return nil, err
+	}
+
+	return dvsNetworkResourcePoolFromKey(dvs, key)
+}
+
+// testGetDistributedPortMirroringSession is a convenience method to fetch a
+// port mirroring session by resource name.
+func testGetDistributedPortMirroringSession(s *terraform.State, resourceName string) (*types.VMwareVspanSession, error) {
+	vars, err := testClientVariablesForResource(
+		s,
+		fmt.Sprintf("%s.%s", resourceVSphereDistributedPortMirroringSessionName, resourceName),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	dvsID, key, err := dvsEntryParseID(vars.resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	dvs, err := dvsFromMOID(vars.client, dvsID)
+	if err != nil {
+		return nil, err
+	}
+
+	return dvsVspanSessionFromKey(dvs, key)
+}
+
+// testGetDistributedPort is a convenience method to fetch a port managed by
+// a vsphere_distributed_port resource.
+func testGetDistributedPort(s *terraform.State, resourceName string) (*types.DistributedVirtualPort, error) {
+	vars, err := testClientVariablesForResource(
+		s,
+		fmt.Sprintf("%s.%s", resourceVSphereDistributedPortName, resourceName),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	dvsID, key, err := resourceVSphereDistributedPortParseID(vars.resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	dvs, err := dvsFromMOID(vars.client, dvsID)
+	if err != nil {
+		return nil, err
+	}
+
+	return dvsPortFromKey(dvs, key)
+}
+
 // testCheckResourceNotAttr is an inverse check of TestCheckResourceAttr. It
 // checks to make sure the resource attribute does *not* match a certain value.
 func testCheckResourceNotAttr(name, key, value string) resource.TestCheckFunc {
@@ -1105,3 +1178,13 @@ func testGetDatastoreClusterVMAntiAffinityRule(s *terraform.State, resourceName
 
 	return resourceVSphereDatastoreClusterVMAntiAffinityRuleFindEntry(pod, key)
 }
+
+// testGetKeyProvider is a convenience method to fetch a key provider by
+// resource name.
+func testGetKeyProvider(s *terraform.State, resourceName string) (*types.KmipClusterInfo, error) {
+	vars, err := testClientVariablesForResource(s, fmt.Sprintf("vsphere_key_provider.%s", resourceName))
+	if err != nil {
+		return nil, err
+	}
+	return cryptomanager.KeyProvider(vars.client, vars.resourceID)
+}
@@ -0,0 +1,50 @@
+package vsphere
+
+import (
+	"context"
+
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// hostDateTimeSystemFromHostSystemID locates a HostDateTimeSystem from a
+// specified HostSystem managed object ID.
+func hostDateTimeSystemFromHostSystemID(client *govmomi.Client, hsID string) (*object.HostDateTimeSystem, error) {
+	hs, err := hostsystem.FromID(client, hsID)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return hs.ConfigManager().DateTimeSystem(ctx)
+}
+
+// hostNtpServers returns the NTP servers that are configured on a
+// HostDateTimeSystem.
+func hostNtpServers(dts *object.HostDateTimeSystem) ([]string, error) {
+	var mdts mo.HostDateTimeSystem
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	if err := dts.Properties(ctx, dts.Reference(), []string{"dateTimeInfo"}, &mdts); err != nil {
+		return nil, err
+	}
+	if mdts.DateTimeInfo.NtpConfig == nil {
+		return nil, nil
+	}
+	return mdts.DateTimeInfo.NtpConfig.Server, nil
+}
+
+// updateHostNtpServers sets the NTP servers on a HostDateTimeSystem.
+func updateHostNtpServers(dts *object.HostDateTimeSystem, servers []string) error {
+	config := types.HostDateTimeConfig{
+		NtpConfig: &types.HostNtpConfig{
+			Server: servers,
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return dts.UpdateConfig(ctx, config)
+}
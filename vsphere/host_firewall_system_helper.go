@@ -0,0 +1,67 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// hostFirewallSystemFromHostSystemID locates a HostFirewallSystem from a
+// specified HostSystem managed object ID.
+func hostFirewallSystemFromHostSystemID(client *govmomi.Client, hsID string) (*object.HostFirewallSystem, error) {
+	hs, err := hostsystem.FromID(client, hsID)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return hs.ConfigManager().FirewallSystem(ctx)
+}
+
+// hostFirewallRulesetFromKey locates a firewall ruleset on a
+// HostFirewallSystem by its key, ie: sshServer.
+func hostFirewallRulesetFromKey(fs *object.HostFirewallSystem, key string) (*types.HostFirewallRuleset, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	info, err := fs.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, ruleset := range info.Ruleset {
+		if ruleset.Key == key {
+			return &ruleset, nil
+		}
+	}
+	return nil, fmt.Errorf("could not find firewall ruleset %s", key)
+}
+
+// updateHostFirewallRuleset exposes the UpdateRuleset method of the
+// HostFirewallSystem MO, which sets the hosts that are allowed to connect
+// through a ruleset.
+func updateHostFirewallRuleset(fs *object.HostFirewallSystem, key string, spec types.HostFirewallRulesetRulesetSpec) error {
+	req := &types.UpdateRuleset{
+		This: fs.Reference(),
+		Id:   key,
+		Spec: spec,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	_, err := methods.UpdateRuleset(ctx, fs.Client(), req)
+	return err
+}
+
+// enableHostFirewallRuleset enables or disables a firewall ruleset on a
+// HostFirewallSystem.
+func enableHostFirewallRuleset(fs *object.HostFirewallSystem, key string, enabled bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	if enabled {
+		return fs.EnableRuleset(ctx, key)
+	}
+	return fs.DisableRuleset(ctx, key)
+}
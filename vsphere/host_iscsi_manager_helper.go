@@ -0,0 +1,81 @@
+package vsphere
+
+import (
+	"context"
+	"errors"
+
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// hostIscsiManagerFromHostSystemID locates the IscsiManager of a HostSystem,
+// which manages the binding of VMkernel adapters to iSCSI adapters, from a
+// specified HostSystem managed object ID.
+func hostIscsiManagerFromHostSystemID(client *govmomi.Client, hsID string) (types.ManagedObjectReference, error) {
+	hs, err := hostsystem.FromID(client, hsID)
+	if err != nil {
+		return types.ManagedObjectReference{}, err
+	}
+	var props mo.HostSystem
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	if err := hs.Properties(ctx, hs.Reference(), []string{"configManager.iscsiManager"}, &props); err != nil {
+		return types.ManagedObjectReference{}, err
+	}
+	if props.ConfigManager.IscsiManager == nil {
+		return types.ManagedObjectReference{}, errors.New("host does not support iSCSI port binding")
+	}
+	return *props.ConfigManager.IscsiManager, nil
+}
+
+// hostIscsiBoundVnics exposes the QueryBoundVnics method of the IscsiManager
+// MO, and returns the names of the VMkernel adapters that are bound to an
+// iSCSI adapter.
+func hostIscsiBoundVnics(client *govmomi.Client, ref types.ManagedObjectReference, device string) ([]string, error) {
+	req := &types.QueryBoundVnics{
+		This:         ref,
+		IScsiHbaName: device,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	resp, err := methods.QueryBoundVnics(ctx, client, req)
+	if err != nil {
+		return nil, err
+	}
+	var vnics []string
+	for _, port := range resp.Returnval {
+		vnics = append(vnics, port.VnicDevice)
+	}
+	return vnics, nil
+}
+
+// bindHostIscsiVnic exposes the BindVnic method of the IscsiManager MO, which
+// binds a VMkernel adapter to an iSCSI adapter.
+func bindHostIscsiVnic(client *govmomi.Client, ref types.ManagedObjectReference, device, vnic string) error {
+	req := &types.BindVnic{
+		This:         ref,
+		IScsiHbaName: device,
+		VnicDevice:   vnic,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	_, err := methods.BindVnic(ctx, client, req)
+	return err
+}
+
+// unbindHostIscsiVnic exposes the UnbindVnic method of the IscsiManager MO,
+// which unbinds a VMkernel adapter from an iSCSI adapter.
+func unbindHostIscsiVnic(client *govmomi.Client, ref types.ManagedObjectReference, device, vnic string) error {
+	req := &types.UnbindVnic{
+		This:         ref,
+		IScsiHbaName: device,
+		VnicDevice:   vnic,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	_, err := methods.UnbindVnic(ctx, client, req)
+	return err
+}
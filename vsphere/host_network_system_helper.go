@@ -115,3 +115,122 @@ func networkObjectFromHostSystem(client *govmomi.Client, hs *object.HostSystem,
 
 	return nil, fmt.Errorf("could not find a matching %q on host ID %q", name, hs.Reference().Value)
 }
+
+// migrateHostNetworkToDVS moves the supplied physical NICs of a host, along
+// with the supplied virtual NICs, to a DVS that the host is already a member
+// of. vnics maps the device names of the virtual NICs to the keys of the
+// distributed port groups that they are moved to.
+//
+// Everything is moved in a single UpdateNetworkConfig call, so that a virtual
+// NIC that is used for management traffic is never left on a switch without
+// any uplinks. The physical NICs are removed from any standard switch that
+// they are currently bridged to as part of the same call.
+func migrateHostNetworkToDVS(client *govmomi.Client, hsID, dvsUUID string, pnics []string, vnics map[string]string) error {
+	ns, err := hostNetworkSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error loading host network system: %s", err)
+	}
+
+	var mns mo.HostNetworkSystem
+	pc := client.PropertyCollector()
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	if err := pc.RetrieveOne(ctx, ns.Reference(), []string{"networkInfo.vswitch"}, &mns); err != nil {
+		return fmt.Errorf("error fetching host network properties: %s", err)
+	}
+
+	moving := make(map[string]bool)
+	var pnSpecs []types.DistributedVirtualSwitchHostMemberPnicSpec
+	for _, pnic := range pnics {
+		moving[pnic] = true
+		pnSpecs = append(pnSpecs, types.DistributedVirtualSwitchHostMemberPnicSpec{
+			PnicDevice: pnic,
+		})
+	}
+
+	config := types.HostNetworkConfig{
+		ProxySwitch: []types.HostProxySwitchConfig{
+			{
+				ChangeOperation: string(types.HostConfigChangeOperationEdit),
+				Uuid:            dvsUUID,
+				Spec: &types.HostProxySwitchSpec{
+					Backing: &types.DistributedVirtualSwitchHostMemberPnicBacking{
+						PnicSpec: pnSpecs,
+					},
+				},
+			},
+		},
+	}
+
+	for _, sw := range mns.NetworkInfo.Vswitch {
+		bridge, ok := sw.Spec.Bridge.(*types.HostVirtualSwitchBondBridge)
+		if !ok {
+			continue
+		}
+		var kept []string
+		for _, nic := range bridge.NicDevice {
+			if !moving[nic] {
+				kept = append(kept, nic)
+			}
+		}
+		if len(kept) == len(bridge.NicDevice) {
+			continue
+		}
+		spec := sw.Spec
+		if len(kept) > 0 {
+			nb := *bridge
+			nb.NicDevice = kept
+			spec.Bridge = &nb
+		} else {
+			spec.Bridge = nil
+		}
+		if spec.Policy != nil && spec.Policy.NicTeaming != nil && spec.Policy.NicTeaming.NicOrder != nil {
+			order := *spec.Policy.NicTeaming.NicOrder
+			order.ActiveNic = hostNetworkSystemFilterNics(order.ActiveNic, moving)
+			order.StandbyNic = hostNetworkSystemFilterNics(order.StandbyNic, moving)
+			teaming := *spec.Policy.NicTeaming
+			teaming.NicOrder = &order
+			policy := *spec.Policy
+			policy.NicTeaming = &teaming
+			spec.Policy = &policy
+		}
+		config.Vswitch = append(config.Vswitch, types.HostVirtualSwitchConfig{
+			ChangeOperation: string(types.HostConfigChangeOperationEdit),
+			Name:            sw.Name,
+			Spec:            &spec,
+		})
+	}
+
+	for device, pgKey := range vnics {
+		config.Vnic = append(config.Vnic, types.HostVirtualNicConfig{
+			ChangeOperation: string(types.HostConfigChangeOperationEdit),
+			Device:          device,
+			Portgroup:       "",
+			Spec: &types.HostVirtualNicSpec{
+				DistributedVirtualPort: &types.DistributedVirtualSwitchPortConnection{
+					SwitchUuid:   dvsUUID,
+					PortgroupKey: pgKey,
+				},
+			},
+		})
+	}
+
+	uctx, ucancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer ucancel()
+	if _, err := ns.UpdateNetworkConfig(uctx, config, string(types.HostConfigChangeModeModify)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// hostNetworkSystemFilterNics returns the NICs in nics that are not in
+// remove.
+func hostNetworkSystemFilterNics(nics []string, remove map[string]bool) []string {
+	var result []string
+	for _, nic := range nics {
+		if !remove[nic] {
+			result = append(result, nic)
+		}
+	}
+	return result
+}
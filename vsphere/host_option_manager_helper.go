@@ -0,0 +1,150 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// hostOptionManagerFromHostSystemID locates the OptionManager of a HostSystem,
+// which holds the advanced settings of the host, from a specified HostSystem
+// managed object ID.
+func hostOptionManagerFromHostSystemID(client *govmomi.Client, hsID string) (*object.OptionManager, error) {
+	hs, err := hostsystem.FromID(client, hsID)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return hs.ConfigManager().OptionManager(ctx)
+}
+
+// hostOptionValue returns the current value of an advanced setting on a host.
+// The returned boolean is false if the setting does not exist on the host.
+func hostOptionValue(om *object.OptionManager, key string) (interface{}, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	values, err := om.Query(ctx, key)
+	if err != nil {
+		if isHostOptionInvalidNameError(err) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("error querying advanced setting %q: %s", key, err)
+	}
+	for _, value := range values {
+		if ov := value.GetOptionValue(); ov.Key == key {
+			return ov.Value, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// hostOptionValues returns the current values of the supplied advanced
+// settings on a host, converted to strings. Settings that do not exist on the
+// host are left out, so that a refresh does not fail on a misspelled key.
+// The key is rejected when the setting is applied instead.
+func hostOptionValues(om *object.OptionManager, keys []string) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, key := range keys {
+		value, ok, err := hostOptionValue(om, key)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			log.Printf("[DEBUG] Advanced setting %q not found, skipping", key)
+			continue
+		}
+		values[key] = fmt.Sprint(value)
+	}
+	return values, nil
+}
+
+// isHostOptionInvalidNameError checks to see if the supplied error is the
+// InvalidName fault that OptionManager returns when querying a setting that
+// does not exist.
+func isHostOptionInvalidNameError(err error) bool {
+	if soap.IsSoapFault(err) {
+		if _, ok := soap.ToSoapFault(err).VimFault().(types.InvalidName); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// updateHostOptionValues sets the supplied advanced settings on a host. The
+// settings are typed on the host, so each value is converted to the type of
+// the current value of the setting first.
+func updateHostOptionValues(om *object.OptionManager, values map[string]string) error {
+	if len(values) < 1 {
+		return nil
+	}
+	var update []types.BaseOptionValue
+	for key, value := range values {
+		current, ok, err := hostOptionValue(om, key)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("advanced setting %q does not exist on the host", key)
+		}
+		v, err := convertHostOptionValue(current, value)
+		if err != nil {
+			return fmt.Errorf("invalid value for advanced setting %q: %s", key, err)
+		}
+		update = append(update, &types.OptionValue{
+			Key:   key,
+			Value: v,
+		})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return om.Update(ctx, update)
+}
+
+// convertHostOptionValue converts a string value to the type of the current
+// value of an advanced setting.
+func convertHostOptionValue(current interface{}, value string) (interface{}, error) {
+	switch current.(type) {
+	case int32:
+		v, err := strconv.ParseInt(value, 10, 32)
+		return int32(v), err
+	case int64:
+		v, err := strconv.ParseInt(value, 10, 64)
+		return v, err
+	case bool:
+		return strconv.ParseBool(value)
+	}
+	return value, nil
+}
+
+// hostOptionValueDiffSuppressFunc suppresses the diff of an advanced setting
+// when the configured value converts to the value that is in state, ie: "1"
+// or "True" for a setting that is true, or "010" for a setting that is 10.
+// The value in state is read from the host, so its format tells the type of
+// the setting.
+func hostOptionValueDiffSuppressFunc(k, old, new string, d *schema.ResourceData) bool {
+	if old == "" || new == "" {
+		return false
+	}
+	var current interface{} = old
+	switch old {
+	case "true":
+		current = true
+	case "false":
+		current = false
+	default:
+		if v, err := strconv.ParseInt(old, 10, 64); err == nil {
+			current = v
+		}
+	}
+	v, err := convertHostOptionValue(current, new)
+	return err == nil && v == current
+}
@@ -0,0 +1,58 @@
+package vsphere
+
+import (
+	"context"
+
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// hostServiceSystemFromHostSystemID locates a HostServiceSystem from a
+// specified HostSystem managed object ID.
+func hostServiceSystemFromHostSystemID(client *govmomi.Client, hsID string) (*object.HostServiceSystem, error) {
+	hs, err := hostsystem.FromID(client, hsID)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return hs.ConfigManager().ServiceSystem(ctx)
+}
+
+// hostServices returns the services on a HostServiceSystem, keyed by service
+// key, ie: TSM-SSH or ntpd.
+func hostServices(ss *object.HostServiceSystem) (map[string]types.HostService, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	services, err := ss.Service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]types.HostService)
+	for _, service := range services {
+		m[service.Key] = service
+	}
+	return m, nil
+}
+
+// updateHostService sets the startup policy of a service on a
+// HostServiceSystem, and then starts or stops the service if its running
+// state does not match the desired state.
+func updateHostService(ss *object.HostServiceSystem, current types.HostService, policy string, running bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	if current.Policy != policy {
+		if err := ss.UpdatePolicy(ctx, current.Key, policy); err != nil {
+			return err
+		}
+	}
+	switch {
+	case running && !current.Running:
+		return ss.Start(ctx, current.Key)
+	case !running && current.Running:
+		return ss.Stop(ctx, current.Key)
+	}
+	return nil
+}
@@ -2,10 +2,14 @@ package vsphere
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
 )
 
 // hostStorageSystemFromHostSystemID locates a HostStorageSystem from a
@@ -19,3 +23,137 @@ func hostStorageSystemFromHostSystemID(client *govmomi.Client, hsID string) (*ob
 	defer cancel()
 	return hs.ConfigManager().StorageSystem(ctx)
 }
+
+// hostSoftwareInternetScsiHba returns the software iSCSI adapter on a
+// HostStorageSystem. nil is returned if software iSCSI is not enabled.
+func hostSoftwareInternetScsiHba(ss *object.HostStorageSystem) (*types.HostInternetScsiHba, error) {
+	var hss mo.HostStorageSystem
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	if err := ss.Properties(ctx, ss.Reference(), []string{"storageDeviceInfo.hostBusAdapter"}, &hss); err != nil {
+		return nil, fmt.Errorf("error querying storage system properties: %s", err)
+	}
+	if hss.StorageDeviceInfo == nil {
+		return nil, nil
+	}
+	for _, hba := range hss.StorageDeviceInfo.HostBusAdapter {
+		if iscsi, ok := hba.(*types.HostInternetScsiHba); ok && iscsi.IsSoftwareBased {
+			return iscsi, nil
+		}
+	}
+	return nil, nil
+}
+
+// updateHostSoftwareInternetScsiEnabled exposes the
+// UpdateSoftwareInternetScsiEnabled method of the HostStorageSystem MO, which
+// enables or disables the software iSCSI adapter.
+func updateHostSoftwareInternetScsiEnabled(ss *object.HostStorageSystem, enabled bool) error {
+	req := &types.UpdateSoftwareInternetScsiEnabled{
+		This:    ss.Reference(),
+		Enabled: enabled,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	_, err := methods.UpdateSoftwareInternetScsiEnabled(ctx, ss.Client(), req)
+	return err
+}
+
+// updateHostInternetScsiName exposes the UpdateInternetScsiName method of the
+// HostStorageSystem MO, which sets the IQN of an iSCSI adapter.
+func updateHostInternetScsiName(ss *object.HostStorageSystem, device, name string) error {
+	req := &types.UpdateInternetScsiName{
+		This:           ss.Reference(),
+		IScsiHbaDevice: device,
+		IScsiName:      name,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	_, err := methods.UpdateInternetScsiName(ctx, ss.Client(), req)
+	return err
+}
+
+// addHostInternetScsiSendTargets exposes the AddInternetScsiSendTargets method
+// of the HostStorageSystem MO, which adds dynamic discovery targets to an
+// iSCSI adapter.
+func addHostInternetScsiSendTargets(ss *object.HostStorageSystem, device string, targets []types.HostInternetScsiHbaSendTarget) error {
+	req := &types.AddInternetScsiSendTargets{
+		This:           ss.Reference(),
+		IScsiHbaDevice: device,
+		Targets:        targets,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	_, err := methods.AddInternetScsiSendTargets(ctx, ss.Client(), req)
+	return err
+}
+
+// removeHostInternetScsiSendTargets exposes the RemoveInternetScsiSendTargets
+// method of the HostStorageSystem MO, which removes dynamic discovery targets
+// from an iSCSI adapter.
+func removeHostInternetScsiSendTargets(ss *object.HostStorageSystem, device string, targets []types.HostInternetScsiHbaSendTarget) error {
+	req := &types.RemoveInternetScsiSendTargets{
+		This:           ss.Reference(),
+		IScsiHbaDevice: device,
+		Targets:        targets,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	_, err := methods.RemoveInternetScsiSendTargets(ctx, ss.Client(), req)
+	return err
+}
+
+// addHostInternetScsiStaticTargets exposes the AddInternetScsiStaticTargets
+// method of the HostStorageSystem MO, which adds static discovery targets to
+// an iSCSI adapter.
+func addHostInternetScsiStaticTargets(ss *object.HostStorageSystem, device string, targets []types.HostInternetScsiHbaStaticTarget) error {
+	req := &types.AddInternetScsiStaticTargets{
+		This:           ss.Reference(),
+		IScsiHbaDevice: device,
+		Targets:        targets,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	_, err := methods.AddInternetScsiStaticTargets(ctx, ss.Client(), req)
+	return err
+}
+
+// removeHostInternetScsiStaticTargets exposes the
+// RemoveInternetScsiStaticTargets method of the HostStorageSystem MO, which
+// removes static discovery targets from an iSCSI adapter.
+func removeHostInternetScsiStaticTargets(ss *object.HostStorageSystem, device string, targets []types.HostInternetScsiHbaStaticTarget) error {
+	req := &types.RemoveInternetScsiStaticTargets{
+		This:           ss.Reference(),
+		IScsiHbaDevice: device,
+		Targets:        targets,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	_, err := methods.RemoveInternetScsiStaticTargets(ctx, ss.Client(), req)
+	return err
+}
+
+// updateHostInternetScsiAuthenticationProperties exposes the
+// UpdateInternetScsiAuthenticationProperties method of the HostStorageSystem
+// MO, which sets the CHAP settings of an iSCSI adapter.
+func updateHostInternetScsiAuthenticationProperties(ss *object.HostStorageSystem, device string, props types.HostInternetScsiHbaAuthenticationProperties) error {
+	req := &types.UpdateInternetScsiAuthenticationProperties{
+		This:                     ss.Reference(),
+		IScsiHbaDevice:           device,
+		AuthenticationProperties: props,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	_, err := methods.UpdateInternetScsiAuthenticationProperties(ctx, ss.Client(), req)
+	return err
+}
+
+// rescanHostStorage rescans all of the storage adapters on a
+// HostStorageSystem for new devices, and then for new VMFS volumes.
+func rescanHostStorage(ss *object.HostStorageSystem) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	if err := ss.RescanAllHba(ctx); err != nil {
+		return err
+	}
+	return ss.RescanVmfs(ctx)
+}
@@ -0,0 +1,172 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// hostVsanSystemFromHostSystemID locates a HostVsanSystem from a specified
+// HostSystem managed object ID.
+func hostVsanSystemFromHostSystemID(client *govmomi.Client, hsID string) (*object.HostVsanSystem, error) {
+	hs, err := hostsystem.FromID(client, hsID)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return hs.ConfigManager().VsanSystem(ctx)
+}
+
+// hostVsanDiskMappings returns the vSAN disk mappings (disk groups) that are
+// currently configured on a HostVsanSystem.
+func hostVsanDiskMappings(vs *object.HostVsanSystem) ([]types.VsanHostDiskMapping, error) {
+	var mvs mo.HostVsanSystem
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	if err := vs.Properties(ctx, vs.Reference(), []string{"config"}, &mvs); err != nil {
+		return nil, fmt.Errorf("error fetching vSAN properties: %s", err)
+	}
+	if mvs.Config.StorageInfo == nil {
+		return nil, nil
+	}
+	return mvs.Config.StorageInfo.DiskMapping, nil
+}
+
+// hostScsiDisksFromNames looks up SCSI disks on a host by their canonical
+// names, ie: naa.xxxx, and returns them in the order they were requested.
+func hostScsiDisksFromNames(client *govmomi.Client, hsID string, names []string) ([]types.HostScsiDisk, error) {
+	ss, err := hostStorageSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return nil, fmt.Errorf("error loading host storage system: %s", err)
+	}
+	var hss mo.HostStorageSystem
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	if err := ss.Properties(ctx, ss.Reference(), []string{"storageDeviceInfo.scsiLun"}, &hss); err != nil {
+		return nil, fmt.Errorf("error querying storage system properties: %s", err)
+	}
+
+	available := make(map[string]types.HostScsiDisk)
+	for _, sl := range hss.StorageDeviceInfo.ScsiLun {
+		if hsd, ok := sl.(*types.HostScsiDisk); ok {
+			available[hsd.CanonicalName] = *hsd
+		}
+	}
+	var disks []types.HostScsiDisk
+	for _, name := range names {
+		disk, ok := available[name]
+		if !ok {
+			return nil, fmt.Errorf("disk %q not found on host %q", name, hostsystem.NameOrID(client, hsID))
+		}
+		disks = append(disks, disk)
+	}
+	return disks, nil
+}
+
+// hostVsanMaintenanceSpec returns the maintenance spec used when removing
+// disks from vSAN. Data is only moved to the extent that all objects remain
+// accessible, which is the same as the default in the vSphere client.
+func hostVsanMaintenanceSpec() *types.HostMaintenanceSpec {
+	return &types.HostMaintenanceSpec{
+		VsanMode: &types.VsanHostDecommissionMode{
+			ObjectAction: string(types.VsanHostDecommissionModeObjectActionEnsureObjectAccessibility),
+		},
+	}
+}
+
+// initializeHostVsanDisks exposes the InitializeDisks_Task method of the
+// HostVsanSystem MO, which creates new vSAN disk groups. Capacity disks in a
+// mapping with a cache disk that is already in use are added to the existing
+// disk group. The task is waited on for up to the supplied timeout.
+func initializeHostVsanDisks(client *govmomi.Client, vs *object.HostVsanSystem, mappings []types.VsanHostDiskMapping, timeout time.Duration) error {
+	req := &types.InitializeDisks_Task{
+		This:    vs.Reference(),
+		Mapping: mappings,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	resp, err := methods.InitializeDisks_Task(ctx, client, req)
+	if err != nil {
+		return err
+	}
+	return waitForHostVsanTask(client, resp.Returnval, timeout)
+}
+
+// removeHostVsanDiskMappings exposes the RemoveDiskMapping_Task method of the
+// HostVsanSystem MO, which removes entire vSAN disk groups. Moving the data
+// off the disks can take a long time, so the task is waited on for up to the
+// supplied timeout.
+func removeHostVsanDiskMappings(client *govmomi.Client, vs *object.HostVsanSystem, mappings []types.VsanHostDiskMapping, timeout time.Duration) error {
+	req := &types.RemoveDiskMapping_Task{
+		This:            vs.Reference(),
+		Mapping:         mappings,
+		MaintenanceSpec: hostVsanMaintenanceSpec(),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	resp, err := methods.RemoveDiskMapping_Task(ctx, client, req)
+	if err != nil {
+		return err
+	}
+	return waitForHostVsanTask(client, resp.Returnval, timeout)
+}
+
+// removeHostVsanDisks exposes the RemoveDisk_Task method of the
+// HostVsanSystem MO, which removes capacity disks from vSAN disk groups. The
+// task is waited on for up to the supplied timeout.
+func removeHostVsanDisks(client *govmomi.Client, vs *object.HostVsanSystem, disks []types.HostScsiDisk, timeout time.Duration) error {
+	req := &types.RemoveDisk_Task{
+		This:            vs.Reference(),
+		Disk:            disks,
+		MaintenanceSpec: hostVsanMaintenanceSpec(),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	resp, err := methods.RemoveDisk_Task(ctx, client, req)
+	if err != nil {
+		return err
+	}
+	return waitForHostVsanTask(client, resp.Returnval, timeout)
+}
+
+// waitForHostVsanTask waits for a task returned by one of the HostVsanSystem
+// disk methods. These tasks return a result for each disk group or disk,
+// which carries the error for any disk that failed.
+func waitForHostVsanTask(client *govmomi.Client, ref types.ManagedObjectReference, timeout time.Duration) error {
+	task := object.NewTask(client.Client, ref)
+	tctx, tcancel := context.WithTimeout(context.Background(), timeout)
+	defer tcancel()
+	info, err := task.WaitForResult(tctx, nil)
+	if err != nil {
+		return err
+	}
+	var diskResults []types.VsanHostDiskResult
+	switch results := info.Result.(type) {
+	case types.ArrayOfVsanHostDiskMapResult:
+		for _, result := range results.VsanHostDiskMapResult {
+			if result.Error != nil {
+				return fmt.Errorf("error configuring disk group with cache disk %q: %s", result.Mapping.Ssd.CanonicalName, result.Error.LocalizedMessage)
+			}
+			diskResults = append(diskResults, result.DiskResult...)
+		}
+	case types.ArrayOfVsanHostDiskResult:
+		diskResults = results.VsanHostDiskResult
+	}
+	for _, result := range diskResults {
+		if result.Error != nil {
+			return fmt.Errorf("error configuring disk %q: %s", result.Disk.CanonicalName, result.Error.LocalizedMessage)
+		}
+	}
+	return nil
+}
@@ -161,6 +161,20 @@ func OSFamily(client *govmomi.Client, ref types.ManagedObjectReference, guest st
 	return b.OSFamily(ctx, guest)
 }
 
+// ConfigTargetFromReference fetches the config target for a specific compute
+// resource from a supplied managed object reference. The config target is
+// narrowed down to a single host if one is supplied.
+func ConfigTargetFromReference(client *govmomi.Client, ref types.ManagedObjectReference, host *object.HostSystem) (*types.ConfigTarget, error) {
+	log.Printf("[DEBUG] Fetching config target for object reference %q", ref.Value)
+	b, err := EnvironmentBrowserFromReference(client, ref)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	return b.QueryConfigTarget(ctx, host)
+}
+
 // EnvironmentBrowserFromReference loads an environment browser for the
 // specific compute resource reference. The reference can be either a
 // standalone host or cluster.
@@ -0,0 +1,425 @@
+package contentlibrary
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/ovfdeploy"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/vmware/govmomi/ovf"
+	"github.com/vmware/govmomi/vapi/library"
+	"github.com/vmware/govmomi/vapi/rest"
+	"github.com/vmware/govmomi/vapi/vcenter"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const (
+	// LibraryTypeLocal is the type of a local content library.
+	LibraryTypeLocal = "LOCAL"
+
+	// LibraryTypeSubscribed is the type of a content library that is
+	// subscribed to a published library.
+	LibraryTypeSubscribed = "SUBSCRIBED"
+
+	// ItemTypeOvf is the type of a library item that contains an OVF
+	// template.
+	ItemTypeOvf = "ovf"
+
+	// ItemTypeIso is the type of a library item that contains an ISO image.
+	ItemTypeIso = "iso"
+
+	// storageBackingTypeDatastore is the type of a storage backing that is a
+	// datastore.
+	storageBackingTypeDatastore = "DATASTORE"
+
+	// subscribedLibraryPath is the REST API path for subscribed libraries.
+	// govmomi only supports creating local libraries, so subscribed libraries
+	// are managed directly through this path.
+	subscribedLibraryPath = "/rest/com/vmware/content/subscribed-library"
+
+	// updateSessionPollInterval is the interval at which the state of an update
+	// session is polled while waiting on it to complete.
+	updateSessionPollInterval = time.Second * 5
+)
+
+// Subscription represents the subscription settings of a subscribed content
+// library.
+type Subscription struct {
+	AuthenticationMethod string `json:"authentication_method,omitempty"`
+	AutomaticSyncEnabled bool   `json:"automatic_sync_enabled"`
+	OnDemand             bool   `json:"on_demand"`
+	Password             string `json:"password,omitempty"`
+	SubscriptionURL      string `json:"subscription_url,omitempty"`
+	UserName             string `json:"user_name,omitempty"`
+}
+
+// subscribedLibrary is the create spec and read result of a subscribed content
+// library.
+type subscribedLibrary struct {
+	library.Library
+	Subscription *Subscription `json:"subscription_info,omitempty"`
+}
+
+// IsNotFoundError checks to see if the supplied error is a not found error
+// returned by the content library API.
+func IsNotFoundError(err error) bool {
+	if err == nil {
+		return false
+	}
+	return strings.Contains(err.Error(), "404 Not Found") || strings.Contains(err.Error(), "com.vmware.vapi.std.errors.not_found")
+}
+
+// FromID locates a content library by its ID.
+func FromID(client *rest.Client, id string) (*library.Library, error) {
+	log.Printf("[DEBUG] Locating content library with ID %q", id)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	return library.NewManager(client).GetLibraryByID(ctx, id)
+}
+
+// FromName locates a content library by its name.
+func FromName(client *rest.Client, name string) (*library.Library, error) {
+	log.Printf("[DEBUG] Locating content library with name %q", name)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	ids, err := library.NewManager(client).FindLibrary(ctx, library.Find{Name: name})
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case len(ids) < 1:
+		return nil, fmt.Errorf("content library %q not found", name)
+	case len(ids) > 1:
+		return nil, fmt.Errorf("multiple content libraries with name %q found", name)
+	}
+	return library.NewManager(client).GetLibraryByID(ctx, ids[0])
+}
+
+// SubscriptionFromID returns the subscription settings of the subscribed
+// content library with the supplied ID.
+func SubscriptionFromID(client *rest.Client, id string) (*Subscription, error) {
+	log.Printf("[DEBUG] Reading subscription for content library %q", id)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	u := subscribedLibraryURL(client, id)
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	var res subscribedLibrary
+	if err := client.Do(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return res.Subscription, nil
+}
+
+// Create creates a content library with the supplied name and description,
+// backed by the datastores with the supplied IDs. If subscription is not nil,
+// a subscribed library is created; otherwise a local library is created. The
+// ID of the new library is returned.
+func Create(client *rest.Client, name, description string, datastoreIDs []string, subscription *Subscription) (string, error) {
+	log.Printf("[DEBUG] Creating content library %q", name)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	lib := library.Library{
+		Name:        name,
+		Description: description,
+		Type:        LibraryTypeLocal,
+	}
+	for _, id := range datastoreIDs {
+		lib.Storage = append(lib.Storage, library.StorageBackings{
+			DatastoreID: id,
+			Type:        storageBackingTypeDatastore,
+		})
+	}
+	if subscription == nil {
+		return library.NewManager(client).CreateLibrary(ctx, lib)
+	}
+
+	lib.Type = LibraryTypeSubscribed
+	spec := struct {
+		Library subscribedLibrary `json:"create_spec"`
+	}{subscribedLibrary{Library: lib, Subscription: subscription}}
+	req, err := jsonRequest(http.MethodPost, subscribedLibraryURL(client, ""), spec)
+	if err != nil {
+		return "", err
+	}
+	var id string
+	return id, client.Do(ctx, req, &id)
+}
+
+// Delete deletes the supplied content library.
+func Delete(client *rest.Client, lib *library.Library) error {
+	log.Printf("[DEBUG] Deleting content library %q", lib.ID)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	if lib.Type != LibraryTypeSubscribed {
+		return library.NewManager(client).DeleteLibrary(ctx, lib)
+	}
+	req, err := http.NewRequest(http.MethodDelete, subscribedLibraryURL(client, lib.ID).String(), nil)
+	if err != nil {
+		return err
+	}
+	return client.Do(ctx, req, nil)
+}
+
+// ItemFromID locates a content library item by its ID.
+func ItemFromID(client *rest.Client, id string) (*library.Item, error) {
+	log.Printf("[DEBUG] Locating content library item with ID %q", id)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	return library.NewManager(client).GetLibraryItem(ctx, id)
+}
+
+// ItemFromName locates a content library item by its name in the content
+// library with the supplied ID.
+func ItemFromName(client *rest.Client, libraryID, name string) (*library.Item, error) {
+	log.Printf("[DEBUG] Locating content library item with name %q in library %q", name, libraryID)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	ids, err := library.NewManager(client).FindLibraryItems(ctx, library.FindItem{LibraryID: libraryID, Name: name})
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case len(ids) < 1:
+		return nil, fmt.Errorf("content library item %q not found", name)
+	case len(ids) > 1:
+		return nil, fmt.Errorf("multiple content library items with name %q found", name)
+	}
+	return library.NewManager(client).GetLibraryItem(ctx, ids[0])
+}
+
+// IsItem checks to see if the supplied ID refers to an existing content
+// library item. Errors other than a not found error are returned.
+func IsItem(client *rest.Client, id string) (bool, error) {
+	_, err := ItemFromID(client, id)
+	switch {
+	case err == nil:
+		return true, nil
+	case IsNotFoundError(err):
+		return false, nil
+	}
+	return false, err
+}
+
+// ItemTypeFromPath returns the library item type for the file at the supplied
+// path or URL, based on its extension. For URLs, the extension is taken from
+// the path of the URL, without any query string.
+func ItemTypeFromPath(p string) (string, error) {
+	name := p
+	if isRemote(p) {
+		u, err := url.Parse(p)
+		if err != nil {
+			return "", fmt.Errorf("could not parse URL %q: %s", p, err)
+		}
+		name = u.Path
+	}
+	switch strings.ToLower(path.Ext(name)) {
+	case ".ovf", ".ova":
+		return ItemTypeOvf, nil
+	case ".iso":
+		return ItemTypeIso, nil
+	}
+	return "", fmt.Errorf("could not determine the item type of %q: expected an .ovf, .ova, or .iso file", p)
+}
+
+// CreateItem creates a content library item in the library with the supplied
+// ID, and uploads the file at the supplied local path or remote URL to it. For
+// OVF templates, the descriptor and the files it references are uploaded. The
+// upload is given up on if it does not complete within the supplied timeout.
+// The ID of the new item is returned.
+func CreateItem(client *rest.Client, libraryID, name, description, itemType, file string, timeout time.Duration) (string, error) {
+	src, err := newSource(file)
+	if err != nil {
+		return "", err
+	}
+
+	log.Printf("[DEBUG] Creating content library item %q in library %q", name, libraryID)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	m := library.NewManager(client)
+	id, err := m.CreateLibraryItem(ctx, library.Item{
+		Name:        name,
+		Description: description,
+		LibraryID:   libraryID,
+		Type:        itemType,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if err := uploadItem(client, id, itemType, src, timeout); err != nil {
+		dctx, dcancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+		defer dcancel()
+		if derr := m.DeleteLibraryItem(dctx, &library.Item{ID: id}); derr != nil {
+			log.Printf("[WARN] Could not delete content library item %q after failed upload: %s", id, derr)
+		}
+		return "", err
+	}
+	return id, nil
+}
+
+// DeleteItem deletes the content library item with the supplied ID.
+func DeleteItem(client *rest.Client, id string) error {
+	log.Printf("[DEBUG] Deleting content library item %q", id)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	return library.NewManager(client).DeleteLibraryItem(ctx, &library.Item{ID: id})
+}
+
+// DeployItem deploys a virtual machine from the OVF template in the content
+// library item with the supplied ID, and returns a reference to the new
+// virtual machine.
+func DeployItem(client *rest.Client, id string, deploy vcenter.Deploy, timeout time.Duration) (*types.ManagedObjectReference, error) {
+	log.Printf("[DEBUG] Deploying virtual machine %q from content library item %q", deploy.Name, id)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	ref, err := vcenter.NewManager(client).DeployLibraryItem(ctx, id, deploy)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			err = errors.New("timeout waiting for deployment to complete")
+		}
+		return nil, err
+	}
+	log.Printf("[DEBUG] Virtual machine %q deployed from content library item %q (MOID: %q)", deploy.Name, id, ref.Value)
+	return ref, nil
+}
+
+// newSource returns an ovfdeploy.Source for the supplied local path or remote
+// URL.
+func newSource(file string) (*ovfdeploy.Source, error) {
+	if isRemote(file) {
+		return ovfdeploy.NewSource("", file, false)
+	}
+	return ovfdeploy.NewSource(file, "", false)
+}
+
+// isRemote returns true if the supplied file is an HTTP(S) URL.
+func isRemote(file string) bool {
+	return strings.HasPrefix(file, "http://") || strings.HasPrefix(file, "https://")
+}
+
+// uploadItem uploads the files from the supplied source to the content library
+// item with the supplied ID through an update session, waiting up to the
+// supplied timeout for the upload to complete.
+func uploadItem(client *rest.Client, id, itemType string, src *ovfdeploy.Source, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	m := library.NewManager(client)
+	session, err := m.CreateLibraryItemUpdateSession(ctx, library.Session{LibraryItemID: id})
+	if err != nil {
+		return fmt.Errorf("could not create update session: %s", err)
+	}
+
+	if err := uploadItemFiles(ctx, client, session, itemType, src); err != nil {
+		fctx, fcancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+		defer fcancel()
+		if ferr := m.FailLibraryItemUpdateSession(fctx, session); ferr != nil {
+			log.Printf("[WARN] Could not fail update session %q: %s", session, ferr)
+		}
+		return err
+	}
+
+	if err := m.CompleteLibraryItemUpdateSession(ctx, session); err != nil {
+		return fmt.Errorf("could not complete update session: %s", err)
+	}
+	if err := m.WaitOnLibraryItemUpdateSession(ctx, session, updateSessionPollInterval, nil); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			err = errors.New("timeout waiting for upload to complete")
+		}
+		return fmt.Errorf("error waiting on update session: %s", err)
+	}
+	return nil
+}
+
+// uploadItemFiles uploads the files that make up a library item to the
+// supplied update session. ISO items consist of the single source file. OVF
+// items consist of the descriptor and each file it references.
+func uploadItemFiles(ctx context.Context, client *rest.Client, session, itemType string, src *ovfdeploy.Source) error {
+	if itemType != ItemTypeOvf {
+		r, size, err := src.Open()
+		if err != nil {
+			return fmt.Errorf("could not open %q: %s", src.Name(), err)
+		}
+		defer r.Close()
+		return uploadItemFile(ctx, client, session, src.Name(), r, size)
+	}
+
+	descriptor, err := src.Descriptor()
+	if err != nil {
+		return err
+	}
+	env, err := ovf.Unmarshal(strings.NewReader(descriptor))
+	if err != nil {
+		return fmt.Errorf("could not parse OVF descriptor: %s", err)
+	}
+	name := strings.TrimSuffix(src.Name(), path.Ext(src.Name())) + ".ovf"
+	if err := uploadItemFile(ctx, client, session, name, strings.NewReader(descriptor), int64(len(descriptor))); err != nil {
+		return err
+	}
+	var names []string
+	for _, ref := range env.References {
+		names = append(names, ref.Href)
+	}
+	return src.Files(names, func(name string, r io.Reader, size int64) error {
+		return uploadItemFile(ctx, client, session, name, r, size)
+	})
+}
+
+// uploadItemFile adds a file to the supplied update session and uploads its
+// contents to the resulting upload endpoint. The upload is cancelled when the
+// supplied context is done.
+func uploadItemFile(ctx context.Context, client *rest.Client, session, name string, r io.Reader, size int64) error {
+	log.Printf("[DEBUG] Uploading %q to update session %q", name, session)
+	f, err := library.NewManager(client).AddLibraryItemFile(ctx, session, library.UpdateFile{
+		Name:       name,
+		SourceType: "PUSH",
+		Size:       size,
+	})
+	if err != nil {
+		return fmt.Errorf("could not add %q to update session: %s", name, err)
+	}
+	u, err := url.Parse(f.UploadEndpoint.URI)
+	if err != nil {
+		return fmt.Errorf("could not parse upload endpoint for %q: %s", name, err)
+	}
+	p := soap.DefaultUpload
+	p.ContentLength = size
+	if err := client.Upload(ctx, r, u, &p); err != nil {
+		return fmt.Errorf("error uploading %q: %s", name, err)
+	}
+	return nil
+}
+
+// subscribedLibraryURL returns the URL of the subscribed library API,
+// optionally for a specific library ID.
+func subscribedLibraryURL(client *rest.Client, id string) *url.URL {
+	u := client.URL()
+	u.Path = subscribedLibraryPath
+	if id != "" {
+		u.Path += "/id:" + id
+	}
+	u.RawQuery = ""
+	return u
+}
+
+// jsonRequest returns a new request with the supplied body encoded as JSON.
+func jsonRequest(method string, u *url.URL, body interface{}) (*http.Request, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return http.NewRequest(method, u.String(), bytes.NewReader(b))
+}
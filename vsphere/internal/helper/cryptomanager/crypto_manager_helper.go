@@ -0,0 +1,155 @@
+package cryptomanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// Properties fetches the properties of the crypto manager for the supplied
+// client. An error is returned if the endpoint does not have a KMIP crypto
+// manager, such as when connecting directly to ESXi.
+func Properties(client *govmomi.Client) (*mo.CryptoManagerKmip, error) {
+	ref := client.ServiceContent.CryptoManager
+	if ref == nil || ref.Type != "CryptoManagerKmip" {
+		return nil, errors.New("the crypto manager is not available on this endpoint")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	var props mo.CryptoManagerKmip
+	pc := property.DefaultCollector(client.Client)
+	if err := pc.RetrieveOne(ctx, *ref, nil, &props); err != nil {
+		return nil, err
+	}
+	return &props, nil
+}
+
+// KeyProviders returns the KMS clusters (key providers) that are registered
+// with the crypto manager.
+func KeyProviders(client *govmomi.Client) ([]types.KmipClusterInfo, error) {
+	log.Printf("[DEBUG] Fetching registered key providers")
+	props, err := Properties(client)
+	if err != nil {
+		return nil, err
+	}
+	return props.KmipServers, nil
+}
+
+// DefaultKeyProvider returns the key provider that is marked as the default
+// for the vCenter Server, or nil if there is none.
+func DefaultKeyProvider(client *govmomi.Client) (*types.KmipClusterInfo, error) {
+	providers, err := KeyProviders(client)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range providers {
+		if p.UseAsDefault {
+			log.Printf("[DEBUG] Found default key provider %q", p.ClusterId.Id)
+			return &p, nil
+		}
+	}
+	log.Printf("[DEBUG] No default key provider found")
+	return nil, nil
+}
+
+// KeyProvider returns the key provider with the supplied ID, or nil if no key
+// provider with that ID is registered.
+func KeyProvider(client *govmomi.Client, id string) (*types.KmipClusterInfo, error) {
+	providers, err := KeyProviders(client)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range providers {
+		if p.ClusterId.Id == id {
+			return &p, nil
+		}
+	}
+	return nil, nil
+}
+
+// RegisterServer registers a KMS server with the crypto manager. The key
+// provider referenced in the spec is created if it does not exist yet.
+func RegisterServer(client *govmomi.Client, spec types.KmipServerSpec) error {
+	log.Printf("[DEBUG] Registering KMS server %q with key provider %q", spec.Info.Name, spec.ClusterId.Id)
+	req := &types.RegisterKmipServer{
+		This:   *client.ServiceContent.CryptoManager,
+		Server: spec,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	_, err := methods.RegisterKmipServer(ctx, client, req)
+	return err
+}
+
+// UpdateServer updates the settings of a KMS server that is registered with
+// the crypto manager.
+func UpdateServer(client *govmomi.Client, spec types.KmipServerSpec) error {
+	log.Printf("[DEBUG] Updating KMS server %q in key provider %q", spec.Info.Name, spec.ClusterId.Id)
+	req := &types.UpdateKmipServer{
+		This:   *client.ServiceContent.CryptoManager,
+		Server: spec,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	_, err := methods.UpdateKmipServer(ctx, client, req)
+	return err
+}
+
+// RemoveServer removes a KMS server from a key provider. The key provider is
+// removed along with its last server.
+func RemoveServer(client *govmomi.Client, id, name string) error {
+	log.Printf("[DEBUG] Removing KMS server %q from key provider %q", name, id)
+	req := &types.RemoveKmipServer{
+		This:       *client.ServiceContent.CryptoManager,
+		ClusterId:  types.KeyProviderId{Id: id},
+		ServerName: name,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	_, err := methods.RemoveKmipServer(ctx, client, req)
+	return err
+}
+
+// MarkDefault marks the key provider with the supplied ID as the default key
+// provider for the vCenter Server.
+func MarkDefault(client *govmomi.Client, id string) error {
+	log.Printf("[DEBUG] Marking key provider %q as default", id)
+	req := &types.MarkDefault{
+		This:      *client.ServiceContent.CryptoManager,
+		ClusterId: types.KeyProviderId{Id: id},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	_, err := methods.MarkDefault(ctx, client, req)
+	return err
+}
+
+// GenerateKey generates a new key in the key provider with the supplied ID.
+func GenerateKey(client *govmomi.Client, id string) (*types.CryptoKeyId, error) {
+	log.Printf("[DEBUG] Generating a new key in key provider %q", id)
+	if _, err := Properties(client); err != nil {
+		return nil, err
+	}
+	req := &types.GenerateKey{
+		This:        *client.ServiceContent.CryptoManager,
+		KeyProvider: &types.KeyProviderId{Id: id},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	res, err := methods.GenerateKey(ctx, client, req)
+	if err != nil {
+		return nil, err
+	}
+	if !res.Returnval.Success {
+		return nil, fmt.Errorf("could not generate key in key provider %q: %s", id, res.Returnval.Reason)
+	}
+	return &res.Returnval.KeyId, nil
+}
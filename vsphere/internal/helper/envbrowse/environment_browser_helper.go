@@ -111,3 +111,26 @@ func (b *EnvironmentBrowser) QueryConfigOptionDescriptor(ctx context.Context) ([
 	}
 	return res.Returnval, nil
 }
+
+// QueryConfigTarget returns the ConfigTarget for the optionally supplied host.
+// This describes the devices available to virtual machines on the host, such
+// as PCI passthrough devices and shared GPU profiles. If no host is supplied,
+// the results reflect all hosts in the compute resource that this browser
+// targets.
+func (b *EnvironmentBrowser) QueryConfigTarget(ctx context.Context, host *object.HostSystem) (*types.ConfigTarget, error) {
+	req := types.QueryConfigTarget{
+		This: b.Reference(),
+	}
+	if host != nil {
+		ref := host.Reference()
+		req.Host = &ref
+	}
+	res, err := methods.QueryConfigTarget(ctx, b.Client(), &req)
+	if err != nil {
+		return nil, err
+	}
+	if res.Returnval == nil {
+		return nil, errors.New("no config target was found for the supplied criteria")
+	}
+	return res.Returnval, nil
+}
@@ -0,0 +1,359 @@
+package esxsettings
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/vmware/govmomi/vapi/rest"
+)
+
+const (
+	// ComplianceStatusCompliant is the compliance status of a cluster or host
+	// that is running the desired software.
+	ComplianceStatusCompliant = "COMPLIANT"
+
+	// apiPath is the path of the REST API that the ESX settings (vSphere
+	// Lifecycle Manager) services are served under. The older /rest API that
+	// govmomi uses does not serve these services.
+	apiPath = "/api"
+
+	// sessionHeader is the header that carries the API session ID. The
+	// session cookie of the REST client is scoped to the /rest path, so it is
+	// sent explicitly to the /api path through this header.
+	sessionHeader = "vmware-api-session-id"
+
+	taskStatusSucceeded = "SUCCEEDED"
+	taskStatusFailed    = "FAILED"
+)
+
+// taskPollInterval is the interval at which the state of a task is polled
+// while waiting on it to complete. It is a variable so that tests can shorten
+// it.
+var taskPollInterval = time.Second * 10
+
+// AddOn is a vendor add-on in a software specification.
+type AddOn struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Software is the desired software of a cluster: an ESXi base image, an
+// optional vendor add-on, and additional components, keyed by name with their
+// versions.
+type Software struct {
+	BaseImage  string
+	AddOn      *AddOn
+	Components map[string]string
+}
+
+// HostCompliance is the compliance of a single host against the desired
+// software of its cluster.
+type HostCompliance struct {
+	Status string `json:"status"`
+}
+
+// Compliance is the compliance of a cluster against its desired software,
+// with the compliance of each host keyed by the host's managed object ID.
+type Compliance struct {
+	Status string                    `json:"status"`
+	Hosts  map[string]HostCompliance `json:"hosts"`
+}
+
+// softwareInfo is the read result of the software of a cluster.
+type softwareInfo struct {
+	BaseImage *struct {
+		Version string `json:"version"`
+	} `json:"base_image"`
+	AddOn      *AddOn `json:"add_on"`
+	Components map[string]struct {
+		Version string `json:"version"`
+	} `json:"components"`
+}
+
+// componentsUpdateSpec is the update spec for the components of a draft.
+type componentsUpdateSpec struct {
+	ComponentsToSet    map[string]string `json:"components_to_set,omitempty"`
+	ComponentsToDelete []string          `json:"components_to_delete,omitempty"`
+}
+
+// applySpec is the spec used to apply a commit to a cluster.
+type applySpec struct {
+	Commit     string `json:"commit,omitempty"`
+	AcceptEULA bool   `json:"accept_eula"`
+}
+
+// taskInfo is the read result of a task.
+type taskInfo struct {
+	Status string `json:"status"`
+	Error  *struct {
+		Messages []struct {
+			DefaultMessage string `json:"default_message"`
+		} `json:"messages"`
+	} `json:"error"`
+}
+
+// StatusError is the error returned when the API responds with a status code
+// other than 2xx.
+type StatusError struct {
+	Method     string
+	Path       string
+	Status     string
+	StatusCode int
+	Detail     string
+}
+
+// Error implements error for StatusError.
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s %s: %s: %s", e.Method, e.Path, e.Status, e.Detail)
+}
+
+// IsNotFoundError checks to see if the supplied error is a not found error
+// returned by the ESX settings API.
+func IsNotFoundError(err error) bool {
+	if serr, ok := err.(*StatusError); ok {
+		return serr.StatusCode == http.StatusNotFound
+	}
+	return false
+}
+
+// SoftwareFromCluster returns the desired software of the cluster with the
+// supplied managed object ID.
+func SoftwareFromCluster(client *rest.Client, clusterID string) (*Software, error) {
+	log.Printf("[DEBUG] Reading desired software for cluster %q", clusterID)
+	var info softwareInfo
+	if err := do(client, http.MethodGet, softwarePath(clusterID), nil, nil, &info); err != nil {
+		return nil, err
+	}
+	sw := &Software{
+		AddOn:      info.AddOn,
+		Components: make(map[string]string),
+	}
+	if info.BaseImage != nil {
+		sw.BaseImage = info.BaseImage.Version
+	}
+	for name, component := range info.Components {
+		sw.Components[name] = component.Version
+	}
+	return sw, nil
+}
+
+// ApplySoftware sets the desired software of a cluster and remediates the
+// hosts in the cluster against it. This is done by creating a draft, setting
+// the base image, add-on, and components on it, committing it, and applying
+// the commit. Components named in remove are removed from the desired
+// software.
+func ApplySoftware(client *rest.Client, clusterID string, sw *Software, remove []string, acceptEULA bool, timeout time.Duration) error {
+	log.Printf("[DEBUG] Setting desired software for cluster %q", clusterID)
+	var draft string
+	if err := do(client, http.MethodPost, softwarePath(clusterID)+"/drafts", nil, nil, &draft); err != nil {
+		return fmt.Errorf("error creating draft: %s", err)
+	}
+	commit, err := commitDraft(client, clusterID, draft, sw, remove)
+	if err != nil {
+		// Clean up the draft so that it does not block the next attempt.
+		if derr := do(client, http.MethodDelete, draftPath(clusterID, draft), nil, nil, nil); derr != nil {
+			log.Printf("[DEBUG] Error deleting draft %q for cluster %q: %s", draft, clusterID, derr)
+		}
+		return err
+	}
+	return apply(client, clusterID, commit, acceptEULA, timeout)
+}
+
+// Remediate remediates the hosts in a cluster against the latest desired
+// software of the cluster.
+func Remediate(client *rest.Client, clusterID string, acceptEULA bool, timeout time.Duration) error {
+	log.Printf("[DEBUG] Remediating cluster %q", clusterID)
+	return apply(client, clusterID, "", acceptEULA, timeout)
+}
+
+// Scan scans the hosts in a cluster for compliance against the desired
+// software of the cluster and waits for the scan to finish. The result is
+// stored by vCenter and can be read with ComplianceFromCluster.
+func Scan(client *rest.Client, clusterID string, timeout time.Duration) error {
+	log.Printf("[DEBUG] Scanning cluster %q for software compliance", clusterID)
+	var task string
+	q := url.Values{"action": []string{"scan"}, "vmw-task": []string{"true"}}
+	if err := do(client, http.MethodPost, softwarePath(clusterID), q, nil, &task); err != nil {
+		return fmt.Errorf("error starting compliance scan: %s", err)
+	}
+	if err := waitForTask(client, task, timeout); err != nil {
+		return fmt.Errorf("error scanning for compliance: %s", err)
+	}
+	return nil
+}
+
+// ComplianceFromCluster returns the result of the last compliance scan of a
+// cluster. It does not start a new scan. If the cluster has never been
+// scanned, a not found error is returned.
+func ComplianceFromCluster(client *rest.Client, clusterID string) (*Compliance, error) {
+	log.Printf("[DEBUG] Reading software compliance for cluster %q", clusterID)
+	var res Compliance
+	if err := do(client, http.MethodGet, softwarePath(clusterID)+"/compliance", nil, nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// commitDraft sets the supplied software on a draft and commits it,
+// returning the ID of the commit.
+func commitDraft(client *rest.Client, clusterID, draft string, sw *Software, remove []string) (string, error) {
+	p := draftPath(clusterID, draft)
+	baseImage := struct {
+		Version string `json:"version"`
+	}{sw.BaseImage}
+	if err := do(client, http.MethodPut, p+"/software/base-image", nil, baseImage, nil); err != nil {
+		return "", fmt.Errorf("error setting base image: %s", err)
+	}
+	if sw.AddOn != nil {
+		if err := do(client, http.MethodPut, p+"/software/add-on", nil, sw.AddOn, nil); err != nil {
+			return "", fmt.Errorf("error setting add-on: %s", err)
+		}
+	} else {
+		if err := do(client, http.MethodDelete, p+"/software/add-on", nil, nil, nil); err != nil && !IsNotFoundError(err) {
+			return "", fmt.Errorf("error removing add-on: %s", err)
+		}
+	}
+	if len(sw.Components) > 0 || len(remove) > 0 {
+		spec := componentsUpdateSpec{
+			ComponentsToSet:    sw.Components,
+			ComponentsToDelete: remove,
+		}
+		if err := do(client, http.MethodPatch, p+"/software/components", nil, spec, nil); err != nil {
+			return "", fmt.Errorf("error setting components: %s", err)
+		}
+	}
+
+	var commit string
+	q := url.Values{"action": []string{"commit"}}
+	message := struct {
+		Message string `json:"message"`
+	}{"Committed by Terraform"}
+	if err := do(client, http.MethodPost, p, q, message, &commit); err != nil {
+		return "", fmt.Errorf("error committing draft: %s", err)
+	}
+	return commit, nil
+}
+
+// apply applies a commit to a cluster and waits for the remediation of the
+// hosts to finish. If commit is empty, the latest commit is applied.
+func apply(client *rest.Client, clusterID, commit string, acceptEULA bool, timeout time.Duration) error {
+	var task string
+	q := url.Values{"action": []string{"apply"}, "vmw-task": []string{"true"}}
+	spec := applySpec{
+		Commit:     commit,
+		AcceptEULA: acceptEULA,
+	}
+	if err := do(client, http.MethodPost, softwarePath(clusterID), q, spec, &task); err != nil {
+		return fmt.Errorf("error starting remediation: %s", err)
+	}
+	if err := waitForTask(client, task, timeout); err != nil {
+		return fmt.Errorf("error remediating cluster: %s", err)
+	}
+	return nil
+}
+
+// waitForTask polls a task until it has completed, returning an error if it
+// fails or does not complete within the supplied timeout.
+func waitForTask(client *rest.Client, id string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		var info taskInfo
+		if err := do(client, http.MethodGet, "/cis/tasks/"+id, nil, nil, &info); err != nil {
+			return err
+		}
+		switch info.Status {
+		case taskStatusSucceeded:
+			return nil
+		case taskStatusFailed:
+			if info.Error != nil && len(info.Error.Messages) > 0 {
+				return errors.New(info.Error.Messages[0].DefaultMessage)
+			}
+			return fmt.Errorf("task %q failed", id)
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timeout waiting for task %q to complete", id)
+		}
+		time.Sleep(taskPollInterval)
+	}
+}
+
+// softwarePath returns the API path of the software of a cluster.
+func softwarePath(clusterID string) string {
+	return "/esx/settings/clusters/" + clusterID + "/software"
+}
+
+// draftPath returns the API path of a software draft of a cluster.
+func draftPath(clusterID, draft string) string {
+	return softwarePath(clusterID) + "/drafts/" + draft
+}
+
+// sessionID returns the ID of the session of the REST client.
+func sessionID(client *rest.Client) (string, error) {
+	if client.Jar != nil {
+		for _, c := range client.Jar.Cookies(client.URL()) {
+			if c.Name == sessionHeader {
+				return c.Value, nil
+			}
+		}
+	}
+	return "", errors.New("REST API session not found")
+}
+
+// do sends a request to the API and decodes the JSON response into res, if
+// res is not nil.
+func do(client *rest.Client, method, path string, query url.Values, body, res interface{}) error {
+	u := client.URL()
+	u.Path = apiPath + path
+	u.RawQuery = query.Encode()
+
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, u.String(), r)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	id, err := sessionID(client)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(sessionHeader, id)
+
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	return client.Client.Do(ctx, req, func(resp *http.Response) error {
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			detail, _ := ioutil.ReadAll(resp.Body)
+			return &StatusError{
+				Method:     method,
+				Path:       u.Path,
+				Status:     resp.Status,
+				StatusCode: resp.StatusCode,
+				Detail:     string(bytes.TrimSpace(detail)),
+			}
+		}
+		if res == nil {
+			return nil
+		}
+		return json.NewDecoder(resp.Body).Decode(res)
+	})
+}
@@ -0,0 +1,200 @@
+package esxsettings
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vmware/govmomi/vapi/rest"
+	"github.com/vmware/govmomi/vim25/soap"
+)
+
+// testRequest is a request received by the test API server.
+type testRequest struct {
+	Method string
+	Path   string
+	Query  string
+	Body   string
+}
+
+// testServer is a fake ESX settings API. Responses are keyed by method and
+// path. Every request received is recorded in order.
+type testServer struct {
+	t         *testing.T
+	responses map[string]interface{}
+
+	mu       sync.Mutex
+	requests []testRequest
+}
+
+func (s *testServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get(sessionHeader) != "session" {
+		s.t.Errorf("%s %s: missing session header", r.Method, r.URL.Path)
+	}
+	body, _ := ioutil.ReadAll(r.Body)
+	s.mu.Lock()
+	s.requests = append(s.requests, testRequest{
+		Method: r.Method,
+		Path:   strings.TrimPrefix(r.URL.Path, apiPath),
+		Query:  r.URL.Query().Get("action"),
+		Body:   string(body),
+	})
+	s.mu.Unlock()
+
+	res, ok := s.responses[r.Method+" "+strings.TrimPrefix(r.URL.Path, apiPath)]
+	if !ok {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	if res == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// testClient starts a test API server with the supplied responses and
+// returns a REST client logged in to it, along with a function that stops the
+// server.
+func testClient(t *testing.T, responses map[string]interface{}) (*rest.Client, *testServer, func()) {
+	s := &testServer{t: t, responses: responses}
+	ts := httptest.NewServer(s)
+
+	u, err := url.Parse(ts.URL + "/rest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &rest.Client{Client: soap.NewClient(u, true)}
+	client.Jar.SetCookies(client.URL(), []*http.Cookie{{Name: sessionHeader, Value: "session"}})
+
+	old := taskPollInterval
+	taskPollInterval = time.Millisecond
+	return client, s, func() {
+		taskPollInterval = old
+		ts.Close()
+	}
+}
+
+func TestApplySoftware(t *testing.T) {
+	sp := softwarePath("domain-c1")
+	client, s, done := testClient(t, map[string]interface{}{
+		"POST " + sp + "/drafts":                        "1",
+		"PUT " + sp + "/drafts/1/software/base-image":   nil,
+		"PUT " + sp + "/drafts/1/software/add-on":       nil,
+		"PATCH " + sp + "/drafts/1/software/components": nil,
+		"POST " + sp + "/drafts/1":                      "2",
+		"POST " + sp:                                    "task-1",
+		"GET /cis/tasks/task-1":                         json.RawMessage(`{"status":"SUCCEEDED"}`),
+	})
+	defer done()
+
+	sw := &Software{
+		BaseImage:  "7.0.0-1",
+		AddOn:      &AddOn{Name: "vendor", Version: "1.0"},
+		Components: map[string]string{"driver": "2.0"},
+	}
+	if err := ApplySoftware(client, "domain-c1", sw, []string{"old"}, true, time.Minute); err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+
+	expected := []testRequest{
+		{Method: "POST", Path: sp + "/drafts"},
+		{Method: "PUT", Path: sp + "/drafts/1/software/base-image", Body: `{"version":"7.0.0-1"}`},
+		{Method: "PUT", Path: sp + "/drafts/1/software/add-on", Body: `{"name":"vendor","version":"1.0"}`},
+		{Method: "PATCH", Path: sp + "/drafts/1/software/components", Body: `{"components_to_set":{"driver":"2.0"},"components_to_delete":["old"]}`},
+		{Method: "POST", Path: sp + "/drafts/1", Query: "commit", Body: `{"message":"Committed by Terraform"}`},
+		{Method: "POST", Path: sp, Query: "apply", Body: `{"commit":"2","accept_eula":true}`},
+		{Method: "GET", Path: "/cis/tasks/task-1"},
+	}
+	if !reflect.DeepEqual(expected, s.requests) {
+		t.Fatalf("expected requests %#v, got %#v", expected, s.requests)
+	}
+}
+
+func TestApplySoftwareDeletesDraftOnError(t *testing.T) {
+	sp := softwarePath("domain-c1")
+	client, s, done := testClient(t, map[string]interface{}{
+		"POST " + sp + "/drafts":     "1",
+		"DELETE " + sp + "/drafts/1": nil,
+	})
+	defer done()
+
+	err := ApplySoftware(client, "domain-c1", &Software{BaseImage: "7.0.0-1"}, nil, false, time.Minute)
+	if err == nil || !strings.Contains(err.Error(), "error setting base image") {
+		t.Fatalf("expected base image error, got %v", err)
+	}
+	last := s.requests[len(s.requests)-1]
+	if last.Method != "DELETE" || last.Path != sp+"/drafts/1" {
+		t.Fatalf("expected draft to be deleted, last request was %s %s", last.Method, last.Path)
+	}
+}
+
+func TestWaitForTaskFailed(t *testing.T) {
+	client, _, done := testClient(t, map[string]interface{}{
+		"POST " + softwarePath("domain-c1"): "task-1",
+		"GET /cis/tasks/task-1":             json.RawMessage(`{"status":"FAILED","error":{"messages":[{"default_message":"host is in maintenance mode"}]}}`),
+	})
+	defer done()
+
+	err := Remediate(client, "domain-c1", false, time.Minute)
+	if err == nil || !strings.Contains(err.Error(), "host is in maintenance mode") {
+		t.Fatalf("expected task error, got %v", err)
+	}
+}
+
+func TestWaitForTaskTimeout(t *testing.T) {
+	client, _, done := testClient(t, map[string]interface{}{
+		"POST " + softwarePath("domain-c1"): "task-1",
+		"GET /cis/tasks/task-1":             json.RawMessage(`{"status":"RUNNING"}`),
+	})
+	defer done()
+
+	err := Scan(client, "domain-c1", time.Millisecond*20)
+	if err == nil || !strings.Contains(err.Error(), "timeout waiting for task") {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+}
+
+func TestComplianceFromClusterNotFound(t *testing.T) {
+	client, _, done := testClient(t, map[string]interface{}{})
+	defer done()
+
+	_, err := ComplianceFromCluster(client, "domain-c1")
+	if !IsNotFoundError(err) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+	if IsNotFoundError(errors.New("not found")) {
+		t.Fatal("expected a plain error not to be a not found error")
+	}
+}
+
+func TestComplianceFromCluster(t *testing.T) {
+	client, _, done := testClient(t, map[string]interface{}{
+		"GET " + softwarePath("domain-c1") + "/compliance": json.RawMessage(`{"status":"NON_COMPLIANT","hosts":{"host-1":{"status":"COMPLIANT"},"host-2":{"status":"NON_COMPLIANT"}}}`),
+	})
+	defer done()
+
+	actual, err := ComplianceFromCluster(client, "domain-c1")
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	expected := &Compliance{
+		Status: "NON_COMPLIANT",
+		Hosts: map[string]HostCompliance{
+			"host-1": {Status: ComplianceStatusCompliant},
+			"host-2": {Status: "NON_COMPLIANT"},
+		},
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %#v, got %#v", expected, actual)
+	}
+}
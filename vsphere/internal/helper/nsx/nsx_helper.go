@@ -13,6 +13,10 @@ import (
 	"github.com/vmware/govmomi/vim25/types"
 )
 
+// opaqueNetworkSegmentPathKey is the extra configuration key that NSX-T uses
+// to record the policy path of the segment backing an opaque network.
+const opaqueNetworkSegmentPathKey = "com.vmware.opaquenetwork.segment.path"
+
 // OpaqueNetworkFromNetworkID looks for an opaque network via its opaque network ID.
 //
 // As NSX support in the Terraform provider is not 100% as of the time of this
@@ -22,6 +26,28 @@ import (
 // network backing to the managed object reference that represents the opaque
 // network in vCenter.
 func OpaqueNetworkFromNetworkID(client *govmomi.Client, id string) (*object.OpaqueNetwork, error) {
+	return opaqueNetworkFromFilter(client, func(net mo.OpaqueNetwork) bool {
+		return net.Summary.(*types.OpaqueNetworkSummary).OpaqueNetworkId == id
+	}, fmt.Sprintf("ID %q", id))
+}
+
+// OpaqueNetworkFromSegmentPath looks for an opaque network via the policy
+// path of the NSX-T segment that backs it, ie: /infra/segments/web.
+//
+// NSX-T publishes the segment path in the extra configuration of the opaque
+// network. Opaque networks created by older versions of NSX-T, or through the
+// NSX-T manager API instead of the policy API, will not have this set and
+// cannot be found with this function.
+func OpaqueNetworkFromSegmentPath(client *govmomi.Client, path string) (*object.OpaqueNetwork, error) {
+	return opaqueNetworkFromFilter(client, func(net mo.OpaqueNetwork) bool {
+		return SegmentPath(&net) == path
+	}, fmt.Sprintf("segment path %q", path))
+}
+
+// opaqueNetworkFromFilter returns the first opaque network that matches the
+// supplied filter function. desc is used to describe the search in the error
+// that is returned if no network matches.
+func opaqueNetworkFromFilter(client *govmomi.Client, filter func(mo.OpaqueNetwork) bool, desc string) (*object.OpaqueNetwork, error) {
 	// We use the same ContainerView logic that we use with networkFromID, but we
 	// go a step further and limit it to opaque networks only.
 	m := view.NewManager(client.Client)
@@ -40,13 +66,13 @@ func OpaqueNetworkFromNetworkID(client *govmomi.Client, id string) (*object.Opaq
 	}()
 
 	var networks []mo.OpaqueNetwork
-	err = v.Retrieve(vctx, []string{"OpaqueNetwork"}, nil, &networks)
+	err = v.Retrieve(vctx, []string{"OpaqueNetwork"}, []string{"summary", "extraConfig"}, &networks)
 	if err != nil {
 		return nil, err
 	}
 
 	for _, net := range networks {
-		if net.Summary.(*types.OpaqueNetworkSummary).OpaqueNetworkId == id {
+		if filter(net) {
 			ref := net.Reference()
 			finder := find.NewFinder(client.Client, false)
 			fctx, fcancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
@@ -61,5 +87,31 @@ func OpaqueNetworkFromNetworkID(client *govmomi.Client, id string) (*object.Opaq
 			return nref.(*object.OpaqueNetwork), nil
 		}
 	}
-	return nil, fmt.Errorf("could not find opaque network with ID %q", id)
+	return nil, fmt.Errorf("could not find opaque network with %s", desc)
+}
+
+// OpaqueNetworkProperties gets the properties for a specific opaque network.
+func OpaqueNetworkProperties(net *object.OpaqueNetwork) (*mo.OpaqueNetwork, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	var props mo.OpaqueNetwork
+	if err := net.Properties(ctx, net.Reference(), nil, &props); err != nil {
+		return nil, err
+	}
+	return &props, nil
+}
+
+// SegmentPath returns the policy path of the NSX-T segment backing an opaque
+// network, or an empty string if the network does not have one.
+func SegmentPath(props *mo.OpaqueNetwork) string {
+	for _, bov := range props.ExtraConfig {
+		ov := bov.GetOptionValue()
+		if ov.Key != opaqueNetworkSegmentPathKey {
+			continue
+		}
+		if v, ok := ov.Value.(string); ok {
+			return v
+		}
+	}
+	return ""
 }
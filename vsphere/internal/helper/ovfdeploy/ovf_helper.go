@@ -0,0 +1,368 @@
+package ovfdeploy
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/virtualmachine"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/nfc"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/ovf"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// Source represents an OVF or OVA package, either on the local filesystem or
+// at a remote HTTP(S) URL.
+type Source struct {
+	// The local path or remote URL of the package.
+	path string
+
+	// The parsed URL of the package, if it is at a remote URL.
+	url *url.URL
+
+	// Whether or not the package is at a remote URL.
+	remote bool
+
+	// Whether or not the package is an OVA (tar) archive.
+	ova bool
+
+	// The HTTP client used to fetch remote packages.
+	httpClient *http.Client
+}
+
+// NewSource returns a new Source for the OVF or OVA package at the supplied
+// local path or remote URL. Remote URLs must have an http or https scheme.
+// allowUnverifiedSSL controls whether or not TLS certificate verification is
+// skipped when fetching remote packages.
+func NewSource(localPath, remoteURL string, allowUnverifiedSSL bool) (*Source, error) {
+	s := &Source{}
+	switch {
+	case localPath != "" && remoteURL != "":
+		return nil, errors.New("only one of a local path or a remote URL can be specified")
+	case localPath != "":
+		s.path = localPath
+	case remoteURL != "":
+		u, err := url.Parse(remoteURL)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse URL %q: %s", remoteURL, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return nil, fmt.Errorf("unsupported URL scheme %q in %q", u.Scheme, remoteURL)
+		}
+		s.path = remoteURL
+		s.url = u
+		s.remote = true
+		s.httpClient = &http.Client{
+			Transport: &http.Transport{
+				Proxy: http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{
+					InsecureSkipVerify: allowUnverifiedSSL,
+				},
+			},
+		}
+	default:
+		return nil, errors.New("one of a local path or a remote URL must be specified")
+	}
+	s.ova = strings.EqualFold(path.Ext(s.Name()), ".ova")
+	return s, nil
+}
+
+// errStopWalk is returned by the function supplied to walkArchive to stop
+// reading the archive without an error.
+var errStopWalk = errors.New("stop walking archive")
+
+// Descriptor returns the OVF descriptor of the package. For OVA packages, this
+// is the first file in the archive with an .ovf extension.
+func (s *Source) Descriptor() (string, error) {
+	log.Printf("[DEBUG] Reading OVF descriptor from %q", s.path)
+	if !s.ova {
+		r, _, err := s.open(s.path)
+		if err != nil {
+			return "", fmt.Errorf("could not read OVF descriptor: %s", err)
+		}
+		defer r.Close()
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			return "", fmt.Errorf("could not read OVF descriptor: %s", err)
+		}
+		return string(b), nil
+	}
+	var b []byte
+	err := s.walkArchive(func(name string, r io.Reader, _ int64) error {
+		if !strings.EqualFold(path.Ext(name), ".ovf") {
+			return nil
+		}
+		var err error
+		if b, err = ioutil.ReadAll(r); err != nil {
+			return err
+		}
+		return errStopWalk
+	})
+	if err != nil {
+		return "", fmt.Errorf("could not read OVF descriptor: %s", err)
+	}
+	if b == nil {
+		return "", fmt.Errorf("could not read OVF descriptor: no .ovf file found in archive %q", s.path)
+	}
+	return string(b), nil
+}
+
+// Name returns the base file name of the package. For remote packages, this
+// is taken from the path of the URL, without any query string.
+func (s *Source) Name() string {
+	if s.remote {
+		return path.Base(s.url.Path)
+	}
+	return path.Base(filepath.ToSlash(s.path))
+}
+
+// Open opens the package file itself and returns its contents and size.
+func (s *Source) Open() (io.ReadCloser, int64, error) {
+	return s.open(s.path)
+}
+
+// Files calls the supplied function with the contents and size of each of the
+// supplied files referenced by the OVF descriptor. For OVA packages, the
+// archive is opened once and the files are read from it in the order they
+// are stored in, which is the order of the references in the descriptor for
+// a well-formed package. Otherwise, each file is opened in turn, resolved
+// relative to the location of the descriptor. An error is returned if any of
+// the files cannot be found.
+func (s *Source) Files(names []string, fn func(name string, r io.Reader, size int64) error) error {
+	if !s.ova {
+		for _, name := range names {
+			if err := s.file(name, fn); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	pending := make(map[string]string)
+	for _, name := range names {
+		pending[path.Clean(name)] = name
+	}
+	if len(pending) < 1 {
+		return nil
+	}
+	err := s.walkArchive(func(entry string, r io.Reader, size int64) error {
+		name, ok := pending[path.Clean(entry)]
+		if !ok {
+			return nil
+		}
+		delete(pending, path.Clean(entry))
+		if err := fn(name, r, size); err != nil {
+			return err
+		}
+		if len(pending) < 1 {
+			return errStopWalk
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if _, ok := pending[path.Clean(name)]; ok {
+			return fmt.Errorf("could not find %q in archive %q", name, s.path)
+		}
+	}
+	return nil
+}
+
+// file opens a single file referenced by the OVF descriptor of an OVF
+// package and calls the supplied function with its contents and size.
+func (s *Source) file(name string, fn func(name string, r io.Reader, size int64) error) error {
+	var r io.ReadCloser
+	var size int64
+	var err error
+	if s.remote {
+		var ref *url.URL
+		if ref, err = url.Parse(name); err != nil {
+			return err
+		}
+		r, size, err = s.open(s.url.ResolveReference(ref).String())
+	} else {
+		r, size, err = s.open(filepath.Join(filepath.Dir(s.path), name))
+	}
+	if err != nil {
+		return fmt.Errorf("could not open %q: %s", name, err)
+	}
+	defer r.Close()
+	return fn(name, r, size)
+}
+
+// walkArchive opens the OVA archive and calls the supplied function with the
+// name, contents and size of each file in it, in archive order, until the
+// end of the archive is reached or the function returns an error. If the
+// function returns errStopWalk, walkArchive stops and returns nil.
+func (s *Source) walkArchive(fn func(name string, r io.Reader, size int64) error) error {
+	f, _, err := s.open(s.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	tr := tar.NewReader(f)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading archive %q: %s", s.path, err)
+		}
+		if h.FileInfo().IsDir() {
+			continue
+		}
+		if err := fn(h.Name, tr, h.Size); err != nil {
+			if err == errStopWalk {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// open opens a local file or fetches a remote URL, and returns its contents
+// and size.
+func (s *Source) open(p string) (io.ReadCloser, int64, error) {
+	if !s.remote {
+		f, err := os.Open(p)
+		if err != nil {
+			return nil, 0, err
+		}
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, 0, err
+		}
+		return f, info.Size(), nil
+	}
+	resp, err := s.httpClient.Get(p)
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, 0, fmt.Errorf("error fetching %q: %s", p, resp.Status)
+	}
+	return resp.Body, resp.ContentLength, nil
+}
+
+// CreateImportSpec wraps OvfManager.CreateImportSpec and returns an error if
+// the resulting import spec contains any errors. Warnings are logged.
+func CreateImportSpec(
+	client *govmomi.Client,
+	descriptor string,
+	pool *object.ResourcePool,
+	ds *object.Datastore,
+	params types.OvfCreateImportSpecParams,
+) (*types.OvfCreateImportSpecResult, error) {
+	log.Printf("[DEBUG] Creating OVF import spec for %q", params.EntityName)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	m := ovf.NewManager(client.Client)
+	spec, err := m.CreateImportSpec(ctx, descriptor, pool, ds, params)
+	if err != nil {
+		return nil, err
+	}
+	if len(spec.Error) > 0 {
+		var msgs []string
+		for _, e := range spec.Error {
+			msgs = append(msgs, e.LocalizedMessage)
+		}
+		return nil, fmt.Errorf("error creating OVF import spec: %s", strings.Join(msgs, "; "))
+	}
+	for _, w := range spec.Warning {
+		log.Printf("[WARN] OVF import spec for %q: %s", params.EntityName, w.LocalizedMessage)
+	}
+	return spec, nil
+}
+
+// Deploy imports the virtual machine described by the supplied import spec
+// into the supplied resource pool and folder, uploads the files in the
+// package through the resulting NFC lease, and returns the new virtual
+// machine. timeout is the time, in minutes, to wait for the upload to
+// complete.
+func Deploy(
+	client *govmomi.Client,
+	src *Source,
+	spec *types.OvfCreateImportSpecResult,
+	pool *object.ResourcePool,
+	fo *object.Folder,
+	hs *object.HostSystem,
+	timeout int,
+) (*object.VirtualMachine, error) {
+	log.Printf("[DEBUG] Importing OVF package %q", src.path)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	lease, err := pool.ImportVApp(ctx, spec.ImportSpec, fo, hs)
+	if err != nil {
+		return nil, fmt.Errorf("error importing OVF package: %s", err)
+	}
+	info, err := lease.Wait(ctx, spec.FileItem)
+	if err != nil {
+		return nil, fmt.Errorf("error waiting on NFC lease: %s", err)
+	}
+
+	if err := upload(lease, info, src, timeout); err != nil {
+		actx, acancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+		defer acancel()
+		if aerr := lease.Abort(actx, nil); aerr != nil {
+			log.Printf("[WARN] Could not abort NFC lease: %s", aerr)
+		}
+		return nil, err
+	}
+
+	cctx, ccancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer ccancel()
+	if err := lease.Complete(cctx); err != nil {
+		return nil, fmt.Errorf("error completing NFC lease: %s", err)
+	}
+	log.Printf("[DEBUG] OVF package %q: import complete (MOID: %q)", src.path, info.Entity.Value)
+	return virtualmachine.FromMOID(client, info.Entity.Value)
+}
+
+// upload uploads each of the file items in the lease info from the package
+// source, keeping the lease alive while doing so.
+func upload(lease *nfc.Lease, info *nfc.LeaseInfo, src *Source, timeout int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*time.Duration(timeout))
+	defer cancel()
+	updater := lease.StartUpdater(ctx, info)
+	defer updater.Done()
+
+	items := make(map[string]nfc.FileItem)
+	var names []string
+	for _, item := range info.Items {
+		items[item.Path] = item
+		names = append(names, item.Path)
+	}
+	return src.Files(names, func(name string, r io.Reader, size int64) error {
+		log.Printf("[DEBUG] Uploading %q from OVF package %q", name, src.path)
+		opts := soap.Upload{
+			ContentLength: size,
+		}
+		if err := lease.Upload(ctx, items[name], r, opts); err != nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				err = errors.New("timeout waiting for upload to complete")
+			}
+			return fmt.Errorf("error uploading %q: %s", name, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,235 @@
+package ovfdeploy
+
+import (
+	"archive/tar"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testDescriptor = `<?xml version="1.0" encoding="UTF-8"?><Envelope/>`
+
+const testDisk = "disk contents"
+
+// testWriteOva writes an OVA archive containing a descriptor and a disk to
+// the supplied path.
+func testWriteOva(t *testing.T, p string) {
+	f, err := os.Create(p)
+	if err != nil {
+		t.Fatalf("error creating OVA: %s", err)
+	}
+	defer f.Close()
+	testWriteOvaTo(t, f)
+}
+
+// testWriteOvaTo writes an OVA archive containing a descriptor and a disk to
+// the supplied writer.
+func testWriteOvaTo(t *testing.T, w io.Writer) {
+	tw := tar.NewWriter(w)
+	files := []struct {
+		name string
+		body string
+	}{
+		{name: "test.ovf", body: testDescriptor},
+		{name: "test-disk1.vmdk", body: testDisk},
+	}
+	for _, file := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: file.name, Mode: 0600, Size: int64(len(file.body))}); err != nil {
+			t.Fatalf("error writing OVA header: %s", err)
+		}
+		if _, err := tw.Write([]byte(file.body)); err != nil {
+			t.Fatalf("error writing OVA file: %s", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("error closing OVA: %s", err)
+	}
+}
+
+func TestNewSource(t *testing.T) {
+	cases := []struct {
+		name      string
+		localPath string
+		remoteURL string
+		ova       bool
+		success   bool
+	}{
+		{
+			name:      "local OVF",
+			localPath: "/tmp/test.ovf",
+			success:   true,
+		},
+		{
+			name:      "remote OVA",
+			remoteURL: "https://example.com/test.OVA",
+			ova:       true,
+			success:   true,
+		},
+		{
+			name:      "remote OVA with query string",
+			remoteURL: "https://example.com/test.ova?token=abc.ovf",
+			ova:       true,
+			success:   true,
+		},
+		{
+			name:      "remote OVF with query string",
+			remoteURL: "https://example.com/test.ovf?file=test.ova",
+			success:   true,
+		},
+		{
+			name:      "unsupported scheme",
+			remoteURL: "ftp://example.com/test.ova",
+			success:   false,
+		},
+		{
+			name:      "both set",
+			localPath: "/tmp/test.ovf",
+			remoteURL: "https://example.com/test.ova",
+			success:   false,
+		},
+		{
+			name:    "none set",
+			success: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := NewSource(tc.localPath, tc.remoteURL, false)
+			if tc.success != (err == nil) {
+				t.Fatalf("expected success to be %t, got error %v", tc.success, err)
+			}
+			if err == nil && s.ova != tc.ova {
+				t.Fatalf("expected ova to be %t, got %t", tc.ova, s.ova)
+			}
+		})
+	}
+}
+
+func TestSourceLocalOva(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ovfdeploy")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	p := filepath.Join(dir, "test.ova")
+	testWriteOva(t, p)
+
+	s, err := NewSource(p, "", false)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	descriptor, err := s.Descriptor()
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if descriptor != testDescriptor {
+		t.Fatalf("expected descriptor %q, got %q", testDescriptor, descriptor)
+	}
+
+	files := testSourceFiles(t, s, "test-disk1.vmdk")
+	if files["test-disk1.vmdk"] != testDisk {
+		t.Fatalf("expected disk %q, got %q", testDisk, files["test-disk1.vmdk"])
+	}
+
+	if err := s.Files([]string{"test-disk1.vmdk", "missing.vmdk"}, func(string, io.Reader, int64) error { return nil }); err == nil {
+		t.Fatal("expected error, got none")
+	}
+}
+
+func TestSourceRemoteOva(t *testing.T) {
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/test.ova" {
+			http.NotFound(w, r)
+			return
+		}
+		testWriteOvaTo(t, w)
+	}))
+	defer ts.Close()
+
+	s, err := NewSource("", ts.URL+"/test.ova?token=abc", false)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if s.Name() != "test.ova" {
+		t.Fatalf("expected name %q, got %q", "test.ova", s.Name())
+	}
+	descriptor, err := s.Descriptor()
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if descriptor != testDescriptor {
+		t.Fatalf("expected descriptor %q, got %q", testDescriptor, descriptor)
+	}
+	expected := map[string]string{
+		"test.ovf":        testDescriptor,
+		"test-disk1.vmdk": testDisk,
+	}
+	if actual := testSourceFiles(t, s, "test.ovf", "test-disk1.vmdk"); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected files %#v, got %#v", expected, actual)
+	}
+	// The archive is read once for the descriptor and once for the files.
+	if requests != 2 {
+		t.Fatalf("expected 2 requests for the archive, got %d", requests)
+	}
+}
+
+// testSourceFiles reads the supplied files from the source, and returns their
+// contents keyed by name. The size of each file is checked against its
+// contents.
+func testSourceFiles(t *testing.T, s *Source, names ...string) map[string]string {
+	files := make(map[string]string)
+	err := s.Files(names, func(name string, r io.Reader, size int64) error {
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		if size != int64(len(b)) {
+			t.Fatalf("expected size of %q to be %d, got %d", name, len(b), size)
+		}
+		files[name] = string(b)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	return files
+}
+
+func TestSourceLocalOvf(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ovfdeploy")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	p := filepath.Join(dir, "test.ovf")
+	if err := ioutil.WriteFile(p, []byte(testDescriptor), 0600); err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "test-disk1.vmdk"), []byte(testDisk), 0600); err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+
+	s, err := NewSource(p, "", false)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	descriptor, err := s.Descriptor()
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if descriptor != testDescriptor {
+		t.Fatalf("expected descriptor %q, got %q", testDescriptor, descriptor)
+	}
+	files := testSourceFiles(t, s, "test-disk1.vmdk")
+	if files["test-disk1.vmdk"] != testDisk {
+		t.Fatalf("expected disk %q, got %q", testDisk, files["test-disk1.vmdk"])
+	}
+}
@@ -128,6 +128,17 @@ func OSFamily(client *govmomi.Client, pool *object.ResourcePool, guest string) (
 	return computeresource.OSFamily(client, pprops.Owner, guest)
 }
 
+// ConfigTarget uses the resource pool's environment browser to get the config
+// target for the pool, optionally narrowed down to the supplied host.
+func ConfigTarget(client *govmomi.Client, pool *object.ResourcePool, host *object.HostSystem) (*types.ConfigTarget, error) {
+	log.Printf("[DEBUG] Fetching config target for resource pool %q", pool.Reference().Value)
+	pprops, err := Properties(pool)
+	if err != nil {
+		return nil, err
+	}
+	return computeresource.ConfigTargetFromReference(client, pprops.Owner, host)
+}
+
 // Create creates a ResourcePool.
 func Create(rp *object.ResourcePool, name string, spec *types.ResourceConfigSpec) (*object.ResourcePool, error) {
 	log.Printf("[DEBUG] Creating resource pool %q", fmt.Sprintf("%s/%s", rp.InventoryPath, name))
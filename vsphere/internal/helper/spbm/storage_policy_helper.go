@@ -0,0 +1,162 @@
+package spbm
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/vmware/govmomi/pbm"
+	"github.com/vmware/govmomi/pbm/methods"
+	pbmtypes "github.com/vmware/govmomi/pbm/types"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const (
+	// serverObjectTypeVirtualMachine is the PbmServerObjectRef object type for
+	// the home (configuration files) of a virtual machine.
+	serverObjectTypeVirtualMachine = "virtualMachine"
+
+	// serverObjectTypeVirtualDiskID is the PbmServerObjectRef object type for a
+	// virtual disk attached to a virtual machine.
+	serverObjectTypeVirtualDiskID = "virtualDiskId"
+)
+
+// PolicySpecByID returns a profile spec slice suitable for use in the Profile
+// fields of VirtualMachineConfigSpec, VirtualDeviceConfigSpec, and the
+// various relocate specs.
+func PolicySpecByID(id string) []types.BaseVirtualMachineProfileSpec {
+	return []types.BaseVirtualMachineProfileSpec{
+		&types.VirtualMachineDefinedProfileSpec{
+			ProfileId: id,
+		},
+	}
+}
+
+// PolicyIDByName finds a storage policy by its name and returns its ID.
+func PolicyIDByName(client *pbm.Client, name string) (string, error) {
+	log.Printf("[DEBUG] Looking up storage policy ID for name %q", name)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	return client.ProfileIDByName(ctx, name)
+}
+
+// PolicyByID fetches the full capability profile for the storage policy with
+// the supplied ID. A nil profile is returned if the policy could not be found.
+func PolicyByID(client *pbm.Client, id string) (*pbmtypes.PbmCapabilityProfile, error) {
+	log.Printf("[DEBUG] Fetching storage policy %q", id)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	profiles, err := client.RetrieveContent(ctx, []pbmtypes.PbmProfileId{{UniqueId: id}})
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(profiles) < 1 {
+		return nil, nil
+	}
+	profile, ok := profiles[0].(*pbmtypes.PbmCapabilityProfile)
+	if !ok {
+		return nil, fmt.Errorf("profile %q is not a capability profile (type %T)", id, profiles[0])
+	}
+	return profile, nil
+}
+
+// isNotFoundError checks to see if the supplied error is the fault returned
+// when retrieving a storage policy that does not exist. Depending on the
+// version, vCenter returns either a PbmFaultNotFound or an InvalidArgument
+// fault for the profile IDs.
+func isNotFoundError(err error) bool {
+	if !soap.IsSoapFault(err) {
+		return false
+	}
+	switch soap.ToSoapFault(err).VimFault().(type) {
+	case pbmtypes.PbmFaultNotFound, types.InvalidArgument:
+		return true
+	}
+	return false
+}
+
+// Create creates a storage policy from the supplied create spec and returns
+// its ID.
+func Create(client *pbm.Client, spec pbmtypes.PbmCapabilityProfileCreateSpec) (string, error) {
+	log.Printf("[DEBUG] Creating storage policy %q", spec.Name)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	id, err := client.CreateProfile(ctx, spec)
+	if err != nil {
+		return "", err
+	}
+	log.Printf("[DEBUG] Storage policy %q created with ID %q", spec.Name, id.UniqueId)
+	return id.UniqueId, nil
+}
+
+// Update updates the storage policy with the supplied ID using the supplied
+// update spec.
+func Update(client *pbm.Client, id string, spec pbmtypes.PbmCapabilityProfileUpdateSpec) error {
+	log.Printf("[DEBUG] Updating storage policy %q", id)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	return client.UpdateProfile(ctx, pbmtypes.PbmProfileId{UniqueId: id}, spec)
+}
+
+// Delete deletes the storage policy with the supplied ID.
+func Delete(client *pbm.Client, id string) error {
+	log.Printf("[DEBUG] Deleting storage policy %q", id)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	outcomes, err := client.DeleteProfile(ctx, []pbmtypes.PbmProfileId{{UniqueId: id}})
+	if err != nil {
+		return err
+	}
+	for _, outcome := range outcomes {
+		if outcome.Fault != nil {
+			return fmt.Errorf("error deleting storage policy %q: %s", id, outcome.Fault.LocalizedMessage)
+		}
+	}
+	return nil
+}
+
+// PolicyIDByVirtualMachine returns the ID of the storage policy associated
+// with the home of the virtual machine referenced by vmMOID. An empty string
+// is returned if the virtual machine has no associated policy.
+func PolicyIDByVirtualMachine(client *pbm.Client, vmMOID string) (string, error) {
+	log.Printf("[DEBUG] Looking up storage policy for virtual machine %q", vmMOID)
+	return policyIDByServerObject(client, pbmtypes.PbmServerObjectRef{
+		ObjectType: serverObjectTypeVirtualMachine,
+		Key:        vmMOID,
+	})
+}
+
+// PolicyIDByVirtualDisk returns the ID of the storage policy associated with
+// the virtual disk at device key diskKey on the virtual machine referenced by
+// vmMOID. An empty string is returned if the disk has no associated policy.
+func PolicyIDByVirtualDisk(client *pbm.Client, vmMOID string, diskKey int) (string, error) {
+	log.Printf("[DEBUG] Looking up storage policy for disk key %d on virtual machine %q", diskKey, vmMOID)
+	return policyIDByServerObject(client, pbmtypes.PbmServerObjectRef{
+		ObjectType: serverObjectTypeVirtualDiskID,
+		Key:        fmt.Sprintf("%s:%d", vmMOID, diskKey),
+	})
+}
+
+// policyIDByServerObject returns the unique ID of the first profile associated
+// with the supplied server object reference.
+func policyIDByServerObject(client *pbm.Client, ref pbmtypes.PbmServerObjectRef) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	req := pbmtypes.PbmQueryAssociatedProfile{
+		This:   client.ServiceContent.ProfileManager,
+		Entity: ref,
+	}
+	res, err := methods.PbmQueryAssociatedProfile(ctx, client, &req)
+	if err != nil {
+		return "", err
+	}
+	if len(res.Returnval) < 1 {
+		return "", nil
+	}
+	return res.Returnval[0].UniqueId, nil
+}
@@ -85,6 +85,30 @@ func MergeSchema(dst, src map[string]*schema.Schema) {
 	}
 }
 
+// SchemaToComputed turns all of the items in the supplied
+// map[string]*schema.Schema, and any nested resource schemas, into
+// computed-only items. This allows resource schemas to be reused in data
+// sources. The schema is modified in place.
+func SchemaToComputed(s map[string]*schema.Schema) {
+	for _, v := range s {
+		v.Required = false
+		v.Optional = false
+		v.Computed = true
+		v.ForceNew = false
+		v.Default = nil
+		v.DefaultFunc = nil
+		v.ConflictsWith = nil
+		v.MinItems = 0
+		v.MaxItems = 0
+		v.ValidateFunc = nil
+		v.DiffSuppressFunc = nil
+		v.StateFunc = nil
+		if r, ok := v.Elem.(*schema.Resource); ok {
+			SchemaToComputed(r.Schema)
+		}
+	}
+}
+
 // StringPtr makes a *string out of the value passed in through v.
 //
 // vSphere uses nil values in strings to omit values in the SOAP XML request,
@@ -0,0 +1,148 @@
+// Package vsan contains a minimal client for the vSAN management API, which
+// is served by vCenter on its own SOAP endpoint. The vendored govmomi does not
+// provide bindings for this API, so the handful of types and methods used by
+// the provider are defined here, following the layout of the generated
+// govmomi bindings.
+package vsan
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const (
+	// Namespace is the SOAP namespace of the vSAN management API.
+	Namespace = "vsan"
+
+	// Path is the path of the vSAN management API endpoint on vCenter.
+	Path = "/vsanHealth"
+)
+
+// vcClusterConfigSystem is the well-known reference to the vSAN cluster
+// configuration system on vCenter.
+var vcClusterConfigSystem = types.ManagedObjectReference{
+	Type:  "VsanVcClusterConfigSystem",
+	Value: "vsan-cluster-config-system",
+}
+
+// DataEfficiencyConfig is the deduplication and compression configuration of
+// a vSAN cluster.
+type DataEfficiencyConfig struct {
+	DedupEnabled       bool  `xml:"dedupEnabled"`
+	CompressionEnabled *bool `xml:"compressionEnabled"`
+}
+
+// DataEncryptionConfig is the data at rest encryption configuration of a
+// vSAN cluster.
+type DataEncryptionConfig struct {
+	EncryptionEnabled bool                 `xml:"encryptionEnabled"`
+	KmsProviderID     *types.KeyProviderId `xml:"kmsProviderId,omitempty"`
+}
+
+// ConfigInfoEx is the extended vSAN configuration of a cluster, as returned
+// by the vSAN cluster configuration system. Only the parts that are managed
+// by the provider are decoded.
+type ConfigInfoEx struct {
+	Enabled              *bool                 `xml:"enabled"`
+	DataEfficiencyConfig *DataEfficiencyConfig `xml:"dataEfficiencyConfig,omitempty"`
+	DataEncryptionConfig *DataEncryptionConfig `xml:"dataEncryptionConfig,omitempty"`
+}
+
+// ReconfigSpec is the spec used to change the vSAN configuration of a
+// cluster. Modify is always sent as true, so that only the parts of the
+// configuration that are set in the spec are changed.
+type ReconfigSpec struct {
+	DataEfficiencyConfig *DataEfficiencyConfig `xml:"dataEfficiencyConfig,omitempty"`
+	Modify               bool                  `xml:"modify"`
+	DataEncryptionConfig *DataEncryptionConfig `xml:"dataEncryptionConfig,omitempty"`
+}
+
+type clusterGetConfigRequest struct {
+	This    types.ManagedObjectReference `xml:"_this"`
+	Cluster types.ManagedObjectReference `xml:"cluster"`
+}
+
+type clusterGetConfigResponse struct {
+	Returnval ConfigInfoEx `xml:"returnval"`
+}
+
+type clusterGetConfigBody struct {
+	Req    *clusterGetConfigRequest  `xml:"urn:vsan VsanClusterGetConfig,omitempty"`
+	Res    *clusterGetConfigResponse `xml:"urn:vsan VsanClusterGetConfigResponse,omitempty"`
+	Fault_ *soap.Fault               `xml:"http://schemas.xmlsoap.org/soap/envelope/ Fault,omitempty"`
+}
+
+func (b *clusterGetConfigBody) Fault() *soap.Fault { return b.Fault_ }
+
+type clusterReconfigRequest struct {
+	This             types.ManagedObjectReference `xml:"_this"`
+	Cluster          types.ManagedObjectReference `xml:"cluster"`
+	VsanReconfigSpec ReconfigSpec                 `xml:"vsanReconfigSpec"`
+}
+
+type clusterReconfigResponse struct {
+	Returnval types.ManagedObjectReference `xml:"returnval"`
+}
+
+type clusterReconfigBody struct {
+	Req    *clusterReconfigRequest  `xml:"urn:vsan VsanClusterReconfig,omitempty"`
+	Res    *clusterReconfigResponse `xml:"urn:vsan VsanClusterReconfigResponse,omitempty"`
+	Fault_ *soap.Fault              `xml:"http://schemas.xmlsoap.org/soap/envelope/ Fault,omitempty"`
+}
+
+func (b *clusterReconfigBody) Fault() *soap.Fault { return b.Fault_ }
+
+// newServiceClient returns a SOAP client for the vSAN management API that
+// shares the session of the supplied vSphere client.
+func newServiceClient(client *govmomi.Client) *soap.Client {
+	return client.Client.NewServiceClient(Path, Namespace)
+}
+
+// ClusterConfig returns the extended vSAN configuration of the supplied
+// cluster.
+func ClusterConfig(client *govmomi.Client, cluster *object.ClusterComputeResource) (*ConfigInfoEx, error) {
+	log.Printf("[DEBUG] Reading vSAN configuration for cluster %q", cluster.Name())
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	var reqBody, resBody clusterGetConfigBody
+	reqBody.Req = &clusterGetConfigRequest{
+		This:    vcClusterConfigSystem,
+		Cluster: cluster.Reference(),
+	}
+	if err := newServiceClient(client).RoundTrip(ctx, &reqBody, &resBody); err != nil {
+		return nil, err
+	}
+	return &resBody.Res.Returnval, nil
+}
+
+// ReconfigureCluster changes the vSAN configuration of the supplied cluster
+// with the supplied spec, and waits for up to the supplied timeout for the
+// change to complete. Changing deduplication, compression, or encryption
+// rewrites the disk groups in the cluster one at a time, so this can take
+// hours on a cluster that holds data.
+func ReconfigureCluster(client *govmomi.Client, cluster *object.ClusterComputeResource, spec ReconfigSpec, timeout time.Duration) error {
+	log.Printf("[DEBUG] Reconfiguring vSAN for cluster %q", cluster.Name())
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	spec.Modify = true
+	var reqBody, resBody clusterReconfigBody
+	reqBody.Req = &clusterReconfigRequest{
+		This:             vcClusterConfigSystem,
+		Cluster:          cluster.Reference(),
+		VsanReconfigSpec: spec,
+	}
+	if err := newServiceClient(client).RoundTrip(ctx, &reqBody, &resBody); err != nil {
+		return err
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), timeout)
+	defer tcancel()
+	task := object.NewTask(client.Client, resBody.Res.Returnval)
+	return task.Wait(tctx)
+}
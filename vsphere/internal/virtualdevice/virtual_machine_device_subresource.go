@@ -30,6 +30,10 @@ const (
 	// classes.
 	SubresourceControllerTypeSATA = "sata"
 
+	// SubresourceControllerTypeNVMe is a string representation of NVMe
+	// controller classes.
+	SubresourceControllerTypeNVMe = "nvme"
+
 	// SubresourceControllerTypeSCSI is a string representation of all SCSI
 	// controller types.
 	//
@@ -69,6 +73,7 @@ var subresourceIDControllerTypeAllowedValues = []string{
 	SubresourceControllerTypeSCSI,
 	SubresourceControllerTypePCI,
 	SubresourceControllerTypeSATA,
+	SubresourceControllerTypeNVMe,
 }
 
 var sharesLevelAllowedValues = []string{
@@ -134,6 +139,8 @@ func controllerTypeToClass(c types.BaseVirtualController) (string, error) {
 		t = SubresourceControllerTypeIDE
 	case *types.VirtualAHCIController:
 		t = SubresourceControllerTypeSATA
+	case *types.VirtualNVMEController:
+		t = SubresourceControllerTypeNVMe
 	case *types.VirtualPCIController:
 		t = SubresourceControllerTypePCI
 	case *types.ParaVirtualSCSIController, *types.VirtualBusLogicController,
@@ -396,6 +403,10 @@ func findVirtualDeviceInListControllerSelectFunc(ct string, cb int) func(types.B
 			if _, ok := device.(types.BaseVirtualSCSIController); !ok {
 				return false
 			}
+		case SubresourceControllerTypeNVMe:
+			if _, ok := device.(*types.VirtualNVMEController); !ok {
+				return false
+			}
 		case SubresourceControllerTypePCI:
 			if _, ok := device.(*types.VirtualPCIController); !ok {
 				return false
@@ -616,6 +627,53 @@ func createSCSIController(l *object.VirtualDeviceList, ct string, st string) ([]
 	return cspec, err
 }
 
+// NormalizeBus checks the controllers of the supplied type on the virtual
+// machine and creates any that don't exist. A spec slice is returned with the
+// changes. This is used for IDE, SATA and NVMe controllers - SCSI controllers
+// are handled by NormalizeSCSIBus, as they also carry a type and sharing mode.
+//
+// The first number of slots specified by count are normalized by this
+// function. Any others are left unchanged.
+func NormalizeBus(l object.VirtualDeviceList, ct string, count int) (object.VirtualDeviceList, []types.BaseVirtualDeviceConfigSpec, error) {
+	log.Printf("[DEBUG] NormalizeBus: Normalizing first %d controllers on %s bus", count, ct)
+	var spec []types.BaseVirtualDeviceConfigSpec
+	for n := 0; n < count; n++ {
+		if ctlrs := l.Select(findVirtualDeviceInListControllerSelectFunc(ct, n)); len(ctlrs) > 0 {
+			continue
+		}
+		log.Printf("[DEBUG] NormalizeBus: Creating %s controller at bus number %d", ct, n)
+		cspec, err := createController(&l, ct, n)
+		if err != nil {
+			return nil, nil, err
+		}
+		spec = append(spec, cspec...)
+	}
+	log.Printf("[DEBUG] NormalizeBus: Outgoing device list: %s", DeviceListString(l))
+	log.Printf("[DEBUG] NormalizeBus: Outgoing device config spec: %s", DeviceChangeString(spec))
+	return l, spec, nil
+}
+
+// createController creates a new IDE, SATA or NVMe controller at the supplied
+// bus number.
+func createController(l *object.VirtualDeviceList, ct string, bus int) ([]types.BaseVirtualDeviceConfigSpec, error) {
+	var nc types.BaseVirtualController
+	switch ct {
+	case SubresourceControllerTypeIDE:
+		nc = &types.VirtualIDEController{}
+	case SubresourceControllerTypeSATA:
+		nc = &types.VirtualAHCIController{}
+	case SubresourceControllerTypeNVMe:
+		nc = &types.VirtualNVMEController{}
+	default:
+		return nil, fmt.Errorf("cannot create controller of type %s", ct)
+	}
+	nc.GetVirtualController().Key = l.NewKey()
+	nc.GetVirtualController().BusNumber = int32(bus)
+	cspec, err := object.VirtualDeviceList{nc.(types.BaseVirtualDevice)}.ConfigSpec(types.VirtualDeviceConfigSpecOperationAdd)
+	*l = applyDeviceChange(*l, cspec)
+	return cspec, err
+}
+
 // ReadSCSIBusType checks the SCSI bus state and returns a device type
 // depending on if all controllers are one specific kind or not. Only the first
 // number of controllers specified by count are checked.
@@ -662,28 +720,23 @@ func ReadSCSIBusSharing(l object.VirtualDeviceList, count int) string {
 	return string(last)
 }
 
-// getSCSIController picks a SCSI controller at the specific bus number supplied.
-func pickSCSIController(l object.VirtualDeviceList, bus int) (types.BaseVirtualController, error) {
-	log.Printf("[DEBUG] pickSCSIController: Looking for SCSI controller at bus number %d", bus)
-	l = l.Select(func(device types.BaseVirtualDevice) bool {
-		switch d := device.(type) {
-		case types.BaseVirtualSCSIController:
-			return d.GetVirtualSCSIController().BusNumber == int32(bus)
-		}
-		return false
-	})
+// pickController picks a controller of the supplied type at the specific bus
+// number supplied.
+func pickController(l object.VirtualDeviceList, ct string, bus int) (types.BaseVirtualController, error) {
+	log.Printf("[DEBUG] pickController: Looking for %s controller at bus number %d", ct, bus)
+	l = l.Select(findVirtualDeviceInListControllerSelectFunc(ct, bus))
 
 	if len(l) == 0 {
-		return nil, fmt.Errorf("could not find scsi controller at bus number %d", bus)
+		return nil, fmt.Errorf("could not find %s controller at bus number %d", ct, bus)
 	}
 
-	log.Printf("[DEBUG] pickSCSIController: Found SCSI controller: %s", l.Name(l[0]))
+	log.Printf("[DEBUG] pickController: Found %s controller: %s", ct, l.Name(l[0]))
 	return l[0].(types.BaseVirtualController), nil
 }
 
 // ControllerForCreateUpdate wraps the controller selection logic to make it
 // easier to use in create or update operations. If the controller type is a
-// SCSI device, the bus number is searched as well.
+// SCSI, SATA, or NVMe device, the bus number is searched as well.
 func (r *Subresource) ControllerForCreateUpdate(l object.VirtualDeviceList, ct string, bus int) (types.BaseVirtualController, error) {
 	log.Printf("[DEBUG] ControllerForCreateUpdate: Looking for controller type %s", ct)
 	var ctlr types.BaseVirtualController
@@ -691,14 +744,12 @@ func (r *Subresource) ControllerForCreateUpdate(l object.VirtualDeviceList, ct s
 	switch ct {
 	case SubresourceControllerTypeIDE:
 		ctlr = l.PickController(&types.VirtualIDEController{})
-	case SubresourceControllerTypeSATA:
-		ctlr = l.PickController(&types.VirtualAHCIController{})
-	case SubresourceControllerTypeSCSI:
-		ctlr, err = pickSCSIController(l, bus)
+	case SubresourceControllerTypeSATA, SubresourceControllerTypeSCSI, SubresourceControllerTypeNVMe:
+		ctlr, err = pickController(l, ct, bus)
 	case SubresourceControllerTypePCI:
 		ctlr = l.PickController(&types.VirtualPCIController{})
 	default:
-		return nil, fmt.Errorf("invalid controller type %T", ct)
+		return nil, fmt.Errorf("invalid controller type %s", ct)
 	}
 	if err != nil {
 		return nil, err
@@ -707,10 +758,14 @@ func (r *Subresource) ControllerForCreateUpdate(l object.VirtualDeviceList, ct s
 		return nil, fmt.Errorf("could not find an available %s controller", ct)
 	}
 
-	// Assert that we are on bus 0 when we aren't looking for a SCSI controller.
-	// We currently do not support attaching devices to multiple non-SCSI buses.
-	if ctlr.GetVirtualController().BusNumber != 0 && ct != SubresourceControllerTypeSCSI {
-		return nil, fmt.Errorf("there are no available slots on the primary %s controller", ct)
+	// Assert that we are on bus 0 when we aren't looking for a controller by bus
+	// number. We currently do not support attaching devices to multiple IDE or
+	// PCI buses here.
+	switch ct {
+	case SubresourceControllerTypeIDE, SubresourceControllerTypePCI:
+		if ctlr.GetVirtualController().BusNumber != 0 {
+			return nil, fmt.Errorf("there are no available slots on the primary %s controller", ct)
+		}
 	}
 	log.Printf("[DEBUG] ControllerForCreateUpdate: Found controller: %s", l.Name(ctlr.(types.BaseVirtualDevice)))
 
@@ -15,11 +15,13 @@ import (
 	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/mitchellh/copystructure"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/datastore"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/spbm"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/storagepod"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/pbm"
 	"github.com/vmware/govmomi/vim25/types"
 )
 
@@ -72,6 +74,26 @@ var diskSubresourceSharingAllowedValues = []string{
 	string(types.VirtualDiskSharingSharingMultiWriter),
 }
 
+// diskControllerTypeAllowedValues is the list of controller types that a disk
+// can be attached to. This is also the order that disks on different
+// controller types are sorted in, which is how the disks in a source virtual
+// machine are lined up with the disks in configuration when cloning.
+var diskControllerTypeAllowedValues = []string{
+	SubresourceControllerTypeSCSI,
+	SubresourceControllerTypeSATA,
+	SubresourceControllerTypeNVMe,
+	SubresourceControllerTypeIDE,
+}
+
+// diskControllerUnitCount is the number of disks that can be attached to a
+// single controller of each of the supported disk controller types.
+var diskControllerUnitCount = map[string]int{
+	SubresourceControllerTypeSCSI: 15,
+	SubresourceControllerTypeSATA: 30,
+	SubresourceControllerTypeNVMe: 15,
+	SubresourceControllerTypeIDE:  2,
+}
+
 // DiskSubresourceSchema represents the schema for the disk sub-resource.
 func DiskSubresourceSchema() map[string]*schema.Schema {
 	s := map[string]*schema.Schema{
@@ -175,6 +197,14 @@ func DiskSubresourceSchema() map[string]*schema.Schema {
 			ValidateFunc: validation.IntAtLeast(0),
 		},
 
+		// VirtualDeviceConfigSpec
+		"storage_policy_id": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Computed:    true,
+			Description: "The ID of the storage policy to assign to the virtual disk. Removing it leaves the current policy in place.",
+		},
+
 		// VirtualDisk
 		"size": {
 			Type:         schema.TypeInt,
@@ -195,12 +225,19 @@ func DiskSubresourceSchema() map[string]*schema.Schema {
 				return nil, nil
 			},
 		},
+		"controller_type": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      SubresourceControllerTypeSCSI,
+			Description:  "The type of controller the disk should be connected to. Can be one of scsi, sata, nvme, or ide.",
+			ValidateFunc: validation.StringInSlice(diskControllerTypeAllowedValues, false),
+		},
 		"unit_number": {
 			Type:         schema.TypeInt,
 			Optional:     true,
 			Default:      0,
-			Description:  "The unique device number for this disk. This number determines where on the SCSI bus this device will be attached.",
-			ValidateFunc: validation.IntBetween(0, 59),
+			Description:  "The unique device number for this disk. This number determines where on the bus of the disk's controller type this device will be attached.",
+			ValidateFunc: validation.IntBetween(0, 119),
 		},
 		"keep_on_remove": {
 			Type:        schema.TypeBool,
@@ -410,7 +447,7 @@ func diskApplyOperationCreateUpdate(
 // returned, all necessary values are just set and committed to state.
 func DiskRefreshOperation(d *schema.ResourceData, c *govmomi.Client, l object.VirtualDeviceList) error {
 	log.Printf("[DEBUG] DiskRefreshOperation: Beginning refresh")
-	devices := SelectDisks(l, d.Get("scsi_controller_count").(int))
+	devices := SelectDisks(l, DiskControllerCounts(d))
 	log.Printf("[DEBUG] DiskRefreshOperation: Disk devices located: %s", DeviceListString(devices))
 	curSet := d.Get(subresourceTypeDisk).([]interface{})
 	log.Printf("[DEBUG] DiskRefreshOperation: Current resource set from state: %s", subresourceListString(curSet))
@@ -513,6 +550,26 @@ func DiskRefreshOperation(d *schema.ResourceData, c *govmomi.Client, l object.Vi
 	return d.Set(subresourceTypeDisk, newSet)
 }
 
+// DiskStoragePolicyRefreshOperation reads the storage policy associated with
+// each disk in state and saves it to the disk's storage_policy_id attribute.
+//
+// This is run after DiskRefreshOperation, when all disks in state have had
+// their device keys populated.
+func DiskStoragePolicyRefreshOperation(d *schema.ResourceData, pc *pbm.Client, vmMOID string) error {
+	log.Printf("[DEBUG] DiskStoragePolicyRefreshOperation: Reading storage policies for disks")
+	curSet := d.Get(subresourceTypeDisk).([]interface{})
+	for i, item := range curSet {
+		m := item.(map[string]interface{})
+		policyID, err := spbm.PolicyIDByVirtualDisk(pc, vmMOID, m["key"].(int))
+		if err != nil {
+			return fmt.Errorf("disk.%d: error reading storage policy: %s", i, err)
+		}
+		m["storage_policy_id"] = policyID
+	}
+	log.Printf("[DEBUG] DiskStoragePolicyRefreshOperation: Refresh of disk storage policies complete")
+	return d.Set(subresourceTypeDisk, curSet)
+}
+
 // DiskDestroyOperation process the destroy operation for virtual disks.
 //
 // Disks are the only real operation that require special destroy logic, and
@@ -560,6 +617,8 @@ func DiskDestroyOperation(d *schema.ResourceData, c *govmomi.Client, l object.Vi
 // whole:
 //
 // * Ensuring all names are unique across the set.
+// * Ensuring all unit numbers are unique across disks of the same controller
+// type.
 // * Ensuring that at least one element in the set has a unit_number of 0.
 func DiskDiffOperation(d *schema.ResourceDiff, c *govmomi.Client) error {
 	log.Printf("[DEBUG] DiskDiffOperation: Beginning disk diff customization")
@@ -568,7 +627,7 @@ func DiskDiffOperation(d *schema.ResourceDiff, c *govmomi.Client) error {
 	log.Printf("[DEBUG] DiskDiffOperation: Beginning collective diff validation (indexes aligned to new config)")
 	names := make(map[string]struct{})
 	attachments := make(map[string]struct{})
-	units := make(map[int]struct{})
+	units := make(map[string]map[int]struct{})
 	if len(n.([]interface{})) < 1 {
 		return errors.New("there must be at least one disk specified")
 	}
@@ -593,17 +652,27 @@ func DiskDiffOperation(d *schema.ResourceDiff, c *govmomi.Client) error {
 			attachments[path] = struct{}{}
 		}
 
-		if _, ok := units[nm["unit_number"].(int)]; ok {
-			return fmt.Errorf("disk: duplicate unit_number %d", nm["unit_number"].(int))
+		ct := nm["controller_type"].(string)
+		if units[ct] == nil {
+			units[ct] = make(map[int]struct{})
+		}
+		if _, ok := units[ct][nm["unit_number"].(int)]; ok {
+			return fmt.Errorf("disk: duplicate unit_number %d on %s controller", nm["unit_number"].(int), ct)
 		}
 		names[name] = struct{}{}
-		units[nm["unit_number"].(int)] = struct{}{}
+		units[ct][nm["unit_number"].(int)] = struct{}{}
 		r := NewDiskSubresource(c, d, nm, nil, ni)
 		if err := r.DiffGeneral(); err != nil {
 			return fmt.Errorf("%s: %s", r.Addr(), err)
 		}
 	}
-	if _, ok := units[0]; !ok {
+	var unitZero bool
+	for _, u := range units {
+		if _, ok := u[0]; ok {
+			unitZero = true
+		}
+	}
+	if !unitZero {
 		return errors.New("at least one disk must have a unit_number of 0")
 	}
 
@@ -701,7 +770,7 @@ nextNew:
 // existing state.
 func DiskCloneValidateOperation(d *schema.ResourceDiff, c *govmomi.Client, l object.VirtualDeviceList, linked bool) error {
 	log.Printf("[DEBUG] DiskCloneValidateOperation: Checking existing virtual disk configuration")
-	devices := SelectDisks(l, d.Get("scsi_controller_count").(int))
+	devices := SelectDisks(l, DiskControllerCounts(d))
 	// Sort the device list, in case it's not sorted already.
 	devSort := virtualDeviceListSorter{
 		Sort:       devices,
@@ -782,14 +851,14 @@ func DiskCloneValidateOperation(d *schema.ResourceDiff, c *govmomi.Client, l obj
 			}
 		}
 
-		// Finally, we don't support non-SCSI (ie: SATA, IDE, NVMe) disks, so kick
-		// back an error if we see one of those.
+		// Finally, the disks in configuration are lined up with the disks in the
+		// source by controller type, so make sure that these match.
 		ct, _, _, err := splitDevAddr(r.DevAddr())
 		if err != nil {
 			return fmt.Errorf("%s: error parsing device address after reading disk %q: %s", tr.Addr(), targetPath, err)
 		}
-		if ct != SubresourceControllerTypeSCSI {
-			return fmt.Errorf("%s: unsupported controller type %s for disk %q. Please use a template with SCSI disks only", tr.Addr(), ct, targetPath)
+		if targetCt := tr.Get("controller_type").(string); ct != targetCt {
+			return fmt.Errorf("%s: disk name %s must have same value for controller_type as source (expected: %s, got: %s)", tr.Addr(), targetName, ct, targetCt)
 		}
 	}
 	log.Printf("[DEBUG] DiskCloneValidateOperation: All disks in source validated successfully")
@@ -874,7 +943,7 @@ func DiskMigrateRelocateOperation(d *schema.ResourceData, c *govmomi.Client, l o
 // configurations fully in sync with what is defined.
 func DiskCloneRelocateOperation(d *schema.ResourceData, c *govmomi.Client, l object.VirtualDeviceList) ([]types.VirtualMachineRelocateSpecDiskLocator, error) {
 	log.Printf("[DEBUG] DiskCloneRelocateOperation: Generating full disk relocate spec list")
-	devices := SelectDisks(l, d.Get("scsi_controller_count").(int))
+	devices := SelectDisks(l, DiskControllerCounts(d))
 	log.Printf("[DEBUG] DiskCloneRelocateOperation: Disk devices located: %s", DeviceListString(devices))
 	// Sort the device list, in case it's not sorted already.
 	devSort := virtualDeviceListSorter{
@@ -935,7 +1004,7 @@ func DiskCloneRelocateOperation(d *schema.ResourceData, c *govmomi.Client, l obj
 // virtual device operations rely pretty heavily on.
 func DiskPostCloneOperation(d *schema.ResourceData, c *govmomi.Client, l object.VirtualDeviceList) (object.VirtualDeviceList, []types.BaseVirtualDeviceConfigSpec, error) {
 	log.Printf("[DEBUG] DiskPostCloneOperation: Looking for disk device changes post-clone")
-	devices := SelectDisks(l, d.Get("scsi_controller_count").(int))
+	devices := SelectDisks(l, DiskControllerCounts(d))
 	log.Printf("[DEBUG] DiskPostCloneOperation: Disk devices located: %s", DeviceListString(devices))
 	// Sort the device list, in case it's not sorted already.
 	devSort := virtualDeviceListSorter{
@@ -974,6 +1043,9 @@ func DiskPostCloneOperation(d *schema.ResourceData, c *govmomi.Client, l object.
 		if err != nil {
 			return nil, nil, fmt.Errorf("error copying source set for disk at unit_number %d: %s", src["unit_number"].(int), err)
 		}
+		// The storage policy of the source disk is not known at this point, so
+		// clear it to ensure any policy defined in configuration gets applied.
+		old.(map[string]interface{})["storage_policy_id"] = ""
 		rOld := NewDiskSubresource(c, d, old.(map[string]interface{}), nil, i)
 		if err := rOld.Read(l); err != nil {
 			return nil, nil, fmt.Errorf("%s: %s", rOld.Addr(), err)
@@ -1035,10 +1107,11 @@ func DiskPostCloneOperation(d *schema.ResourceData, c *govmomi.Client, l object.
 // DiskImportOperation validates the disk configuration of the virtual
 // machine's VirtualDeviceList to ensure it will be imported properly, and also
 // saves device addresses into state for disks defined in config. Both the
-// imported device list is sorted by the device's unit number on the SCSI bus.
+// imported device list is sorted by the device's controller type, and then
+// unit number on the controller's bus.
 func DiskImportOperation(d *schema.ResourceData, c *govmomi.Client, l object.VirtualDeviceList) error {
 	log.Printf("[DEBUG] DiskImportOperation: Performing pre-read import and validation of virtual disks")
-	devices := SelectDisks(l, d.Get("scsi_controller_count").(int))
+	devices := SelectDisks(l, DiskControllerCounts(d))
 	// Sort the device list, in case it's not sorted already.
 	devSort := virtualDeviceListSorter{
 		Sort:       devices,
@@ -1050,7 +1123,8 @@ func DiskImportOperation(d *schema.ResourceData, c *govmomi.Client, l object.Vir
 	log.Printf("[DEBUG] DiskImportOperation: Disk devices order after sort: %s", DeviceListString(devices))
 
 	// Read in the disks. We don't do anything with the results here other than
-	// validate that the disks are SCSI disks. The read operation validates the rest.
+	// validate that the disks are on supported controllers. The read operation
+	// validates the rest.
 	var curSet []interface{}
 	log.Printf("[DEBUG] DiskImportOperation: Validating disk type and saving ")
 	for i, device := range devices {
@@ -1063,13 +1137,9 @@ func DiskImportOperation(d *schema.ResourceData, c *govmomi.Client, l object.Vir
 		if err != nil {
 			return fmt.Errorf("error computing device address: %s", err)
 		}
-		ct, _, _, err := splitDevAddr(addr)
-		if err != nil {
+		if _, _, _, err := splitDevAddr(addr); err != nil {
 			return fmt.Errorf("disk.%d: error parsing device address %s: %s", i, addr, err)
 		}
-		if ct != SubresourceControllerTypeSCSI {
-			return fmt.Errorf("disk.%d: unsupported controller type %s for disk %s. The VM resource supports SCSI disks only", i, ct, addr)
-		}
 		// As one final validation, as we are no longer reading here, validate that
 		// this is a VMDK-backed virtual disk to make sure we aren't importing RDM
 		// disks or what not. The device should have already been validated as a
@@ -1112,9 +1182,12 @@ func DiskImportOperation(d *schema.ResourceData, c *govmomi.Client, l object.Vir
 // on a virtual machine. This is used in the VM data source to discover
 // specific options of all of the disks on the virtual machine sorted by the
 // order that they would be added in if a clone were to be done.
-func ReadDiskAttrsForDataSource(l object.VirtualDeviceList, count int) ([]map[string]interface{}, error) {
-	log.Printf("[DEBUG] ReadDiskAttrsForDataSource: Fetching select attributes for disks across %d SCSI controllers", count)
-	devices := SelectDisks(l, count)
+//
+// counts is the number of controllers of each controller type to scan for
+// disks on.
+func ReadDiskAttrsForDataSource(l object.VirtualDeviceList, counts map[string]int) ([]map[string]interface{}, error) {
+	log.Printf("[DEBUG] ReadDiskAttrsForDataSource: Fetching select attributes for disks across controllers: %v", counts)
+	devices := SelectDisks(l, counts)
 	log.Printf("[DEBUG] ReadDiskAttrsForDataSource: Disk devices located: %s", DeviceListString(devices))
 	// Sort the device list, in case it's not sorted already.
 	devSort := virtualDeviceListSorter{
@@ -1131,6 +1204,14 @@ func ReadDiskAttrsForDataSource(l object.VirtualDeviceList, count int) ([]map[st
 		if !ok {
 			return nil, fmt.Errorf("disk number %d has an unsupported backing type (expected flat VMDK version 2, got %T)", i, disk.Backing)
 		}
+		ctlr, err := findControllerForDevice(l, disk)
+		if err != nil {
+			return nil, err
+		}
+		ct, err := controllerTypeToClass(ctlr)
+		if err != nil {
+			return nil, err
+		}
 		m := make(map[string]interface{})
 		var eager, thin bool
 		if backing.EagerlyScrub != nil {
@@ -1142,6 +1223,7 @@ func ReadDiskAttrsForDataSource(l object.VirtualDeviceList, count int) ([]map[st
 		m["size"] = diskCapacityInGiB(disk)
 		m["eagerly_scrub"] = eager
 		m["thin_provisioned"] = thin
+		m["controller_type"] = ct
 		out = append(out, m)
 	}
 	log.Printf("[DEBUG] ReadDiskAttrsForDataSource: Attributes returned: %+v", out)
@@ -1183,6 +1265,9 @@ func (r *DiskSubresource) Create(l object.VirtualDeviceList) ([]types.BaseVirtua
 	if r.Get("attach").(bool) {
 		dspec[0].GetVirtualDeviceConfigSpec().FileOperation = ""
 	}
+	if policyID, _ := r.Get("storage_policy_id").(string); policyID != "" {
+		dspec[0].GetVirtualDeviceConfigSpec().Profile = spbm.PolicySpecByID(policyID)
+	}
 	spec = append(spec, dspec...)
 	log.Printf("[DEBUG] %s: Device config operations from create: %s", r, DeviceChangeString(spec))
 	log.Printf("[DEBUG] %s: Create finished", r)
@@ -1201,6 +1286,11 @@ func (r *DiskSubresource) Read(l object.VirtualDeviceList) error {
 	if err != nil {
 		return err
 	}
+	ct, err := controllerTypeToClass(ctlr)
+	if err != nil {
+		return err
+	}
+	r.Set("controller_type", ct)
 	r.Set("unit_number", unit)
 	if err := r.SaveDevIDs(disk, ctlr); err != nil {
 		return err
@@ -1266,8 +1356,8 @@ func (r *DiskSubresource) Update(l object.VirtualDeviceList) ([]types.BaseVirtua
 		return nil, fmt.Errorf("cannot find disk device: %s", err)
 	}
 
-	// Has the unit number changed?
-	if r.HasChange("unit_number") {
+	// Has the unit number or controller type changed?
+	if r.HasChange("unit_number") || r.HasChange("controller_type") {
 		ctlr, err := r.assignDisk(l, disk)
 		if err != nil {
 			return nil, fmt.Errorf("cannot assign disk: %s", err)
@@ -1298,6 +1388,11 @@ func (r *DiskSubresource) Update(l object.VirtualDeviceList) ([]types.BaseVirtua
 	}
 	// Clear file operation - VirtualDeviceList currently sets this to replace, which is invalid
 	dspec[0].GetVirtualDeviceConfigSpec().FileOperation = ""
+	// Only send the storage policy if it has changed, to avoid re-applying the
+	// policy on every update to the disk.
+	if policyID, _ := r.Get("storage_policy_id").(string); r.HasChange("storage_policy_id") && policyID != "" {
+		dspec[0].GetVirtualDeviceConfigSpec().Profile = spbm.PolicySpecByID(policyID)
+	}
 	log.Printf("[DEBUG] %s: Device config operations from update: %s", r, DeviceChangeString(dspec))
 	log.Printf("[DEBUG] %s: Update complete", r)
 	return dspec, nil
@@ -1388,6 +1483,12 @@ func (r *DiskSubresource) DiffExisting() error {
 		}
 	}
 
+	// Carry forward the storage policy if one is not defined in configuration.
+	if policyID, _ := r.Get("storage_policy_id").(string); policyID == "" {
+		opolicyID, _ := r.GetChange("storage_policy_id")
+		r.Set("storage_policy_id", opolicyID)
+	}
+
 	// Preserve the share value if we don't have custom shares set
 	osc, _ := r.GetChange("io_share_count")
 	if r.Get("io_share_level").(string) != string(types.SharesLevelCustom) {
@@ -1434,13 +1535,18 @@ func (r *DiskSubresource) DiffGeneral() error {
 		return err
 	}
 
-	// Enforce the maximum unit number, which is the current value of
-	// scsi_controller_count * 15 - 1.
-	ctlrCount := r.rdd.Get("scsi_controller_count").(int)
-	maxUnit := ctlrCount*15 - 1
+	// Enforce the maximum unit number, which is the current value of the
+	// controller count for the disk's controller type, multiplied by the number
+	// of disks a controller of that type can take, minus 1.
+	ct := r.Get("controller_type").(string)
+	ctlrCount := DiskControllerCounts(r.rdd)[ct]
+	if ctlrCount < 1 {
+		return fmt.Errorf("%s_controller_count must be at least 1 to use controller_type %s on disk %q", ct, ct, name)
+	}
+	maxUnit := ctlrCount*diskControllerUnitCount[ct] - 1
 	currentUnit := r.Get("unit_number").(int)
 	if currentUnit > maxUnit {
-		return fmt.Errorf("unit_number on disk %q too high (%d) - maximum value is %d with %d SCSI controller(s)", name, currentUnit, maxUnit, ctlrCount)
+		return fmt.Errorf("unit_number on disk %q too high (%d) - maximum value is %d with %d %s controller(s)", name, currentUnit, maxUnit, ctlrCount, strings.ToUpper(ct))
 	}
 
 	if r.Get("attach").(bool) {
@@ -1595,6 +1701,11 @@ func (r *DiskSubresource) Relocate(l object.VirtualDeviceList, clone bool) (type
 	dsref := ds.Reference()
 	relocate.Datastore = dsref
 
+	// Carry the storage policy over to the new location, if we have one.
+	if policyID, _ := r.Get("storage_policy_id").(string); policyID != "" {
+		relocate.Profile = spbm.PolicySpecByID(policyID)
+	}
+
 	// Add additional backing options if we are cloning.
 	if r.rdd.Id() == "" {
 		log.Printf("[DEBUG] %s: Adding additional options to relocator for cloning", r)
@@ -1736,33 +1847,46 @@ func (r *DiskSubresource) assignBackingInfo(disk *types.VirtualDisk) error {
 }
 
 // assignDisk takes a unit number and assigns it correctly to a controller on
-// the SCSI bus. An error is returned if the assigned unit number is taken.
+// the bus for the disk's controller type. An error is returned if the assigned
+// unit number is taken.
 func (r *DiskSubresource) assignDisk(l object.VirtualDeviceList, disk *types.VirtualDisk) (types.BaseVirtualController, error) {
+	ct := r.Get("controller_type").(string)
 	number := r.Get("unit_number").(int)
-	// Figure out the bus number, and look up the SCSI controller that matches
-	// that. You can attach 15 disks to a SCSI controller, and we allow a maximum
-	// of 30 devices.
-	bus := number / 15
+	// Figure out the bus number, and look up the controller that matches that.
+	// You can attach 15 disks to a SCSI or NVMe controller, 30 disks to a SATA
+	// controller, and 2 disks to an IDE controller.
+	perCtlr := diskControllerUnitCount[ct]
+	bus := number / perCtlr
 	// Also determine the unit number on that controller.
-	unit := int32(math.Mod(float64(number), 15))
+	unit := int32(math.Mod(float64(number), float64(perCtlr)))
 
-	// Find the controller.
-	ctlr, err := r.ControllerForCreateUpdate(l, SubresourceControllerTypeSCSI, bus)
+	// Find the controller. IDE controllers are looked up directly, as
+	// ControllerForCreateUpdate only looks at the primary IDE controller.
+	var ctlr types.BaseVirtualController
+	var err error
+	if ct == SubresourceControllerTypeIDE {
+		ctlr, err = pickController(l, ct, bus)
+	} else {
+		ctlr, err = r.ControllerForCreateUpdate(l, ct, bus)
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	// Build the unit list.
-	units := make([]bool, 16)
-	// Reserve the SCSI unit number
-	scsiUnit := ctlr.(types.BaseVirtualSCSIController).GetVirtualSCSIController().ScsiCtlrUnitNumber
-	units[scsiUnit] = true
+	units := make([]bool, perCtlr+1)
+	// Reserve the SCSI unit number, if this is a SCSI controller.
+	ctlrUnit := int32(perCtlr)
+	if sc, ok := ctlr.(types.BaseVirtualSCSIController); ok {
+		ctlrUnit = sc.GetVirtualSCSIController().ScsiCtlrUnitNumber
+		units[ctlrUnit] = true
+	}
 
 	ckey := ctlr.GetVirtualController().Key
 
 	for _, device := range l {
 		d := device.GetVirtualDevice()
-		if d.ControllerKey != ckey || d.UnitNumber == nil {
+		if d.ControllerKey != ckey || d.UnitNumber == nil || int(*d.UnitNumber) >= len(units) {
 			continue
 		}
 		units[*d.UnitNumber] = true
@@ -1770,12 +1894,12 @@ func (r *DiskSubresource) assignDisk(l object.VirtualDeviceList, disk *types.Vir
 
 	// We now have a valid list of units. If we need to, shift up the desired
 	// unit number so it's not taking the unit of the controller itself.
-	if unit >= scsiUnit {
+	if unit >= ctlrUnit {
 		unit++
 	}
 
 	if units[unit] {
-		return nil, fmt.Errorf("unit number %d on SCSI bus %d is in use", unit, bus)
+		return nil, fmt.Errorf("unit number %d on %s bus %d is in use", unit, strings.ToUpper(ct), bus)
 	}
 
 	// If we made it this far, we are good to go!
@@ -1785,8 +1909,8 @@ func (r *DiskSubresource) assignDisk(l object.VirtualDeviceList, disk *types.Vir
 }
 
 // findControllerInfo determines the normalized unit number for the disk device
-// based on the SCSI controller and unit number it's connected to. The
-// controller is also returned.
+// based on the controller and unit number it's connected to. The controller is
+// also returned.
 func (r *Subresource) findControllerInfo(l object.VirtualDeviceList, disk *types.VirtualDisk) (int, types.BaseVirtualController, error) {
 	ctlr := l.FindByKey(disk.ControllerKey)
 	if ctlr == nil {
@@ -1795,16 +1919,21 @@ func (r *Subresource) findControllerInfo(l object.VirtualDeviceList, disk *types
 	if disk.UnitNumber == nil {
 		return -1, nil, fmt.Errorf("unit number on disk key %d is unset", disk.Key)
 	}
-	sc, ok := ctlr.(types.BaseVirtualSCSIController)
+	bc, ok := ctlr.(types.BaseVirtualController)
 	if !ok {
-		return -1, nil, fmt.Errorf("controller at key %d is not a SCSI controller (actual: %T)", ctlr.GetVirtualDevice().Key, ctlr)
+		return -1, nil, fmt.Errorf("device at key %d is not a controller (actual: %T)", ctlr.GetVirtualDevice().Key, ctlr)
+	}
+	ct, _ := controllerTypeToClass(bc)
+	perCtlr, ok := diskControllerUnitCount[ct]
+	if !ok {
+		return -1, nil, fmt.Errorf("controller at key %d is not a supported disk controller (actual: %T)", ctlr.GetVirtualDevice().Key, ctlr)
 	}
 	unit := *disk.UnitNumber
-	if unit > sc.GetVirtualSCSIController().ScsiCtlrUnitNumber {
+	if sc, ok := ctlr.(types.BaseVirtualSCSIController); ok && unit > sc.GetVirtualSCSIController().ScsiCtlrUnitNumber {
 		unit--
 	}
-	unit = unit + 15*sc.GetVirtualSCSIController().BusNumber
-	return int(unit), ctlr.(types.BaseVirtualController), nil
+	unit = unit + int32(perCtlr)*bc.GetVirtualController().BusNumber
+	return int(unit), bc, nil
 }
 
 // diskRelocateListString pretty-prints a list of
@@ -1844,8 +1973,9 @@ func (l virtualDeviceListSorter) Len() int {
 }
 
 // Less helps implement sort.Interface for virtualDeviceListSorter. A
-// BaseVirtualDevice is "less" than another device if its controller's bus
-// number and unit number combination are earlier in the order than the other.
+// BaseVirtualDevice is "less" than another device if its controller's type,
+// bus number, and unit number combination are earlier in the order than the
+// other.
 func (l virtualDeviceListSorter) Less(i, j int) bool {
 	li := l.Sort[i]
 	lj := l.Sort[j]
@@ -1854,8 +1984,15 @@ func (l virtualDeviceListSorter) Less(i, j int) bool {
 	if liCtlr == nil || ljCtlr == nil {
 		panic(errors.New("virtualDeviceListSorter cannot be used with devices that are not assigned to a controller"))
 	}
-	if liCtlr.(types.BaseVirtualController).GetVirtualController().BusNumber < liCtlr.(types.BaseVirtualController).GetVirtualController().BusNumber {
-		return true
+	liCt, _ := controllerTypeToClass(liCtlr.(types.BaseVirtualController))
+	ljCt, _ := controllerTypeToClass(ljCtlr.(types.BaseVirtualController))
+	if liOrder, ljOrder := diskControllerTypeOrder(liCt), diskControllerTypeOrder(ljCt); liOrder != ljOrder {
+		return liOrder < ljOrder
+	}
+	liBus := liCtlr.(types.BaseVirtualController).GetVirtualController().BusNumber
+	ljBus := ljCtlr.(types.BaseVirtualController).GetVirtualController().BusNumber
+	if liBus != ljBus {
+		return liBus < ljBus
 	}
 	liUnit := li.GetVirtualDevice().UnitNumber
 	ljUnit := lj.GetVirtualDevice().UnitNumber
@@ -1870,7 +2007,8 @@ func (l virtualDeviceListSorter) Swap(i, j int) {
 	l.Sort[i], l.Sort[j] = l.Sort[j], l.Sort[i]
 }
 
-// virtualDiskSubresourceSorter sorts a list of disk sub-resources, based on unit number.
+// virtualDiskSubresourceSorter sorts a list of disk sub-resources, based on
+// controller type and unit number.
 type virtualDiskSubresourceSorter []interface{}
 
 // Len implements sort.Interface for virtualDiskSubresourceSorter.
@@ -1882,6 +2020,11 @@ func (s virtualDiskSubresourceSorter) Len() int {
 func (s virtualDiskSubresourceSorter) Less(i, j int) bool {
 	mi := s[i].(map[string]interface{})
 	mj := s[j].(map[string]interface{})
+	miCt, _ := mi["controller_type"].(string)
+	mjCt, _ := mj["controller_type"].(string)
+	if miOrder, mjOrder := diskControllerTypeOrder(miCt), diskControllerTypeOrder(mjCt); miOrder != mjOrder {
+		return miOrder < mjOrder
+	}
 	return mi["unit_number"].(int) < mj["unit_number"].(int)
 }
 
@@ -1900,11 +2043,12 @@ func datastorePathHasBase(p, b string) bool {
 	return path.Base(dp.Path) == path.Base(b)
 }
 
-// SelectDisks looks for disks that Terraform is supposed to manage. count is
-// the number of controllers that Terraform is managing and serves as an upper
-// limit (count - 1) of the SCSI bus number for a controller that eligible
-// disks need to be attached to.
-func SelectDisks(l object.VirtualDeviceList, count int) object.VirtualDeviceList {
+// SelectDisks looks for disks that Terraform is supposed to manage. counts is
+// the number of controllers of each controller type that Terraform is managing,
+// keyed by controller type, and serves as an upper limit (count - 1) of the bus
+// number for a controller of that type that eligible disks need to be attached
+// to.
+func SelectDisks(l object.VirtualDeviceList, counts map[string]int) object.VirtualDeviceList {
 	devices := l.Select(func(device types.BaseVirtualDevice) bool {
 		if disk, ok := device.(*types.VirtualDisk); ok {
 			ctlr, err := findControllerForDevice(l, disk)
@@ -1912,8 +2056,13 @@ func SelectDisks(l object.VirtualDeviceList, count int) object.VirtualDeviceList
 				log.Printf("[DEBUG] DiskRefreshOperation: Error looking for controller for device %q: %s", l.Name(disk), err)
 				return false
 			}
-			if sc, ok := ctlr.(types.BaseVirtualSCSIController); ok && sc.GetVirtualSCSIController().BusNumber < int32(count) {
-				cd := sc.(types.BaseVirtualDevice)
+			ct, err := controllerTypeToClass(ctlr)
+			if err != nil {
+				log.Printf("[DEBUG] DiskRefreshOperation: Unsupported controller for device %q: %s", l.Name(disk), err)
+				return false
+			}
+			if ctlr.GetVirtualController().BusNumber < int32(counts[ct]) {
+				cd := ctlr.(types.BaseVirtualDevice)
 				log.Printf("[DEBUG] DiskRefreshOperation: Found controller %q for device %q", l.Name(cd), l.Name(disk))
 				return true
 			}
@@ -1923,6 +2072,33 @@ func SelectDisks(l object.VirtualDeviceList, count int) object.VirtualDeviceList
 	return devices
 }
 
+// DiskControllerCounts returns the number of controllers of each disk
+// controller type that Terraform manages on the virtual machine, keyed by
+// controller type. This is the format that SelectDisks expects.
+func DiskControllerCounts(d resourceDataDiff) map[string]int {
+	return map[string]int{
+		SubresourceControllerTypeSCSI: d.Get("scsi_controller_count").(int),
+		SubresourceControllerTypeSATA: d.Get("sata_controller_count").(int),
+		SubresourceControllerTypeNVMe: d.Get("nvme_controller_count").(int),
+		SubresourceControllerTypeIDE:  d.Get("ide_controller_count").(int),
+	}
+}
+
+// diskControllerTypeOrder returns the position of the supplied controller type
+// in the order that disks are sorted in. An empty controller type is treated
+// as SCSI, and unknown controller types are sorted last.
+func diskControllerTypeOrder(ct string) int {
+	if ct == "" {
+		ct = SubresourceControllerTypeSCSI
+	}
+	for i, v := range diskControllerTypeAllowedValues {
+		if v == ct {
+			return i
+		}
+	}
+	return len(diskControllerTypeAllowedValues)
+}
+
 // diskLabelOrName is a helper method that returns the unique label for a disk
 // - either its label or name. An error is returned if both are defined.
 //
@@ -1,8 +1,10 @@
 package virtualdevice
 
 import (
+	"sort"
 	"testing"
 
+	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/vim25/types"
 )
 
@@ -37,3 +39,32 @@ func TestDiskCapacityInGiB(t *testing.T) {
 		})
 	}
 }
+
+func TestVirtualDeviceListSorter(t *testing.T) {
+	unit := func(n int32) *int32 { return &n }
+	l := object.VirtualDeviceList{
+		&types.VirtualIDEController{VirtualController: types.VirtualController{VirtualDevice: types.VirtualDevice{Key: 200}, BusNumber: 0}},
+		&types.VirtualAHCIController{VirtualSATAController: types.VirtualSATAController{VirtualController: types.VirtualController{VirtualDevice: types.VirtualDevice{Key: 15000}, BusNumber: 0}}},
+		&types.VirtualNVMEController{VirtualController: types.VirtualController{VirtualDevice: types.VirtualDevice{Key: 31000}, BusNumber: 0}},
+		&types.ParaVirtualSCSIController{VirtualSCSIController: types.VirtualSCSIController{VirtualController: types.VirtualController{VirtualDevice: types.VirtualDevice{Key: 1000}, BusNumber: 0}}},
+		&types.ParaVirtualSCSIController{VirtualSCSIController: types.VirtualSCSIController{VirtualController: types.VirtualController{VirtualDevice: types.VirtualDevice{Key: 1001}, BusNumber: 1}}},
+	}
+	disks := object.VirtualDeviceList{
+		&types.VirtualDisk{VirtualDevice: types.VirtualDevice{Key: 1, ControllerKey: 200, UnitNumber: unit(0)}},
+		&types.VirtualDisk{VirtualDevice: types.VirtualDevice{Key: 2, ControllerKey: 31000, UnitNumber: unit(0)}},
+		&types.VirtualDisk{VirtualDevice: types.VirtualDevice{Key: 3, ControllerKey: 15000, UnitNumber: unit(1)}},
+		&types.VirtualDisk{VirtualDevice: types.VirtualDevice{Key: 4, ControllerKey: 1001, UnitNumber: unit(0)}},
+		&types.VirtualDisk{VirtualDevice: types.VirtualDevice{Key: 5, ControllerKey: 1000, UnitNumber: unit(1)}},
+		&types.VirtualDisk{VirtualDevice: types.VirtualDevice{Key: 6, ControllerKey: 1000, UnitNumber: unit(0)}},
+	}
+	l = append(l, disks...)
+
+	sort.Sort(virtualDeviceListSorter{Sort: disks, DeviceList: l})
+
+	expected := []int32{6, 5, 4, 3, 2, 1}
+	for i, disk := range disks {
+		if disk.GetVirtualDevice().Key != expected[i] {
+			t.Fatalf("position %d: expected disk %d, got %d", i, expected[i], disk.GetVirtualDevice().Key)
+		}
+	}
+}
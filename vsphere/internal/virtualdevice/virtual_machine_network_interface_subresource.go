@@ -104,6 +104,11 @@ func NetworkInterfaceSubresourceSchema() map[string]*schema.Schema {
 			Computed:    true,
 			Description: "The MAC address of this network interface. Can only be manually set if use_static_mac is true.",
 		},
+		"external_id": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "The external ID of this network interface, which NSX-T uses as the attachment ID of the logical port for the interface.",
+		},
 	}
 	structure.MergeSchema(s, subresourceSchema())
 	return s
@@ -702,6 +707,10 @@ func (r *NetworkInterfaceSubresource) Read(l object.VirtualDeviceList) error {
 
 	r.Set("use_static_mac", card.AddressType == string(types.VirtualEthernetCardMacTypeManual))
 	r.Set("mac_address", card.MacAddress)
+	// NSX-T uses the external ID of the card as the attachment ID of the
+	// logical port that it creates for the interface. The ID of the logical
+	// port itself is not available from vSphere.
+	r.Set("external_id", card.ExternalId)
 
 	version := viapi.ParseVersionFromClient(r.client)
 	if version.Newer(viapi.VSphereVersion{Product: version.Product, Major: 6}) {
@@ -0,0 +1,222 @@
+package virtualdevice
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/resourcepool"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// PciPassthroughApplyOperation processes an apply operation for the PCI
+// passthrough devices on a virtual machine. This covers both DirectPath I/O
+// devices, defined by pci_device_id, and shared PCI devices (vGPU profiles),
+// defined by shared_pci_device.
+//
+// Unlike the other device classes, PCI passthrough devices are not tracked as
+// sub-resources. Instead, the passthrough devices found in the device list are
+// reconciled against configuration: devices that are not in configuration are
+// removed, and missing devices are added after they have been validated against
+// the devices available on the host. As such, this function is also used to
+// process post-clone changes.
+func PciPassthroughApplyOperation(d *schema.ResourceData, c *govmomi.Client, l object.VirtualDeviceList) (object.VirtualDeviceList, []types.BaseVirtualDeviceConfigSpec, error) {
+	log.Printf("[DEBUG] PciPassthroughApplyOperation: Beginning apply operation")
+	wantIDs := make(map[string]bool)
+	for _, v := range d.Get("pci_device_id").(*schema.Set).List() {
+		wantIDs[v.(string)] = true
+	}
+	wantVgpu := d.Get("shared_pci_device").(string)
+
+	var spec []types.BaseVirtualDeviceConfigSpec
+	// Look for devices to remove first, tracking what we already have as we go.
+	for _, dev := range l.SelectByType((*types.VirtualPCIPassthrough)(nil)) {
+		switch backing := dev.GetVirtualDevice().Backing.(type) {
+		case *types.VirtualPCIPassthroughDeviceBackingInfo:
+			if wantIDs[backing.Id] {
+				delete(wantIDs, backing.Id)
+				continue
+			}
+		case *types.VirtualPCIPassthroughVmiopBackingInfo:
+			if backing.Vgpu == wantVgpu {
+				wantVgpu = ""
+				continue
+			}
+		default:
+			// Not a passthrough device that we manage.
+			continue
+		}
+		dspec, err := object.VirtualDeviceList{dev}.ConfigSpec(types.VirtualDeviceConfigSpecOperationRemove)
+		if err != nil {
+			return nil, nil, err
+		}
+		log.Printf("[DEBUG] PciPassthroughApplyOperation: Removing device %s", l.Name(dev))
+		l = applyDeviceChange(l, dspec)
+		spec = append(spec, dspec...)
+	}
+
+	if len(wantIDs) < 1 && wantVgpu == "" {
+		log.Printf("[DEBUG] PciPassthroughApplyOperation: Device config operations from apply: %s", DeviceChangeString(spec))
+		return l, spec, nil
+	}
+
+	// We have devices to add. Validate them against the devices available on
+	// the host, or the cluster if we don't know the host yet.
+	target, err := pciPassthroughConfigTarget(d, c)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error fetching available PCI devices: %s", err)
+	}
+	var ids []string
+	for id := range wantIDs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		info, err := findPciPassthroughInfo(target, id)
+		if err != nil {
+			return nil, nil, err
+		}
+		backing := &types.VirtualPCIPassthroughDeviceBackingInfo{
+			VirtualDeviceDeviceBackingInfo: types.VirtualDeviceDeviceBackingInfo{
+				DeviceName: info.PciDevice.DeviceName,
+			},
+			Id:       info.PciDevice.Id,
+			DeviceId: fmt.Sprintf("%x", uint16(info.PciDevice.DeviceId)),
+			SystemId: info.SystemId,
+			VendorId: info.PciDevice.VendorId,
+		}
+		cspec, err := createPciPassthrough(l, backing)
+		if err != nil {
+			return nil, nil, err
+		}
+		l = applyDeviceChange(l, cspec)
+		spec = append(spec, cspec...)
+	}
+	if wantVgpu != "" {
+		if err := validateSharedPciDevice(target, wantVgpu); err != nil {
+			return nil, nil, err
+		}
+		backing := &types.VirtualPCIPassthroughVmiopBackingInfo{
+			Vgpu: wantVgpu,
+		}
+		cspec, err := createPciPassthrough(l, backing)
+		if err != nil {
+			return nil, nil, err
+		}
+		l = applyDeviceChange(l, cspec)
+		spec = append(spec, cspec...)
+	}
+
+	log.Printf("[DEBUG] PciPassthroughApplyOperation: Device list at end of operation: %s", DeviceListString(l))
+	log.Printf("[DEBUG] PciPassthroughApplyOperation: Device config operations from apply: %s", DeviceChangeString(spec))
+	log.Printf("[DEBUG] PciPassthroughApplyOperation: Apply complete, returning updated spec")
+	return l, spec, nil
+}
+
+// PciPassthroughRefreshOperation processes a refresh operation for the PCI
+// passthrough devices on a virtual machine, setting pci_device_id and
+// shared_pci_device from the devices found in the device list.
+func PciPassthroughRefreshOperation(d *schema.ResourceData, l object.VirtualDeviceList) error {
+	log.Printf("[DEBUG] PciPassthroughRefreshOperation: Beginning refresh")
+	var ids []string
+	var vgpu string
+	for _, dev := range l.SelectByType((*types.VirtualPCIPassthrough)(nil)) {
+		switch backing := dev.GetVirtualDevice().Backing.(type) {
+		case *types.VirtualPCIPassthroughDeviceBackingInfo:
+			ids = append(ids, backing.Id)
+		case *types.VirtualPCIPassthroughVmiopBackingInfo:
+			if vgpu != "" {
+				log.Printf("[WARN] PciPassthroughRefreshOperation: Ignoring additional shared PCI device %q", backing.Vgpu)
+				continue
+			}
+			vgpu = backing.Vgpu
+		}
+	}
+	log.Printf("[DEBUG] PciPassthroughRefreshOperation: Found PCI devices %v and shared PCI device %q", ids, vgpu)
+	if err := d.Set("pci_device_id", ids); err != nil {
+		return err
+	}
+	return d.Set("shared_pci_device", vgpu)
+}
+
+// PciPassthroughDiffOperation validates the PCI passthrough settings on a
+// virtual machine. vSphere requires that all memory on a virtual machine with
+// passthrough devices is reserved.
+func PciPassthroughDiffOperation(d *schema.ResourceDiff) error {
+	if d.Get("pci_device_id").(*schema.Set).Len() < 1 && d.Get("shared_pci_device").(string) == "" {
+		return nil
+	}
+	if !d.NewValueKnown("memory") || !d.NewValueKnown("memory_reservation") {
+		return nil
+	}
+	if d.Get("memory_reservation").(int) != d.Get("memory").(int) {
+		return fmt.Errorf(
+			"memory_reservation must be equal to memory (%d) when pci_device_id or shared_pci_device is set",
+			d.Get("memory").(int),
+		)
+	}
+	return nil
+}
+
+// createPciPassthrough creates a VirtualPCIPassthrough device with the
+// supplied backing, attached to the PCI controller, and returns the config spec
+// to add it.
+func createPciPassthrough(l object.VirtualDeviceList, backing types.BaseVirtualDeviceBackingInfo) ([]types.BaseVirtualDeviceConfigSpec, error) {
+	ctlr := l.PickController(&types.VirtualPCIController{})
+	if ctlr == nil {
+		return nil, fmt.Errorf("could not find an available %s controller", SubresourceControllerTypePCI)
+	}
+	dev := &types.VirtualPCIPassthrough{
+		VirtualDevice: types.VirtualDevice{
+			Key:     l.NewKey(),
+			Backing: backing,
+		},
+	}
+	l.AssignController(dev, ctlr)
+	log.Printf("[DEBUG] createPciPassthrough: Adding device %s", l.Name(dev))
+	return object.VirtualDeviceList{dev}.ConfigSpec(types.VirtualDeviceConfigSpecOperationAdd)
+}
+
+// pciPassthroughConfigTarget fetches the config target for the host that the
+// virtual machine is, or will be, running on. If the host is not known yet,
+// the config target of the resource pool's compute resource is returned.
+func pciPassthroughConfigTarget(d *schema.ResourceData, c *govmomi.Client) (*types.ConfigTarget, error) {
+	pool, err := resourcepool.FromID(c, d.Get("resource_pool_id").(string))
+	if err != nil {
+		return nil, err
+	}
+	var host *object.HostSystem
+	if hsID := d.Get("host_system_id").(string); hsID != "" {
+		if host, err = hostsystem.FromID(c, hsID); err != nil {
+			return nil, err
+		}
+	}
+	return resourcepool.ConfigTarget(c, pool, host)
+}
+
+// findPciPassthroughInfo looks for a PCI device with the supplied ID in the
+// passthrough devices available in the config target.
+func findPciPassthroughInfo(target *types.ConfigTarget, id string) (*types.VirtualMachinePciPassthroughInfo, error) {
+	for _, v := range target.PciPassthrough {
+		info := v.GetVirtualMachinePciPassthroughInfo()
+		if info.PciDevice.Id == id {
+			return info, nil
+		}
+	}
+	return nil, fmt.Errorf("PCI device %q is not available for passthrough on the target host", id)
+}
+
+// validateSharedPciDevice checks to make sure that the supplied vGPU profile
+// is available in the config target.
+func validateSharedPciDevice(target *types.ConfigTarget, vgpu string) error {
+	for _, v := range target.SharedGpuPassthroughTypes {
+		if v.Vgpu == vgpu {
+			return nil
+		}
+	}
+	return fmt.Errorf("shared PCI device %q is not available on the target host", vgpu)
+}
@@ -0,0 +1,78 @@
+package virtualdevice
+
+import (
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const subresourceTypeVTPM = "vtpm"
+
+// vtpmVersionAllowedValues are the allowed values for the version of a virtual
+// TPM device. vSphere only supports TPM 2.0 devices.
+var vtpmVersionAllowedValues = []string{"2.0"}
+
+// VTPMSubresourceSchema represents the schema for the vtpm sub-resource.
+func VTPMSubresourceSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"version": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      "2.0",
+			Description:  "The version of the TPM device. Only 2.0 is supported.",
+			ValidateFunc: validation.StringInSlice(vtpmVersionAllowedValues, false),
+		},
+	}
+}
+
+// VTPMApplyOperation processes an apply operation for the virtual TPM device
+// on a virtual machine. There can only be one TPM device on a virtual machine,
+// so the device is simply added or removed depending on whether or not the
+// vtpm sub-resource is present in configuration. As the device list is
+// reconciled against configuration, this function is also used to process
+// post-clone changes.
+func VTPMApplyOperation(d *schema.ResourceData, l object.VirtualDeviceList) (object.VirtualDeviceList, []types.BaseVirtualDeviceConfigSpec, error) {
+	log.Printf("[DEBUG] VTPMApplyOperation: Beginning apply operation")
+	want := len(d.Get(subresourceTypeVTPM).([]interface{})) > 0
+	devices := l.SelectByType((*types.VirtualTPM)(nil))
+
+	var spec []types.BaseVirtualDeviceConfigSpec
+	var err error
+	switch {
+	case want && len(devices) < 1:
+		dev := &types.VirtualTPM{
+			VirtualDevice: types.VirtualDevice{
+				Key: l.NewKey(),
+			},
+		}
+		log.Printf("[DEBUG] VTPMApplyOperation: Adding TPM device")
+		spec, err = object.VirtualDeviceList{dev}.ConfigSpec(types.VirtualDeviceConfigSpecOperationAdd)
+	case !want && len(devices) > 0:
+		log.Printf("[DEBUG] VTPMApplyOperation: Removing TPM device %s", l.Name(devices[0]))
+		spec, err = devices.ConfigSpec(types.VirtualDeviceConfigSpecOperationRemove)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	l = applyDeviceChange(l, spec)
+	log.Printf("[DEBUG] VTPMApplyOperation: Device config operations from apply: %s", DeviceChangeString(spec))
+	log.Printf("[DEBUG] VTPMApplyOperation: Apply complete, returning updated spec")
+	return l, spec, nil
+}
+
+// VTPMRefreshOperation processes a refresh operation for the virtual TPM
+// device on a virtual machine.
+func VTPMRefreshOperation(d *schema.ResourceData, l object.VirtualDeviceList) error {
+	log.Printf("[DEBUG] VTPMRefreshOperation: Beginning refresh")
+	var vtpm []interface{}
+	if len(l.SelectByType((*types.VirtualTPM)(nil))) > 0 {
+		vtpm = append(vtpm, map[string]interface{}{
+			"version": vtpmVersionAllowedValues[0],
+		})
+	}
+	log.Printf("[DEBUG] VTPMRefreshOperation: TPM device present: %t", len(vtpm) > 0)
+	return d.Set(subresourceTypeVTPM, vtpm)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/datastore"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/resourcepool"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/spbm"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/virtualmachine"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/virtualdevice"
 	"github.com/vmware/govmomi"
@@ -215,6 +216,11 @@ func ExpandVirtualMachineCloneSpec(d *schema.ResourceData, c *govmomi.Client) (t
 		spec.Location.Host = &hsRef
 	}
 
+	// Set the storage policy for the virtual machine home.
+	if policyID := d.Get("storage_policy_id").(string); policyID != "" {
+		spec.Location.Profile = spbm.PolicySpecByID(policyID)
+	}
+
 	// Grab the relocate spec for the disks.
 	l := object.VirtualDeviceList(vprops.Config.Hardware.Device)
 	relocators, err := virtualdevice.DiskCloneRelocateOperation(d, c, l)
@@ -0,0 +1,119 @@
+package vmworkflow
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/network"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// VirtualMachineOvfDeploySchema represents the schema for the VM OVF deploy
+// sub-resource.
+//
+// This is a workflow for vsphere_virtual_machine that facilitates the creation
+// of a virtual machine from an OVF or OVA package, located either on the
+// local filesystem or at a remote URL.
+func VirtualMachineOvfDeploySchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"local_ovf_path": {
+			Type:          schema.TypeString,
+			Optional:      true,
+			Description:   "The path to a local OVF or OVA file to deploy the virtual machine from.",
+			ConflictsWith: []string{"ovf_deploy.0.remote_ovf_url"},
+		},
+		"remote_ovf_url": {
+			Type:          schema.TypeString,
+			Optional:      true,
+			Description:   "The URL of a remote OVF or OVA file to deploy the virtual machine from.",
+			ConflictsWith: []string{"ovf_deploy.0.local_ovf_path"},
+		},
+		"allow_unverified_ssl_cert": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Allow unverified SSL certificates when fetching a remote OVF or OVA file.",
+		},
+		"disk_provisioning": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The disk provisioning type to use for the disks in the package. If not set, the type defined in the package is used.",
+			ValidateFunc: validation.StringInSlice([]string{
+				string(types.OvfCreateImportSpecParamsDiskProvisioningTypeThin),
+				string(types.OvfCreateImportSpecParamsDiskProvisioningTypeThick),
+				string(types.OvfCreateImportSpecParamsDiskProvisioningTypeEagerZeroedThick),
+			}, false),
+		},
+		"deployment_option": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The deployment configuration to use, as defined in the package. If not set, the default configuration is used.",
+		},
+		"ip_allocation_policy": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The IP allocation policy to use for the deployed virtual machine.",
+			ValidateFunc: validation.StringInSlice([]string{
+				string(types.VAppIPAssignmentInfoIpAllocationPolicyDhcpPolicy),
+				string(types.VAppIPAssignmentInfoIpAllocationPolicyTransientPolicy),
+				string(types.VAppIPAssignmentInfoIpAllocationPolicyFixedPolicy),
+				string(types.VAppIPAssignmentInfoIpAllocationPolicyFixedAllocatedPolicy),
+			}, false),
+		},
+		"ip_protocol": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The IP protocol to use for the deployed virtual machine.",
+			ValidateFunc: validation.StringInSlice([]string{
+				string(types.VAppIPAssignmentInfoProtocolsIPv4),
+				string(types.VAppIPAssignmentInfoProtocolsIPv6),
+			}, false),
+		},
+		"ovf_network_map": {
+			Type:        schema.TypeMap,
+			Optional:    true,
+			Description: "A map of the network names defined in the package to the IDs of the networks to map them to.",
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+		"timeout": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      30,
+			Description:  "The timeout, in minutes, to wait for the files in the package to upload.",
+			ValidateFunc: validation.IntAtLeast(10),
+		},
+	}
+}
+
+// ExpandOvfCreateImportSpecParams reads the ovf_deploy sub-resource and
+// returns the parameters used to create the import spec for the package.
+func ExpandOvfCreateImportSpecParams(d *schema.ResourceData, c *govmomi.Client) (types.OvfCreateImportSpecParams, error) {
+	params := types.OvfCreateImportSpecParams{
+		OvfManagerCommonParams: types.OvfManagerCommonParams{
+			DeploymentOption: d.Get("ovf_deploy.0.deployment_option").(string),
+		},
+		EntityName:         d.Get("name").(string),
+		IpAllocationPolicy: d.Get("ovf_deploy.0.ip_allocation_policy").(string),
+		IpProtocol:         d.Get("ovf_deploy.0.ip_protocol").(string),
+		DiskProvisioning:   d.Get("ovf_deploy.0.disk_provisioning").(string),
+	}
+	if hsID, ok := d.GetOk("host_system_id"); ok {
+		params.HostSystem = &types.ManagedObjectReference{
+			Type:  "HostSystem",
+			Value: hsID.(string),
+		}
+	}
+	for name, id := range d.Get("ovf_deploy.0.ovf_network_map").(map[string]interface{}) {
+		net, err := network.FromID(c, id.(string))
+		if err != nil {
+			return params, fmt.Errorf("could not find network ID %q for OVF network %q: %s", id, name, err)
+		}
+		params.NetworkMapping = append(params.NetworkMapping, types.OvfNetworkMapping{
+			Name:    name,
+			Network: net.Reference(),
+		})
+	}
+	return params, nil
+}
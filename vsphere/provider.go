@@ -97,24 +97,33 @@ func Provider() terraform.ResourceProvider {
 			"vsphere_compute_cluster":                         resourceVSphereComputeCluster(),
 			"vsphere_compute_policy":                          resourceVSphereComputePolicy(),
 			"vsphere_compute_cluster_host_group":              resourceVSphereComputeClusterHostGroup(),
+			"vsphere_compute_cluster_image":                   resourceVSphereComputeClusterImage(),
 			"vsphere_compute_cluster_vm_affinity_rule":        resourceVSphereComputeClusterVMAffinityRule(),
 			"vsphere_compute_cluster_vm_anti_affinity_rule":   resourceVSphereComputeClusterVMAntiAffinityRule(),
 			"vsphere_compute_cluster_vm_dependency_rule":      resourceVSphereComputeClusterVMDependencyRule(),
 			"vsphere_compute_cluster_vm_group":                resourceVSphereComputeClusterVMGroup(),
 			"vsphere_compute_cluster_vm_host_rule":            resourceVSphereComputeClusterVMHostRule(),
+			"vsphere_content_library":                         resourceVSphereContentLibrary(),
+			"vsphere_content_library_item":                    resourceVSphereContentLibraryItem(),
 			"vsphere_custom_attribute":                        resourceVSphereCustomAttribute(),
 			"vsphere_datacenter":                              resourceVSphereDatacenter(),
 			"vsphere_datastore_cluster":                       resourceVSphereDatastoreCluster(),
 			"vsphere_datastore_cluster_vm_anti_affinity_rule": resourceVSphereDatastoreClusterVMAntiAffinityRule(),
+			"vsphere_distributed_network_resource_pool":       resourceVSphereDistributedNetworkResourcePool(),
+			"vsphere_distributed_port":                        resourceVSphereDistributedPort(),
 			"vsphere_distributed_port_group":                  resourceVSphereDistributedPortGroup(),
+			"vsphere_distributed_port_mirroring_session":      resourceVSphereDistributedPortMirroringSession(),
 			"vsphere_distributed_virtual_switch":              resourceVSphereDistributedVirtualSwitch(),
 			"vsphere_drs_vm_override":                         resourceVSphereDRSVMOverride(),
 			"vsphere_dpm_host_override":                       resourceVSphereDPMHostOverride(),
 			"vsphere_file":                                    resourceVSphereFile(),
 			"vsphere_folder":                                  resourceVSphereFolder(),
 			"vsphere_ha_vm_override":                          resourceVSphereHAVMOverride(),
+			"vsphere_host_firewall_ruleset":                   resourceVSphereHostFirewallRuleset(),
+			"vsphere_host_iscsi_adapter":                      resourceVSphereHostIscsiAdapter(),
 			"vsphere_host_port_group":                         resourceVSphereHostPortGroup(),
 			"vsphere_host_virtual_switch":                     resourceVSphereHostVirtualSwitch(),
+			"vsphere_key_provider":                            resourceVSphereKeyProvider(),
 			"vsphere_license":                                 resourceVSphereLicense(),
 			"vsphere_resource_pool":                           resourceVSphereResourcePool(),
 			"vsphere_tag":                                     resourceVSphereTag(),
@@ -126,6 +135,7 @@ func Provider() terraform.ResourceProvider {
 			"vsphere_vapp_container":                          resourceVSphereVAppContainer(),
 			"vsphere_vapp_entity":                             resourceVSphereVAppEntity(),
 			"vsphere_vmfs_datastore":                          resourceVSphereVmfsDatastore(),
+			"vsphere_vm_storage_policy":                       resourceVSphereVMStoragePolicy(),
 			"vsphere_virtual_machine_snapshot":                resourceVSphereVirtualMachineSnapshot(),
 			"vsphere_host":                                    resourceVsphereHost(),
 			"vsphere_vnic":                                    resourceVsphereNic(),
@@ -134,15 +144,20 @@ func Provider() terraform.ResourceProvider {
 		DataSourcesMap: map[string]*schema.Resource{
 			"vsphere_compute_cluster":            dataSourceVSphereComputeCluster(),
 			"vsphere_compute_policy":             dataSourceVSphereComputePolicy(),
+			"vsphere_content_library":            dataSourceVSphereContentLibrary(),
+			"vsphere_content_library_item":       dataSourceVSphereContentLibraryItem(),
 			"vsphere_custom_attribute":           dataSourceVSphereCustomAttribute(),
 			"vsphere_datacenter":                 dataSourceVSphereDatacenter(),
 			"vsphere_datastore":                  dataSourceVSphereDatastore(),
 			"vsphere_datastore_cluster":          dataSourceVSphereDatastoreCluster(),
+			"vsphere_distributed_port_group":     dataSourceVSphereDistributedPortGroup(),
 			"vsphere_distributed_virtual_switch": dataSourceVSphereDistributedVirtualSwitch(),
 			"vsphere_folder":                     dataSourceVSphereFolder(),
 			"vsphere_host":                       dataSourceVSphereHost(),
+			"vsphere_host_port_group":            dataSourceVSphereHostPortGroup(),
 			"vsphere_network":                    dataSourceVSphereNetwork(),
 			"vsphere_resource_pool":              dataSourceVSphereResourcePool(),
+			"vsphere_storage_policy":             dataSourceVSphereStoragePolicy(),
 			"vsphere_tag":                        dataSourceVSphereTag(),
 			"vsphere_tag_category":               dataSourceVSphereTagCategory(),
 			"vsphere_vapp_container":             dataSourceVSphereVAppContainer(),
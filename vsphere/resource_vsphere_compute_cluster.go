@@ -1,8 +1,10 @@
 package vsphere
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
@@ -12,6 +14,7 @@ import (
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/vsan"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/vim25/types"
@@ -112,10 +115,11 @@ var clusterInfraUpdateHaConfigInfoRemediationTypeAllowedValues = []string{
 
 func resourceVSphereComputeCluster() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceVSphereComputeClusterCreate,
-		Read:   resourceVSphereComputeClusterRead,
-		Update: resourceVSphereComputeClusterUpdate,
-		Delete: resourceVSphereComputeClusterDelete,
+		Create:        resourceVSphereComputeClusterCreate,
+		Read:          resourceVSphereComputeClusterRead,
+		Update:        resourceVSphereComputeClusterUpdate,
+		Delete:        resourceVSphereComputeClusterDelete,
+		CustomizeDiff: resourceVSphereComputeClusterCustomizeDiff,
 		Importer: &schema.ResourceImporter{
 			State: resourceVSphereComputeClusterImport,
 		},
@@ -452,6 +456,69 @@ func resourceVSphereComputeCluster() *schema.Resource {
 				Description: "The list of IDs for health update providers configured for this cluster.",
 				Elem:        &schema.Schema{Type: schema.TypeString},
 			},
+			// vSAN
+			"vsan_enabled": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Computed:    true,
+				Description: "Enable vSAN on this cluster.",
+			},
+			"vsan_dedup_enabled": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Computed:    true,
+				Description: "Enable deduplication on the vSAN cluster. Requires that vsan_compression_enabled be set.",
+			},
+			"vsan_compression_enabled": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Computed:    true,
+				Description: "Enable compression on the vSAN cluster.",
+			},
+			"vsan_encryption_enabled": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Computed:    true,
+				Description: "Enable encryption of vSAN data at rest. Requires that vsan_encryption_key_provider_id be set.",
+			},
+			"vsan_encryption_key_provider_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The ID of the key provider to use for encryption of vSAN data at rest.",
+			},
+			"vsan_operation_timeout": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      14400,
+				Description:  "The timeout, in seconds, for each vSAN operation that can move data, such as removing disks or disk groups and changing deduplication, compression, or encryption.",
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"vsan_disk_group": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "A disk group to configure for vSAN on a host in the cluster. Requires that vSAN be enabled. Only the disk groups in this set are managed; other disk groups on the hosts are left alone.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"host_system_id": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The managed object ID of the host the disk group is on. The host must be in host_system_ids.",
+						},
+						"cache": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The canonical name of the disk to use for the vSAN cache tier.",
+						},
+						"storage": {
+							Type:        schema.TypeSet,
+							Required:    true,
+							Description: "The canonical names of the disks to use for the vSAN capacity tier.",
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
 			"resource_pool_id": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -494,6 +561,15 @@ func resourceVSphereComputeClusterCreate(d *schema.ResourceData, meta interface{
 		return err
 	}
 
+	// vSAN disk groups can only be created once vSAN has been enabled.
+	if err := resourceVSphereComputeClusterApplyVsanDiskGroupAdditions(d, meta); err != nil {
+		return err
+	}
+
+	if err := resourceVSphereComputeClusterApplyVsanDataServices(d, meta, cluster); err != nil {
+		return err
+	}
+
 	// All done!
 	log.Printf("[DEBUG] %s: Create finished successfully", resourceVSphereComputeClusterIDString(d))
 	return resourceVSphereComputeClusterRead(d, meta)
@@ -553,6 +629,12 @@ func resourceVSphereComputeClusterUpdate(d *schema.ResourceData, meta interface{
 		return err
 	}
 
+	// vSAN disk groups are removed before hosts leave the cluster and before
+	// vSAN is disabled.
+	if err := resourceVSphereComputeClusterApplyVsanDiskGroupRemovals(d, meta); err != nil {
+		return err
+	}
+
 	if err := resourceVSphereComputeClusterProcessHostUpdate(d, meta, cluster); err != nil {
 		return err
 	}
@@ -561,6 +643,14 @@ func resourceVSphereComputeClusterUpdate(d *schema.ResourceData, meta interface{
 		return err
 	}
 
+	if err := resourceVSphereComputeClusterApplyVsanDiskGroupAdditions(d, meta); err != nil {
+		return err
+	}
+
+	if err := resourceVSphereComputeClusterApplyVsanDataServices(d, meta, cluster); err != nil {
+		return err
+	}
+
 	if err := resourceVSphereComputeClusterApplyTags(d, meta, cluster); err != nil {
 		return err
 	}
@@ -616,6 +706,20 @@ func resourceVSphereComputeClusterDelete(d *schema.ResourceData, meta interface{
 	return nil
 }
 
+func resourceVSphereComputeClusterCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning diff customization and validation", resourceVSphereComputeClusterIDString(d))
+
+	if err := resourceVSphereComputeClusterValidateVsanDiskGroups(d); err != nil {
+		return err
+	}
+	if err := resourceVSphereComputeClusterValidateVsanDataServices(d); err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] %s: Diff customization and validation complete", resourceVSphereComputeClusterIDString(d))
+	return nil
+}
+
 func resourceVSphereComputeClusterImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
 	p := d.Id()
 	cluster, err := resourceVSphereComputeClusterGetClusterFromPath(meta, p, "")
@@ -644,6 +748,7 @@ func resourceVSphereComputeClusterImportSetDefaults(d *schema.ResourceData) erro
 		"ha_admission_control_slot_policy_explicit_cpu":    s["ha_admission_control_slot_policy_explicit_cpu"].Default,
 		"ha_admission_control_slot_policy_explicit_memory": s["ha_admission_control_slot_policy_explicit_memory"].Default,
 		"host_cluster_exit_timeout":                        s["host_cluster_exit_timeout"].Default,
+		"vsan_operation_timeout":                           s["vsan_operation_timeout"].Default,
 	})
 }
 
@@ -803,6 +908,348 @@ func resourceVSphereComputeClusterApplyClusterConfiguration(
 	return clustercomputeresource.Reconfigure(cluster, spec)
 }
 
+// computeClusterVsanDiskGroup represents a single vsan_disk_group entry.
+type computeClusterVsanDiskGroup struct {
+	HostSystemID string
+	Cache        string
+	Storage      []string
+}
+
+// expandComputeClusterVsanDiskGroups reads a vsan_disk_group set and returns
+// the disk groups in it, keyed by the host system ID and cache disk.
+func expandComputeClusterVsanDiskGroups(s *schema.Set) map[string]computeClusterVsanDiskGroup {
+	m := make(map[string]computeClusterVsanDiskGroup)
+	for _, v := range s.List() {
+		dg := v.(map[string]interface{})
+		g := computeClusterVsanDiskGroup{
+			HostSystemID: dg["host_system_id"].(string),
+			Cache:        dg["cache"].(string),
+			Storage:      structure.SliceInterfacesToStrings(dg["storage"].(*schema.Set).List()),
+		}
+		m[g.HostSystemID+":"+g.Cache] = g
+	}
+	return m
+}
+
+// resourceVSphereComputeClusterValidateVsanDiskGroups checks that vSAN is
+// enabled when disk groups are configured, and that every disk group is on a
+// host in host_system_ids. This is done at diff time so that the apply does
+// not fail half way through, after other changes to the cluster have been
+// made.
+func resourceVSphereComputeClusterValidateVsanDiskGroups(d *schema.ResourceDiff) error {
+	if !d.NewValueKnown("vsan_disk_group") {
+		return nil
+	}
+	groups := expandComputeClusterVsanDiskGroups(d.Get("vsan_disk_group").(*schema.Set))
+	if len(groups) < 1 {
+		return nil
+	}
+	if d.NewValueKnown("vsan_enabled") && !d.Get("vsan_enabled").(bool) {
+		return errors.New("vsan_enabled must be set to configure vsan_disk_group")
+	}
+	if !d.NewValueKnown("host_system_ids") {
+		return nil
+	}
+	hosts := d.Get("host_system_ids").(*schema.Set)
+	for _, g := range groups {
+		if !hosts.Contains(g.HostSystemID) {
+			return fmt.Errorf("host %q for vSAN disk group %q is not in host_system_ids", g.HostSystemID, g.Cache)
+		}
+	}
+	return nil
+}
+
+// resourceVSphereComputeClusterValidateVsanDataServices checks that vSAN is
+// enabled when deduplication, compression, or encryption are enabled, and
+// that the settings are consistent with each other.
+func resourceVSphereComputeClusterValidateVsanDataServices(d *schema.ResourceDiff) error {
+	if d.Get("vsan_dedup_enabled").(bool) && !d.Get("vsan_compression_enabled").(bool) {
+		return errors.New("vsan_compression_enabled must be set when vsan_dedup_enabled is set")
+	}
+	if d.Get("vsan_encryption_enabled").(bool) && d.NewValueKnown("vsan_encryption_key_provider_id") && d.Get("vsan_encryption_key_provider_id").(string) == "" {
+		return errors.New("vsan_encryption_key_provider_id must be set when vsan_encryption_enabled is set")
+	}
+	if !d.NewValueKnown("vsan_enabled") || d.Get("vsan_enabled").(bool) {
+		return nil
+	}
+	for _, k := range computeClusterVsanDataServiceKeys {
+		if k == "vsan_encryption_key_provider_id" || !d.HasChange(k) {
+			continue
+		}
+		if d.Get(k).(bool) {
+			return fmt.Errorf("vsan_enabled must be set to enable %s", k)
+		}
+	}
+	return nil
+}
+
+// resourceVSphereComputeClusterApplyVsanDiskGroupRemovals removes any vSAN
+// disk groups, or capacity disks in disk groups, that have been removed from
+// the configuration. Only disk groups that were in the configuration before
+// are in state, so disk groups that Terraform does not manage are never
+// removed.
+func resourceVSphereComputeClusterApplyVsanDiskGroupRemovals(d *schema.ResourceData, meta interface{}) error {
+	if !d.HasChange("vsan_disk_group") {
+		return nil
+	}
+	log.Printf("[DEBUG] %s: Removing any old vSAN disk groups", resourceVSphereComputeClusterIDString(d))
+	client, err := resourceVSphereComputeClusterClient(meta)
+	if err != nil {
+		return err
+	}
+
+	o, n := d.GetChange("vsan_disk_group")
+	oldGroups := expandComputeClusterVsanDiskGroups(o.(*schema.Set))
+	newGroups := expandComputeClusterVsanDiskGroups(n.(*schema.Set))
+	for k, og := range oldGroups {
+		vs, err := hostVsanSystemFromHostSystemID(client, og.HostSystemID)
+		if err != nil {
+			return fmt.Errorf("error loading vSAN system for host %q: %s", og.HostSystemID, err)
+		}
+		ng, ok := newGroups[k]
+		if ok {
+			removed := structure.SliceInterfacesToStrings(
+				schema.NewSet(schema.HashString, structure.SliceStringsToInterfaces(og.Storage)).Difference(
+					schema.NewSet(schema.HashString, structure.SliceStringsToInterfaces(ng.Storage)),
+				).List(),
+			)
+			if len(removed) < 1 {
+				continue
+			}
+			disks, err := hostScsiDisksFromNames(client, og.HostSystemID, removed)
+			if err != nil {
+				return err
+			}
+			if err := removeHostVsanDisks(client, vs, disks, resourceVSphereComputeClusterVsanOperationTimeout(d)); err != nil {
+				return fmt.Errorf("error removing disks from vSAN disk group %q: %s", og.Cache, err)
+			}
+			continue
+		}
+		mappings, err := hostVsanDiskMappings(vs)
+		if err != nil {
+			return err
+		}
+		for _, mapping := range mappings {
+			if mapping.Ssd.CanonicalName != og.Cache {
+				continue
+			}
+			if err := removeHostVsanDiskMappings(client, vs, []types.VsanHostDiskMapping{mapping}, resourceVSphereComputeClusterVsanOperationTimeout(d)); err != nil {
+				return fmt.Errorf("error removing vSAN disk group %q: %s", og.Cache, err)
+			}
+		}
+	}
+	return nil
+}
+
+// resourceVSphereComputeClusterApplyVsanDiskGroupAdditions creates any new
+// vSAN disk groups, and adds any new capacity disks to existing disk groups.
+func resourceVSphereComputeClusterApplyVsanDiskGroupAdditions(d *schema.ResourceData, meta interface{}) error {
+	if !d.HasChange("vsan_disk_group") {
+		return nil
+	}
+	log.Printf("[DEBUG] %s: Adding any new vSAN disk groups", resourceVSphereComputeClusterIDString(d))
+	client, err := resourceVSphereComputeClusterClient(meta)
+	if err != nil {
+		return err
+	}
+
+	o, n := d.GetChange("vsan_disk_group")
+	oldGroups := expandComputeClusterVsanDiskGroups(o.(*schema.Set))
+	newGroups := expandComputeClusterVsanDiskGroups(n.(*schema.Set))
+	for k, ng := range newGroups {
+		storage := ng.Storage
+		if og, ok := oldGroups[k]; ok {
+			storage = structure.SliceInterfacesToStrings(
+				schema.NewSet(schema.HashString, structure.SliceStringsToInterfaces(ng.Storage)).Difference(
+					schema.NewSet(schema.HashString, structure.SliceStringsToInterfaces(og.Storage)),
+				).List(),
+			)
+			if len(storage) < 1 {
+				continue
+			}
+		}
+		vs, err := hostVsanSystemFromHostSystemID(client, ng.HostSystemID)
+		if err != nil {
+			return fmt.Errorf("error loading vSAN system for host %q: %s", ng.HostSystemID, err)
+		}
+		// A disk group that already exists on the host, but is not tracked yet,
+		// is adopted. Only the capacity disks that are not in it yet are added.
+		mappings, err := hostVsanDiskMappings(vs)
+		if err != nil {
+			return err
+		}
+		for _, mapping := range mappings {
+			if mapping.Ssd.CanonicalName != ng.Cache {
+				continue
+			}
+			existing := make(map[string]struct{})
+			for _, disk := range mapping.NonSsd {
+				existing[disk.CanonicalName] = struct{}{}
+			}
+			var missing []string
+			for _, name := range storage {
+				if _, ok := existing[name]; !ok {
+					missing = append(missing, name)
+				}
+			}
+			storage = missing
+		}
+		if len(storage) < 1 {
+			continue
+		}
+		disks, err := hostScsiDisksFromNames(client, ng.HostSystemID, append([]string{ng.Cache}, storage...))
+		if err != nil {
+			return err
+		}
+		mapping := types.VsanHostDiskMapping{
+			Ssd:    disks[0],
+			NonSsd: disks[1:],
+		}
+		if err := initializeHostVsanDisks(client, vs, []types.VsanHostDiskMapping{mapping}, resourceVSphereComputeClusterVsanOperationTimeout(d)); err != nil {
+			return fmt.Errorf("error configuring vSAN disk group %q: %s", ng.Cache, err)
+		}
+	}
+	return nil
+}
+
+// resourceVSphereComputeClusterVsanOperationTimeout returns the timeout for
+// vSAN operations that can move data, from vsan_operation_timeout.
+func resourceVSphereComputeClusterVsanOperationTimeout(d *schema.ResourceData) time.Duration {
+	return time.Duration(d.Get("vsan_operation_timeout").(int)) * time.Second
+}
+
+// computeClusterVsanDataServiceKeys are the keys of the vSAN settings that
+// are managed through the vSAN management API rather than through the cluster
+// configuration.
+var computeClusterVsanDataServiceKeys = []string{
+	"vsan_dedup_enabled",
+	"vsan_compression_enabled",
+	"vsan_encryption_enabled",
+	"vsan_encryption_key_provider_id",
+}
+
+// resourceVSphereComputeClusterApplyVsanDataServices applies the vSAN
+// deduplication, compression, and encryption settings of the cluster. These
+// are not part of the cluster configuration, and are changed through the vSAN
+// management API instead. Only the settings that have changed are sent.
+func resourceVSphereComputeClusterApplyVsanDataServices(
+	d *schema.ResourceData,
+	meta interface{},
+	cluster *object.ClusterComputeResource,
+) error {
+	var changed bool
+	for _, k := range computeClusterVsanDataServiceKeys {
+		if d.HasChange(k) {
+			changed = true
+		}
+	}
+	// The settings go away with vSAN itself, so there is nothing to do when
+	// vSAN is disabled.
+	if !changed || !d.Get("vsan_enabled").(bool) {
+		return nil
+	}
+	log.Printf("[DEBUG] %s: Applying vSAN data services", resourceVSphereComputeClusterIDString(d))
+	client, err := resourceVSphereComputeClusterClient(meta)
+	if err != nil {
+		return err
+	}
+
+	var spec vsan.ReconfigSpec
+	if d.HasChange("vsan_dedup_enabled") || d.HasChange("vsan_compression_enabled") {
+		spec.DataEfficiencyConfig = &vsan.DataEfficiencyConfig{
+			DedupEnabled:       d.Get("vsan_dedup_enabled").(bool),
+			CompressionEnabled: structure.GetBool(d, "vsan_compression_enabled"),
+		}
+	}
+	if d.HasChange("vsan_encryption_enabled") || d.HasChange("vsan_encryption_key_provider_id") {
+		spec.DataEncryptionConfig = &vsan.DataEncryptionConfig{
+			EncryptionEnabled: d.Get("vsan_encryption_enabled").(bool),
+		}
+		if id := d.Get("vsan_encryption_key_provider_id").(string); id != "" {
+			spec.DataEncryptionConfig.KmsProviderID = &types.KeyProviderId{Id: id}
+		}
+	}
+	if err := vsan.ReconfigureCluster(client, cluster, spec, resourceVSphereComputeClusterVsanOperationTimeout(d)); err != nil {
+		return fmt.Errorf("error configuring vSAN data services: %s", err)
+	}
+	return nil
+}
+
+// flattenComputeClusterVsanDataServices saves the vSAN deduplication,
+// compression, and encryption settings of the cluster into the supplied
+// ResourceData. The vSAN management API is only queried when vSAN is enabled.
+func flattenComputeClusterVsanDataServices(
+	d *schema.ResourceData,
+	client *govmomi.Client,
+	cluster *object.ClusterComputeResource,
+) error {
+	var dedup, compression, encryption bool
+	var keyProviderID string
+	if d.Get("vsan_enabled").(bool) {
+		config, err := vsan.ClusterConfig(client, cluster)
+		if err != nil {
+			return fmt.Errorf("error reading vSAN configuration: %s", err)
+		}
+		if c := config.DataEfficiencyConfig; c != nil {
+			dedup = c.DedupEnabled
+			compression = c.CompressionEnabled != nil && *c.CompressionEnabled
+		}
+		if c := config.DataEncryptionConfig; c != nil {
+			encryption = c.EncryptionEnabled
+			if c.KmsProviderID != nil {
+				keyProviderID = c.KmsProviderID.Id
+			}
+		}
+	}
+	return structure.SetBatch(d, map[string]interface{}{
+		"vsan_dedup_enabled":              dedup,
+		"vsan_compression_enabled":        compression,
+		"vsan_encryption_enabled":         encryption,
+		"vsan_encryption_key_provider_id": keyProviderID,
+	})
+}
+
+// flattenComputeClusterVsanDiskGroups refreshes the vSAN disk groups that are
+// currently tracked in the supplied ResourceData, keyed by host and cache
+// disk. Disk groups that are not tracked, such as ones created outside of
+// Terraform or by auto-claim, are never read into state, so that they are not
+// planned for removal. Tracked disk groups that no longer exist are dropped.
+func flattenComputeClusterVsanDiskGroups(d *schema.ResourceData, client *govmomi.Client) error {
+	tracked := expandComputeClusterVsanDiskGroups(d.Get("vsan_disk_group").(*schema.Set))
+	hosts := make(map[string]struct{})
+	for _, g := range tracked {
+		hosts[g.HostSystemID] = struct{}{}
+	}
+	var groups []interface{}
+	if d.Get("vsan_enabled").(bool) {
+		for hsID := range hosts {
+			vs, err := hostVsanSystemFromHostSystemID(client, hsID)
+			if err != nil {
+				return fmt.Errorf("error loading vSAN system for host %q: %s", hsID, err)
+			}
+			mappings, err := hostVsanDiskMappings(vs)
+			if err != nil {
+				return err
+			}
+			for _, mapping := range mappings {
+				if _, ok := tracked[hsID+":"+mapping.Ssd.CanonicalName]; !ok {
+					continue
+				}
+				var storage []string
+				for _, disk := range mapping.NonSsd {
+					storage = append(storage, disk.CanonicalName)
+				}
+				groups = append(groups, map[string]interface{}{
+					"host_system_id": hsID,
+					"cache":          mapping.Ssd.CanonicalName,
+					"storage":        storage,
+				})
+			}
+		}
+	}
+	return d.Set("vsan_disk_group", groups)
+}
+
 // resourceVSphereComputeClusterApplyTags processes the tags step for both
 // create and update for vsphere_compute_cluster.
 func resourceVSphereComputeClusterApplyTags(d *schema.ResourceData, meta interface{}, cluster *object.ClusterComputeResource) error {
@@ -1153,16 +1600,25 @@ func resourceVSphereComputeClusterFlattenData(
 		return err
 	}
 
-	return flattenClusterConfigSpecEx(d, props.ConfigurationEx.(*types.ClusterConfigInfoEx), version)
+	if err := flattenClusterConfigSpecEx(d, props.ConfigurationEx.(*types.ClusterConfigInfoEx), version); err != nil {
+		return err
+	}
+
+	if err := flattenComputeClusterVsanDataServices(d, client, cluster); err != nil {
+		return err
+	}
+
+	return flattenComputeClusterVsanDiskGroups(d, client)
 }
 
 // expandClusterConfigSpecEx reads certain ResourceData keys and returns a
 // ClusterConfigSpecEx.
 func expandClusterConfigSpecEx(d *schema.ResourceData, version viapi.VSphereVersion) *types.ClusterConfigSpecEx {
 	obj := &types.ClusterConfigSpecEx{
-		DasConfig: expandClusterDasConfigInfo(d, version),
-		DpmConfig: expandClusterDpmConfigInfo(d),
-		DrsConfig: expandClusterDrsConfigInfo(d),
+		DasConfig:  expandClusterDasConfigInfo(d, version),
+		DpmConfig:  expandClusterDpmConfigInfo(d),
+		DrsConfig:  expandClusterDrsConfigInfo(d),
+		VsanConfig: expandVsanClusterConfigInfo(d),
 	}
 
 	if version.Newer(viapi.VSphereVersion{Product: version.Product, Major: 6, Minor: 5}) {
@@ -1186,6 +1642,9 @@ func flattenClusterConfigSpecEx(d *schema.ResourceData, obj *types.ClusterConfig
 	if err := flattenClusterDrsConfigInfo(d, obj.DrsConfig); err != nil {
 		return err
 	}
+	if err := flattenVsanClusterConfigInfo(d, obj.VsanConfigInfo); err != nil {
+		return err
+	}
 
 	if version.Newer(viapi.VSphereVersion{Product: version.Product, Major: 6, Minor: 5}) {
 		if err := flattenClusterInfraUpdateHaConfigInfo(d, obj.InfraUpdateHaConfig); err != nil {
@@ -1749,6 +2208,37 @@ func flattenClusterProactiveDrsConfigInfo(d *schema.ResourceData, obj *types.Clu
 	})
 }
 
+// expandVsanClusterConfigInfo reads certain ResourceData keys and returns a
+// VsanClusterConfigInfo. Disks are not claimed automatically, as disk groups
+// are managed through vsan_disk_group.
+//
+// nil is returned when vsan_enabled has not been changed in the
+// configuration, so that the vSAN configuration of a cluster where vSAN was
+// enabled outside of Terraform is left alone.
+func expandVsanClusterConfigInfo(d *schema.ResourceData) *types.VsanClusterConfigInfo {
+	if !d.HasChange("vsan_enabled") {
+		return nil
+	}
+	obj := &types.VsanClusterConfigInfo{
+		Enabled: structure.GetBool(d, "vsan_enabled"),
+		DefaultConfig: &types.VsanClusterConfigInfoHostDefaultInfo{
+			AutoClaimStorage: structure.BoolPtr(false),
+		},
+	}
+
+	return obj
+}
+
+// flattenVsanClusterConfigInfo saves a VsanClusterConfigInfo into the
+// supplied ResourceData.
+func flattenVsanClusterConfigInfo(d *schema.ResourceData, obj *types.VsanClusterConfigInfo) error {
+	var enabled bool
+	if obj != nil && obj.Enabled != nil {
+		enabled = *obj.Enabled
+	}
+	return d.Set("vsan_enabled", enabled)
+}
+
 // resourceVSphereComputeClusterIDString prints a friendly string for the
 // vsphere_compute_cluster resource.
 func resourceVSphereComputeClusterIDString(d structure.ResourceIDStringer) string {
@@ -1789,6 +2279,12 @@ func resourceVSphereComputeClusterHasClusterConfigChangeExcluded(k string) bool
 		"name",
 		"datacenter_id",
 		"host_system_ids",
+		"vsan_disk_group",
+		"vsan_dedup_enabled",
+		"vsan_compression_enabled",
+		"vsan_encryption_enabled",
+		"vsan_encryption_key_provider_id",
+		"vsan_operation_timeout",
 		"folder",
 		"host_cluster_exit_timeout",
 		"force_evacuate_on_destroy",
@@ -0,0 +1,312 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/clustercomputeresource"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/esxsettings"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi/vapi/rest"
+)
+
+func resourceVSphereComputeClusterImage() *schema.Resource {
+	return &schema.Resource{
+		Create:        resourceVSphereComputeClusterImageCreate,
+		Read:          resourceVSphereComputeClusterImageRead,
+		Update:        resourceVSphereComputeClusterImageUpdate,
+		Delete:        resourceVSphereComputeClusterImageDelete,
+		CustomizeDiff: resourceVSphereComputeClusterImageCustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"compute_cluster_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the cluster. The cluster must be managed with a single image.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"base_image_version": {
+				Type:        schema.TypeString,
+				Description: "The version of the ESXi base image to set as the desired base image of the cluster.",
+				Required:    true,
+			},
+			"add_on": {
+				Type:        schema.TypeList,
+				Description: "The vendor add-on to include in the desired image of the cluster.",
+				Optional:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Description: "The name of the vendor add-on.",
+							Required:    true,
+						},
+						"version": {
+							Type:        schema.TypeString,
+							Description: "The version of the vendor add-on.",
+							Required:    true,
+						},
+					},
+				},
+			},
+			"components": {
+				Type:        schema.TypeMap,
+				Description: "Additional components to include in the desired image of the cluster, keyed by component name with the component version as the value.",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"accept_eula": {
+				Type:        schema.TypeBool,
+				Description: "Accept the end user license agreement of the software when remediating the hosts in the cluster.",
+				Optional:    true,
+				Default:     false,
+			},
+			"remediation_timeout": {
+				Type:         schema.TypeInt,
+				Description:  "The time, in seconds, to wait for the hosts in the cluster to be remediated or scanned for compliance.",
+				Optional:     true,
+				Default:      3600,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"scan_on_refresh": {
+				Type:        schema.TypeBool,
+				Description: "Scan the hosts in the cluster for compliance on every refresh. When false, the compliance attributes report the result of the last scan, which is only run after an apply.",
+				Optional:    true,
+				Default:     false,
+			},
+			"compliance_status": {
+				Type:        schema.TypeString,
+				Description: "The compliance status of the cluster against its desired image, as of the last compliance scan.",
+				Computed:    true,
+			},
+			"host_compliance": {
+				Type:        schema.TypeMap,
+				Description: "The compliance status of each host in the cluster against the desired image, keyed by host managed object ID, as of the last compliance scan.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func resourceVSphereComputeClusterImageCreate(d *schema.ResourceData, meta interface{}) error {
+	client, err := computeClusterImageClient(meta)
+	if err != nil {
+		return err
+	}
+	clusterID := d.Get("compute_cluster_id").(string)
+	if _, err := clustercomputeresource.FromID(meta.(*VSphereClient).vimClient, clusterID); err != nil {
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+
+	if err := esxsettings.ApplySoftware(
+		client,
+		clusterID,
+		expandComputeClusterImageSoftware(d),
+		nil,
+		d.Get("accept_eula").(bool),
+		computeClusterImageRemediationTimeout(d),
+	); err != nil {
+		return fmt.Errorf("error applying image to cluster %q: %s", clusterID, err)
+	}
+	d.SetId(clusterID)
+
+	if err := esxsettings.Scan(client, clusterID, computeClusterImageRemediationTimeout(d)); err != nil {
+		return fmt.Errorf("error checking compliance of cluster %q: %s", clusterID, err)
+	}
+	return computeClusterImageRead(d, meta, false)
+}
+
+func resourceVSphereComputeClusterImageRead(d *schema.ResourceData, meta interface{}) error {
+	return computeClusterImageRead(d, meta, d.Get("scan_on_refresh").(bool))
+}
+
+// computeClusterImageRead reads the image and compliance of a cluster. If scan
+// is true, the hosts in the cluster are scanned for compliance first.
+// Otherwise, the result of the last scan is read. Create and Update scan after
+// an apply themselves, so they never ask for a second scan here.
+func computeClusterImageRead(d *schema.ResourceData, meta interface{}, scan bool) error {
+	client, err := computeClusterImageClient(meta)
+	if err != nil {
+		return err
+	}
+	if _, err := clustercomputeresource.FromID(meta.(*VSphereClient).vimClient, d.Id()); err != nil {
+		if viapi.IsManagedObjectNotFoundError(err) {
+			log.Printf("[DEBUG] Cluster %q not found, removing image from state", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+
+	sw, err := esxsettings.SoftwareFromCluster(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("error reading image of cluster %q: %s", d.Id(), err)
+	}
+	if err := flattenComputeClusterImageSoftware(d, sw); err != nil {
+		return err
+	}
+
+	// Scanning is a task that can take a long time, so unless scan_on_refresh
+	// is set, only the result of the last scan is read and it can be stale.
+	if scan {
+		if err := esxsettings.Scan(client, d.Id(), computeClusterImageRemediationTimeout(d)); err != nil {
+			return fmt.Errorf("error checking compliance of cluster %q: %s", d.Id(), err)
+		}
+	}
+	compliance, err := esxsettings.ComplianceFromCluster(client, d.Id())
+	if err != nil {
+		if !esxsettings.IsNotFoundError(err) {
+			return fmt.Errorf("error reading compliance of cluster %q: %s", d.Id(), err)
+		}
+		log.Printf("[DEBUG] Cluster %q has not been scanned for compliance yet", d.Id())
+		compliance = &esxsettings.Compliance{}
+	}
+	d.Set("compliance_status", compliance.Status)
+	hosts := make(map[string]interface{})
+	for id, host := range compliance.Hosts {
+		hosts[id] = host.Status
+	}
+	if err := d.Set("host_compliance", hosts); err != nil {
+		return fmt.Errorf("error setting host_compliance: %s", err)
+	}
+	return nil
+}
+
+func resourceVSphereComputeClusterImageUpdate(d *schema.ResourceData, meta interface{}) error {
+	client, err := computeClusterImageClient(meta)
+	if err != nil {
+		return err
+	}
+	acceptEULA := d.Get("accept_eula").(bool)
+	timeout := computeClusterImageRemediationTimeout(d)
+
+	if d.HasChange("base_image_version") || d.HasChange("add_on") || d.HasChange("components") {
+		// Components that are no longer in the configuration are removed from
+		// the image. Components that were never managed by this resource are
+		// left alone.
+		var remove []string
+		o, n := d.GetChange("components")
+		for name := range o.(map[string]interface{}) {
+			if _, ok := n.(map[string]interface{})[name]; !ok {
+				remove = append(remove, name)
+			}
+		}
+		if err := esxsettings.ApplySoftware(client, d.Id(), expandComputeClusterImageSoftware(d), remove, acceptEULA, timeout); err != nil {
+			return fmt.Errorf("error applying image to cluster %q: %s", d.Id(), err)
+		}
+	} else if d.Get("compliance_status").(string) != esxsettings.ComplianceStatusCompliant {
+		if err := esxsettings.Remediate(client, d.Id(), acceptEULA, timeout); err != nil {
+			return fmt.Errorf("error remediating cluster %q: %s", d.Id(), err)
+		}
+	}
+
+	if err := esxsettings.Scan(client, d.Id(), timeout); err != nil {
+		return fmt.Errorf("error checking compliance of cluster %q: %s", d.Id(), err)
+	}
+	return computeClusterImageRead(d, meta, false)
+}
+
+func resourceVSphereComputeClusterImageDelete(d *schema.ResourceData, meta interface{}) error {
+	// A cluster that is managed with an image cannot go back to being
+	// unmanaged, and there is no prior image to go back to, so the image is
+	// left on the cluster and the resource is only removed from state.
+	log.Printf("[DEBUG] Removing image of cluster %q from state, the image is left on the cluster", d.Id())
+	return nil
+}
+
+func resourceVSphereComputeClusterImageCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" {
+		return nil
+	}
+	// Hosts that have drifted from the desired image are remediated on the
+	// next apply. Marking the compliance attributes as computed is what puts
+	// the resource in the plan when the configuration itself has not changed.
+	status := d.Get("compliance_status").(string)
+	if status == "" || status == esxsettings.ComplianceStatusCompliant {
+		return nil
+	}
+	log.Printf("[DEBUG] Cluster %q has compliance status %q, planning remediation", d.Id(), status)
+	if err := d.SetNewComputed("compliance_status"); err != nil {
+		return err
+	}
+	return d.SetNewComputed("host_compliance")
+}
+
+// computeClusterImageClient returns the REST client used to manage the image
+// of a cluster, which requires vCenter.
+func computeClusterImageClient(meta interface{}) (*rest.Client, error) {
+	client := meta.(*VSphereClient)
+	if err := viapi.ValidateVirtualCenter(client.vimClient); err != nil {
+		return nil, err
+	}
+	if client.restClient == nil {
+		return nil, errors.New("cluster images require a vCenter connection")
+	}
+	return client.restClient, nil
+}
+
+// computeClusterImageRemediationTimeout returns the remediation_timeout
+// attribute as a time.Duration.
+func computeClusterImageRemediationTimeout(d *schema.ResourceData) time.Duration {
+	return time.Duration(d.Get("remediation_timeout").(int)) * time.Second
+}
+
+// expandComputeClusterImageSoftware reads the desired image of the cluster
+// from the resource data.
+func expandComputeClusterImageSoftware(d *schema.ResourceData) *esxsettings.Software {
+	sw := &esxsettings.Software{
+		BaseImage:  d.Get("base_image_version").(string),
+		Components: make(map[string]string),
+	}
+	if addOns := d.Get("add_on").([]interface{}); len(addOns) > 0 && addOns[0] != nil {
+		addOn := addOns[0].(map[string]interface{})
+		sw.AddOn = &esxsettings.AddOn{
+			Name:    addOn["name"].(string),
+			Version: addOn["version"].(string),
+		}
+	}
+	for name, version := range d.Get("components").(map[string]interface{}) {
+		sw.Components[name] = version.(string)
+	}
+	return sw
+}
+
+// flattenComputeClusterImageSoftware saves the desired image of the cluster to
+// the resource data. The image also reports the components that are part of
+// the base image and add-on, so only the components that are in the
+// configuration are saved.
+func flattenComputeClusterImageSoftware(d *schema.ResourceData, sw *esxsettings.Software) error {
+	d.Set("compute_cluster_id", d.Id())
+	d.Set("base_image_version", sw.BaseImage)
+
+	var addOns []interface{}
+	if sw.AddOn != nil {
+		addOns = append(addOns, map[string]interface{}{
+			"name":    sw.AddOn.Name,
+			"version": sw.AddOn.Version,
+		})
+	}
+	if err := d.Set("add_on", addOns); err != nil {
+		return fmt.Errorf("error setting add_on: %s", err)
+	}
+
+	components := make(map[string]interface{})
+	for name := range d.Get("components").(map[string]interface{}) {
+		if version, ok := sw.Components[name]; ok {
+			components[name] = version
+		}
+	}
+	if err := d.Set("components", components); err != nil {
+		return fmt.Errorf("error setting components: %s", err)
+	}
+	return nil
+}
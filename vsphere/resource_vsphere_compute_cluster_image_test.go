@@ -0,0 +1,94 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccResourceVSphereComputeClusterImage_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccSkipIfEsxi(t)
+			testAccResourceVSphereComputeClusterImagePreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereComputeClusterImageConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(
+						"vsphere_compute_cluster_image.image", "id",
+						"data.vsphere_compute_cluster.compute_cluster", "id",
+					),
+					resource.TestCheckResourceAttr(
+						"vsphere_compute_cluster_image.image",
+						"base_image_version",
+						os.Getenv("VSPHERE_ESXI_BASE_IMAGE_VERSION"),
+					),
+					resource.TestCheckResourceAttr("vsphere_compute_cluster_image.image", "compliance_status", "COMPLIANT"),
+				),
+			},
+			{
+				ResourceName:      "vsphere_compute_cluster_image.image",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateVerifyIgnore: []string{
+					"accept_eula",
+					"remediation_timeout",
+					"scan_on_refresh",
+				},
+			},
+		},
+	})
+}
+
+func testAccResourceVSphereComputeClusterImagePreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_DATACENTER") == "" {
+		t.Skip("set VSPHERE_DATACENTER to run vsphere_compute_cluster_image acceptance tests")
+	}
+	if os.Getenv("VSPHERE_IMAGE_CLUSTER") == "" {
+		t.Skip("set VSPHERE_IMAGE_CLUSTER to run vsphere_compute_cluster_image acceptance tests")
+	}
+	if os.Getenv("VSPHERE_ESXI_BASE_IMAGE_VERSION") == "" {
+		t.Skip("set VSPHERE_ESXI_BASE_IMAGE_VERSION to run vsphere_compute_cluster_image acceptance tests")
+	}
+}
+
+func testAccResourceVSphereComputeClusterImageConfig() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster" {
+  default = "%s"
+}
+
+variable "base_image_version" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_compute_cluster" "compute_cluster" {
+  name          = "${var.cluster}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_compute_cluster_image" "image" {
+  compute_cluster_id = "${data.vsphere_compute_cluster.compute_cluster.id}"
+  base_image_version = "${var.base_image_version}"
+  accept_eula        = true
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_IMAGE_CLUSTER"),
+		os.Getenv("VSPHERE_ESXI_BASE_IMAGE_VERSION"),
+	)
+}
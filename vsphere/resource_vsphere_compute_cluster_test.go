@@ -98,6 +98,59 @@ func TestAccResourceVSphereComputeCluster_drsHAEnabled(t *testing.T) {
 	})
 }
 
+func TestAccResourceVSphereComputeCluster_vsanDiskGroup(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereComputeClusterPreCheck(t)
+			testAccResourceVSphereComputeClusterVsanPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereComputeClusterCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereComputeClusterConfigVsanDiskGroup(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereComputeClusterCheckExists(true),
+					testAccResourceVSphereComputeClusterCheckVsanEnabled(true),
+					resource.TestCheckResourceAttr("vsphere_compute_cluster.compute_cluster", "vsan_disk_group.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereComputeCluster_vsanDataServices(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereComputeClusterPreCheck(t)
+			testAccResourceVSphereComputeClusterVsanPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereComputeClusterCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereComputeClusterConfigVsanDiskGroup(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereComputeClusterCheckExists(true),
+					resource.TestCheckResourceAttr("vsphere_compute_cluster.compute_cluster", "vsan_dedup_enabled", "false"),
+					resource.TestCheckResourceAttr("vsphere_compute_cluster.compute_cluster", "vsan_compression_enabled", "false"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereComputeClusterConfigVsanDataServices(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereComputeClusterCheckExists(true),
+					testAccResourceVSphereComputeClusterCheckVsanEnabled(true),
+					resource.TestCheckResourceAttr("vsphere_compute_cluster.compute_cluster", "vsan_dedup_enabled", "true"),
+					resource.TestCheckResourceAttr("vsphere_compute_cluster.compute_cluster", "vsan_compression_enabled", "true"),
+				),
+			},
+		},
+	})
+}
+
 func TestAccResourceVSphereComputeCluster_explicitFailoverHost(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
@@ -369,6 +422,15 @@ func testAccResourceVSphereComputeClusterPreCheck(t *testing.T) {
 	}
 }
 
+func testAccResourceVSphereComputeClusterVsanPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_VSAN_CACHE_DISK") == "" {
+		t.Skip("set VSPHERE_VSAN_CACHE_DISK to run vsphere_compute_cluster vSAN acceptance tests")
+	}
+	if os.Getenv("VSPHERE_VSAN_STORAGE_DISK") == "" {
+		t.Skip("set VSPHERE_VSAN_STORAGE_DISK to run vsphere_compute_cluster vSAN acceptance tests")
+	}
+}
+
 func testAccResourceVSphereComputeClusterCheckExists(expected bool) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		_, err := testGetComputeCluster(s, "compute_cluster")
@@ -414,6 +476,21 @@ func testAccResourceVSphereComputeClusterCheckHAEnabled(expected bool) resource.
 	}
 }
 
+func testAccResourceVSphereComputeClusterCheckVsanEnabled(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetComputeClusterProperties(s, "compute_cluster")
+		if err != nil {
+			return err
+		}
+		vsanConfig := props.ConfigurationEx.(*types.ClusterConfigInfoEx).VsanConfigInfo
+		actual := vsanConfig != nil && vsanConfig.Enabled != nil && *vsanConfig.Enabled
+		if expected != actual {
+			return fmt.Errorf("expected vSAN enabled to be %t, got %t", expected, actual)
+		}
+		return nil
+	}
+}
+
 func testAccResourceVSphereComputeClusterCheckAdmissionControlMode(expected string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		props, err := testGetComputeClusterProperties(s, "compute_cluster")
@@ -645,6 +722,102 @@ resource "vsphere_compute_cluster" "compute_cluster" {
 	)
 }
 
+func testAccResourceVSphereComputeClusterConfigVsanDiskGroup() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "hosts" {
+  default = [
+    "%s",
+    "%s",
+  ]
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_host" "hosts" {
+  count         = "${length(var.hosts)}"
+  name          = "${var.hosts[count.index]}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_compute_cluster" "compute_cluster" {
+  name            = "terraform-compute-cluster-test"
+  datacenter_id   = "${data.vsphere_datacenter.dc.id}"
+  host_system_ids = "${data.vsphere_host.hosts.*.id}"
+
+  vsan_enabled = true
+
+  vsan_disk_group {
+    host_system_id = "${data.vsphere_host.hosts.0.id}"
+    cache          = "%s"
+    storage        = ["%s"]
+  }
+
+  force_evacuate_on_destroy = true
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_ESXI_HOST4"),
+		os.Getenv("VSPHERE_ESXI_HOST5"),
+		os.Getenv("VSPHERE_VSAN_CACHE_DISK"),
+		os.Getenv("VSPHERE_VSAN_STORAGE_DISK"),
+	)
+}
+
+func testAccResourceVSphereComputeClusterConfigVsanDataServices() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "hosts" {
+  default = [
+    "%s",
+    "%s",
+  ]
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_host" "hosts" {
+  count         = "${length(var.hosts)}"
+  name          = "${var.hosts[count.index]}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_compute_cluster" "compute_cluster" {
+  name            = "terraform-compute-cluster-test"
+  datacenter_id   = "${data.vsphere_datacenter.dc.id}"
+  host_system_ids = "${data.vsphere_host.hosts.*.id}"
+
+  vsan_enabled             = true
+  vsan_dedup_enabled       = true
+  vsan_compression_enabled = true
+
+  vsan_disk_group {
+    host_system_id = "${data.vsphere_host.hosts.0.id}"
+    cache          = "%s"
+    storage        = ["%s"]
+  }
+
+  force_evacuate_on_destroy = true
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_ESXI_HOST4"),
+		os.Getenv("VSPHERE_ESXI_HOST5"),
+		os.Getenv("VSPHERE_VSAN_CACHE_DISK"),
+		os.Getenv("VSPHERE_VSAN_STORAGE_DISK"),
+	)
+}
+
 func testAccResourceVSphereComputeClusterConfigDRSHABasic() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
@@ -86,7 +86,10 @@ func resourceVSphereComputePolicyCreate(d *schema.ResourceData, meta interface{}
 	fields["capability"] = data.NewStringValue(capabilityFullName)
 	var createSpec = data.NewStructValue("", fields)
 
-	connector := meta.(*VSphereClient).vApiConnector
+	connector, err := meta.(*VSphereClient).VApiConnector()
+	if err != nil {
+		return err
+	}
 	policyClient := compute.NewDefaultPoliciesClient(connector)
 	result, err := policyClient.Create(createSpec)
 	if err != nil {
@@ -101,7 +104,10 @@ func resourceVSphereComputePolicyCreate(d *schema.ResourceData, meta interface{}
 func resourceVSphereComputePolicyRead(d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[DEBUG] %s: Beginning read", resourceVSphereComputePolicyIDString(d))
 
-	connector := meta.(*VSphereClient).vApiConnector
+	connector, err := meta.(*VSphereClient).VApiConnector()
+	if err != nil {
+		return err
+	}
 	policyClient := compute.NewDefaultPoliciesClient(connector)
 	summaryStruct, err := policyClient.Get(d.Id())
 	if err != nil {
@@ -133,7 +139,10 @@ func resourceVSphereComputePolicyRead(d *schema.ResourceData, meta interface{})
 }
 
 func resourceVSphereComputePolicyDelete(d *schema.ResourceData, meta interface{}) error {
-	connector := meta.(*VSphereClient).vApiConnector
+	connector, err := meta.(*VSphereClient).VApiConnector()
+	if err != nil {
+		return err
+	}
 	policyClient := compute.NewDefaultPoliciesClient(connector)
 	if err := policyClient.Delete(d.Id()); err != nil {
 		return err
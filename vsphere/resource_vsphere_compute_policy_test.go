@@ -61,7 +61,10 @@ func TestAccResourceVSphereComputePolicy_basic(t *testing.T) {
 }
 
 func testAccCheckVSphereComputePolicyDestroy(s *terraform.State) error {
-	connector := testAccProvider.Meta().(*VSphereClient).vApiConnector
+	connector, err := testAccProvider.Meta().(*VSphereClient).VApiConnector()
+	if err != nil {
+		return err
+	}
 	policyClient := compute.NewDefaultPoliciesClient(connector)
 
 	for _, rs := range s.RootModule().Resources {
@@ -92,9 +95,12 @@ func testAccCheckVSphereComputePolicyExists(n string) resource.TestCheckFunc {
 			return fmt.Errorf("no ID is set")
 		}
 
-		connector := testAccProvider.Meta().(*VSphereClient).vApiConnector
+		connector, err := testAccProvider.Meta().(*VSphereClient).VApiConnector()
+		if err != nil {
+			return err
+		}
 		policyClient := compute.NewDefaultPoliciesClient(connector)
-		_, err := policyClient.Get(rs.Primary.ID)
+		_, err = policyClient.Get(rs.Primary.ID)
 
 		if err != nil {
 			if err.Error() == (errors.NotFound{}.Error()) {
@@ -0,0 +1,253 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/contentlibrary"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/vmware/govmomi/vapi/rest"
+)
+
+const (
+	// contentLibraryAuthenticationMethodNone defines the API value for a
+	// subscription without authentication.
+	contentLibraryAuthenticationMethodNone = "NONE"
+
+	// contentLibraryAuthenticationMethodBasic defines the API value for a
+	// subscription that uses basic authentication.
+	contentLibraryAuthenticationMethodBasic = "BASIC"
+)
+
+func resourceVSphereContentLibrary() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereContentLibraryCreate,
+		Read:   resourceVSphereContentLibraryRead,
+		Delete: resourceVSphereContentLibraryDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereContentLibraryImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name of the content library.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Description: "The description of the content library.",
+				Optional:    true,
+				ForceNew:    true,
+			},
+			"storage_backing": {
+				Type:        schema.TypeSet,
+				Description: "The IDs of the datastores that back the content library.",
+				Required:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"subscription": {
+				Type:        schema.TypeList,
+				Description: "The subscription settings of the content library. When set, the library is subscribed to a published library.",
+				Optional:    true,
+				ForceNew:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"subscription_url": {
+							Type:        schema.TypeString,
+							Description: "The URL of the published library to subscribe to.",
+							Required:    true,
+							ForceNew:    true,
+						},
+						"authentication_method": {
+							Type:        schema.TypeString,
+							Description: "The authentication method used to connect to the published library. Can be one of NONE or BASIC.",
+							Optional:    true,
+							ForceNew:    true,
+							Default:     contentLibraryAuthenticationMethodNone,
+							ValidateFunc: validation.StringInSlice(
+								[]string{
+									contentLibraryAuthenticationMethodNone,
+									contentLibraryAuthenticationMethodBasic,
+								},
+								false,
+							),
+						},
+						"username": {
+							Type:        schema.TypeString,
+							Description: "The username used to authenticate to the published library.",
+							Optional:    true,
+							ForceNew:    true,
+						},
+						"password": {
+							Type:        schema.TypeString,
+							Description: "The password used to authenticate to the published library.",
+							Optional:    true,
+							ForceNew:    true,
+							Sensitive:   true,
+						},
+						"automatic_sync": {
+							Type:        schema.TypeBool,
+							Description: "Automatically synchronize the library with the published library.",
+							Optional:    true,
+							ForceNew:    true,
+							Default:     false,
+						},
+						"on_demand": {
+							Type:        schema.TypeBool,
+							Description: "Download the content of library items only when they are used.",
+							Optional:    true,
+							ForceNew:    true,
+							Default:     true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func resourceVSphereContentLibraryCreate(d *schema.ResourceData, meta interface{}) error {
+	rc, err := contentLibraryClient(meta)
+	if err != nil {
+		return err
+	}
+	id, err := contentlibrary.Create(
+		rc,
+		d.Get("name").(string),
+		d.Get("description").(string),
+		structure.SliceInterfacesToStrings(d.Get("storage_backing").(*schema.Set).List()),
+		expandContentLibrarySubscription(d),
+	)
+	if err != nil {
+		return fmt.Errorf("could not create content library: %s", err)
+	}
+	if id == "" {
+		return errors.New("no ID was returned")
+	}
+	d.SetId(id)
+	return resourceVSphereContentLibraryRead(d, meta)
+}
+
+func resourceVSphereContentLibraryRead(d *schema.ResourceData, meta interface{}) error {
+	rc, err := contentLibraryClient(meta)
+	if err != nil {
+		return err
+	}
+	id := d.Id()
+	lib, err := contentlibrary.FromID(rc, id)
+	if err != nil {
+		if contentlibrary.IsNotFoundError(err) {
+			log.Printf("[DEBUG] Content library %s: Resource has been deleted", id)
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+	d.Set("name", lib.Name)
+	d.Set("description", lib.Description)
+
+	var datastoreIDs []string
+	for _, backing := range lib.Storage {
+		datastoreIDs = append(datastoreIDs, backing.DatastoreID)
+	}
+	if err := d.Set("storage_backing", datastoreIDs); err != nil {
+		return fmt.Errorf("could not set storage backing data for content library: %s", err)
+	}
+
+	if lib.Type != contentlibrary.LibraryTypeSubscribed {
+		return d.Set("subscription", nil)
+	}
+	subscription, err := contentlibrary.SubscriptionFromID(rc, id)
+	if err != nil {
+		return fmt.Errorf("could not read subscription for content library: %s", err)
+	}
+	if err := d.Set("subscription", flattenContentLibrarySubscription(d, subscription)); err != nil {
+		return fmt.Errorf("could not set subscription data for content library: %s", err)
+	}
+	return nil
+}
+
+func resourceVSphereContentLibraryDelete(d *schema.ResourceData, meta interface{}) error {
+	rc, err := contentLibraryClient(meta)
+	if err != nil {
+		return err
+	}
+	id := d.Id()
+	lib, err := contentlibrary.FromID(rc, id)
+	if err != nil {
+		return err
+	}
+	if err := contentlibrary.Delete(rc, lib); err != nil {
+		return fmt.Errorf("could not delete content library with id %q: %s", id, err)
+	}
+	return nil
+}
+
+func resourceVSphereContentLibraryImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	rc, err := contentLibraryClient(meta)
+	if err != nil {
+		return nil, err
+	}
+	lib, err := contentlibrary.FromName(rc, d.Id())
+	if err != nil {
+		return nil, err
+	}
+	d.SetId(lib.ID)
+	return []*schema.ResourceData{d}, nil
+}
+
+// contentLibraryClient returns the REST client used to manage content
+// libraries, or an error if it is unavailable on the current connection.
+func contentLibraryClient(meta interface{}) (*rest.Client, error) {
+	client := meta.(*VSphereClient)
+	if client.restClient == nil {
+		return nil, errors.New("content libraries require a vCenter connection")
+	}
+	return client.restClient, nil
+}
+
+// expandContentLibrarySubscription reads the subscription block from the
+// resource data and returns the subscription settings for the library. nil is
+// returned if the block is not set.
+func expandContentLibrarySubscription(d *schema.ResourceData) *contentlibrary.Subscription {
+	subscriptions := d.Get("subscription").([]interface{})
+	if len(subscriptions) < 1 || subscriptions[0] == nil {
+		return nil
+	}
+	s := subscriptions[0].(map[string]interface{})
+	return &contentlibrary.Subscription{
+		SubscriptionURL:      s["subscription_url"].(string),
+		AuthenticationMethod: s["authentication_method"].(string),
+		UserName:             s["username"].(string),
+		Password:             s["password"].(string),
+		AutomaticSyncEnabled: s["automatic_sync"].(bool),
+		OnDemand:             s["on_demand"].(bool),
+	}
+}
+
+// flattenContentLibrarySubscription returns the subscription settings of a
+// library in a form suitable for saving to the subscription block. The
+// password is never returned by the API, so it is carried over from the
+// current state.
+func flattenContentLibrarySubscription(d *schema.ResourceData, s *contentlibrary.Subscription) []interface{} {
+	if s == nil {
+		return nil
+	}
+	return []interface{}{
+		map[string]interface{}{
+			"subscription_url":      s.SubscriptionURL,
+			"authentication_method": s.AuthenticationMethod,
+			"username":              s.UserName,
+			"password":              d.Get("subscription.0.password").(string),
+			"automatic_sync":        s.AutomaticSyncEnabled,
+			"on_demand":             s.OnDemand,
+		},
+	}
+}
@@ -0,0 +1,154 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/contentlibrary"
+)
+
+func resourceVSphereContentLibraryItem() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereContentLibraryItemCreate,
+		Read:   resourceVSphereContentLibraryItemRead,
+		Update: resourceVSphereContentLibraryItemUpdate,
+		Delete: resourceVSphereContentLibraryItemDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereContentLibraryItemImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name of the content library item.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"library_id": {
+				Type:        schema.TypeString,
+				Description: "The ID of the content library to create the item in.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Description: "The description of the content library item.",
+				Optional:    true,
+				ForceNew:    true,
+			},
+			"file_url": {
+				Type:        schema.TypeString,
+				Description: "The local path or HTTP(S) URL of the OVF, OVA, or ISO file to upload to the item.",
+				Required:    true,
+				ForceNew:    true,
+				// The file an item was created from cannot be read back, so it is
+				// empty after import. The configured value is taken on as it is
+				// instead of replacing the item.
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					return old == "" && d.Id() != ""
+				},
+			},
+			"type": {
+				Type:        schema.TypeString,
+				Description: "The type of the content library item. Can be one of ovf or iso. If not set, the type is inferred from the extension of file_url.",
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				ValidateFunc: validation.StringInSlice(
+					[]string{
+						contentlibrary.ItemTypeOvf,
+						contentlibrary.ItemTypeIso,
+					},
+					false,
+				),
+			},
+			"upload_timeout": {
+				Type:         schema.TypeInt,
+				Description:  "The amount of time, in minutes, to wait for the file to be uploaded to the item.",
+				Optional:     true,
+				Default:      30,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+		},
+	}
+}
+
+func resourceVSphereContentLibraryItemCreate(d *schema.ResourceData, meta interface{}) error {
+	rc, err := contentLibraryClient(meta)
+	if err != nil {
+		return err
+	}
+	file := d.Get("file_url").(string)
+	itemType := d.Get("type").(string)
+	if itemType == "" {
+		if itemType, err = contentlibrary.ItemTypeFromPath(file); err != nil {
+			return err
+		}
+	}
+	id, err := contentlibrary.CreateItem(
+		rc,
+		d.Get("library_id").(string),
+		d.Get("name").(string),
+		d.Get("description").(string),
+		itemType,
+		file,
+		time.Duration(d.Get("upload_timeout").(int))*time.Minute,
+	)
+	if err != nil {
+		return fmt.Errorf("could not create content library item: %s", err)
+	}
+	if id == "" {
+		return errors.New("no ID was returned")
+	}
+	d.SetId(id)
+	return resourceVSphereContentLibraryItemRead(d, meta)
+}
+
+func resourceVSphereContentLibraryItemRead(d *schema.ResourceData, meta interface{}) error {
+	rc, err := contentLibraryClient(meta)
+	if err != nil {
+		return err
+	}
+	id := d.Id()
+	item, err := contentlibrary.ItemFromID(rc, id)
+	if err != nil {
+		if contentlibrary.IsNotFoundError(err) {
+			log.Printf("[DEBUG] Content library item %s: Resource has been deleted", id)
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+	d.Set("name", item.Name)
+	d.Set("library_id", item.LibraryID)
+	d.Set("description", item.Description)
+	d.Set("type", item.Type)
+	return nil
+}
+
+func resourceVSphereContentLibraryItemUpdate(d *schema.ResourceData, meta interface{}) error {
+	// Only upload_timeout can be changed without replacing the item, and it is
+	// only used on create.
+	return resourceVSphereContentLibraryItemRead(d, meta)
+}
+
+func resourceVSphereContentLibraryItemDelete(d *schema.ResourceData, meta interface{}) error {
+	rc, err := contentLibraryClient(meta)
+	if err != nil {
+		return err
+	}
+	id := d.Id()
+	if err := contentlibrary.DeleteItem(rc, id); err != nil {
+		return fmt.Errorf("could not delete content library item with id %q: %s", id, err)
+	}
+	return nil
+}
+
+func resourceVSphereContentLibraryItemImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	d.Set("upload_timeout", resourceVSphereContentLibraryItem().Schema["upload_timeout"].Default)
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,129 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/contentlibrary"
+)
+
+func TestAccResourceVSphereContentLibraryItem_ovf(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccSkipIfEsxi(t)
+			testAccCheckEnvVariables(t, []string{"VSPHERE_OVF_URL"})
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereContentLibraryItemExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereContentLibraryItemConfig(os.Getenv("VSPHERE_OVF_URL")),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereContentLibraryItemExists(true),
+					resource.TestCheckResourceAttr("vsphere_content_library_item.item", "type", "ovf"),
+					resource.TestCheckResourceAttrPair(
+						"vsphere_content_library_item.item", "library_id",
+						"vsphere_content_library.library", "id",
+					),
+				),
+			},
+			{
+				ResourceName:      "vsphere_content_library_item.item",
+				ImportState:       true,
+				ImportStateVerify: true,
+				// The file an item was created from is not stored on the item.
+				ImportStateVerifyIgnore: []string{"file_url"},
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereContentLibraryItem_iso(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccSkipIfEsxi(t)
+			testAccCheckEnvVariables(t, []string{"VSPHERE_ISO_URL"})
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereContentLibraryItemExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereContentLibraryItemConfig(os.Getenv("VSPHERE_ISO_URL")),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereContentLibraryItemExists(true),
+					resource.TestCheckResourceAttr("vsphere_content_library_item.item", "type", "iso"),
+				),
+			},
+		},
+	})
+}
+
+func testAccResourceVSphereContentLibraryItemExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		tVars, err := testClientVariablesForResource(s, "vsphere_content_library_item.item")
+		if err != nil {
+			if !expected {
+				// The resource is gone from state along with the library.
+				return nil
+			}
+			return err
+		}
+		_, err = contentlibrary.ItemFromID(testAccProvider.Meta().(*VSphereClient).restClient, tVars.resourceID)
+		switch {
+		case err != nil && contentlibrary.IsNotFoundError(err) && !expected:
+			// Expected missing
+			return nil
+		case err != nil:
+			return err
+		case !expected:
+			return fmt.Errorf("expected content library item %q to be missing", tVars.resourceID)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereContentLibraryItemConfig(file string) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "file_url" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_content_library" "library" {
+  name            = "terraform-test-library"
+  storage_backing = ["${data.vsphere_datastore.datastore.id}"]
+}
+
+resource "vsphere_content_library_item" "item" {
+  name        = "terraform-test-item"
+  description = "Managed by Terraform"
+  library_id  = "${vsphere_content_library.library.id}"
+  file_url    = "${var.file_url}"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		file,
+	)
+}
@@ -0,0 +1,168 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/contentlibrary"
+	"github.com/vmware/govmomi/vapi/library"
+)
+
+func TestAccResourceVSphereContentLibrary_basic(t *testing.T) {
+	testAccResourceTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccSkipIfEsxi(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereContentLibraryExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereContentLibraryConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereContentLibraryExists(true),
+					resource.TestCheckResourceAttr("vsphere_content_library.library", "storage_backing.#", "1"),
+					resource.TestCheckResourceAttr("vsphere_content_library.library", "subscription.#", "0"),
+				),
+			},
+			{
+				ResourceName:      "vsphere_content_library.library",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					return "terraform-test-library", nil
+				},
+				Config: testAccResourceVSphereContentLibraryConfig(),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereContentLibrary_subscribed(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccSkipIfEsxi(t)
+			testAccCheckEnvVariables(t, []string{"VSPHERE_CONTENT_LIBRARY_SUBSCRIPTION_URL"})
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereContentLibraryExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereContentLibraryConfigSubscribed(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereContentLibraryExists(true),
+					resource.TestCheckResourceAttr("vsphere_content_library.library", "subscription.#", "1"),
+					resource.TestCheckResourceAttr(
+						"vsphere_content_library.library",
+						"subscription.0.subscription_url",
+						os.Getenv("VSPHERE_CONTENT_LIBRARY_SUBSCRIPTION_URL"),
+					),
+				),
+			},
+		},
+	})
+}
+
+func testAccResourceVSphereContentLibraryExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		lib, err := testGetContentLibrary(s, "library")
+		if err != nil {
+			if contentlibrary.IsNotFoundError(err) && !expected {
+				// Expected missing
+				return nil
+			}
+			return err
+		}
+		if !expected {
+			return fmt.Errorf("expected content library %q to be missing", lib.ID)
+		}
+		if lib == nil {
+			return errors.New("content library not found")
+		}
+		return nil
+	}
+}
+
+// testGetContentLibrary gets the content library for the
+// vsphere_content_library resource with the supplied name.
+func testGetContentLibrary(s *terraform.State, resourceName string) (*library.Library, error) {
+	tVars, err := testClientVariablesForResource(s, fmt.Sprintf("vsphere_content_library.%s", resourceName))
+	if err != nil {
+		return nil, err
+	}
+	return contentlibrary.FromID(testAccProvider.Meta().(*VSphereClient).restClient, tVars.resourceID)
+}
+
+func testAccResourceVSphereContentLibraryConfig() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_content_library" "library" {
+  name            = "terraform-test-library"
+  description     = "Managed by Terraform"
+  storage_backing = ["${data.vsphere_datastore.datastore.id}"]
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_DATASTORE"),
+	)
+}
+
+func testAccResourceVSphereContentLibraryConfigSubscribed() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "subscription_url" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_content_library" "library" {
+  name            = "terraform-test-library"
+  storage_backing = ["${data.vsphere_datastore.datastore.id}"]
+
+  subscription {
+    subscription_url = "${var.subscription_url}"
+    on_demand        = true
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_CONTENT_LIBRARY_SUBSCRIPTION_URL"),
+	)
+}
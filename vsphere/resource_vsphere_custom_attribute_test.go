@@ -10,7 +10,7 @@ import (
 )
 
 func TestAccResourceVSphereCustomAttribute_basic(t *testing.T) {
-	resource.Test(t, resource.TestCase{
+	testAccResourceTest(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 		},
@@ -48,7 +48,7 @@ func TestAccResourceVSphereCustomAttribute_basic(t *testing.T) {
 	})
 }
 func TestAccResourceVSphereCustomAttribute_withType(t *testing.T) {
-	resource.Test(t, resource.TestCase{
+	testAccResourceTest(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 		},
@@ -67,7 +67,7 @@ func TestAccResourceVSphereCustomAttribute_withType(t *testing.T) {
 	})
 }
 func TestAccResourceVSphereCustomAttribute_rename(t *testing.T) {
-	resource.Test(t, resource.TestCase{
+	testAccResourceTest(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 		},
@@ -92,7 +92,7 @@ func TestAccResourceVSphereCustomAttribute_rename(t *testing.T) {
 }
 
 func TestAccResourceVSphereCustomAttribute_changeType(t *testing.T) {
-	resource.Test(t, resource.TestCase{
+	testAccResourceTest(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 		},
@@ -0,0 +1,210 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const resourceVSphereDistributedNetworkResourcePoolName = "vsphere_distributed_network_resource_pool"
+
+func resourceVSphereDistributedNetworkResourcePool() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereDistributedNetworkResourcePoolCreate,
+		Read:   resourceVSphereDistributedNetworkResourcePoolRead,
+		Update: resourceVSphereDistributedNetworkResourcePoolUpdate,
+		Delete: resourceVSphereDistributedNetworkResourcePoolDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereDistributedNetworkResourcePoolImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"distributed_virtual_switch_uuid": {
+				Type:        schema.TypeString,
+				Description: "The UUID of the DVS to create the network resource pool on.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name of the network resource pool.",
+				Required:    true,
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Description: "The description of the network resource pool.",
+				Optional:    true,
+			},
+			"reservation_mbit": {
+				Type:         schema.TypeInt,
+				Description:  "The amount of bandwidth, in Mbits/sec, reserved for the virtual machine network adapters in this network resource pool.",
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"key": {
+				Type:        schema.TypeString,
+				Description: "The key of the network resource pool, for use in the network_resource_pool_key of a port group.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceVSphereDistributedNetworkResourcePoolCreate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning create", resourceVSphereDistributedNetworkResourcePoolIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return err
+	}
+	dvs, err := dvsFromUUID(client, d.Get("distributed_virtual_switch_uuid").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate distributed virtual switch: %s", err)
+	}
+
+	spec := expandDvsVmVnicResourcePoolConfigSpec(d)
+	spec.Operation = string(types.ConfigSpecOperationAdd)
+	if err := reconfigureDVSVmVnicNetworkResourcePool(client, dvs, []types.DvsVmVnicResourcePoolConfigSpec{spec}); err != nil {
+		return fmt.Errorf("error creating network resource pool: %s", err)
+	}
+
+	// The reconfigure does not return the key that vCenter generated for the
+	// pool, so the pool is read back by the name it was created with.
+	pool, err := dvsNetworkResourcePoolFromName(dvs, spec.Name)
+	if err != nil {
+		return err
+	}
+	d.SetId(dvsEntryFlattenID(dvs, pool.Key))
+
+	log.Printf("[DEBUG] %s: Create finished successfully", resourceVSphereDistributedNetworkResourcePoolIDString(d))
+	return resourceVSphereDistributedNetworkResourcePoolRead(d, meta)
+}
+
+func resourceVSphereDistributedNetworkResourcePoolRead(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning read", resourceVSphereDistributedNetworkResourcePoolIDString(d))
+	dvs, key, err := dvsEntryObjects(d, meta)
+	if err != nil {
+		return err
+	}
+
+	pool, err := dvsNetworkResourcePoolFromKey(dvs, key)
+	if err != nil {
+		return err
+	}
+	if pool == nil {
+		// The pool is missing, blank out the ID so it can be re-created.
+		d.SetId("")
+		return nil
+	}
+
+	props, err := dvsProperties(dvs)
+	if err != nil {
+		return fmt.Errorf("error fetching DVS properties: %s", err)
+	}
+	d.Set("distributed_virtual_switch_uuid", props.Uuid)
+	if err := flattenDVSVmVnicNetworkResourcePool(d, pool); err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] %s: Read completed successfully", resourceVSphereDistributedNetworkResourcePoolIDString(d))
+	return nil
+}
+
+func resourceVSphereDistributedNetworkResourcePoolUpdate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning update", resourceVSphereDistributedNetworkResourcePoolIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	dvs, key, err := dvsEntryObjects(d, meta)
+	if err != nil {
+		return err
+	}
+
+	pool, err := dvsNetworkResourcePoolFromKey(dvs, key)
+	if err != nil {
+		return err
+	}
+	if pool == nil {
+		return fmt.Errorf("network resource pool %q not found", key)
+	}
+
+	spec := expandDvsVmVnicResourcePoolConfigSpec(d)
+	spec.Operation = string(types.ConfigSpecOperationEdit)
+	spec.Key = key
+	spec.ConfigVersion = pool.ConfigVersion
+	if err := reconfigureDVSVmVnicNetworkResourcePool(client, dvs, []types.DvsVmVnicResourcePoolConfigSpec{spec}); err != nil {
+		return fmt.Errorf("error updating network resource pool: %s", err)
+	}
+
+	log.Printf("[DEBUG] %s: Update finished successfully", resourceVSphereDistributedNetworkResourcePoolIDString(d))
+	return resourceVSphereDistributedNetworkResourcePoolRead(d, meta)
+}
+
+func resourceVSphereDistributedNetworkResourcePoolDelete(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning delete", resourceVSphereDistributedNetworkResourcePoolIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	dvs, key, err := dvsEntryObjects(d, meta)
+	if err != nil {
+		return err
+	}
+
+	spec := types.DvsVmVnicResourcePoolConfigSpec{
+		Operation: string(types.ConfigSpecOperationRemove),
+		Key:       key,
+	}
+	if err := reconfigureDVSVmVnicNetworkResourcePool(client, dvs, []types.DvsVmVnicResourcePoolConfigSpec{spec}); err != nil {
+		return fmt.Errorf("error deleting network resource pool: %s", err)
+	}
+
+	log.Printf("[DEBUG] %s: Deleted successfully", resourceVSphereDistributedNetworkResourcePoolIDString(d))
+	return nil
+}
+
+func resourceVSphereDistributedNetworkResourcePoolImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	dvs, name, err := dvsEntryImport(d, meta)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := dvsNetworkResourcePoolFromName(dvs, name)
+	if err != nil {
+		return nil, err
+	}
+	d.SetId(dvsEntryFlattenID(dvs, pool.Key))
+	return []*schema.ResourceData{d}, nil
+}
+
+// expandDvsVmVnicResourcePoolConfigSpec reads certain ResourceData keys and
+// returns a DvsVmVnicResourcePoolConfigSpec. The operation, key, and config
+// version are left for the caller to fill in.
+func expandDvsVmVnicResourcePoolConfigSpec(d *schema.ResourceData) types.DvsVmVnicResourcePoolConfigSpec {
+	return types.DvsVmVnicResourcePoolConfigSpec{
+		Name:        d.Get("name").(string),
+		Description: d.Get("description").(string),
+		AllocationInfo: &types.DvsVmVnicResourceAllocation{
+			ReservationQuota: int64(d.Get("reservation_mbit").(int)),
+		},
+	}
+}
+
+// flattenDVSVmVnicNetworkResourcePool saves a DVSVmVnicNetworkResourcePool
+// into the supplied ResourceData.
+func flattenDVSVmVnicNetworkResourcePool(d *schema.ResourceData, obj *types.DVSVmVnicNetworkResourcePool) error {
+	var reservation int64
+	if obj.AllocationInfo != nil {
+		reservation = obj.AllocationInfo.ReservationQuota
+	}
+	return structure.SetBatch(d, map[string]interface{}{
+		"name":             obj.Name,
+		"description":      obj.Description,
+		"reservation_mbit": int(reservation),
+		"key":              obj.Key,
+	})
+}
+
+// resourceVSphereDistributedNetworkResourcePoolIDString prints a friendly
+// string for the vsphere_distributed_network_resource_pool resource.
+func resourceVSphereDistributedNetworkResourcePoolIDString(d structure.ResourceIDStringer) string {
+	return structure.ResourceIDString(d, resourceVSphereDistributedNetworkResourcePoolName)
+}
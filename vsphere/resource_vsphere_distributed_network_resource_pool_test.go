@@ -0,0 +1,193 @@
+package vsphere
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+)
+
+func TestAccResourceVSphereDistributedNetworkResourcePool_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccSkipIfEsxi(t)
+			testAccResourceVSphereDistributedNetworkResourcePoolPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereDistributedNetworkResourcePoolExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereDistributedNetworkResourcePoolConfig(50),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereDistributedNetworkResourcePoolExists(true),
+					testAccResourceVSphereDistributedNetworkResourcePoolMatchReservation(50),
+					resource.TestCheckResourceAttrPair(
+						"vsphere_distributed_port_group.pg", "network_resource_pool_key",
+						"vsphere_distributed_network_resource_pool.pool", "key",
+					),
+				),
+			},
+			{
+				ResourceName:      "vsphere_distributed_network_resource_pool.pool",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					dvs, err := testGetDVS(s, "dvs")
+					if err != nil {
+						return "", err
+					}
+					b, err := json.Marshal(map[string]string{
+						"distributed_virtual_switch_path": dvs.InventoryPath,
+						"name":                            "terraform-test-pool",
+					})
+					if err != nil {
+						return "", err
+					}
+					return string(b), nil
+				},
+				Config: testAccResourceVSphereDistributedNetworkResourcePoolConfig(50),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereDistributedNetworkResourcePoolExists(true),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereDistributedNetworkResourcePool_updateReservation(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccSkipIfEsxi(t)
+			testAccResourceVSphereDistributedNetworkResourcePoolPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereDistributedNetworkResourcePoolExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereDistributedNetworkResourcePoolConfig(50),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereDistributedNetworkResourcePoolExists(true),
+					testAccResourceVSphereDistributedNetworkResourcePoolMatchReservation(50),
+				),
+			},
+			{
+				Config: testAccResourceVSphereDistributedNetworkResourcePoolConfig(100),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereDistributedNetworkResourcePoolExists(true),
+					testAccResourceVSphereDistributedNetworkResourcePoolMatchReservation(100),
+				),
+			},
+		},
+	})
+}
+
+func testAccResourceVSphereDistributedNetworkResourcePoolPreCheck(t *testing.T) {
+	testAccCheckEnvVariables(t, []string{"VSPHERE_ESXI_HOST", "VSPHERE_HOST_NIC0"})
+}
+
+func testAccResourceVSphereDistributedNetworkResourcePoolExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		pool, err := testGetDistributedNetworkResourcePool(s, "pool")
+		if err != nil {
+			if viapi.IsManagedObjectNotFoundError(err) && expected == false {
+				// DVS is missing
+				return nil
+			}
+			return err
+		}
+
+		switch {
+		case pool == nil && !expected:
+			// Expected missing
+			return nil
+		case pool == nil && expected:
+			return fmt.Errorf("network resource pool not found")
+		case pool != nil && !expected:
+			return fmt.Errorf("expected network resource pool %q to be missing", pool.Key)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereDistributedNetworkResourcePoolMatchReservation(expected int64) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		pool, err := testGetDistributedNetworkResourcePool(s, "pool")
+		if err != nil {
+			return err
+		}
+		if pool == nil {
+			return fmt.Errorf("network resource pool not found")
+		}
+		var actual int64
+		if pool.AllocationInfo != nil {
+			actual = pool.AllocationInfo.ReservationQuota
+		}
+		if actual != expected {
+			return fmt.Errorf("expected reservation to be %d, got %d", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereDistributedNetworkResourcePoolConfig(reservation int) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "esxi_host" {
+  default = "%s"
+}
+
+variable "network_interface" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_host" "host" {
+  name          = "${var.esxi_host}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_distributed_virtual_switch" "dvs" {
+  name          = "terraform-test-dvs"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+
+  network_resource_control_enabled = true
+  network_resource_control_version = "version3"
+  virtualmachine_reservation_mbit  = 200
+
+  host {
+    host_system_id = "${data.vsphere_host.host.id}"
+    devices        = ["${var.network_interface}"]
+  }
+}
+
+resource "vsphere_distributed_network_resource_pool" "pool" {
+  name                            = "terraform-test-pool"
+  description                     = "Managed by Terraform"
+  distributed_virtual_switch_uuid = "${vsphere_distributed_virtual_switch.dvs.id}"
+  reservation_mbit                = %d
+}
+
+resource "vsphere_distributed_port_group" "pg" {
+  name                            = "terraform-test-pg"
+  distributed_virtual_switch_uuid = "${vsphere_distributed_virtual_switch.dvs.id}"
+  network_resource_pool_key       = "${vsphere_distributed_network_resource_pool.pool.key}"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_ESXI_HOST"),
+		os.Getenv("VSPHERE_HOST_NIC0"),
+		reservation,
+	)
+}
@@ -0,0 +1,301 @@
+package vsphere
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const resourceVSphereDistributedPortName = "vsphere_distributed_port"
+
+func resourceVSphereDistributedPort() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"distributed_virtual_switch_uuid": {
+			Type:        schema.TypeString,
+			Description: "The UUID of the DVS the port is on.",
+			Required:    true,
+			ForceNew:    true,
+		},
+		"port_key": {
+			Type:        schema.TypeString,
+			Description: "The key of the port to manage.",
+			Required:    true,
+			ForceNew:    true,
+		},
+		"name": {
+			Type:        schema.TypeString,
+			Description: "The name of the port.",
+			Optional:    true,
+		},
+		"description": {
+			Type:        schema.TypeString,
+			Description: "The description of the port.",
+			Optional:    true,
+		},
+		"portgroup_key": {
+			Type:        schema.TypeString,
+			Description: "The key of the port group the port belongs to, if any.",
+			Computed:    true,
+		},
+	}
+
+	structure.MergeSchema(s, schemaVMwareDVSPortSetting())
+
+	return &schema.Resource{
+		Create: resourceVSphereDistributedPortCreate,
+		Read:   resourceVSphereDistributedPortRead,
+		Update: resourceVSphereDistributedPortUpdate,
+		Delete: resourceVSphereDistributedPortDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereDistributedPortImport,
+		},
+		Schema: s,
+	}
+}
+
+func resourceVSphereDistributedPortCreate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning create", resourceVSphereDistributedPortIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return err
+	}
+	dvs, err := dvsFromUUID(client, d.Get("distributed_virtual_switch_uuid").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate distributed virtual switch: %s", err)
+	}
+
+	key := d.Get("port_key").(string)
+	port, err := dvsPortFromKey(dvs, key)
+	if err != nil {
+		return fmt.Errorf("error fetching port %q: %s", key, err)
+	}
+	if port == nil {
+		return fmt.Errorf("port %q not found on DVS %q", key, dvs.Name())
+	}
+
+	spec := expandDVPortConfigSpec(d, true)
+	spec.ConfigVersion = port.Config.ConfigVersion
+	if err := reconfigureDVSPort(client, dvs, []types.DVPortConfigSpec{spec}); err != nil {
+		return fmt.Errorf("error configuring port %q: %s", key, err)
+	}
+	d.SetId(resourceVSphereDistributedPortFlattenID(dvs, key))
+
+	log.Printf("[DEBUG] %s: Create finished successfully", resourceVSphereDistributedPortIDString(d))
+	return resourceVSphereDistributedPortRead(d, meta)
+}
+
+func resourceVSphereDistributedPortRead(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning read", resourceVSphereDistributedPortIDString(d))
+	dvs, key, err := resourceVSphereDistributedPortObjects(d, meta)
+	if err != nil {
+		return err
+	}
+
+	port, err := dvsPortFromKey(dvs, key)
+	if err != nil {
+		return fmt.Errorf("error fetching port %q: %s", key, err)
+	}
+	if port == nil {
+		// The port is missing, blank out the ID so it can be re-created.
+		d.SetId("")
+		return nil
+	}
+
+	if err := flattenDistributedVirtualPort(d, port); err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] %s: Read completed successfully", resourceVSphereDistributedPortIDString(d))
+	return nil
+}
+
+func resourceVSphereDistributedPortUpdate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning update", resourceVSphereDistributedPortIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	dvs, key, err := resourceVSphereDistributedPortObjects(d, meta)
+	if err != nil {
+		return err
+	}
+
+	port, err := dvsPortFromKey(dvs, key)
+	if err != nil {
+		return fmt.Errorf("error fetching port %q: %s", key, err)
+	}
+	if port == nil {
+		return fmt.Errorf("port %q not found on DVS %q", key, dvs.Name())
+	}
+
+	// Only send the port setting if it has changed, as the policies in state
+	// that are inherited from the port group would otherwise be sent as
+	// overrides.
+	var settingChanged bool
+	for k := range schemaVMwareDVSPortSetting() {
+		if d.HasChange(k) {
+			settingChanged = true
+			break
+		}
+	}
+	spec := expandDVPortConfigSpec(d, settingChanged)
+	spec.ConfigVersion = port.Config.ConfigVersion
+	if err := reconfigureDVSPort(client, dvs, []types.DVPortConfigSpec{spec}); err != nil {
+		return fmt.Errorf("error updating port %q: %s", key, err)
+	}
+
+	log.Printf("[DEBUG] %s: Update finished successfully", resourceVSphereDistributedPortIDString(d))
+	return resourceVSphereDistributedPortRead(d, meta)
+}
+
+func resourceVSphereDistributedPortDelete(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning delete", resourceVSphereDistributedPortIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	dvs, key, err := resourceVSphereDistributedPortObjects(d, meta)
+	if err != nil {
+		return err
+	}
+
+	port, err := dvsPortFromKey(dvs, key)
+	if err != nil {
+		return fmt.Errorf("error fetching port %q: %s", key, err)
+	}
+	if port == nil {
+		// Nothing left to reset.
+		return nil
+	}
+
+	// Ports are owned by their port group or the DVS, so they are not
+	// removed. Instead, all of the policy overrides on the port are reset so
+	// that the port inherits its settings again.
+	spec := types.DVPortConfigSpec{
+		Operation:     string(types.ConfigSpecOperationEdit),
+		Key:           key,
+		Setting:       inheritedVMwareDVSPortSetting(),
+		ConfigVersion: port.Config.ConfigVersion,
+	}
+	if err := reconfigureDVSPort(client, dvs, []types.DVPortConfigSpec{spec}); err != nil {
+		return fmt.Errorf("error resetting port %q: %s", key, err)
+	}
+
+	log.Printf("[DEBUG] %s: Deleted successfully", resourceVSphereDistributedPortIDString(d))
+	return nil
+}
+
+func resourceVSphereDistributedPortImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	var data map[string]string
+	if err := json.Unmarshal([]byte(d.Id()), &data); err != nil {
+		return nil, err
+	}
+	dvsPath, ok := data["distributed_virtual_switch_path"]
+	if !ok {
+		return nil, errors.New("missing distributed_virtual_switch_path in input data")
+	}
+	key, ok := data["port_key"]
+	if !ok {
+		return nil, errors.New("missing port_key in input data")
+	}
+
+	client := meta.(*VSphereClient).vimClient
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return nil, err
+	}
+	dvs, err := dvsFromPath(client, dvsPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot locate distributed virtual switch %q: %s", dvsPath, err)
+	}
+
+	port, err := dvsPortFromKey(dvs, key)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching port %q: %s", key, err)
+	}
+	if port == nil {
+		return nil, fmt.Errorf("port %q not found on DVS %q", key, dvsPath)
+	}
+	d.SetId(resourceVSphereDistributedPortFlattenID(dvs, key))
+	return []*schema.ResourceData{d}, nil
+}
+
+// expandDVPortConfigSpec reads certain ResourceData keys and returns a
+// DVPortConfigSpec that edits the port. The port setting is only included
+// when withSetting is true. The config version is left for the caller to fill
+// in.
+func expandDVPortConfigSpec(d *schema.ResourceData, withSetting bool) types.DVPortConfigSpec {
+	spec := types.DVPortConfigSpec{
+		Operation:   string(types.ConfigSpecOperationEdit),
+		Key:         d.Get("port_key").(string),
+		Name:        d.Get("name").(string),
+		Description: d.Get("description").(string),
+	}
+	if withSetting {
+		// Assign through a check for nil so that a nil setting does not end
+		// up as a non-nil interface value.
+		if setting := expandVMwareDVSPortSetting(d); setting != nil {
+			spec.Setting = setting
+		}
+	}
+	return spec
+}
+
+// flattenDistributedVirtualPort reads various fields from a
+// DistributedVirtualPort into the passed in ResourceData.
+func flattenDistributedVirtualPort(d *schema.ResourceData, obj *types.DistributedVirtualPort) error {
+	d.Set("distributed_virtual_switch_uuid", obj.DvsUuid)
+	d.Set("port_key", obj.Key)
+	d.Set("name", obj.Config.Name)
+	d.Set("description", obj.Config.Description)
+	d.Set("portgroup_key", obj.PortgroupKey)
+
+	if setting, ok := obj.Config.Setting.(*types.VMwareDVSPortSetting); ok {
+		return flattenVMwareDVSPortSetting(d, setting)
+	}
+	return nil
+}
+
+// resourceVSphereDistributedPortIDString prints a friendly string for the
+// vsphere_distributed_port resource.
+func resourceVSphereDistributedPortIDString(d structure.ResourceIDStringer) string {
+	return structure.ResourceIDString(d, resourceVSphereDistributedPortName)
+}
+
+// resourceVSphereDistributedPortFlattenID makes an ID for the
+// vsphere_distributed_port resource.
+func resourceVSphereDistributedPortFlattenID(dvs *object.VmwareDistributedVirtualSwitch, key string) string {
+	return strings.Join([]string{dvs.Reference().Value, key}, ":")
+}
+
+// resourceVSphereDistributedPortParseID parses an ID for the
+// vsphere_distributed_port and outputs its parts.
+func resourceVSphereDistributedPortParseID(id string) (string, string, error) {
+	parts := strings.SplitN(id, ":", 2)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("bad ID %q", id)
+	}
+	return parts[0], parts[1], nil
+}
+
+// resourceVSphereDistributedPortObjects handles the fetching of the DVS and
+// the port key from the resource ID.
+func resourceVSphereDistributedPortObjects(
+	d *schema.ResourceData,
+	meta interface{},
+) (*object.VmwareDistributedVirtualSwitch, string, error) {
+	client := meta.(*VSphereClient).vimClient
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return nil, "", err
+	}
+	dvsID, key, err := resourceVSphereDistributedPortParseID(d.Id())
+	if err != nil {
+		return nil, "", err
+	}
+	dvs, err := dvsFromMOID(client, dvsID)
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot locate distributed virtual switch: %s", err)
+	}
+	return dvs, key, nil
+}
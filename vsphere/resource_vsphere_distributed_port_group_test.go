@@ -106,6 +106,39 @@ func TestAccResourceVSphereDistributedPortGroup_overrideVlan(t *testing.T) {
 	})
 }
 
+func TestAccResourceVSphereDistributedPortGroup_trafficFilter(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereDistributedPortGroupPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereDistributedPortGroupExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereDistributedPortGroupConfigTrafficFilter(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereDistributedPortGroupExists(true),
+					testAccResourceVSphereDistributedPortGroupHasTrafficRules(2),
+					resource.TestCheckResourceAttr(
+						"vsphere_distributed_port_group.pg", "traffic_filter.0.rule.0.dscp_tag", "46",
+					),
+					resource.TestCheckResourceAttr(
+						"vsphere_distributed_port_group.pg", "traffic_filter.0.rule.0.ip_qualifier.0.destination_port", "5060",
+					),
+				),
+			},
+			{
+				Config: testAccResourceVSphereDistributedPortGroupConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereDistributedPortGroupExists(true),
+					testAccResourceVSphereDistributedPortGroupHasTrafficRules(0),
+				),
+			},
+		},
+	})
+}
+
 func TestAccResourceVSphereDistributedPortGroup_singleTag(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
@@ -249,6 +282,28 @@ func testAccResourceVSphereDistributedPortGroupHasVlanRange(emin, emax int32) re
 	}
 }
 
+func testAccResourceVSphereDistributedPortGroupHasTrafficRules(expected int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetDVPortgroupProperties(s, "pg")
+		if err != nil {
+			return err
+		}
+		pc := props.Config.DefaultPortConfig.(*types.VMwareDVSPortSetting)
+		var actual int
+		if pc.FilterPolicy != nil {
+			for _, fc := range pc.FilterPolicy.FilterConfig {
+				if tfc, ok := fc.(*types.DvsTrafficFilterConfig); ok && tfc.TrafficRuleset != nil {
+					actual += len(tfc.TrafficRuleset.Rules)
+				}
+			}
+		}
+		if actual != expected {
+			return fmt.Errorf("expected %d traffic rules, got %d", expected, actual)
+		}
+		return nil
+	}
+}
+
 func testAccResourceVSphereDistributedPortGroupCheckTags(tagResName string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		dvs, err := testGetDVPortgroup(s, "pg")
@@ -447,6 +502,55 @@ resource "vsphere_distributed_port_group" "pg" {
 	)
 }
 
+func testAccResourceVSphereDistributedPortGroupConfigTrafficFilter() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_distributed_virtual_switch" "dvs" {
+  name          = "terraform-test-dvs"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_distributed_port_group" "pg" {
+  name                            = "terraform-test-pg"
+  distributed_virtual_switch_uuid = "${vsphere_distributed_virtual_switch.dvs.id}"
+
+  traffic_filter {
+    rule {
+      description = "sip"
+      action      = "tag"
+      dscp_tag    = 46
+
+      ip_qualifier {
+        protocol         = 17
+        destination_port = "5060"
+      }
+    }
+
+    rule {
+      description = "block-telnet"
+      action      = "drop"
+      direction   = "incomingPackets"
+
+      ip_qualifier {
+        protocol            = 6
+        destination_address = "192.0.2.0/24"
+        destination_port    = "23"
+      }
+    }
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+	)
+}
+
 func testAccResourceVSphereDistributedPortGroupConfigSingleTag() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
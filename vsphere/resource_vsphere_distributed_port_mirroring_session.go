@@ -0,0 +1,495 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/dvportgroup"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const resourceVSphereDistributedPortMirroringSessionName = "vsphere_distributed_port_mirroring_session"
+
+const (
+	portMirroringSourceDirectionBoth        = "both"
+	portMirroringSourceDirectionTransmitted = "transmitted"
+	portMirroringSourceDirectionReceived    = "received"
+)
+
+var portMirroringSessionTypeAllowedValues = []string{
+	string(types.VMwareDVSVspanSessionTypeDvPortMirror),
+	string(types.VMwareDVSVspanSessionTypeRemoteMirrorSource),
+	string(types.VMwareDVSVspanSessionTypeRemoteMirrorDest),
+	string(types.VMwareDVSVspanSessionTypeEncapsulatedRemoteMirrorSource),
+	string(types.VMwareDVSVspanSessionTypeMixedDestMirror),
+}
+
+var portMirroringSourceDirectionAllowedValues = []string{
+	portMirroringSourceDirectionBoth,
+	portMirroringSourceDirectionTransmitted,
+	portMirroringSourceDirectionReceived,
+}
+
+var portMirroringEncapsulationTypeAllowedValues = []string{
+	string(types.VMwareDVSVspanSessionEncapTypeGre),
+	string(types.VMwareDVSVspanSessionEncapTypeErspan2),
+	string(types.VMwareDVSVspanSessionEncapTypeErspan3),
+}
+
+func resourceVSphereDistributedPortMirroringSession() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereDistributedPortMirroringSessionCreate,
+		Read:   resourceVSphereDistributedPortMirroringSessionRead,
+		Update: resourceVSphereDistributedPortMirroringSessionUpdate,
+		Delete: resourceVSphereDistributedPortMirroringSessionDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereDistributedPortMirroringSessionImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"distributed_virtual_switch_uuid": {
+				Type:        schema.TypeString,
+				Description: "The UUID of the DVS to create the port mirroring session on.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name of the port mirroring session.",
+				Required:    true,
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Description: "The description of the port mirroring session.",
+				Optional:    true,
+			},
+			"enabled": {
+				Type:        schema.TypeBool,
+				Description: "Whether or not the port mirroring session is enabled.",
+				Optional:    true,
+				Default:     true,
+			},
+			"session_type": {
+				Type:         schema.TypeString,
+				Description:  "The type of the port mirroring session. Can be one of dvPortMirror, remoteMirrorSource, remoteMirrorDest, encapsulatedRemoteMirrorSource, or mixedDestMirror.",
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice(portMirroringSessionTypeAllowedValues, false),
+			},
+			"source_direction": {
+				Type:         schema.TypeString,
+				Description:  "The direction of the traffic to mirror from the source ports and VLANs. Can be one of both, transmitted, or received.",
+				Optional:     true,
+				Default:      portMirroringSourceDirectionBoth,
+				ValidateFunc: validation.StringInSlice(portMirroringSourceDirectionAllowedValues, false),
+			},
+			"source_port_keys": {
+				Type:        schema.TypeSet,
+				Description: "The keys of the distributed ports to mirror traffic from.",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"source_port_group_keys": {
+				Type:        schema.TypeSet,
+				Description: "The keys of the distributed port groups to mirror traffic from. All ports in the port groups are added to the source ports of the session.",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"source_vlans": {
+				Type:        schema.TypeSet,
+				Description: "The IDs of the VLANs to mirror traffic from, for remoteMirrorDest sessions.",
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeInt,
+					ValidateFunc: validation.IntBetween(1, 4094),
+				},
+			},
+			"destination_port_keys": {
+				Type:        schema.TypeSet,
+				Description: "The keys of the distributed ports to send mirrored traffic to.",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"destination_uplinks": {
+				Type:        schema.TypeSet,
+				Description: "The names of the uplinks to send mirrored traffic to, for remoteMirrorSource and mixedDestMirror sessions.",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"destination_ip_addresses": {
+				Type:        schema.TypeSet,
+				Description: "The IP addresses to send encapsulated mirrored traffic to, for encapsulatedRemoteMirrorSource sessions.",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"encapsulation_vlan_id": {
+				Type:         schema.TypeInt,
+				Description:  "The VLAN ID to encapsulate mirrored traffic with, for remoteMirrorSource sessions.",
+				Optional:     true,
+				ValidateFunc: validation.IntBetween(1, 4094),
+			},
+			"strip_original_vlan": {
+				Type:        schema.TypeBool,
+				Description: "Whether or not to strip the original VLAN tag from mirrored traffic.",
+				Optional:    true,
+			},
+			"encapsulation_type": {
+				Type:         schema.TypeString,
+				Description:  "The encapsulation type of mirrored traffic, for encapsulatedRemoteMirrorSource sessions. Can be one of gre, erspan2, or erspan3.",
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.StringInSlice(portMirroringEncapsulationTypeAllowedValues, false),
+			},
+			"erspan_id": {
+				Type:         schema.TypeInt,
+				Description:  "The ERSPAN session ID, for erspan2 and erspan3 encapsulation types.",
+				Optional:     true,
+				ValidateFunc: validation.IntBetween(0, 1023),
+			},
+			"mirrored_packet_length": {
+				Type:         schema.TypeInt,
+				Description:  "The length, in bytes, to truncate mirrored packets to. The default is to mirror packets at their full length.",
+				Optional:     true,
+				ValidateFunc: validation.IntBetween(60, 9000),
+			},
+			"sampling_rate": {
+				Type:         schema.TypeInt,
+				Description:  "The rate at which packets are sampled. A value of n mirrors one out of every n packets.",
+				Optional:     true,
+				Default:      1,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"normal_traffic_allowed": {
+				Type:        schema.TypeBool,
+				Description: "Whether or not the destination ports can send and receive normal traffic.",
+				Optional:    true,
+			},
+			"key": {
+				Type:        schema.TypeString,
+				Description: "The key of the port mirroring session.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceVSphereDistributedPortMirroringSessionCreate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning create", resourceVSphereDistributedPortMirroringSessionIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return err
+	}
+	dvs, err := dvsFromUUID(client, d.Get("distributed_virtual_switch_uuid").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate distributed virtual switch: %s", err)
+	}
+
+	session, err := expandVMwareVspanSession(d, client)
+	if err != nil {
+		return err
+	}
+	if err := resourceVSphereDistributedPortMirroringSessionReconfigure(client, dvs, session, types.ConfigSpecOperationAdd); err != nil {
+		return fmt.Errorf("error creating port mirroring session: %s", err)
+	}
+
+	// Sessions are added through the DVS configuration, which has no way of
+	// returning the generated session key. Look the session up by name to get
+	// it.
+	session, err = dvsVspanSessionFromName(dvs, session.Name)
+	if err != nil {
+		return err
+	}
+	d.SetId(dvsEntryFlattenID(dvs, session.Key))
+
+	log.Printf("[DEBUG] %s: Create finished successfully", resourceVSphereDistributedPortMirroringSessionIDString(d))
+	return resourceVSphereDistributedPortMirroringSessionRead(d, meta)
+}
+
+func resourceVSphereDistributedPortMirroringSessionRead(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning read", resourceVSphereDistributedPortMirroringSessionIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	dvs, key, err := dvsEntryObjects(d, meta)
+	if err != nil {
+		return err
+	}
+
+	session, err := dvsVspanSessionFromKey(dvs, key)
+	if err != nil {
+		return err
+	}
+	if session == nil {
+		// The session is missing, blank out the ID so it can be re-created.
+		d.SetId("")
+		return nil
+	}
+
+	props, err := dvsProperties(dvs)
+	if err != nil {
+		return fmt.Errorf("error fetching DVS properties: %s", err)
+	}
+	d.Set("distributed_virtual_switch_uuid", props.Uuid)
+	if err := flattenVMwareVspanSession(d, client, session); err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] %s: Read completed successfully", resourceVSphereDistributedPortMirroringSessionIDString(d))
+	return nil
+}
+
+func resourceVSphereDistributedPortMirroringSessionUpdate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning update", resourceVSphereDistributedPortMirroringSessionIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	dvs, key, err := dvsEntryObjects(d, meta)
+	if err != nil {
+		return err
+	}
+
+	session, err := expandVMwareVspanSession(d, client)
+	if err != nil {
+		return err
+	}
+	session.Key = key
+	if err := resourceVSphereDistributedPortMirroringSessionReconfigure(client, dvs, session, types.ConfigSpecOperationEdit); err != nil {
+		return fmt.Errorf("error updating port mirroring session: %s", err)
+	}
+
+	log.Printf("[DEBUG] %s: Update finished successfully", resourceVSphereDistributedPortMirroringSessionIDString(d))
+	return resourceVSphereDistributedPortMirroringSessionRead(d, meta)
+}
+
+func resourceVSphereDistributedPortMirroringSessionDelete(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning delete", resourceVSphereDistributedPortMirroringSessionIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	dvs, key, err := dvsEntryObjects(d, meta)
+	if err != nil {
+		return err
+	}
+
+	session := &types.VMwareVspanSession{Key: key}
+	if err := resourceVSphereDistributedPortMirroringSessionReconfigure(client, dvs, session, types.ConfigSpecOperationRemove); err != nil {
+		return fmt.Errorf("error deleting port mirroring session: %s", err)
+	}
+
+	log.Printf("[DEBUG] %s: Deleted successfully", resourceVSphereDistributedPortMirroringSessionIDString(d))
+	return nil
+}
+
+func resourceVSphereDistributedPortMirroringSessionImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	dvs, name, err := dvsEntryImport(d, meta)
+	if err != nil {
+		return nil, err
+	}
+	session, err := dvsVspanSessionFromName(dvs, name)
+	if err != nil {
+		return nil, err
+	}
+	d.SetId(dvsEntryFlattenID(dvs, session.Key))
+	return []*schema.ResourceData{d}, nil
+}
+
+// expandVMwareVspanSession reads certain ResourceData keys and returns a
+// VMwareVspanSession. The ports of any source port groups are resolved and
+// added to the source ports of the session.
+func expandVMwareVspanSession(d *schema.ResourceData, client *govmomi.Client) (*types.VMwareVspanSession, error) {
+	portKeys := structure.SliceInterfacesToStrings(d.Get("source_port_keys").(*schema.Set).List())
+	pgPorts, err := portMirroringPortGroupPortKeys(
+		client,
+		d.Get("distributed_virtual_switch_uuid").(string),
+		structure.SliceInterfacesToStrings(d.Get("source_port_group_keys").(*schema.Set).List()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, keys := range pgPorts {
+		portKeys = append(portKeys, keys...)
+	}
+
+	var vlans []int32
+	for _, v := range d.Get("source_vlans").(*schema.Set).List() {
+		vlans = append(vlans, int32(v.(int)))
+	}
+
+	var source *types.VMwareVspanPort
+	if len(portKeys) > 0 || len(vlans) > 0 {
+		source = &types.VMwareVspanPort{
+			PortKey: portKeys,
+			Vlans:   vlans,
+		}
+	}
+	obj := &types.VMwareVspanSession{
+		Name:                 d.Get("name").(string),
+		Description:          d.Get("description").(string),
+		Enabled:              d.Get("enabled").(bool),
+		SessionType:          d.Get("session_type").(string),
+		EncapsulationVlanId:  int32(d.Get("encapsulation_vlan_id").(int)),
+		StripOriginalVlan:    d.Get("strip_original_vlan").(bool),
+		MirroredPacketLength: int32(d.Get("mirrored_packet_length").(int)),
+		NormalTrafficAllowed: d.Get("normal_traffic_allowed").(bool),
+		SamplingRate:         int32(d.Get("sampling_rate").(int)),
+		EncapType:            d.Get("encapsulation_type").(string),
+		ErspanId:             int32(d.Get("erspan_id").(int)),
+		DestinationPort: &types.VMwareVspanPort{
+			PortKey:        structure.SliceInterfacesToStrings(d.Get("destination_port_keys").(*schema.Set).List()),
+			UplinkPortName: structure.SliceInterfacesToStrings(d.Get("destination_uplinks").(*schema.Set).List()),
+			IpAddress:      structure.SliceInterfacesToStrings(d.Get("destination_ip_addresses").(*schema.Set).List()),
+		},
+	}
+	switch d.Get("source_direction").(string) {
+	case portMirroringSourceDirectionTransmitted:
+		obj.SourcePortTransmitted = source
+	case portMirroringSourceDirectionReceived:
+		obj.SourcePortReceived = source
+	default:
+		obj.SourcePortTransmitted = source
+		obj.SourcePortReceived = source
+	}
+	return obj, nil
+}
+
+// flattenVMwareVspanSession saves a VMwareVspanSession into the supplied
+// ResourceData.
+//
+// The source ports of the session are matched against the ports of the
+// configured source port groups. Port groups whose ports are all in the
+// session are kept, and their ports are left out of source_port_keys. Port
+// groups that have ports that are not in the session, such as ports that were
+// added to the port group after the session was last applied, are removed
+// from state, so that the session is updated on the next apply.
+func flattenVMwareVspanSession(d *schema.ResourceData, client *govmomi.Client, obj *types.VMwareVspanSession) error {
+	direction := portMirroringSourceDirectionBoth
+	source := obj.SourcePortTransmitted
+	switch {
+	case obj.SourcePortTransmitted != nil && obj.SourcePortReceived == nil:
+		direction = portMirroringSourceDirectionTransmitted
+	case obj.SourcePortTransmitted == nil && obj.SourcePortReceived != nil:
+		direction = portMirroringSourceDirectionReceived
+		source = obj.SourcePortReceived
+	}
+	if source == nil {
+		source = &types.VMwareVspanPort{}
+	}
+	destination := obj.DestinationPort
+	if destination == nil {
+		destination = &types.VMwareVspanPort{}
+	}
+
+	pgPorts, err := portMirroringPortGroupPortKeys(
+		client,
+		d.Get("distributed_virtual_switch_uuid").(string),
+		structure.SliceInterfacesToStrings(d.Get("source_port_group_keys").(*schema.Set).List()),
+	)
+	if err != nil {
+		return err
+	}
+	sessionPorts := make(map[string]bool)
+	for _, key := range source.PortKey {
+		sessionPorts[key] = true
+	}
+	var pgKeys []string
+	pgPortKeys := make(map[string]bool)
+	for pgKey, keys := range pgPorts {
+		complete := true
+		for _, key := range keys {
+			if !sessionPorts[key] {
+				complete = false
+			}
+		}
+		if !complete {
+			log.Printf("[DEBUG] Port group %q has ports that are not in port mirroring session %q", pgKey, obj.Name)
+			continue
+		}
+		pgKeys = append(pgKeys, pgKey)
+		for _, key := range keys {
+			pgPortKeys[key] = true
+		}
+	}
+	var portKeys []string
+	for _, key := range source.PortKey {
+		if !pgPortKeys[key] {
+			portKeys = append(portKeys, key)
+		}
+	}
+	var vlans []int
+	for _, vlan := range source.Vlans {
+		vlans = append(vlans, int(vlan))
+	}
+
+	return structure.SetBatch(d, map[string]interface{}{
+		"name":                     obj.Name,
+		"description":              obj.Description,
+		"enabled":                  obj.Enabled,
+		"session_type":             obj.SessionType,
+		"source_direction":         direction,
+		"source_port_keys":         portKeys,
+		"source_port_group_keys":   pgKeys,
+		"source_vlans":             vlans,
+		"destination_port_keys":    destination.PortKey,
+		"destination_uplinks":      destination.UplinkPortName,
+		"destination_ip_addresses": destination.IpAddress,
+		"encapsulation_vlan_id":    obj.EncapsulationVlanId,
+		"strip_original_vlan":      obj.StripOriginalVlan,
+		"encapsulation_type":       obj.EncapType,
+		"erspan_id":                obj.ErspanId,
+		"mirrored_packet_length":   obj.MirroredPacketLength,
+		"sampling_rate":            obj.SamplingRate,
+		"normal_traffic_allowed":   obj.NormalTrafficAllowed,
+		"key":                      obj.Key,
+	})
+}
+
+// portMirroringPortGroupPortKeys returns the keys of the ports in each of the
+// supplied port groups, keyed by port group key.
+func portMirroringPortGroupPortKeys(client *govmomi.Client, dvsUUID string, pgKeys []string) (map[string][]string, error) {
+	result := make(map[string][]string)
+	for _, pgKey := range pgKeys {
+		pg, err := dvportgroup.FromKey(client, dvsUUID, pgKey)
+		if err != nil {
+			return nil, fmt.Errorf("cannot locate port group %q: %s", pgKey, err)
+		}
+		props, err := dvportgroup.Properties(pg)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching properties for port group %q: %s", pgKey, err)
+		}
+		result[pgKey] = props.PortKeys
+	}
+	return result, nil
+}
+
+// resourceVSphereDistributedPortMirroringSessionReconfigure applies an
+// operation for a port mirroring session through the configuration spec of
+// its DVS.
+func resourceVSphereDistributedPortMirroringSessionReconfigure(
+	client *govmomi.Client,
+	dvs *object.VmwareDistributedVirtualSwitch,
+	session *types.VMwareVspanSession,
+	op types.ConfigSpecOperation,
+) error {
+	props, err := dvsProperties(dvs)
+	if err != nil {
+		return fmt.Errorf("error fetching DVS properties: %s", err)
+	}
+	spec := &types.VMwareDVSConfigSpec{
+		DVSConfigSpec: types.DVSConfigSpec{
+			ConfigVersion: props.Config.GetDVSConfigInfo().ConfigVersion,
+		},
+		VspanConfigSpec: []types.VMwareDVSVspanConfigSpec{
+			{
+				VspanSession: *session,
+				Operation:    string(op),
+			},
+		},
+	}
+	return updateDVSConfiguration(client, dvs, spec)
+}
+
+// resourceVSphereDistributedPortMirroringSessionIDString prints a friendly
+// string for the vsphere_distributed_port_mirroring_session resource.
+func resourceVSphereDistributedPortMirroringSessionIDString(d structure.ResourceIDStringer) string {
+	return structure.ResourceIDString(d, resourceVSphereDistributedPortMirroringSessionName)
+}
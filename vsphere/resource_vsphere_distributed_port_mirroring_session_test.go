@@ -0,0 +1,215 @@
+package vsphere
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+)
+
+func TestAccResourceVSphereDistributedPortMirroringSession_remoteMirrorSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccSkipIfEsxi(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereDistributedPortMirroringSessionExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereDistributedPortMirroringSessionConfigRemoteMirrorSource(100),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereDistributedPortMirroringSessionExists(true),
+					testAccResourceVSphereDistributedPortMirroringSessionHasPortGroupPorts(),
+					resource.TestCheckResourceAttr(
+						"vsphere_distributed_port_mirroring_session.session", "encapsulation_vlan_id", "100",
+					),
+				),
+			},
+			{
+				Config: testAccResourceVSphereDistributedPortMirroringSessionConfigRemoteMirrorSource(200),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereDistributedPortMirroringSessionExists(true),
+					resource.TestCheckResourceAttr(
+						"vsphere_distributed_port_mirroring_session.session", "encapsulation_vlan_id", "200",
+					),
+				),
+			},
+			{
+				ResourceName:      "vsphere_distributed_port_mirroring_session.session",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					dvs, err := testGetDVS(s, "dvs")
+					if err != nil {
+						return "", err
+					}
+					b, err := json.Marshal(map[string]string{
+						"distributed_virtual_switch_path": dvs.InventoryPath,
+						"name":                            "terraform-test-session",
+					})
+					if err != nil {
+						return "", err
+					}
+					return string(b), nil
+				},
+				Config: testAccResourceVSphereDistributedPortMirroringSessionConfigRemoteMirrorSource(200),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereDistributedPortMirroringSession_encapsulatedRemoteMirrorSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccSkipIfEsxi(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereDistributedPortMirroringSessionExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereDistributedPortMirroringSessionConfigEncapsulatedRemoteMirrorSource(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereDistributedPortMirroringSessionExists(true),
+					testAccResourceVSphereDistributedPortMirroringSessionHasPortGroupPorts(),
+					resource.TestCheckResourceAttr(
+						"vsphere_distributed_port_mirroring_session.session", "mirrored_packet_length", "128",
+					),
+					resource.TestCheckResourceAttr(
+						"vsphere_distributed_port_mirroring_session.session", "sampling_rate", "10",
+					),
+				),
+			},
+		},
+	})
+}
+
+func testAccResourceVSphereDistributedPortMirroringSessionExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		session, err := testGetDistributedPortMirroringSession(s, "session")
+		if err != nil {
+			if viapi.IsManagedObjectNotFoundError(err) && expected == false {
+				// DVS is missing
+				return nil
+			}
+			return err
+		}
+
+		switch {
+		case session == nil && !expected:
+			// Expected missing
+			return nil
+		case session == nil && expected:
+			return fmt.Errorf("port mirroring session not found")
+		case session != nil && !expected:
+			return fmt.Errorf("expected port mirroring session %q to be missing", session.Key)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereDistributedPortMirroringSessionHasPortGroupPorts
+// checks that all of the ports of the "pg" port group are source ports of the
+// session.
+func testAccResourceVSphereDistributedPortMirroringSessionHasPortGroupPorts() resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		session, err := testGetDistributedPortMirroringSession(s, "session")
+		if err != nil {
+			return err
+		}
+		if session == nil {
+			return fmt.Errorf("port mirroring session not found")
+		}
+		props, err := testGetDVPortgroupProperties(s, "pg")
+		if err != nil {
+			return err
+		}
+		for _, source := range [][]string{session.SourcePortTransmitted.PortKey, session.SourcePortReceived.PortKey} {
+			ports := make(map[string]bool)
+			for _, key := range source {
+				ports[key] = true
+			}
+			for _, key := range props.PortKeys {
+				if !ports[key] {
+					return fmt.Errorf("expected port %q to be a source port of the session", key)
+				}
+			}
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereDistributedPortMirroringSessionConfigRemoteMirrorSource(vlan int) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_distributed_virtual_switch" "dvs" {
+  name          = "terraform-test-dvs"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+  uplinks       = ["tfup1", "tfup2"]
+}
+
+resource "vsphere_distributed_port_group" "pg" {
+  name                            = "terraform-test-pg"
+  distributed_virtual_switch_uuid = "${vsphere_distributed_virtual_switch.dvs.id}"
+}
+
+resource "vsphere_distributed_port_mirroring_session" "session" {
+  name                            = "terraform-test-session"
+  distributed_virtual_switch_uuid = "${vsphere_distributed_virtual_switch.dvs.id}"
+  session_type                    = "remoteMirrorSource"
+  source_port_group_keys          = ["${vsphere_distributed_port_group.pg.key}"]
+  destination_uplinks             = ["tfup2"]
+  encapsulation_vlan_id           = %d
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		vlan,
+	)
+}
+
+func testAccResourceVSphereDistributedPortMirroringSessionConfigEncapsulatedRemoteMirrorSource() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_distributed_virtual_switch" "dvs" {
+  name          = "terraform-test-dvs"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_distributed_port_group" "pg" {
+  name                            = "terraform-test-pg"
+  distributed_virtual_switch_uuid = "${vsphere_distributed_virtual_switch.dvs.id}"
+}
+
+resource "vsphere_distributed_port_mirroring_session" "session" {
+  name                            = "terraform-test-session"
+  distributed_virtual_switch_uuid = "${vsphere_distributed_virtual_switch.dvs.id}"
+  session_type                    = "encapsulatedRemoteMirrorSource"
+  source_port_group_keys          = ["${vsphere_distributed_port_group.pg.key}"]
+  destination_ip_addresses        = ["192.0.2.10"]
+  encapsulation_type              = "gre"
+  mirrored_packet_length          = 128
+  sampling_rate                   = 10
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+	)
+}
@@ -0,0 +1,158 @@
+package vsphere
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccResourceVSphereDistributedPort_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccSkipIfEsxi(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereDistributedPortIsInherited(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereDistributedPortConfig(100, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereDistributedPortHasVlan(100),
+					resource.TestCheckResourceAttr("vsphere_distributed_port.port", "name", "terraform-test-port"),
+					resource.TestCheckResourceAttrPair(
+						"vsphere_distributed_port.port", "portgroup_key",
+						"vsphere_distributed_port_group.pg", "key",
+					),
+				),
+			},
+			{
+				Config: testAccResourceVSphereDistributedPortConfig(200, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereDistributedPortHasVlan(200),
+					resource.TestCheckResourceAttr("vsphere_distributed_port.port", "block_all_ports", "true"),
+				),
+			},
+			{
+				ResourceName:      "vsphere_distributed_port.port",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					dvs, err := testGetDVS(s, "dvs")
+					if err != nil {
+						return "", err
+					}
+					b, err := json.Marshal(map[string]string{
+						"distributed_virtual_switch_path": dvs.InventoryPath,
+						"port_key":                        "0",
+					})
+					if err != nil {
+						return "", err
+					}
+					return string(b), nil
+				},
+				Config: testAccResourceVSphereDistributedPortConfig(200, true),
+			},
+		},
+	})
+}
+
+// testAccResourceVSphereDistributedPortIsInherited checks that the port has
+// had its VLAN override reset. The DVS is destroyed along with the port in
+// the test, so a missing DVS is treated as a pass as well.
+func testAccResourceVSphereDistributedPortIsInherited() resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		port, err := testGetDistributedPort(s, "port")
+		if err != nil {
+			if viapi.IsManagedObjectNotFoundError(err) {
+				// DVS is missing
+				return nil
+			}
+			return err
+		}
+		if port == nil {
+			return nil
+		}
+		setting, ok := port.Config.Setting.(*types.VMwareDVSPortSetting)
+		if !ok || setting.Vlan == nil {
+			return nil
+		}
+		if !setting.Vlan.GetVmwareDistributedVirtualSwitchVlanSpec().Inherited {
+			return fmt.Errorf("expected VLAN of port %q to be inherited", port.Key)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereDistributedPortHasVlan(expected int32) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		port, err := testGetDistributedPort(s, "port")
+		if err != nil {
+			return err
+		}
+		if port == nil {
+			return fmt.Errorf("port not found")
+		}
+		setting, ok := port.Config.Setting.(*types.VMwareDVSPortSetting)
+		if !ok {
+			return fmt.Errorf("unexpected port setting type %T", port.Config.Setting)
+		}
+		vlan, ok := setting.Vlan.(*types.VmwareDistributedVirtualSwitchVlanIdSpec)
+		if !ok {
+			return fmt.Errorf("unexpected VLAN spec type %T", setting.Vlan)
+		}
+		if vlan.VlanId != expected {
+			return fmt.Errorf("expected VLAN to be %d, got %d", expected, vlan.VlanId)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereDistributedPortConfig returns a configuration that
+// manages the first port of a new DVS without any hosts. Such a DVS has no
+// uplink ports, so the ports of the port group are keyed from 0.
+func testAccResourceVSphereDistributedPortConfig(vlan int, blocked bool) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_distributed_virtual_switch" "dvs" {
+  name          = "terraform-test-dvs"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_distributed_port_group" "pg" {
+  name                            = "terraform-test-pg"
+  distributed_virtual_switch_uuid = "${vsphere_distributed_virtual_switch.dvs.id}"
+  number_of_ports                 = 1
+  block_override_allowed          = true
+  vlan_override_allowed           = true
+}
+
+resource "vsphere_distributed_port" "port" {
+  distributed_virtual_switch_uuid = "${vsphere_distributed_virtual_switch.dvs.id}"
+  port_key                        = "0"
+  name                            = "terraform-test-port"
+  description                     = "Managed by Terraform"
+  vlan_id                         = %d
+  block_all_ports                 = %t
+
+  depends_on = ["vsphere_distributed_port_group.pg"]
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		vlan,
+		blocked,
+	)
+}
@@ -2,13 +2,17 @@ package vsphere
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/customattribute"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/dvportgroup"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/folder"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/vim25/types"
 )
@@ -46,10 +50,90 @@ func resourceVSphereDistributedVirtualSwitch() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			State: resourceVSphereDistributedVirtualSwitchImport,
 		},
-		Schema: s,
+		CustomizeDiff: resourceVSphereDistributedVirtualSwitchCustomizeDiff,
+		Schema:        s,
 	}
 }
 
+func resourceVSphereDistributedVirtualSwitchCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if err := resourceVSphereDistributedVirtualSwitchValidateLacp(d); err != nil {
+		return err
+	}
+	return resourceVSphereDistributedVirtualSwitchValidateMigrations(d, meta)
+}
+
+// resourceVSphereDistributedVirtualSwitchValidateLacp checks that LAGs are
+// only used with the multipleLag LACP API version. vCenter rejects LAGs with
+// singleLag, and the update would otherwise fail partway through, after the
+// LAGs or the version have already been changed.
+func resourceVSphereDistributedVirtualSwitchValidateLacp(d *schema.ResourceDiff) error {
+	if !d.NewValueKnown("lacp_api_version") {
+		return nil
+	}
+	version := d.Get("lacp_api_version").(string)
+	if version == string(types.VMwareDvsLacpApiVersionMultipleLag) {
+		return nil
+	}
+	if len(d.Get("lacp_group").([]interface{})) > 0 {
+		return fmt.Errorf("lacp_group requires lacp_api_version to be %s", types.VMwareDvsLacpApiVersionMultipleLag)
+	}
+	if version != string(types.VMwareDvsLacpApiVersionSingleLag) {
+		return nil
+	}
+	// The LAGs are removed when switching to singleLag, so the uplink teaming
+	// policy cannot still refer to any of them.
+	o, _ := d.GetChange("lacp_group")
+	lags := make(map[string]bool)
+	for _, v := range o.([]interface{}) {
+		if m, ok := v.(map[string]interface{}); ok {
+			lags[m["name"].(string)] = true
+		}
+	}
+	for _, k := range []string{"active_uplinks", "standby_uplinks"} {
+		if !d.NewValueKnown(k) {
+			continue
+		}
+		for _, v := range d.Get(k).([]interface{}) {
+			if name, ok := v.(string); ok && lags[name] {
+				return fmt.Errorf("%s cannot contain LAG %q when lacp_api_version is %s", k, name, types.VMwareDvsLacpApiVersionSingleLag)
+			}
+		}
+	}
+	return nil
+}
+
+// resourceVSphereDistributedVirtualSwitchValidateMigrations checks at plan
+// time that the port groups that virtual NICs are migrated to exist. The port
+// groups are referenced by name, so there is no dependency that would have
+// them created before the migration runs. This means that migrate_vmknics
+// cannot be used when the DVS is created, and that the port groups need to
+// have been created by an earlier apply.
+func resourceVSphereDistributedVirtualSwitchValidateMigrations(d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("host") {
+		return nil
+	}
+	migrations := expandSliceOfDVSHostNetworkMigration(d)
+	if len(migrations) < 1 {
+		return nil
+	}
+	if d.Id() == "" {
+		return errors.New("migrate_vmknics cannot be used when creating a DVS, add it in a later apply, once the port groups to migrate to have been created")
+	}
+	client := meta.(*VSphereClient).vimClient
+	dvs, err := dvsFromUUID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("could not find DVS %q: %s", d.Id(), err)
+	}
+	for _, m := range migrations {
+		for device, name := range m.Vmknics {
+			if _, err := dvsPortgroupFromName(client, dvs, name); err != nil {
+				return fmt.Errorf("cannot migrate %s on host %q: %s. Port groups used in migrate_vmknics must be created in an earlier apply", device, m.HostSystemID, err)
+			}
+		}
+	}
+	return nil
+}
+
 func resourceVSphereDistributedVirtualSwitchCreate(d *schema.ResourceData, meta interface{}) error {
 	client := meta.(*VSphereClient).vimClient
 	if err := viapi.ValidateVirtualCenter(client); err != nil {
@@ -77,6 +161,14 @@ func resourceVSphereDistributedVirtualSwitchCreate(d *schema.ResourceData, meta
 	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
 	defer cancel()
 	spec := expandDVSCreateSpec(d)
+	// LAGs can only be added once the DVS exists, so the uplink order, which
+	// may reference them, is applied after they have been added.
+	lacpAdd, _ := expandSliceOfVMwareDvsLacpGroupSpec(d)
+	if len(lacpAdd) > 0 {
+		if tp := spec.ConfigSpec.(*types.VMwareDVSConfigSpec).DefaultPortConfig.(*types.VMwareDVSPortSetting).UplinkTeamingPolicy; tp != nil {
+			tp.UplinkPortOrder = nil
+		}
+	}
 	task, err := fo.CreateDVS(ctx, spec)
 	if err != nil {
 		return fmt.Errorf("error creating DVS: %s", err)
@@ -104,6 +196,27 @@ func resourceVSphereDistributedVirtualSwitchCreate(d *schema.ResourceData, meta
 		enableDVSNetworkResourceManagement(client, dvs, true)
 	}
 
+	// Add any LAGs, and then apply the uplink order that was left out of the
+	// create spec.
+	if len(lacpAdd) > 0 {
+		if err := updateDVSLacpGroupConfig(client, dvs, lacpAdd); err != nil {
+			return fmt.Errorf("error adding LACP groups: %s", err)
+		}
+		version, err := resourceVSphereDistributedVirtualSwitchConfigVersion(dvs)
+		if err != nil {
+			return err
+		}
+		spec := &types.VMwareDVSConfigSpec{
+			DVSConfigSpec: types.DVSConfigSpec{
+				ConfigVersion:     version,
+				DefaultPortConfig: expandVMwareDVSPortSetting(d),
+			},
+		}
+		if err := updateDVSConfiguration(client, dvs, spec); err != nil {
+			return fmt.Errorf("could not update DVS uplink order: %s", err)
+		}
+	}
+
 	// Apply any pending tags now
 	if tagsClient != nil {
 		if err := processTagDiff(tagsClient, d, object.NewReference(client.Client, dvs.Reference())); err != nil {
@@ -223,11 +336,69 @@ func resourceVSphereDistributedVirtualSwitchUpdate(d *schema.ResourceData, meta
 		d.Set("config_version", props.Config.(*types.VMwareDVSConfigInfo).ConfigVersion)
 	}
 
+	// LAGs can only exist when the LACP API version is multipleLag. When
+	// switching to singleLag, the LAGs are removed before the version is
+	// changed. Otherwise, the version is changed before any LAGs are added.
+	lacpAddEdit, lacpRemove := expandSliceOfVMwareDvsLacpGroupSpec(d)
+	if d.HasChange("lacp_api_version") && d.Get("lacp_api_version").(string) == string(types.VMwareDvsLacpApiVersionSingleLag) && len(lacpRemove) > 0 {
+		if err := updateDVSLacpGroupConfig(client, dvs, lacpRemove); err != nil {
+			return fmt.Errorf("error removing LACP groups: %s", err)
+		}
+		lacpRemove = nil
+		version, err := resourceVSphereDistributedVirtualSwitchConfigVersion(dvs)
+		if err != nil {
+			return err
+		}
+		d.Set("config_version", version)
+	}
+	if d.HasChange("lacp_api_version") {
+		spec := &types.VMwareDVSConfigSpec{
+			DVSConfigSpec: types.DVSConfigSpec{
+				ConfigVersion: d.Get("config_version").(string),
+			},
+			LacpApiVersion: d.Get("lacp_api_version").(string),
+		}
+		if err := updateDVSConfiguration(client, dvs, spec); err != nil {
+			return fmt.Errorf("could not update DVS LACP API version: %s", err)
+		}
+		version, err := resourceVSphereDistributedVirtualSwitchConfigVersion(dvs)
+		if err != nil {
+			return err
+		}
+		d.Set("config_version", version)
+	}
+
+	// Add and edit LAGs before the configuration update so that they can be
+	// referenced in the uplink order, and remove them afterwards, once they are
+	// no longer referenced.
+	if len(lacpAddEdit) > 0 {
+		if err := updateDVSLacpGroupConfig(client, dvs, lacpAddEdit); err != nil {
+			return fmt.Errorf("error updating LACP groups: %s", err)
+		}
+		version, err := resourceVSphereDistributedVirtualSwitchConfigVersion(dvs)
+		if err != nil {
+			return err
+		}
+		d.Set("config_version", version)
+	}
+
 	spec := expandVMwareDVSConfigSpec(d)
 	if err := updateDVSConfiguration(client, dvs, spec); err != nil {
 		return fmt.Errorf("could not update DVS: %s", err)
 	}
 
+	// Move the physical and virtual NICs of hosts with virtual NICs to migrate
+	// now that the hosts are members of the DVS.
+	if err := resourceVSphereDistributedVirtualSwitchMigrateHostNetworking(client, dvs, expandSliceOfDVSHostNetworkMigration(d)); err != nil {
+		return err
+	}
+
+	if len(lacpRemove) > 0 {
+		if err := updateDVSLacpGroupConfig(client, dvs, lacpRemove); err != nil {
+			return fmt.Errorf("error removing LACP groups: %s", err)
+		}
+	}
+
 	// Modify network I/O control if necessary
 	if d.HasChange("network_resource_control_enabled") {
 		enableDVSNetworkResourceManagement(client, dvs, d.Get("network_resource_control_enabled").(bool))
@@ -296,3 +467,61 @@ func resourceVSphereDistributedVirtualSwitchImport(d *schema.ResourceData, meta
 	d.SetId(props.Uuid)
 	return []*schema.ResourceData{d}, nil
 }
+
+// resourceVSphereDistributedVirtualSwitchConfigVersion fetches the current
+// configuration version of a DVS. The configuration version increments with
+// each change to the DVS, so this needs to be refreshed after operations that
+// happen outside of the main configuration update, to ensure that we don't
+// run into ConcurrentAccess errors.
+func resourceVSphereDistributedVirtualSwitchConfigVersion(dvs *object.VmwareDistributedVirtualSwitch) (string, error) {
+	props, err := dvsProperties(dvs)
+	if err != nil {
+		return "", fmt.Errorf("could not get DVS properties: %s", err)
+	}
+	return props.Config.GetDVSConfigInfo().ConfigVersion, nil
+}
+
+// resourceVSphereDistributedVirtualSwitchMigrateHostNetworking migrates the
+// physical and virtual NICs of the supplied hosts to the DVS. The port groups
+// that the virtual NICs are migrated to are looked up by name on the DVS.
+func resourceVSphereDistributedVirtualSwitchMigrateHostNetworking(
+	client *govmomi.Client,
+	dvs *object.VmwareDistributedVirtualSwitch,
+	migrations []dvsHostNetworkMigration,
+) error {
+	if len(migrations) < 1 {
+		return nil
+	}
+	props, err := dvsProperties(dvs)
+	if err != nil {
+		return fmt.Errorf("error fetching DVS properties: %s", err)
+	}
+	pgKeys := make(map[string]string)
+	for _, ref := range props.Portgroup {
+		pg, err := dvportgroup.FromMOID(client, ref.Value)
+		if err != nil {
+			return fmt.Errorf("error locating port group %q: %s", ref.Value, err)
+		}
+		pgProps, err := dvportgroup.Properties(pg)
+		if err != nil {
+			return fmt.Errorf("error fetching port group properties: %s", err)
+		}
+		pgKeys[pgProps.Config.Name] = pgProps.Key
+	}
+
+	for _, m := range migrations {
+		vnics := make(map[string]string)
+		for device, name := range m.Vmknics {
+			key, ok := pgKeys[name]
+			if !ok {
+				return fmt.Errorf("cannot migrate %s on host %q: port group %q not found on DVS %q", device, m.HostSystemID, name, props.Name)
+			}
+			vnics[device] = key
+		}
+		log.Printf("[DEBUG] Migrating %v and %v on host %q to DVS %q", m.Devices, m.Vmknics, m.HostSystemID, props.Name)
+		if err := migrateHostNetworkToDVS(client, m.HostSystemID, props.Uuid, m.Devices, vnics); err != nil {
+			return fmt.Errorf("error migrating network of host %q: %s", m.HostSystemID, err)
+		}
+	}
+	return nil
+}
@@ -1,17 +1,21 @@
 package vsphere
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"path"
 	"reflect"
+	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/folder"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 )
 
@@ -362,6 +366,107 @@ func TestAccResourceVSphereDistributedVirtualSwitch_vlanRanges(t *testing.T) {
 	})
 }
 
+func TestAccResourceVSphereDistributedVirtualSwitch_pvlanMapping(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereDistributedVirtualSwitchPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereDistributedVirtualSwitchExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereDistributedVirtualSwitchConfigPvlanMapping(1002),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereDistributedVirtualSwitchExists(true),
+					testAccResourceVSphereDistributedVirtualSwitchHasPvlanMapping(1000, 1000, "promiscuous"),
+					testAccResourceVSphereDistributedVirtualSwitchHasPvlanMapping(1000, 1001, "isolated"),
+					testAccResourceVSphereDistributedVirtualSwitchHasPvlanMapping(1000, 1002, "community"),
+					resource.TestCheckResourceAttr("vsphere_distributed_virtual_switch.dvs", "pvlan_mapping.#", "3"),
+					resource.TestCheckResourceAttr("vsphere_distributed_port_group.pg", "port_private_secondary_vlan_id", "1001"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereDistributedVirtualSwitchConfigPvlanMapping(1003),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereDistributedVirtualSwitchExists(true),
+					testAccResourceVSphereDistributedVirtualSwitchHasPvlanMapping(1000, 1003, "community"),
+					resource.TestCheckResourceAttr("vsphere_distributed_virtual_switch.dvs", "pvlan_mapping.#", "3"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereDistributedVirtualSwitch_lacpGroup(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereDistributedVirtualSwitchPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereDistributedVirtualSwitchExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereDistributedVirtualSwitchConfigLacpGroup(2, "active"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereDistributedVirtualSwitchExists(true),
+					testAccResourceVSphereDistributedVirtualSwitchHasLacpGroup("terraform-test-lag", 2, "active"),
+					testAccResourceVSphereDistributedVirtualSwitchHasActiveUplinks([]string{"terraform-test-lag"}),
+					resource.TestCheckResourceAttr("vsphere_distributed_virtual_switch.dvs", "lacp_group.0.uplinks.#", "2"),
+					resource.TestCheckResourceAttr("vsphere_distributed_port_group.pg", "active_uplinks.0", "terraform-test-lag"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereDistributedVirtualSwitchConfigLacpGroup(4, "passive"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereDistributedVirtualSwitchExists(true),
+					testAccResourceVSphereDistributedVirtualSwitchHasLacpGroup("terraform-test-lag", 4, "passive"),
+					resource.TestCheckResourceAttr("vsphere_distributed_virtual_switch.dvs", "lacp_group.0.uplinks.#", "4"),
+				),
+			},
+			{
+				Config:      testAccResourceVSphereDistributedVirtualSwitchConfigLacpSingleLag(),
+				ExpectError: regexp.MustCompile(`active_uplinks cannot contain LAG "terraform-test-lag"`),
+				PlanOnly:    true,
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereDistributedVirtualSwitch_migrateVmknics(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereDistributedVirtualSwitchPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereDistributedVirtualSwitchExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereDistributedVirtualSwitchConfigMigrateVmknics(false, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereDistributedVirtualSwitchExists(true),
+				),
+			},
+			{
+				Config: testAccResourceVSphereDistributedVirtualSwitchConfigMigrateVmknics(true, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereDistributedVirtualSwitchExists(true),
+					testAccResourceVSphereDistributedVirtualSwitchHasVmknic("v1"),
+				),
+			},
+			{
+				// Remove the virtual NIC so that the DVS can be destroyed.
+				Config: testAccResourceVSphereDistributedVirtualSwitchConfigMigrateVmknics(true, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereDistributedVirtualSwitchExists(true),
+				),
+			},
+		},
+	})
+}
+
 func TestAccResourceVSphereDistributedVirtualSwitch_singleCustomAttribute(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
@@ -561,6 +666,86 @@ func testAccResourceVSphereDistributedVirtualSwitchHasNetflow() resource.TestChe
 	}
 }
 
+func testAccResourceVSphereDistributedVirtualSwitchHasPvlanMapping(primary, secondary int32, pvlanType string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetDVSProperties(s, "dvs")
+		if err != nil {
+			return err
+		}
+		for _, entry := range props.Config.(*types.VMwareDVSConfigInfo).PvlanConfig {
+			if entry.PrimaryVlanId == primary && entry.SecondaryVlanId == secondary {
+				if entry.PvlanType != pvlanType {
+					return fmt.Errorf("expected PVLAN %d/%d to be of type %s, got %s", primary, secondary, pvlanType, entry.PvlanType)
+				}
+				return nil
+			}
+		}
+		return fmt.Errorf("could not find PVLAN mapping %d/%d", primary, secondary)
+	}
+}
+
+func testAccResourceVSphereDistributedVirtualSwitchHasLacpGroup(name string, uplinks int32, mode string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetDVSProperties(s, "dvs")
+		if err != nil {
+			return err
+		}
+		for _, lag := range props.Config.(*types.VMwareDVSConfigInfo).LacpGroupConfig {
+			if lag.Name != name {
+				continue
+			}
+			if lag.UplinkNum != uplinks {
+				return fmt.Errorf("expected LAG %s to have %d uplinks, got %d", name, uplinks, lag.UplinkNum)
+			}
+			if lag.Mode != mode {
+				return fmt.Errorf("expected LAG %s to be in mode %s, got %s", name, mode, lag.Mode)
+			}
+			return nil
+		}
+		return fmt.Errorf("could not find LAG %s", name)
+	}
+}
+
+// testAccResourceVSphereDistributedVirtualSwitchHasVmknic checks that the
+// virtual NIC of the supplied vsphere_vnic resource is connected to the DVS.
+func testAccResourceVSphereDistributedVirtualSwitchHasVmknic(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetDVSProperties(s, "dvs")
+		if err != nil {
+			return err
+		}
+		rs, ok := s.RootModule().Resources[fmt.Sprintf("vsphere_vnic.%s", name)]
+		if !ok {
+			return fmt.Errorf("vsphere_vnic.%s not found in state", name)
+		}
+		parts := strings.SplitN(rs.Primary.ID, "_", 2)
+		if len(parts) < 2 {
+			return fmt.Errorf("bad vsphere_vnic ID %q", rs.Primary.ID)
+		}
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		ns, err := hostNetworkSystemFromHostSystemID(client, parts[0])
+		if err != nil {
+			return err
+		}
+		var mns mo.HostNetworkSystem
+		ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+		defer cancel()
+		if err := client.PropertyCollector().RetrieveOne(ctx, ns.Reference(), []string{"networkInfo.vnic"}, &mns); err != nil {
+			return err
+		}
+		for _, vnic := range mns.NetworkInfo.Vnic {
+			if vnic.Device != parts[1] {
+				continue
+			}
+			if vnic.Spec.DistributedVirtualPort == nil || vnic.Spec.DistributedVirtualPort.SwitchUuid != props.Uuid {
+				return fmt.Errorf("expected %s to be connected to DVS %q", vnic.Device, props.Uuid)
+			}
+			return nil
+		}
+		return fmt.Errorf("could not find virtual NIC %s", parts[1])
+	}
+}
+
 func testAccResourceVSphereDistributedVirtualSwitchHasVlanRange(emin, emax int32) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		props, err := testGetDVSProperties(s, "dvs")
@@ -1150,6 +1335,211 @@ resource "vsphere_distributed_virtual_switch" "dvs" {
 	)
 }
 
+func testAccResourceVSphereDistributedVirtualSwitchConfigPvlanMapping(community int) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_distributed_virtual_switch" "dvs" {
+  name          = "terraform-test-dvs"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+
+  pvlan_mapping {
+    primary_vlan_id   = 1000
+    secondary_vlan_id = 1000
+    pvlan_type        = "promiscuous"
+  }
+
+  pvlan_mapping {
+    primary_vlan_id   = 1000
+    secondary_vlan_id = 1001
+    pvlan_type        = "isolated"
+  }
+
+  pvlan_mapping {
+    primary_vlan_id   = 1000
+    secondary_vlan_id = %d
+    pvlan_type        = "community"
+  }
+}
+
+resource "vsphere_distributed_port_group" "pg" {
+  name                            = "terraform-test-pg"
+  distributed_virtual_switch_uuid = "${vsphere_distributed_virtual_switch.dvs.id}"
+  port_private_secondary_vlan_id  = 1001
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		community,
+	)
+}
+
+func testAccResourceVSphereDistributedVirtualSwitchConfigLacpGroup(uplinks int, mode string) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_distributed_virtual_switch" "dvs" {
+  name             = "terraform-test-dvs"
+  datacenter_id    = "${data.vsphere_datacenter.dc.id}"
+  lacp_api_version = "multipleLag"
+
+  lacp_group {
+    name                     = "terraform-test-lag"
+    uplink_count             = %d
+    mode                     = "%s"
+    load_balancing_algorithm = "srcDestIpTcpUdpPort"
+  }
+
+  active_uplinks = ["terraform-test-lag"]
+}
+
+resource "vsphere_distributed_port_group" "pg" {
+  name                            = "terraform-test-pg"
+  distributed_virtual_switch_uuid = "${vsphere_distributed_virtual_switch.dvs.id}"
+  active_uplinks                  = ["terraform-test-lag"]
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		uplinks,
+		mode,
+	)
+}
+
+// testAccResourceVSphereDistributedVirtualSwitchConfigLacpSingleLag returns a
+// configuration that switches the DVS in the LAG configuration to singleLag
+// while the LAG is still used as an active uplink.
+func testAccResourceVSphereDistributedVirtualSwitchConfigLacpSingleLag() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_distributed_virtual_switch" "dvs" {
+  name             = "terraform-test-dvs"
+  datacenter_id    = "${data.vsphere_datacenter.dc.id}"
+  lacp_api_version = "singleLag"
+  active_uplinks   = ["terraform-test-lag"]
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+	)
+}
+
+// testAccResourceVSphereDistributedVirtualSwitchConfigMigrateVmknics returns a
+// configuration with a virtual NIC on a standard switch. When migrate is true,
+// the physical NIC of the standard switch and the virtual NIC are migrated to
+// the DVS. The standard switch and virtual NIC ignore the resulting changes.
+// When vnic is false, the virtual NIC is removed so that the DVS can be
+// destroyed.
+func testAccResourceVSphereDistributedVirtualSwitchConfigMigrateVmknics(migrate, vnic bool) string {
+	var hostBlock, vnicBlock string
+	if vnic {
+		vnicBlock = `
+resource "vsphere_vnic" "v1" {
+  host      = "${data.vsphere_host.host.id}"
+  portgroup = "${vsphere_host_port_group.pg.name}"
+
+  ipv4 {
+    dhcp = true
+  }
+
+  lifecycle {
+    ignore_changes = ["portgroup", "distributed_switch_port", "distributed_port_group"]
+  }
+}
+`
+	}
+	if migrate {
+		var migrateBlock string
+		if vnic {
+			migrateBlock = `
+    migrate_vmknics {
+      device         = "${element(split("_", vsphere_vnic.v1.id), 1)}"
+      portgroup_name = "terraform-test-pg"
+    }
+`
+		}
+		hostBlock = fmt.Sprintf(`
+  host {
+    host_system_id = "${data.vsphere_host.host.id}"
+    devices        = ["${var.network_interface}"]
+%s  }
+`, migrateBlock)
+	}
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "esxi_host" {
+  default = "%s"
+}
+
+variable "network_interface" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_host" "host" {
+  name          = "${var.esxi_host}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_host_virtual_switch" "switch" {
+  name           = "vSwitchTerraformTest"
+  host_system_id = "${data.vsphere_host.host.id}"
+
+  network_adapters = ["${var.network_interface}"]
+  active_nics      = ["${var.network_interface}"]
+  standby_nics     = []
+
+  lifecycle {
+    ignore_changes = ["network_adapters", "active_nics"]
+  }
+}
+
+resource "vsphere_host_port_group" "pg" {
+  name                = "PGTerraformTest"
+  host_system_id      = "${data.vsphere_host.host.id}"
+  virtual_switch_name = "${vsphere_host_virtual_switch.switch.name}"
+}
+%s
+resource "vsphere_distributed_virtual_switch" "dvs" {
+  name          = "terraform-test-dvs"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+%s}
+
+resource "vsphere_distributed_port_group" "pg" {
+  name                            = "terraform-test-pg"
+  distributed_virtual_switch_uuid = "${vsphere_distributed_virtual_switch.dvs.id}"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_ESXI_HOST"),
+		os.Getenv("VSPHERE_HOST_NIC1"),
+		vnicBlock,
+		hostBlock,
+	)
+}
+
 func testAccResourceVSphereDistributedVirtualSwitchConfigMultiVlanRange() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
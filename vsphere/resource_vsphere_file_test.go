@@ -30,7 +30,7 @@ func TestAccResourceVSphereFile_basic(t *testing.T) {
 	destinationFile := "tf_file_test.vmdk"
 	sourceFile := testVmdkFile
 
-	resource.Test(t, resource.TestCase{
+	testAccResourceTest(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccCheckEnvVariables(t, []string{"VSPHERE_DATACENTER", "VSPHERE_DATASTORE"})
@@ -77,7 +77,7 @@ func TestAccResourceVSphereFile_basicUploadAndCopy(t *testing.T) {
 		return
 	}
 
-	resource.Test(t, resource.TestCase{
+	testAccResourceTest(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccCheckEnvVariables(t, []string{"VSPHERE_DATACENTER", "VSPHERE_DATASTORE"})
@@ -131,7 +131,7 @@ func TestAccResourceVSphereFile_renamePostCreation(t *testing.T) {
 	destinationFileMoved := "tf_test_file_moved.vmdk"
 	sourceFile := testVmdkFile
 
-	resource.Test(t, resource.TestCase{
+	testAccResourceTest(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccCheckEnvVariables(t, []string{"VSPHERE_DATACENTER", "VSPHERE_DATASTORE"})
@@ -195,7 +195,7 @@ func TestAccResourceVSphereFile_uploadAndCopyAndUpdate(t *testing.T) {
 		return
 	}
 
-	resource.Test(t, resource.TestCase{
+	testAccResourceTest(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccCheckEnvVariables(t, []string{"VSPHERE_DATACENTER", "VSPHERE_DATASTORE"})
@@ -10,6 +10,7 @@ import (
 	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/clustercomputeresource"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
 	"github.com/vmware/govmomi/license"
 	"github.com/vmware/govmomi/object"
@@ -92,10 +93,66 @@ func resourceVsphereHost() *schema.Resource {
 				Default:      "disabled",
 				ValidateFunc: validation.StringInSlice([]string{"disabled", "normal", "strict"}, true),
 			},
+			"ntp_servers": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Computed:    true,
+				Description: "The NTP servers of the host. If not set, the NTP servers on the host are left as they are.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"service": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "The startup policy and running state of services on the host. Services that are not in this set are left as they are.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"key": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The key of the service, ie: TSM-SSH or ntpd.",
+						},
+						"policy": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The startup policy of the service. Valid options are 'on', 'off', 'automatic'.",
+							ValidateFunc: validation.StringInSlice(
+								[]string{
+									string(types.HostServicePolicyOn),
+									string(types.HostServicePolicyOff),
+									string(types.HostServicePolicyAutomatic),
+								},
+								false,
+							),
+						},
+						"running": {
+							Type:        schema.TypeBool,
+							Required:    true,
+							Description: "Whether or not the service is running.",
+						},
+					},
+				},
+			},
+			"syslog": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The remote syslog target of the host, ie: udp://10.0.0.1:514. If not set, the syslog target on the host is left as it is.",
+			},
+			"advanced_options": {
+				Type:             schema.TypeMap,
+				Optional:         true,
+				Description:      "Advanced settings of the host, keyed by setting name. Settings that are removed from this map are left as they are on the host.",
+				Elem:             &schema.Schema{Type: schema.TypeString},
+				DiffSuppressFunc: hostOptionValueDiffSuppressFunc,
+			},
 		},
 	}
 }
 
+// hostSyslogOptionKey is the advanced setting that holds the remote syslog
+// target of a host.
+const hostSyslogOptionKey = "Syslog.global.logHost"
+
 func resourceVsphereHostRead(d *schema.ResourceData, meta interface{}) error {
 
 	// NOTE: Destroying the host without telling vsphere about it will result in us not
@@ -167,6 +224,10 @@ func resourceVsphereHostRead(d *schema.ResourceData, meta interface{}) error {
 		}
 	}
 
+	if err := resourceVSphereHostReadConfig(d, meta); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -283,6 +344,22 @@ func resourceVsphereHostCreate(d *schema.ResourceData, meta interface{}) error {
 		if err != nil {
 			return fmt.Errorf("error while changing lockdown mode for host %s. Error: %s", hostID, err)
 		}
+
+		configKeys := map[string]func(*schema.ResourceData, interface{}, interface{}, interface{}) error{
+			"ntp_servers":      resourceVSphereHostUpdateNtpServers,
+			"service":          resourceVSphereHostUpdateServices,
+			"syslog":           resourceVSphereHostUpdateSyslog,
+			"advanced_options": resourceVSphereHostUpdateAdvancedOptions,
+		}
+		for k, v := range configKeys {
+			newVal, ok := d.GetOk(k)
+			if !ok {
+				continue
+			}
+			if err := v(d, meta, nil, newVal); err != nil {
+				return fmt.Errorf("error while setting %s for host %s. Error: %s", k, hostID, err)
+			}
+		}
 	}
 
 	maintenanceMode := d.Get("maintenance").(bool)
@@ -364,6 +441,22 @@ func resourceVsphereHostUpdate(d *schema.ResourceData, meta interface{}) error {
 		"lockdown":    resourceVSphereHostUpdateLockdownMode,
 		"thumbprint":  resourceVSphereHostUpdateThumbprint,
 	}
+	// The configuration of the host cannot be changed while it is
+	// disconnected. These are applied once the host is connected again, as
+	// the next refresh reads them from the host and shows a diff.
+	configKeys := map[string]func(*schema.ResourceData, interface{}, interface{}, interface{}) error{
+		"ntp_servers":      resourceVSphereHostUpdateNtpServers,
+		"service":          resourceVSphereHostUpdateServices,
+		"syslog":           resourceVSphereHostUpdateSyslog,
+		"advanced_options": resourceVSphereHostUpdateAdvancedOptions,
+	}
+	for k, v := range configKeys {
+		if desiredConnectionState {
+			mutableKeys[k] = v
+		} else if d.HasChange(k) {
+			log.Printf("[DEBUG] Host %s is disconnected, not updating %s", hostID, k)
+		}
+	}
 	for k, v := range mutableKeys {
 		log.Printf("[DEBUG] Checking if key %s changed", k)
 		if !d.HasChange(k) {
@@ -533,6 +626,148 @@ func resourceVSphereHostUpdateCluster(d *schema.ResourceData, meta, old, newVal
 	return nil
 }
 
+func resourceVSphereHostUpdateNtpServers(d *schema.ResourceData, meta, old, newVal interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	dts, err := hostDateTimeSystemFromHostSystemID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("error while retrieving date time system for host %s. Error: %s", d.Id(), err)
+	}
+	servers := structure.SliceInterfacesToStrings(newVal.([]interface{}))
+	if err := updateHostNtpServers(dts, servers); err != nil {
+		return fmt.Errorf("error while updating NTP servers for host %s. Error: %s", d.Id(), err)
+	}
+
+	// ntpd only reads its configuration when it starts, so it is restarted to
+	// pick up the new servers.
+	ss, err := hostServiceSystemFromHostSystemID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("error while retrieving service system for host %s. Error: %s", d.Id(), err)
+	}
+	services, err := hostServices(ss)
+	if err != nil {
+		return fmt.Errorf("error while retrieving services for host %s. Error: %s", d.Id(), err)
+	}
+	if ntpd, ok := services["ntpd"]; ok && ntpd.Running {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+		defer cancel()
+		if err := ss.Restart(ctx, ntpd.Key); err != nil {
+			return fmt.Errorf("error while restarting ntpd on host %s. Error: %s", d.Id(), err)
+		}
+	}
+	return nil
+}
+
+func resourceVSphereHostUpdateServices(d *schema.ResourceData, meta, old, newVal interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	ss, err := hostServiceSystemFromHostSystemID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("error while retrieving service system for host %s. Error: %s", d.Id(), err)
+	}
+	services, err := hostServices(ss)
+	if err != nil {
+		return fmt.Errorf("error while retrieving services for host %s. Error: %s", d.Id(), err)
+	}
+	for _, v := range newVal.(*schema.Set).List() {
+		service := v.(map[string]interface{})
+		key := service["key"].(string)
+		current, ok := services[key]
+		if !ok {
+			return fmt.Errorf("service %q not found on host %s", key, d.Id())
+		}
+		if err := updateHostService(ss, current, service["policy"].(string), service["running"].(bool)); err != nil {
+			return fmt.Errorf("error while updating service %q on host %s. Error: %s", key, d.Id(), err)
+		}
+	}
+	return nil
+}
+
+func resourceVSphereHostUpdateSyslog(d *schema.ResourceData, meta, old, newVal interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	om, err := hostOptionManagerFromHostSystemID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("error while retrieving option manager for host %s. Error: %s", d.Id(), err)
+	}
+	return updateHostOptionValues(om, map[string]string{hostSyslogOptionKey: newVal.(string)})
+}
+
+func resourceVSphereHostUpdateAdvancedOptions(d *schema.ResourceData, meta, old, newVal interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	om, err := hostOptionManagerFromHostSystemID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("error while retrieving option manager for host %s. Error: %s", d.Id(), err)
+	}
+	values := make(map[string]string)
+	for k, v := range newVal.(map[string]interface{}) {
+		values[k] = v.(string)
+	}
+	return updateHostOptionValues(om, values)
+}
+
+// resourceVSphereHostReadConfig reads the NTP servers, services, syslog
+// target, and advanced settings of the host. Only the services and advanced
+// settings that are in the configuration are read.
+func resourceVSphereHostReadConfig(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hostID := d.Id()
+
+	dts, err := hostDateTimeSystemFromHostSystemID(client, hostID)
+	if err != nil {
+		return fmt.Errorf("error while retrieving date time system for host %s. Error: %s", hostID, err)
+	}
+	servers, err := hostNtpServers(dts)
+	if err != nil {
+		return fmt.Errorf("error while retrieving NTP servers for host %s. Error: %s", hostID, err)
+	}
+	if err := d.Set("ntp_servers", servers); err != nil {
+		return fmt.Errorf("error while setting ntp_servers for host %s. Error: %s", hostID, err)
+	}
+
+	ss, err := hostServiceSystemFromHostSystemID(client, hostID)
+	if err != nil {
+		return fmt.Errorf("error while retrieving service system for host %s. Error: %s", hostID, err)
+	}
+	services, err := hostServices(ss)
+	if err != nil {
+		return fmt.Errorf("error while retrieving services for host %s. Error: %s", hostID, err)
+	}
+	var serviceList []interface{}
+	for _, v := range d.Get("service").(*schema.Set).List() {
+		key := v.(map[string]interface{})["key"].(string)
+		if service, ok := services[key]; ok {
+			serviceList = append(serviceList, map[string]interface{}{
+				"key":     service.Key,
+				"policy":  service.Policy,
+				"running": service.Running,
+			})
+		}
+	}
+	if err := d.Set("service", serviceList); err != nil {
+		return fmt.Errorf("error while setting service for host %s. Error: %s", hostID, err)
+	}
+
+	om, err := hostOptionManagerFromHostSystemID(client, hostID)
+	if err != nil {
+		return fmt.Errorf("error while retrieving option manager for host %s. Error: %s", hostID, err)
+	}
+	keys := []string{hostSyslogOptionKey}
+	for k := range d.Get("advanced_options").(map[string]interface{}) {
+		keys = append(keys, k)
+	}
+	values, err := hostOptionValues(om, keys)
+	if err != nil {
+		return fmt.Errorf("error while retrieving advanced settings for host %s. Error: %s", hostID, err)
+	}
+	d.Set("syslog", values[hostSyslogOptionKey])
+	options := make(map[string]interface{})
+	for k := range d.Get("advanced_options").(map[string]interface{}) {
+		options[k] = values[k]
+	}
+	if err := d.Set("advanced_options", options); err != nil {
+		return fmt.Errorf("error while setting advanced_options for host %s. Error: %s", hostID, err)
+	}
+	return nil
+}
+
 func resourceVSphereHostUpdateThumbprint(d *schema.ResourceData, meta, old, newVal interface{}) error {
 	return resourceVSphereHostReconnect(d, meta)
 }
@@ -712,6 +947,9 @@ func validateFields(d *schema.ResourceData) error {
 	if dcSet && clusterSet {
 		return fmt.Errorf("datacenter and cluster arguments are mutually exclusive")
 	}
+	if _, ok := d.Get("advanced_options").(map[string]interface{})[hostSyslogOptionKey]; ok {
+		return fmt.Errorf("use the syslog argument to set %s", hostSyslogOptionKey)
+	}
 	return nil
 }
 
@@ -0,0 +1,346 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const hostFirewallRulesetIDPrefix = "tf-HostFirewallRuleset"
+
+func resourceVSphereHostFirewallRuleset() *schema.Resource {
+	return &schema.Resource{
+		Create:        resourceVSphereHostFirewallRulesetCreate,
+		Read:          resourceVSphereHostFirewallRulesetRead,
+		Update:        resourceVSphereHostFirewallRulesetUpdate,
+		Delete:        resourceVSphereHostFirewallRulesetDelete,
+		CustomizeDiff: resourceVSphereHostFirewallRulesetCustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereHostFirewallRulesetImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"host_system_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the host the ruleset is on.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"key": {
+				Type:        schema.TypeString,
+				Description: "The key of the firewall ruleset, ie: sshServer.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"enabled": {
+				Type:        schema.TypeBool,
+				Description: "Whether or not the ruleset is enabled.",
+				Optional:    true,
+				Default:     true,
+			},
+			"allowed_all_ip": {
+				Type:          schema.TypeBool,
+				Description:   "Allow connections from all IP addresses. Cannot be set together with allowed_ip_addresses or allowed_networks, which turn this off when they are set.",
+				Optional:      true,
+				Default:       true,
+				ConflictsWith: []string{"allowed_ip_addresses", "allowed_networks"},
+				// Setting either list turns off access from all IP addresses, so the
+				// default is not compared against the value read from the host then.
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					return hostFirewallRulesetHasIPList(d)
+				},
+			},
+			"allowed_ip_addresses": {
+				Type:        schema.TypeSet,
+				Description: "The IP addresses that are allowed to connect through the ruleset.",
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.SingleIP(),
+				},
+			},
+			"allowed_networks": {
+				Type:        schema.TypeSet,
+				Description: "The networks, in CIDR notation, that are allowed to connect through the ruleset. The network address cannot have any host bits set, ie: 10.0.0.0/24, not 10.0.0.5/24.",
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateHostFirewallRulesetNetwork,
+				},
+			},
+			"label": {
+				Type:        schema.TypeString,
+				Description: "The display label of the ruleset.",
+				Computed:    true,
+			},
+			"required": {
+				Type:        schema.TypeBool,
+				Description: "Whether or not the ruleset is required by the host and cannot be disabled.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceVSphereHostFirewallRulesetCreate(d *schema.ResourceData, meta interface{}) error {
+	hsID := d.Get("host_system_id").(string)
+	key := d.Get("key").(string)
+	saveHostFirewallRulesetID(d, hsID, key)
+	if err := resourceVSphereHostFirewallRulesetApply(d, meta); err != nil {
+		d.SetId("")
+		return err
+	}
+	return resourceVSphereHostFirewallRulesetRead(d, meta)
+}
+
+func resourceVSphereHostFirewallRulesetRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hsID, key, err := splitHostFirewallRulesetID(d.Id())
+	if err != nil {
+		return err
+	}
+	fs, err := hostFirewallSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		if viapi.IsManagedObjectNotFoundError(err) {
+			log.Printf("[DEBUG] Host %q for firewall ruleset %q not found, removing from state", hsID, key)
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("error loading host firewall system: %s", err)
+	}
+	ruleset, err := hostFirewallRulesetFromKey(fs, key)
+	if err != nil {
+		return fmt.Errorf("error fetching firewall ruleset: %s", err)
+	}
+
+	d.Set("host_system_id", hsID)
+	d.Set("key", ruleset.Key)
+	d.Set("label", ruleset.Label)
+	d.Set("required", ruleset.Required)
+	d.Set("enabled", ruleset.Enabled)
+	return flattenHostFirewallRulesetIPList(d, ruleset.AllowedHosts)
+}
+
+func resourceVSphereHostFirewallRulesetUpdate(d *schema.ResourceData, meta interface{}) error {
+	if err := resourceVSphereHostFirewallRulesetApply(d, meta); err != nil {
+		return err
+	}
+	return resourceVSphereHostFirewallRulesetRead(d, meta)
+}
+
+func resourceVSphereHostFirewallRulesetDelete(d *schema.ResourceData, meta interface{}) error {
+	// Firewall rulesets are part of the host and cannot be removed. The ruleset
+	// is left as it is so that destroying the resource does not open up access
+	// to the host.
+	return nil
+}
+
+func resourceVSphereHostFirewallRulesetCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	// Required rulesets cannot be disabled. Check this at plan time, looking
+	// the ruleset up on the host when it is not in state yet.
+	if d.Get("enabled").(bool) || !d.NewValueKnown("host_system_id") || !d.NewValueKnown("key") {
+		return nil
+	}
+	required := d.Get("required").(bool)
+	if d.Id() == "" {
+		client := meta.(*VSphereClient).vimClient
+		fs, err := hostFirewallSystemFromHostSystemID(client, d.Get("host_system_id").(string))
+		if err != nil {
+			return fmt.Errorf("error loading host firewall system: %s", err)
+		}
+		ruleset, err := hostFirewallRulesetFromKey(fs, d.Get("key").(string))
+		if err != nil {
+			return fmt.Errorf("error fetching firewall ruleset: %s", err)
+		}
+		required = ruleset.Required
+	}
+	if required {
+		return fmt.Errorf("firewall ruleset %q is required by the host and cannot be disabled", d.Get("key").(string))
+	}
+	return nil
+}
+
+func resourceVSphereHostFirewallRulesetImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	s := strings.SplitN(d.Id(), ":", 2)
+	if len(s) != 2 || s[0] == "" || s[1] == "" {
+		return nil, fmt.Errorf("invalid ID %q: must be in the format host_system_id:key", d.Id())
+	}
+	client := meta.(*VSphereClient).vimClient
+	fs, err := hostFirewallSystemFromHostSystemID(client, s[0])
+	if err != nil {
+		return nil, fmt.Errorf("error loading host firewall system: %s", err)
+	}
+	if _, err := hostFirewallRulesetFromKey(fs, s[1]); err != nil {
+		return nil, err
+	}
+	saveHostFirewallRulesetID(d, s[0], s[1])
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceVSphereHostFirewallRulesetApply sets the allowed hosts and the
+// enabled state of the ruleset.
+func resourceVSphereHostFirewallRulesetApply(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hsID, key, err := splitHostFirewallRulesetID(d.Id())
+	if err != nil {
+		return err
+	}
+	allowedHosts, err := expandHostFirewallRulesetIPList(d)
+	if err != nil {
+		return err
+	}
+	fs, err := hostFirewallSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error loading host firewall system: %s", err)
+	}
+	ruleset, err := hostFirewallRulesetFromKey(fs, key)
+	if err != nil {
+		return fmt.Errorf("error fetching firewall ruleset: %s", err)
+	}
+
+	// Some rulesets do not allow the allowed hosts to be changed at all, so the
+	// update is only sent when there is something to change.
+	if !hostFirewallRulesetIPListEqual(ruleset.AllowedHosts, allowedHosts) {
+		spec := types.HostFirewallRulesetRulesetSpec{
+			AllowedHosts: *allowedHosts,
+		}
+		if err := updateHostFirewallRuleset(fs, key, spec); err != nil {
+			return fmt.Errorf("error updating allowed hosts of firewall ruleset: %s", err)
+		}
+	}
+	if enabled := d.Get("enabled").(bool); enabled != ruleset.Enabled {
+		if err := enableHostFirewallRuleset(fs, key, enabled); err != nil {
+			return fmt.Errorf("error enabling or disabling firewall ruleset: %s", err)
+		}
+	}
+	return nil
+}
+
+// hostFirewallRulesetIPListEqual checks to see if two lists of allowed hosts
+// are the same, regardless of order. A nil list allows all IP addresses.
+func hostFirewallRulesetIPListEqual(a, b *types.HostFirewallRulesetIpList) bool {
+	if a == nil {
+		a = &types.HostFirewallRulesetIpList{AllIp: true}
+	}
+	if b == nil {
+		b = &types.HostFirewallRulesetIpList{AllIp: true}
+	}
+	if a.AllIp != b.AllIp {
+		return false
+	}
+	var aNetworks, bNetworks []string
+	for _, network := range a.IpNetwork {
+		aNetworks = append(aNetworks, fmt.Sprintf("%s/%d", network.Network, network.PrefixLength))
+	}
+	for _, network := range b.IpNetwork {
+		bNetworks = append(bNetworks, fmt.Sprintf("%s/%d", network.Network, network.PrefixLength))
+	}
+	return hostFirewallStringSetsEqual(a.IpAddress, b.IpAddress) && hostFirewallStringSetsEqual(aNetworks, bNetworks)
+}
+
+// hostFirewallStringSetsEqual checks to see if two string slices hold the same
+// values, regardless of order.
+func hostFirewallStringSetsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	m := make(map[string]int)
+	for _, v := range a {
+		m[v]++
+	}
+	for _, v := range b {
+		if m[v] == 0 {
+			return false
+		}
+		m[v]--
+	}
+	return true
+}
+
+// hostFirewallRulesetHasIPList checks to see if allowed_ip_addresses or
+// allowed_networks are set.
+func hostFirewallRulesetHasIPList(d *schema.ResourceData) bool {
+	return d.Get("allowed_ip_addresses").(*schema.Set).Len() > 0 || d.Get("allowed_networks").(*schema.Set).Len() > 0
+}
+
+// validateHostFirewallRulesetNetwork validates a network in allowed_networks.
+// The host stores the network address with the host bits masked off, so a
+// network such as 10.0.0.5/24 would be read back as 10.0.0.0/24 and always
+// show a diff. Such networks are rejected, and the canonical form is
+// suggested instead.
+func validateHostFirewallRulesetNetwork(v interface{}, k string) ([]string, []error) {
+	s := v.(string)
+	_, ipNet, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, []error{fmt.Errorf("%s: %q is not a valid CIDR network", k, s)}
+	}
+	if ipNet.String() != s {
+		return nil, []error{fmt.Errorf("%s: %q is not a canonical CIDR network, use %q", k, s, ipNet.String())}
+	}
+	return nil, nil
+}
+
+// expandHostFirewallRulesetIPList reads the allowed hosts of a ruleset from
+// the resource data. Access from all IP addresses is turned off when either of
+// the lists are set.
+func expandHostFirewallRulesetIPList(d *schema.ResourceData) (*types.HostFirewallRulesetIpList, error) {
+	obj := &types.HostFirewallRulesetIpList{
+		AllIp:     d.Get("allowed_all_ip").(bool) && !hostFirewallRulesetHasIPList(d),
+		IpAddress: structure.SliceInterfacesToStrings(d.Get("allowed_ip_addresses").(*schema.Set).List()),
+	}
+	for _, v := range d.Get("allowed_networks").(*schema.Set).List() {
+		_, ipNet, err := net.ParseCIDR(v.(string))
+		if err != nil {
+			return nil, err
+		}
+		prefixLength, _ := ipNet.Mask.Size()
+		obj.IpNetwork = append(obj.IpNetwork, types.HostFirewallRulesetIpNetwork{
+			Network:      ipNet.IP.String(),
+			PrefixLength: int32(prefixLength),
+		})
+	}
+	return obj, nil
+}
+
+// flattenHostFirewallRulesetIPList saves the allowed hosts of a ruleset to
+// the resource data.
+func flattenHostFirewallRulesetIPList(d *schema.ResourceData, obj *types.HostFirewallRulesetIpList) error {
+	if obj == nil {
+		obj = &types.HostFirewallRulesetIpList{AllIp: true}
+	}
+	d.Set("allowed_all_ip", obj.AllIp)
+	if err := d.Set("allowed_ip_addresses", obj.IpAddress); err != nil {
+		return fmt.Errorf("error setting allowed_ip_addresses: %s", err)
+	}
+	var networks []string
+	for _, network := range obj.IpNetwork {
+		networks = append(networks, fmt.Sprintf("%s/%d", network.Network, network.PrefixLength))
+	}
+	if err := d.Set("allowed_networks", networks); err != nil {
+		return fmt.Errorf("error setting allowed_networks: %s", err)
+	}
+	return nil
+}
+
+// saveHostFirewallRulesetID sets a special ID for a ruleset, made up of the
+// HostSystem ID and the ruleset key.
+func saveHostFirewallRulesetID(d *schema.ResourceData, hsID, key string) {
+	d.SetId(fmt.Sprintf("%s:%s:%s", hostFirewallRulesetIDPrefix, hsID, key))
+}
+
+// splitHostFirewallRulesetID splits a vsphere_host_firewall_ruleset resource
+// ID into its counterparts: the HostSystem ID and the ruleset key.
+func splitHostFirewallRulesetID(raw string) (string, string, error) {
+	s := strings.SplitN(raw, ":", 3)
+	if len(s) != 3 || s[0] != hostFirewallRulesetIDPrefix || s[1] == "" || s[2] == "" {
+		return "", "", fmt.Errorf("corrupt ID: %s", raw)
+	}
+	return s[1], s[2], nil
+}
@@ -0,0 +1,108 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccResourceVSphereHostFirewallRuleset_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereHostFirewallRulesetPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccResourceVSphereHostFirewallRulesetConfigRestricted("198.51.100.10/24"),
+				ExpectError: regexp.MustCompile(`not a canonical CIDR network, use "198.51.100.0/24"`),
+			},
+			{
+				Config: testAccResourceVSphereHostFirewallRulesetConfigRestricted("198.51.100.0/24"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vsphere_host_firewall_ruleset.ruleset", "enabled", "true"),
+					resource.TestCheckResourceAttr("vsphere_host_firewall_ruleset.ruleset", "allowed_all_ip", "false"),
+					resource.TestCheckResourceAttr("vsphere_host_firewall_ruleset.ruleset", "allowed_ip_addresses.#", "1"),
+					resource.TestCheckResourceAttr("vsphere_host_firewall_ruleset.ruleset", "allowed_networks.#", "1"),
+					resource.TestCheckResourceAttrSet("vsphere_host_firewall_ruleset.ruleset", "label"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereHostFirewallRulesetConfigOpen(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vsphere_host_firewall_ruleset.ruleset", "enabled", "false"),
+					resource.TestCheckResourceAttr("vsphere_host_firewall_ruleset.ruleset", "allowed_all_ip", "true"),
+					resource.TestCheckResourceAttr("vsphere_host_firewall_ruleset.ruleset", "allowed_ip_addresses.#", "0"),
+					resource.TestCheckResourceAttr("vsphere_host_firewall_ruleset.ruleset", "allowed_networks.#", "0"),
+				),
+			},
+			{
+				ResourceName:      "vsphere_host_firewall_ruleset.ruleset",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					rs, ok := s.RootModule().Resources["vsphere_host_firewall_ruleset.ruleset"]
+					if !ok {
+						return "", errors.New("no resource at address vsphere_host_firewall_ruleset.ruleset")
+					}
+					return strings.TrimPrefix(rs.Primary.ID, hostFirewallRulesetIDPrefix+":"), nil
+				},
+			},
+		},
+	})
+}
+
+func testAccResourceVSphereHostFirewallRulesetPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_DATACENTER") == "" {
+		t.Skip("set VSPHERE_DATACENTER to run vsphere_host_firewall_ruleset acceptance tests")
+	}
+	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST to run vsphere_host_firewall_ruleset acceptance tests")
+	}
+}
+
+func testAccResourceVSphereHostFirewallRulesetConfigRestricted(network string) string {
+	return fmt.Sprintf(`
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_host_firewall_ruleset" "ruleset" {
+  host_system_id       = "${data.vsphere_host.esxi_host.id}"
+  key                  = "syslog"
+  allowed_ip_addresses = ["192.0.2.10"]
+  allowed_networks     = ["%s"]
+}
+`, os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_ESXI_HOST"), network)
+}
+
+func testAccResourceVSphereHostFirewallRulesetConfigOpen() string {
+	return fmt.Sprintf(`
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_host_firewall_ruleset" "ruleset" {
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+  key            = "syslog"
+  enabled        = false
+}
+`, os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_ESXI_HOST"))
+}
@@ -0,0 +1,600 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const (
+	hostIscsiAdapterIDPrefix = "tf-HostIscsiAdapter"
+
+	// hostIscsiDefaultPort is the default TCP port of iSCSI targets.
+	hostIscsiDefaultPort = 3260
+)
+
+func resourceVSphereHostIscsiAdapter() *schema.Resource {
+	return &schema.Resource{
+		Create:        resourceVSphereHostIscsiAdapterCreate,
+		Read:          resourceVSphereHostIscsiAdapterRead,
+		Update:        resourceVSphereHostIscsiAdapterUpdate,
+		Delete:        resourceVSphereHostIscsiAdapterDelete,
+		CustomizeDiff: resourceVSphereHostIscsiAdapterCustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereHostIscsiAdapterImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"host_system_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the host to enable the software iSCSI adapter on.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"iqn": {
+				Type:        schema.TypeString,
+				Description: "The iSCSI qualified name of the adapter. If not set, the name generated by the host is used.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"send_target": {
+				Type:        schema.TypeSet,
+				Description: "The dynamic discovery (send targets) addresses of the adapter.",
+				Optional:    true,
+				Elem:        hostIscsiSendTargetSchema(),
+			},
+			"static_target": {
+				Type:        schema.TypeSet,
+				Description: "The static discovery targets of the adapter.",
+				Optional:    true,
+				Elem:        hostIscsiStaticTargetSchema(),
+			},
+			"chap": {
+				Type:        schema.TypeList,
+				Description: "The CHAP settings of the adapter. If not set, CHAP is not used.",
+				Optional:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"authentication_type": {
+							Type:        schema.TypeString,
+							Description: "The level of CHAP used by the adapter. Can be one of chapDiscouraged, chapPreferred, or chapRequired.",
+							Optional:    true,
+							Default:     string(types.HostInternetScsiHbaChapAuthenticationTypeChapRequired),
+							ValidateFunc: validation.StringInSlice(
+								[]string{
+									string(types.HostInternetScsiHbaChapAuthenticationTypeChapDiscouraged),
+									string(types.HostInternetScsiHbaChapAuthenticationTypeChapPreferred),
+									string(types.HostInternetScsiHbaChapAuthenticationTypeChapRequired),
+								},
+								false,
+							),
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Description: "The CHAP name the adapter uses to authenticate to targets.",
+							Required:    true,
+						},
+						"secret": {
+							Type:        schema.TypeString,
+							Description: "The CHAP secret the adapter uses to authenticate to targets.",
+							Required:    true,
+							Sensitive:   true,
+						},
+						"mutual_name": {
+							Type:        schema.TypeString,
+							Description: "The CHAP name targets use to authenticate to the adapter. Setting this enables mutual CHAP, which requires authentication_type to be chapRequired and mutual_secret to be set.",
+							Optional:    true,
+						},
+						"mutual_secret": {
+							Type:        schema.TypeString,
+							Description: "The CHAP secret targets use to authenticate to the adapter.",
+							Optional:    true,
+							Sensitive:   true,
+						},
+					},
+				},
+			},
+			"bound_vmknics": {
+				Type:        schema.TypeSet,
+				Description: "The VMkernel adapters, ie: vmk1, to bind to the adapter for iSCSI multipathing.",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"device": {
+				Type:        schema.TypeString,
+				Description: "The device name of the adapter, ie: vmhba65.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// hostIscsiSendTargetSchema returns the schema of a send_target block.
+func hostIscsiSendTargetSchema() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"address": {
+				Type:        schema.TypeString,
+				Description: "The IP address or host name of the target.",
+				Required:    true,
+			},
+			"port": {
+				Type:         schema.TypeInt,
+				Description:  "The TCP port of the target.",
+				Optional:     true,
+				Default:      hostIscsiDefaultPort,
+				ValidateFunc: validation.IntBetween(1, 65535),
+			},
+		},
+	}
+}
+
+// hostIscsiStaticTargetSchema returns the schema of a static_target block.
+func hostIscsiStaticTargetSchema() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"address": {
+				Type:        schema.TypeString,
+				Description: "The IP address or host name of the target.",
+				Required:    true,
+			},
+			"port": {
+				Type:         schema.TypeInt,
+				Description:  "The TCP port of the target.",
+				Optional:     true,
+				Default:      hostIscsiDefaultPort,
+				ValidateFunc: validation.IntBetween(1, 65535),
+			},
+			"iqn": {
+				Type:        schema.TypeString,
+				Description: "The iSCSI qualified name of the target.",
+				Required:    true,
+			},
+		},
+	}
+}
+
+func resourceVSphereHostIscsiAdapterCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hsID := d.Get("host_system_id").(string)
+	ss, err := hostStorageSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error loading host storage system: %s", err)
+	}
+
+	hba, err := hostSoftwareInternetScsiHba(ss)
+	if err != nil {
+		return err
+	}
+	// An adapter that is already enabled can have targets and bindings that
+	// are in use, which would be replaced by the configuration here and removed
+	// on destroy. It has to be imported instead so that this is deliberate.
+	if hba != nil {
+		return fmt.Errorf("software iSCSI adapter %s is already enabled on host %s, import it to manage it with Terraform", hba.Device, hsID)
+	}
+	log.Printf("[DEBUG] Enabling software iSCSI on host %q", hsID)
+	if err := updateHostSoftwareInternetScsiEnabled(ss, true); err != nil {
+		return fmt.Errorf("error enabling software iSCSI: %s", err)
+	}
+	hba, err = waitForHostSoftwareInternetScsiHba(ss)
+	if err != nil {
+		return err
+	}
+
+	saveHostIscsiAdapterID(d, hsID, hba.Device)
+	if err := resourceVSphereHostIscsiAdapterApply(d, meta, ss, hba); err != nil {
+		return err
+	}
+	return resourceVSphereHostIscsiAdapterRead(d, meta)
+}
+
+func resourceVSphereHostIscsiAdapterRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hsID, device, err := splitHostIscsiAdapterID(d.Id())
+	if err != nil {
+		return err
+	}
+	ss, err := hostStorageSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error loading host storage system: %s", err)
+	}
+	hba, err := hostSoftwareInternetScsiHba(ss)
+	if err != nil {
+		return err
+	}
+	if hba == nil || hba.Device != device {
+		log.Printf("[DEBUG] Software iSCSI adapter %q not found on host %q, removing from state", device, hsID)
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("host_system_id", hsID)
+	d.Set("device", hba.Device)
+	d.Set("iqn", hba.IScsiName)
+	if err := d.Set("send_target", flattenHostInternetScsiHbaSendTargets(hba.ConfiguredSendTarget)); err != nil {
+		return fmt.Errorf("error setting send_target: %s", err)
+	}
+	if err := d.Set("static_target", flattenHostInternetScsiHbaStaticTargets(hba.ConfiguredStaticTarget)); err != nil {
+		return fmt.Errorf("error setting static_target: %s", err)
+	}
+	if err := flattenHostInternetScsiHbaAuthenticationProperties(d, hba.AuthenticationProperties); err != nil {
+		return err
+	}
+
+	ref, err := hostIscsiManagerFromHostSystemID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error loading host iSCSI manager: %s", err)
+	}
+	vnics, err := hostIscsiBoundVnics(client, ref, hba.Device)
+	if err != nil {
+		return fmt.Errorf("error querying bound VMkernel adapters: %s", err)
+	}
+	if err := d.Set("bound_vmknics", vnics); err != nil {
+		return fmt.Errorf("error setting bound_vmknics: %s", err)
+	}
+	return nil
+}
+
+func resourceVSphereHostIscsiAdapterUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hsID, device, err := splitHostIscsiAdapterID(d.Id())
+	if err != nil {
+		return err
+	}
+	ss, err := hostStorageSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error loading host storage system: %s", err)
+	}
+	hba, err := hostSoftwareInternetScsiHba(ss)
+	if err != nil {
+		return err
+	}
+	if hba == nil || hba.Device != device {
+		return fmt.Errorf("software iSCSI adapter %s not found on host %s", device, hsID)
+	}
+	if err := resourceVSphereHostIscsiAdapterApply(d, meta, ss, hba); err != nil {
+		return err
+	}
+	return resourceVSphereHostIscsiAdapterRead(d, meta)
+}
+
+func resourceVSphereHostIscsiAdapterDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hsID, device, err := splitHostIscsiAdapterID(d.Id())
+	if err != nil {
+		return err
+	}
+	ss, err := hostStorageSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error loading host storage system: %s", err)
+	}
+	hba, err := hostSoftwareInternetScsiHba(ss)
+	if err != nil {
+		return err
+	}
+	if hba == nil || hba.Device != device {
+		return nil
+	}
+
+	// The host keeps the configuration of the adapter when software iSCSI is
+	// disabled, so the targets and bindings are removed first to make sure the
+	// adapter comes back clean if it is enabled again.
+	ref, err := hostIscsiManagerFromHostSystemID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error loading host iSCSI manager: %s", err)
+	}
+	vnics, err := hostIscsiBoundVnics(client, ref, device)
+	if err != nil {
+		return fmt.Errorf("error querying bound VMkernel adapters: %s", err)
+	}
+	for _, vnic := range vnics {
+		if err := unbindHostIscsiVnic(client, ref, device, vnic); err != nil {
+			return fmt.Errorf("error unbinding VMkernel adapter %s: %s", vnic, err)
+		}
+	}
+	if targets := hostInternetScsiHbaStaticTargetsByMethod(hba.ConfiguredStaticTarget); len(targets) > 0 {
+		if err := removeHostInternetScsiStaticTargets(ss, device, targets); err != nil {
+			return fmt.Errorf("error removing static targets: %s", err)
+		}
+	}
+	if len(hba.ConfiguredSendTarget) > 0 {
+		if err := removeHostInternetScsiSendTargets(ss, device, hba.ConfiguredSendTarget); err != nil {
+			return fmt.Errorf("error removing send targets: %s", err)
+		}
+	}
+
+	log.Printf("[DEBUG] Disabling software iSCSI on host %q", hsID)
+	if err := updateHostSoftwareInternetScsiEnabled(ss, false); err != nil {
+		return fmt.Errorf("error disabling software iSCSI: %s", err)
+	}
+	return rescanHostStorage(ss)
+}
+
+func resourceVSphereHostIscsiAdapterCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	// Values that are not known yet are empty here, so they are only checked
+	// once they are known.
+	if !d.NewValueKnown("chap") {
+		return nil
+	}
+	chaps := d.Get("chap").([]interface{})
+	if len(chaps) < 1 || chaps[0] == nil {
+		return nil
+	}
+	chap := chaps[0].(map[string]interface{})
+	mutualName := chap["mutual_name"].(string)
+	mutualSecret := chap["mutual_secret"].(string)
+	switch {
+	case mutualName == "" && mutualSecret != "":
+		return fmt.Errorf("chap: mutual_secret requires mutual_name to be set")
+	case mutualName == "":
+		return nil
+	case mutualSecret == "":
+		return fmt.Errorf("chap: mutual CHAP requires mutual_secret to be set")
+	case chap["authentication_type"].(string) != string(types.HostInternetScsiHbaChapAuthenticationTypeChapRequired):
+		return fmt.Errorf("chap: mutual CHAP requires authentication_type to be %s", types.HostInternetScsiHbaChapAuthenticationTypeChapRequired)
+	}
+	return nil
+}
+
+func resourceVSphereHostIscsiAdapterImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	client := meta.(*VSphereClient).vimClient
+	hsID := d.Id()
+	ss, err := hostStorageSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return nil, fmt.Errorf("error loading host storage system: %s", err)
+	}
+	hba, err := hostSoftwareInternetScsiHba(ss)
+	if err != nil {
+		return nil, err
+	}
+	if hba == nil {
+		return nil, fmt.Errorf("software iSCSI is not enabled on host %s", hsID)
+	}
+	saveHostIscsiAdapterID(d, hsID, hba.Device)
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceVSphereHostIscsiAdapterApply sets the IQN, targets, CHAP settings
+// and VMkernel bindings of the adapter, and then rescans the host so that
+// the LUNs on the targets show up.
+func resourceVSphereHostIscsiAdapterApply(d *schema.ResourceData, meta interface{}, ss *object.HostStorageSystem, hba *types.HostInternetScsiHba) error {
+	client := meta.(*VSphereClient).vimClient
+	hsID := d.Get("host_system_id").(string)
+	device := hba.Device
+
+	if iqn, ok := d.GetOk("iqn"); ok && iqn.(string) != hba.IScsiName {
+		if err := updateHostInternetScsiName(ss, device, iqn.(string)); err != nil {
+			return fmt.Errorf("error setting IQN: %s", err)
+		}
+	}
+
+	// CHAP is set before the targets are added so that the first login to the
+	// targets is already authenticated.
+	if d.IsNewResource() || d.HasChange("chap") {
+		if err := updateHostInternetScsiAuthenticationProperties(ss, device, expandHostInternetScsiHbaAuthenticationProperties(d)); err != nil {
+			return fmt.Errorf("error setting CHAP: %s", err)
+		}
+	}
+
+	currentSend := flattenHostInternetScsiHbaSendTargets(hba.ConfiguredSendTarget)
+	desiredSend := d.Get("send_target").(*schema.Set)
+	if removed := currentSend.Difference(desiredSend); removed.Len() > 0 {
+		if err := removeHostInternetScsiSendTargets(ss, device, expandHostInternetScsiHbaSendTargets(removed)); err != nil {
+			return fmt.Errorf("error removing send targets: %s", err)
+		}
+	}
+	if added := desiredSend.Difference(currentSend); added.Len() > 0 {
+		if err := addHostInternetScsiSendTargets(ss, device, expandHostInternetScsiHbaSendTargets(added)); err != nil {
+			return fmt.Errorf("error adding send targets: %s", err)
+		}
+	}
+
+	currentStatic := flattenHostInternetScsiHbaStaticTargets(hba.ConfiguredStaticTarget)
+	desiredStatic := d.Get("static_target").(*schema.Set)
+	if removed := currentStatic.Difference(desiredStatic); removed.Len() > 0 {
+		if err := removeHostInternetScsiStaticTargets(ss, device, expandHostInternetScsiHbaStaticTargets(removed)); err != nil {
+			return fmt.Errorf("error removing static targets: %s", err)
+		}
+	}
+	if added := desiredStatic.Difference(currentStatic); added.Len() > 0 {
+		if err := addHostInternetScsiStaticTargets(ss, device, expandHostInternetScsiHbaStaticTargets(added)); err != nil {
+			return fmt.Errorf("error adding static targets: %s", err)
+		}
+	}
+
+	ref, err := hostIscsiManagerFromHostSystemID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error loading host iSCSI manager: %s", err)
+	}
+	vnics, err := hostIscsiBoundVnics(client, ref, device)
+	if err != nil {
+		return fmt.Errorf("error querying bound VMkernel adapters: %s", err)
+	}
+	currentVnics := schema.NewSet(schema.HashString, structure.SliceStringsToInterfaces(vnics))
+	desiredVnics := d.Get("bound_vmknics").(*schema.Set)
+	for _, vnic := range currentVnics.Difference(desiredVnics).List() {
+		if err := unbindHostIscsiVnic(client, ref, device, vnic.(string)); err != nil {
+			return fmt.Errorf("error unbinding VMkernel adapter %s: %s", vnic, err)
+		}
+	}
+	for _, vnic := range desiredVnics.Difference(currentVnics).List() {
+		if err := bindHostIscsiVnic(client, ref, device, vnic.(string)); err != nil {
+			return fmt.Errorf("error binding VMkernel adapter %s: %s", vnic, err)
+		}
+	}
+
+	if err := rescanHostStorage(ss); err != nil {
+		return fmt.Errorf("error rescanning host storage: %s", err)
+	}
+	return nil
+}
+
+// waitForHostSoftwareInternetScsiHba waits for the software iSCSI adapter to
+// show up on a host after software iSCSI has been enabled.
+func waitForHostSoftwareInternetScsiHba(ss *object.HostStorageSystem) (*types.HostInternetScsiHba, error) {
+	var hba *types.HostInternetScsiHba
+	err := resource.Retry(defaultAPITimeout, func() *resource.RetryError {
+		var err error
+		hba, err = hostSoftwareInternetScsiHba(ss)
+		if err != nil {
+			return resource.NonRetryableError(err)
+		}
+		if hba == nil {
+			time.Sleep(time.Second * 5)
+			return resource.RetryableError(fmt.Errorf("software iSCSI adapter not found"))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error waiting for software iSCSI adapter: %s", err)
+	}
+	return hba, nil
+}
+
+// expandHostInternetScsiHbaAuthenticationProperties reads the CHAP settings
+// of the adapter from the resource data.
+func expandHostInternetScsiHbaAuthenticationProperties(d *schema.ResourceData) types.HostInternetScsiHbaAuthenticationProperties {
+	obj := types.HostInternetScsiHbaAuthenticationProperties{
+		ChapAuthEnabled:              false,
+		ChapAuthenticationType:       string(types.HostInternetScsiHbaChapAuthenticationTypeChapProhibited),
+		MutualChapAuthenticationType: string(types.HostInternetScsiHbaChapAuthenticationTypeChapProhibited),
+	}
+	chaps := d.Get("chap").([]interface{})
+	if len(chaps) < 1 || chaps[0] == nil {
+		return obj
+	}
+	chap := chaps[0].(map[string]interface{})
+	obj.ChapAuthEnabled = true
+	obj.ChapAuthenticationType = chap["authentication_type"].(string)
+	obj.ChapName = chap["name"].(string)
+	obj.ChapSecret = chap["secret"].(string)
+	if chap["mutual_name"].(string) != "" {
+		obj.MutualChapAuthenticationType = string(types.HostInternetScsiHbaChapAuthenticationTypeChapRequired)
+		obj.MutualChapName = chap["mutual_name"].(string)
+		obj.MutualChapSecret = chap["mutual_secret"].(string)
+	}
+	return obj
+}
+
+// flattenHostInternetScsiHbaAuthenticationProperties saves the CHAP settings
+// of the adapter to the resource data. The host does not return the CHAP
+// secrets, so these are kept as they are in state.
+func flattenHostInternetScsiHbaAuthenticationProperties(d *schema.ResourceData, obj types.HostInternetScsiHbaAuthenticationProperties) error {
+	var chaps []interface{}
+	if obj.ChapAuthEnabled && obj.ChapAuthenticationType != string(types.HostInternetScsiHbaChapAuthenticationTypeChapProhibited) {
+		var secret, mutualSecret string
+		if old := d.Get("chap").([]interface{}); len(old) > 0 && old[0] != nil {
+			secret = old[0].(map[string]interface{})["secret"].(string)
+			mutualSecret = old[0].(map[string]interface{})["mutual_secret"].(string)
+		}
+		chap := map[string]interface{}{
+			"authentication_type": obj.ChapAuthenticationType,
+			"name":                obj.ChapName,
+			"secret":              secret,
+		}
+		if obj.MutualChapAuthenticationType == string(types.HostInternetScsiHbaChapAuthenticationTypeChapRequired) {
+			chap["mutual_name"] = obj.MutualChapName
+			chap["mutual_secret"] = mutualSecret
+		}
+		chaps = append(chaps, chap)
+	}
+	if err := d.Set("chap", chaps); err != nil {
+		return fmt.Errorf("error setting chap: %s", err)
+	}
+	return nil
+}
+
+// expandHostInternetScsiHbaSendTargets converts a set of send_target blocks
+// into send targets.
+func expandHostInternetScsiHbaSendTargets(s *schema.Set) []types.HostInternetScsiHbaSendTarget {
+	var targets []types.HostInternetScsiHbaSendTarget
+	for _, v := range s.List() {
+		target := v.(map[string]interface{})
+		targets = append(targets, types.HostInternetScsiHbaSendTarget{
+			Address: target["address"].(string),
+			Port:    int32(target["port"].(int)),
+		})
+	}
+	return targets
+}
+
+// flattenHostInternetScsiHbaSendTargets converts send targets into a set of
+// send_target blocks.
+func flattenHostInternetScsiHbaSendTargets(targets []types.HostInternetScsiHbaSendTarget) *schema.Set {
+	s := schema.NewSet(schema.HashResource(hostIscsiSendTargetSchema()), nil)
+	for _, target := range targets {
+		s.Add(map[string]interface{}{
+			"address": target.Address,
+			"port":    int(target.Port),
+		})
+	}
+	return s
+}
+
+// expandHostInternetScsiHbaStaticTargets converts a set of static_target
+// blocks into static targets.
+func expandHostInternetScsiHbaStaticTargets(s *schema.Set) []types.HostInternetScsiHbaStaticTarget {
+	var targets []types.HostInternetScsiHbaStaticTarget
+	for _, v := range s.List() {
+		target := v.(map[string]interface{})
+		targets = append(targets, types.HostInternetScsiHbaStaticTarget{
+			Address:   target["address"].(string),
+			Port:      int32(target["port"].(int)),
+			IScsiName: target["iqn"].(string),
+		})
+	}
+	return targets
+}
+
+// flattenHostInternetScsiHbaStaticTargets converts the static targets that
+// were added by static discovery into a set of static_target blocks.
+func flattenHostInternetScsiHbaStaticTargets(targets []types.HostInternetScsiHbaStaticTarget) *schema.Set {
+	s := schema.NewSet(schema.HashResource(hostIscsiStaticTargetSchema()), nil)
+	for _, target := range hostInternetScsiHbaStaticTargetsByMethod(targets) {
+		s.Add(map[string]interface{}{
+			"address": target.Address,
+			"port":    int(target.Port),
+			"iqn":     target.IScsiName,
+		})
+	}
+	return s
+}
+
+// hostInternetScsiHbaStaticTargetsByMethod filters out the static targets
+// that were found through dynamic discovery. These are managed by the host
+// and cannot be removed directly.
+func hostInternetScsiHbaStaticTargetsByMethod(targets []types.HostInternetScsiHbaStaticTarget) []types.HostInternetScsiHbaStaticTarget {
+	var static []types.HostInternetScsiHbaStaticTarget
+	for _, target := range targets {
+		if target.DiscoveryMethod == "" || target.DiscoveryMethod == string(types.HostInternetScsiHbaStaticTargetTargetDiscoveryMethodStaticMethod) {
+			static = append(static, target)
+		}
+	}
+	return static
+}
+
+// saveHostIscsiAdapterID sets a special ID for an iSCSI adapter, made up of
+// the HostSystem ID and the device name of the adapter.
+func saveHostIscsiAdapterID(d *schema.ResourceData, hsID, device string) {
+	d.SetId(fmt.Sprintf("%s:%s:%s", hostIscsiAdapterIDPrefix, hsID, device))
+}
+
+// splitHostIscsiAdapterID splits a vsphere_host_iscsi_adapter resource ID
+// into its counterparts: the HostSystem ID and the device name of the
+// adapter.
+func splitHostIscsiAdapterID(raw string) (string, string, error) {
+	s := strings.SplitN(raw, ":", 3)
+	if len(s) != 3 || s[0] != hostIscsiAdapterIDPrefix || s[1] == "" || s[2] == "" {
+		return "", "", fmt.Errorf("corrupt ID: %s", raw)
+	}
+	return s[1], s[2], nil
+}
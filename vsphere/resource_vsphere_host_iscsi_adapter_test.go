@@ -0,0 +1,100 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccResourceVSphereHostIscsiAdapter_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereHostIscsiAdapterPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereHostIscsiAdapterConfig(false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("vsphere_host_iscsi_adapter.iscsi", "device"),
+					resource.TestCheckResourceAttrSet("vsphere_host_iscsi_adapter.iscsi", "iqn"),
+					resource.TestCheckResourceAttr("vsphere_host_iscsi_adapter.iscsi", "send_target.#", "1"),
+					resource.TestCheckResourceAttr("vsphere_host_iscsi_adapter.iscsi", "chap.#", "0"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereHostIscsiAdapterConfig(true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vsphere_host_iscsi_adapter.iscsi", "send_target.#", "1"),
+					resource.TestCheckResourceAttr("vsphere_host_iscsi_adapter.iscsi", "chap.#", "1"),
+					resource.TestCheckResourceAttr("vsphere_host_iscsi_adapter.iscsi", "chap.0.name", "terraform-test"),
+				),
+			},
+			{
+				ResourceName:            "vsphere_host_iscsi_adapter.iscsi",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"chap.0.secret"},
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					rs, ok := s.RootModule().Resources["vsphere_host_iscsi_adapter.iscsi"]
+					if !ok {
+						return "", errors.New("no resource at address vsphere_host_iscsi_adapter.iscsi")
+					}
+					return rs.Primary.Attributes["host_system_id"], nil
+				},
+			},
+		},
+	})
+}
+
+func testAccResourceVSphereHostIscsiAdapterPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_DATACENTER") == "" {
+		t.Skip("set VSPHERE_DATACENTER to run vsphere_host_iscsi_adapter acceptance tests")
+	}
+	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST to run vsphere_host_iscsi_adapter acceptance tests")
+	}
+	if os.Getenv("VSPHERE_ISCSI_TARGET") == "" {
+		t.Skip("set VSPHERE_ISCSI_TARGET to run vsphere_host_iscsi_adapter acceptance tests")
+	}
+}
+
+func testAccResourceVSphereHostIscsiAdapterConfig(chap bool) string {
+	var chapBlock string
+	if chap {
+		chapBlock = `
+  chap {
+    name   = "terraform-test"
+    secret = "terraform-test-secret"
+  }
+`
+	}
+	return fmt.Sprintf(`
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_host_iscsi_adapter" "iscsi" {
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+
+  send_target {
+    address = "%s"
+  }
+%s}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_ESXI_HOST"),
+		os.Getenv("VSPHERE_ISCSI_TARGET"),
+		chapBlock,
+	)
+}
@@ -159,6 +159,40 @@ func TestAccResourceVSphereHost_lockdown(t *testing.T) {
 
 }
 
+func TestAccResourceVSphereHost_config(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccCheckEnvVariables(t, []string{"ESX_HOSTNAME", "ESX_USERNAME", "ESX_PASSWORD"})
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccVSphereHostDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVSphereHostConfig_config("on", true, "1"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccVSphereHostExists("vsphere_host.h1"),
+					resource.TestCheckResourceAttr("vsphere_host.h1", "ntp_servers.#", "2"),
+					resource.TestCheckResourceAttr("vsphere_host.h1", "ntp_servers.0", "0.pool.ntp.org"),
+					resource.TestCheckResourceAttr("vsphere_host.h1", "service.#", "1"),
+					resource.TestCheckResourceAttr("vsphere_host.h1", "syslog", "udp://127.0.0.1:514"),
+					resource.TestCheckResourceAttr("vsphere_host.h1", "advanced_options.UserVars.SuppressShellWarning", "1"),
+				),
+			},
+			{
+				Config: testAccVSphereHostConfig_config("off", false, "0"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccVSphereHostExists("vsphere_host.h1"),
+					resource.TestCheckResourceAttr("vsphere_host.h1", "service.#", "1"),
+					resource.TestCheckResourceAttr("vsphere_host.h1", "advanced_options.UserVars.SuppressShellWarning", "0"),
+				),
+			},
+		},
+	})
+
+}
+
 func TestAccResourceVSphereHost_lockdown_invalid(t *testing.T) {
 
 	resource.Test(t, resource.TestCase{
@@ -579,3 +613,48 @@ func testAccVSphereHostConfig_lockdown(lockdown string) string {
 		os.Getenv("VSPHERE_LICENSE"),
 		lockdown)
 }
+
+func testAccVSphereHostConfig_config(sshPolicy string, sshRunning bool, suppressShellWarning string) string {
+	return fmt.Sprintf(`
+	data "vsphere_datacenter" "dc" {
+	  name = "%s"
+	}
+		
+	resource "vsphere_compute_cluster" "c1" {
+	  name = "%s"
+	  datacenter_id = data.vsphere_datacenter.dc.id
+	}
+		
+	resource "vsphere_host" "h1" {
+	  hostname = "%s"
+	  username = "%s"
+	  password = "%s"
+	  thumbprint = "%s"
+	
+	  license = "%s"
+	  cluster = vsphere_compute_cluster.c1.id
+
+	  ntp_servers = ["0.pool.ntp.org", "1.pool.ntp.org"]
+	  syslog      = "udp://127.0.0.1:514"
+
+	  service {
+	    key     = "TSM-SSH"
+	    policy  = "%s"
+	    running = %t
+	  }
+
+	  advanced_options = {
+	    "UserVars.SuppressShellWarning" = "%s"
+	  }
+	}
+	`, os.Getenv("VSPHERE_DATACENTER"),
+		"TestCluster",
+		os.Getenv("ESX_HOSTNAME"),
+		os.Getenv("ESX_USERNAME"),
+		os.Getenv("ESX_PASSWORD"),
+		os.Getenv("ESX_THUMBPRINT"),
+		os.Getenv("VSPHERE_LICENSE"),
+		sshPolicy,
+		sshRunning,
+		suppressShellWarning)
+}
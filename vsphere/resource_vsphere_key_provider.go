@@ -0,0 +1,309 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/cryptomanager"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// defaultKmipServerPort is the default port that KMS servers listen on.
+const defaultKmipServerPort = 5696
+
+func resourceVSphereKeyProvider() *schema.Resource {
+	return &schema.Resource{
+		Create:        resourceVSphereKeyProviderCreate,
+		Read:          resourceVSphereKeyProviderRead,
+		Update:        resourceVSphereKeyProviderUpdate,
+		Delete:        resourceVSphereKeyProviderDelete,
+		CustomizeDiff: resourceVSphereKeyProviderCustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereKeyProviderImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name of the key provider. This is also the ID of the key provider.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"default": {
+				Type:        schema.TypeBool,
+				Description: "Mark this key provider as the default key provider for the vCenter Server.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"server": {
+				Type:        schema.TypeList,
+				Description: "The KMS servers in the key provider.",
+				Required:    true,
+				MinItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Description: "The name of the KMS server.",
+							Required:    true,
+						},
+						"address": {
+							Type:        schema.TypeString,
+							Description: "The address of the KMS server.",
+							Required:    true,
+						},
+						"port": {
+							Type:         schema.TypeInt,
+							Description:  "The port of the KMS server.",
+							Optional:     true,
+							Default:      defaultKmipServerPort,
+							ValidateFunc: validation.IntBetween(1, 65535),
+						},
+						"proxy_address": {
+							Type:        schema.TypeString,
+							Description: "The address of the proxy server to connect to the KMS server through.",
+							Optional:    true,
+						},
+						"proxy_port": {
+							Type:         schema.TypeInt,
+							Description:  "The port of the proxy server.",
+							Optional:     true,
+							ValidateFunc: validation.IntBetween(1, 65535),
+						},
+						"username": {
+							Type:        schema.TypeString,
+							Description: "The username to authenticate to the KMS server with.",
+							Optional:    true,
+						},
+						"password": {
+							Type:        schema.TypeString,
+							Description: "The password to authenticate to the KMS server with.",
+							Optional:    true,
+							Sensitive:   true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func resourceVSphereKeyProviderCreate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning create", resourceVSphereKeyProviderIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return err
+	}
+	name := d.Get("name").(string)
+	p, err := cryptomanager.KeyProvider(client, name)
+	if err != nil {
+		return err
+	}
+	if p != nil {
+		return fmt.Errorf("key provider %q already exists", name)
+	}
+
+	for _, spec := range expandKmipServerSpecs(d, name) {
+		if err := cryptomanager.RegisterServer(client, spec); err != nil {
+			return fmt.Errorf("error registering KMS server %q: %s", spec.Info.Name, err)
+		}
+		// The key provider exists once its first server has been registered.
+		d.SetId(name)
+	}
+
+	if d.Get("default").(bool) {
+		if err := cryptomanager.MarkDefault(client, name); err != nil {
+			return fmt.Errorf("error marking key provider %q as default: %s", name, err)
+		}
+	}
+
+	log.Printf("[DEBUG] %s: Create finished successfully", resourceVSphereKeyProviderIDString(d))
+	return resourceVSphereKeyProviderRead(d, meta)
+}
+
+func resourceVSphereKeyProviderRead(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning read", resourceVSphereKeyProviderIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return err
+	}
+	p, err := cryptomanager.KeyProvider(client, d.Id())
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		log.Printf("[DEBUG] %s: Key provider not found, marking resource as gone", resourceVSphereKeyProviderIDString(d))
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("name", p.ClusterId.Id)
+	d.Set("default", p.UseAsDefault)
+	if err := d.Set("server", flattenKmipServerInfos(d, p.Servers)); err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] %s: Read finished successfully", resourceVSphereKeyProviderIDString(d))
+	return nil
+}
+
+func resourceVSphereKeyProviderUpdate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning update", resourceVSphereKeyProviderIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return err
+	}
+
+	if d.HasChange("server") {
+		o, _ := d.GetChange("server")
+		old := make(map[string]bool)
+		for _, v := range o.([]interface{}) {
+			old[v.(map[string]interface{})["name"].(string)] = true
+		}
+		// Add and update servers first, so that the key provider is not removed
+		// along with its last server when all servers are replaced.
+		for _, spec := range expandKmipServerSpecs(d, d.Id()) {
+			if old[spec.Info.Name] {
+				delete(old, spec.Info.Name)
+				if err := cryptomanager.UpdateServer(client, spec); err != nil {
+					return fmt.Errorf("error updating KMS server %q: %s", spec.Info.Name, err)
+				}
+				continue
+			}
+			if err := cryptomanager.RegisterServer(client, spec); err != nil {
+				return fmt.Errorf("error registering KMS server %q: %s", spec.Info.Name, err)
+			}
+		}
+		for name := range old {
+			if err := cryptomanager.RemoveServer(client, d.Id(), name); err != nil {
+				return fmt.Errorf("error removing KMS server %q: %s", name, err)
+			}
+		}
+	}
+
+	if d.HasChange("default") && d.Get("default").(bool) {
+		if err := cryptomanager.MarkDefault(client, d.Id()); err != nil {
+			return fmt.Errorf("error marking key provider %q as default: %s", d.Id(), err)
+		}
+	}
+
+	log.Printf("[DEBUG] %s: Update finished successfully", resourceVSphereKeyProviderIDString(d))
+	return resourceVSphereKeyProviderRead(d, meta)
+}
+
+func resourceVSphereKeyProviderDelete(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning delete", resourceVSphereKeyProviderIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return err
+	}
+	p, err := cryptomanager.KeyProvider(client, d.Id())
+	if err != nil {
+		return err
+	}
+	if p != nil {
+		// The key provider is removed along with its last server.
+		for _, server := range p.Servers {
+			if err := cryptomanager.RemoveServer(client, d.Id(), server.Name); err != nil {
+				return fmt.Errorf("error removing KMS server %q: %s", server.Name, err)
+			}
+		}
+	}
+
+	log.Printf("[DEBUG] %s: Delete finished successfully", resourceVSphereKeyProviderIDString(d))
+	d.SetId("")
+	return nil
+}
+
+func resourceVSphereKeyProviderCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	// A key provider cannot be unmarked as the default, only replaced as the
+	// default by another key provider.
+	if o, n := d.GetChange("default"); d.Id() != "" && o.(bool) && !n.(bool) {
+		return fmt.Errorf("key provider %q cannot be unmarked as the default key provider; mark another key provider as the default instead", d.Id())
+	}
+	return nil
+}
+
+func resourceVSphereKeyProviderImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	client := meta.(*VSphereClient).vimClient
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return nil, err
+	}
+	p, err := cryptomanager.KeyProvider(client, d.Id())
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, fmt.Errorf("key provider %q not found", d.Id())
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+// expandKmipServerSpecs reads the server list from the resource data and
+// returns a KmipServerSpec for each server, as part of the key provider with
+// the supplied ID.
+func expandKmipServerSpecs(d *schema.ResourceData, id string) []types.KmipServerSpec {
+	var specs []types.KmipServerSpec
+	for _, v := range d.Get("server").([]interface{}) {
+		server := v.(map[string]interface{})
+		specs = append(specs, types.KmipServerSpec{
+			ClusterId: types.KeyProviderId{Id: id},
+			Info: types.KmipServerInfo{
+				Name:         server["name"].(string),
+				Address:      server["address"].(string),
+				Port:         int32(server["port"].(int)),
+				ProxyAddress: server["proxy_address"].(string),
+				ProxyPort:    int32(server["proxy_port"].(int)),
+				UserName:     server["username"].(string),
+			},
+			Password: server["password"].(string),
+		})
+	}
+	return specs
+}
+
+// flattenKmipServerInfos flattens the KMS servers of a key provider. Servers
+// are returned in the order they appear in configuration, followed by any
+// servers that are not in configuration. Server passwords cannot be read back
+// from vSphere, so they are carried over from the current state.
+func flattenKmipServerInfos(d *schema.ResourceData, servers []types.KmipServerInfo) []interface{} {
+	order := make(map[string]int)
+	passwords := make(map[string]string)
+	for i, v := range d.Get("server").([]interface{}) {
+		server := v.(map[string]interface{})
+		order[server["name"].(string)] = i
+		passwords[server["name"].(string)] = server["password"].(string)
+	}
+	sort.SliceStable(servers, func(i, j int) bool {
+		oi, iok := order[servers[i].Name]
+		oj, jok := order[servers[j].Name]
+		if iok && jok {
+			return oi < oj
+		}
+		return iok && !jok
+	})
+	var result []interface{}
+	for _, server := range servers {
+		result = append(result, map[string]interface{}{
+			"name":          server.Name,
+			"address":       server.Address,
+			"port":          int(server.Port),
+			"proxy_address": server.ProxyAddress,
+			"proxy_port":    int(server.ProxyPort),
+			"username":      server.UserName,
+			"password":      passwords[server.Name],
+		})
+	}
+	return result
+}
+
+// resourceVSphereKeyProviderIDString prints a friendly string for the
+// vsphere_key_provider resource.
+func resourceVSphereKeyProviderIDString(d structure.ResourceIDStringer) string {
+	return structure.ResourceIDString(d, "vsphere_key_provider")
+}
@@ -0,0 +1,173 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccResourceVSphereKeyProvider_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereKeyProviderPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereKeyProviderExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereKeyProviderConfig(false, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereKeyProviderExists(true),
+					testAccResourceVSphereKeyProviderServerCount(1),
+					resource.TestCheckResourceAttr("vsphere_key_provider.kp", "server.0.port", "5696"),
+				),
+			},
+			{
+				ResourceName:            "vsphere_key_provider.kp",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"server.0.password"},
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereKeyProvider_addServer(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereKeyProviderPreCheck(t)
+			testAccCheckEnvVariables(t, []string{"VSPHERE_KMS_ADDRESS2"})
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereKeyProviderExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereKeyProviderConfig(false, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereKeyProviderExists(true),
+					testAccResourceVSphereKeyProviderServerCount(1),
+				),
+			},
+			{
+				Config: testAccResourceVSphereKeyProviderConfig(true, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereKeyProviderExists(true),
+					testAccResourceVSphereKeyProviderServerCount(2),
+				),
+			},
+			{
+				Config: testAccResourceVSphereKeyProviderConfig(false, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereKeyProviderExists(true),
+					testAccResourceVSphereKeyProviderServerCount(1),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereKeyProvider_default(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereKeyProviderPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereKeyProviderExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereKeyProviderConfig(false, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereKeyProviderExists(true),
+					testAccResourceVSphereKeyProviderIsDefault(true),
+				),
+			},
+			{
+				Config:      testAccResourceVSphereKeyProviderConfig(false, false),
+				ExpectError: regexp.MustCompile("cannot be unmarked as the default key provider"),
+				PlanOnly:    true,
+			},
+		},
+	})
+}
+
+func testAccResourceVSphereKeyProviderPreCheck(t *testing.T) {
+	testAccSkipIfEsxi(t)
+	testAccCheckEnvVariables(t, []string{"VSPHERE_KMS_ADDRESS"})
+}
+
+func testAccResourceVSphereKeyProviderExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		kp, err := testGetKeyProvider(s, "kp")
+		if err != nil {
+			return err
+		}
+		switch {
+		case kp == nil && expected:
+			return errors.New("expected key provider to exist")
+		case kp != nil && !expected:
+			return errors.New("expected key provider to be missing")
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereKeyProviderServerCount(expected int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		kp, err := testGetKeyProvider(s, "kp")
+		if err != nil {
+			return err
+		}
+		if actual := len(kp.Servers); actual != expected {
+			return fmt.Errorf("expected %d KMS servers, got %d", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereKeyProviderIsDefault(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		kp, err := testGetKeyProvider(s, "kp")
+		if err != nil {
+			return err
+		}
+		if kp.UseAsDefault != expected {
+			return fmt.Errorf("expected default to be %t, got %t", expected, kp.UseAsDefault)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereKeyProviderConfig(secondServer, isDefault bool) string {
+	var extra string
+	if secondServer {
+		extra = fmt.Sprintf(`
+  server {
+    name    = "terraform-test-kms-2"
+    address = "%s"
+  }
+`, os.Getenv("VSPHERE_KMS_ADDRESS2"))
+	}
+	return fmt.Sprintf(`
+resource "vsphere_key_provider" "kp" {
+  name    = "terraform-test-key-provider"
+  default = %t
+
+  server {
+    name    = "terraform-test-kms"
+    address = "%s"
+  }
+%s}
+`,
+		isDefault,
+		os.Getenv("VSPHERE_KMS_ADDRESS"),
+		extra,
+	)
+}
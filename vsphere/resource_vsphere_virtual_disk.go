@@ -11,6 +11,7 @@ import (
 	"context"
 
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/cryptomanager"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
@@ -102,6 +103,15 @@ func resourceVSphereVirtualDisk() *schema.Resource {
 				Optional: true,
 				ForceNew: true,
 			},
+
+			"encryption": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				ForceNew:    true,
+				MaxItems:    1,
+				Description: "A specification for encrypting the virtual disk.",
+				Elem:        &schema.Resource{Schema: schemaVirtualMachineEncryption(true)},
+			},
 		},
 	}
 }
@@ -138,6 +148,15 @@ func resourceVSphereVirtualDiskCreate(d *schema.ResourceData, meta interface{})
 		vDisk.createDirectories = v.(bool)
 	}
 
+	var crypto types.BaseCryptoSpec
+	if id := encryptionKeyProviderID(d); id != "" {
+		key, err := cryptomanager.GenerateKey(client, id)
+		if err != nil {
+			return fmt.Errorf("Error generating encryption key: %s", err)
+		}
+		crypto = &types.CryptoSpecEncrypt{CryptoKeyId: *key}
+	}
+
 	finder := find.NewFinder(client.Client, true)
 
 	dc, err := getDatacenter(client, d.Get("datacenter").(string))
@@ -172,7 +191,7 @@ func resourceVSphereVirtualDiskCreate(d *schema.ResourceData, meta interface{})
 		}
 	}
 
-	err = createHardDisk(client, vDisk.size, ds.Path(vDisk.vmdkPath), vDisk.initType, vDisk.adapterType, vDisk.datacenter)
+	err = createHardDisk(client, vDisk.size, ds.Path(vDisk.vmdkPath), vDisk.initType, vDisk.adapterType, vDisk.datacenter, crypto)
 	if err != nil {
 		return err
 	}
@@ -236,6 +255,7 @@ func resourceVSphereVirtualDiskRead(d *schema.ResourceData, meta interface{}) er
 		Query: []types.BaseFileQuery{&types.VmDiskFileQuery{Details: &types.VmDiskFileQueryFlags{
 			CapacityKb: true,
 			DiskType:   true,
+			Encryption: types.NewBool(true),
 		}}},
 		Details: &types.FileQueryFlags{
 			FileSize:     true,
@@ -283,7 +303,8 @@ func resourceVSphereVirtualDiskRead(d *schema.ResourceData, meta interface{}) er
 
 	fileInfo := res.File[0]
 	log.Printf("[DEBUG] resourceVSphereVirtualDiskRead - fileinfo: %#v", fileInfo)
-	size := fileInfo.(*types.VmDiskFileInfo).CapacityKb / 1024 / 1024
+	diskInfo := fileInfo.(*types.VmDiskFileInfo)
+	size := diskInfo.CapacityKb / 1024 / 1024
 
 	d.SetId(vDisk.vmdkPath)
 
@@ -291,6 +312,13 @@ func resourceVSphereVirtualDiskRead(d *schema.ResourceData, meta interface{}) er
 	d.Set("vmdk_path", vDisk.vmdkPath)
 	d.Set("datacenter", d.Get("datacenter"))
 	d.Set("datastore", d.Get("datastore"))
+	var keyID *types.CryptoKeyId
+	if diskInfo.Encryption != nil {
+		keyID = diskInfo.Encryption.KeyId
+	}
+	if err := d.Set("encryption", flattenCryptoKeyID(keyID)); err != nil {
+		return fmt.Errorf("error setting encryption: %s", err)
+	}
 	// Todo collect and write type info
 
 	return nil
@@ -347,8 +375,9 @@ func isAlreadyExists(err error) bool {
 		strings.HasSuffix(err.Error(), "already exists")
 }
 
-// createHardDisk creates a new Hard Disk.
-func createHardDisk(client *govmomi.Client, size int, diskPath string, diskType string, adapterType string, dc string) error {
+// createHardDisk creates a new Hard Disk. The disk is encrypted if a crypto
+// spec is supplied.
+func createHardDisk(client *govmomi.Client, size int, diskPath string, diskType string, adapterType string, dc string, crypto types.BaseCryptoSpec) error {
 	var vDiskType string
 	switch diskType {
 	case "thin":
@@ -366,6 +395,7 @@ func createHardDisk(client *govmomi.Client, size int, diskPath string, diskType
 			DiskType:    vDiskType,
 		},
 		CapacityKb: int64(1024 * 1024 * size),
+		Crypto:     crypto,
 	}
 	datacenter, err := getDatacenter(client, dc)
 	if err != nil {
@@ -102,6 +102,31 @@ func TestAccResourceVSphereVirtualDisk_withParent(t *testing.T) {
 	})
 }
 
+func TestAccResourceVSphereVirtualDisk_encryption(t *testing.T) {
+	rString := acctest.RandString(5)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualDiskPreCheck(t)
+			testAccSkipIfEsxi(t)
+			testAccCheckEnvVariables(t, []string{"VSPHERE_KEY_PROVIDER"})
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccVSphereVirtualDiskExists("vsphere_virtual_disk.foo", false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckVSphereVirtuaDiskConfig_encryption(rString),
+				Check: resource.ComposeTestCheckFunc(
+					testAccVSphereVirtualDiskExists("vsphere_virtual_disk.foo", true),
+					resource.TestCheckResourceAttr("vsphere_virtual_disk.foo", "encryption.0.key_provider_id", os.Getenv("VSPHERE_KEY_PROVIDER")),
+					resource.TestCheckResourceAttrSet("vsphere_virtual_disk.foo", "encryption.0.key_id"),
+				),
+			},
+		},
+	})
+}
+
 func testAccResourceVSphereVirtualDiskPreCheck(t *testing.T) {
 	if os.Getenv("VSPHERE_DATACENTER") == "" {
 		t.Skip("set VSPHERE_DATACENTER to run vsphere_virtual_disk acceptance tests")
@@ -312,3 +337,49 @@ resource "vsphere_virtual_disk" "foo" {
 		rName,
 	)
 }
+
+func testAccCheckVSphereVirtuaDiskConfig_encryption(rName string) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "key_provider" {
+  default = "%s"
+}
+
+variable "rstring" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_datastore" "ds" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_virtual_disk" "foo" {
+  size       = 1
+  vmdk_path  = "tfTestDisk-${var.rstring}.vmdk"
+  type       = "thin"
+  datacenter = "${data.vsphere_datacenter.dc.name}"
+  datastore  = "${data.vsphere_datastore.ds.name}"
+
+  encryption {
+    key_provider_id = "${var.key_provider}"
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_KEY_PROVIDER"),
+		rName,
+	)
+}
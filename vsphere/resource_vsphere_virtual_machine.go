@@ -5,14 +5,19 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/contentlibrary"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/cryptomanager"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/customattribute"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/datastore"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/folder"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/ovfdeploy"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/resourcepool"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/spbm"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/storagepod"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/vappcontainer"
@@ -22,6 +27,8 @@ import (
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/vmworkflow"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vapi/vcenter"
+	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 )
 
@@ -79,6 +86,12 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 			ConflictsWith: []string{"datastore_id"},
 			Description:   "The ID of a datastore cluster to put the virtual machine in.",
 		},
+		"storage_policy_id": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Computed:    true,
+			Description: "The ID of the storage policy to assign to the virtual machine home directory. Removing it leaves the current policy in place.",
+		},
 		"folder": {
 			Type:        schema.TypeString,
 			Optional:    true,
@@ -140,7 +153,7 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 			Optional:     true,
 			Default:      1,
 			Description:  "The number of SCSI controllers that Terraform manages on this virtual machine. This directly affects the amount of disks you can add to the virtual machine and the maximum disk unit number. Note that lowering this value does not remove controllers.",
-			ValidateFunc: validation.IntBetween(1, 4),
+			ValidateFunc: validation.IntBetween(0, 4),
 		},
 		"scsi_type": {
 			Type:         schema.TypeString,
@@ -156,6 +169,38 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 			Description:  "Mode for sharing the SCSI bus. The modes are physicalSharing, virtualSharing, and noSharing.",
 			ValidateFunc: validation.StringInSlice(virtualdevice.SCSIBusSharingAllowedValues, false),
 		},
+		"sata_controller_count": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      0,
+			Description:  "The number of SATA controllers that Terraform manages on this virtual machine. This directly affects the amount of SATA disks you can add to the virtual machine and the maximum SATA disk unit number. Note that lowering this value does not remove controllers.",
+			ValidateFunc: validation.IntBetween(0, 4),
+		},
+		"nvme_controller_count": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      0,
+			Description:  "The number of NVMe controllers that Terraform manages on this virtual machine. This directly affects the amount of NVMe disks you can add to the virtual machine and the maximum NVMe disk unit number. Note that lowering this value does not remove controllers.",
+			ValidateFunc: validation.IntBetween(0, 4),
+		},
+		"ide_controller_count": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      0,
+			Description:  "The number of IDE controllers that Terraform manages disks on for this virtual machine. This directly affects the amount of IDE disks you can add to the virtual machine and the maximum IDE disk unit number.",
+			ValidateFunc: validation.IntBetween(0, 2),
+		},
+		"pci_device_id": {
+			Type:        schema.TypeSet,
+			Optional:    true,
+			Description: "A list of host PCI device IDs to create DirectPath I/O passthrough devices for.",
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+		"shared_pci_device": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The shared PCI device (vGPU) profile to attach to the virtual machine, for example grid_p40-4q.",
+		},
 		// NOTE: disk is only optional so that we can flag it as computed and use
 		// it in ResourceDiff. We validate this field in ResourceDiff to enforce it
 		// having a minimum count of 1 for now - but may support diskless VMs
@@ -182,6 +227,25 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 			MaxItems:    1,
 			Elem:        &schema.Resource{Schema: virtualdevice.CdromSubresourceSchema()},
 		},
+		"vtpm": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "A specification for a virtual Trusted Platform Module (TPM) device on this virtual machine.",
+			MaxItems:    1,
+			Elem:        &schema.Resource{Schema: virtualdevice.VTPMSubresourceSchema()},
+		},
+		"encryption": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "A specification for encrypting the virtual machine home and disks.",
+			MaxItems:    1,
+			Elem:        &schema.Resource{Schema: schemaVirtualMachineEncryption(false)},
+		},
+		"crypto_state": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "The encryption state of the virtual machine.",
+		},
 		"clone": {
 			Type:        schema.TypeList,
 			Optional:    true,
@@ -189,6 +253,14 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 			MaxItems:    1,
 			Elem:        &schema.Resource{Schema: vmworkflow.VirtualMachineCloneSchema()},
 		},
+		"ovf_deploy": {
+			Type:          schema.TypeList,
+			Optional:      true,
+			Description:   "A specification for deploying a virtual machine from an OVF or OVA package.",
+			MaxItems:      1,
+			ConflictsWith: []string{"clone"},
+			Elem:          &schema.Resource{Schema: vmworkflow.VirtualMachineOvfDeploySchema()},
+		},
 		"reboot_required": {
 			Type:        schema.TypeBool,
 			Computed:    true,
@@ -256,6 +328,8 @@ func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{
 	switch {
 	case len(d.Get("clone").([]interface{})) > 0:
 		vm, err = resourceVSphereVirtualMachineCreateClone(d, meta)
+	case len(d.Get("ovf_deploy").([]interface{})) > 0:
+		vm, err = resourceVSphereVirtualMachineCreateOvf(d, meta)
 	default:
 		vm, err = resourceVSphereVirtualMachineCreateBare(d, meta)
 	}
@@ -423,9 +497,12 @@ func resourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{})
 
 	// Perform pending device read operations.
 	devices := object.VirtualDeviceList(vprops.Config.Hardware.Device)
-	// Read the state of the SCSI bus.
-	d.Set("scsi_type", virtualdevice.ReadSCSIBusType(devices, d.Get("scsi_controller_count").(int)))
-	d.Set("scsi_bus_sharing", virtualdevice.ReadSCSIBusSharing(devices, d.Get("scsi_controller_count").(int)))
+	// Read the state of the SCSI bus. There is nothing to read if Terraform
+	// does not manage any SCSI controllers.
+	if scsiCount := d.Get("scsi_controller_count").(int); scsiCount > 0 {
+		d.Set("scsi_type", virtualdevice.ReadSCSIBusType(devices, scsiCount))
+		d.Set("scsi_bus_sharing", virtualdevice.ReadSCSIBusSharing(devices, scsiCount))
+	}
 	// Disks first
 	if err := virtualdevice.DiskRefreshOperation(d, client, devices); err != nil {
 		return err
@@ -438,6 +515,32 @@ func resourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{})
 	if err := virtualdevice.CdromRefreshOperation(d, client, devices); err != nil {
 		return err
 	}
+	// PCI passthrough devices
+	if err := virtualdevice.PciPassthroughRefreshOperation(d, devices); err != nil {
+		return err
+	}
+	// Virtual TPM
+	if err := virtualdevice.VTPMRefreshOperation(d, devices); err != nil {
+		return err
+	}
+	// Encryption. This needs to come after the virtual TPM, as a virtual TPM
+	// encrypts the virtual machine home on its own.
+	if err := flattenVirtualMachineCryptoInfo(d, vprops.Config.KeyId, vprops.Runtime.CryptoState); err != nil {
+		return err
+	}
+
+	// Read the storage policies of the VM home and disks if we have a
+	// connection to the SPBM endpoint.
+	if pc := meta.(*VSphereClient).pbmClient; pc != nil {
+		policyID, err := spbm.PolicyIDByVirtualMachine(pc, moid)
+		if err != nil {
+			return fmt.Errorf("error reading virtual machine storage policy: %s", err)
+		}
+		d.Set("storage_policy_id", policyID)
+		if err := virtualdevice.DiskStoragePolicyRefreshOperation(d, pc, moid); err != nil {
+			return err
+		}
+	}
 
 	// Read tags if we have the ability to do so
 	if tagsClient, _ := meta.(*VSphereClient).TagsManager(); tagsClient != nil {
@@ -542,6 +645,10 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 	if spec.DeviceChange, err = applyVirtualDevices(d, client, devices); err != nil {
 		return err
 	}
+	if err = resourceVSphereVirtualMachineApplyCrypto(d, client, devices, vprops.Config.KeyId, &spec); err != nil {
+		return err
+	}
+	changed = changed || spec.Crypto != nil
 	// Only carry out the reconfigure if we actually have a change to process.
 	if changed || len(spec.DeviceChange) > 0 {
 		//Check to see if we need to shutdown the VM for this process.
@@ -700,6 +807,11 @@ func resourceVSphereVirtualMachineCustomizeDiff(d *schema.ResourceDiff, meta int
 		}
 	}
 
+	// Storage policies are only available through vCenter.
+	if err := resourceVSphereVirtualMachineCustomizeDiffStoragePolicyOperation(d, meta); err != nil {
+		return err
+	}
+
 	// Validate cdrom sub-resources
 	if err := virtualdevice.CdromDiffOperation(d, client); err != nil {
 		return err
@@ -710,6 +822,26 @@ func resourceVSphereVirtualMachineCustomizeDiff(d *schema.ResourceDiff, meta int
 		return err
 	}
 
+	// Validate PCI passthrough devices
+	if err := virtualdevice.PciPassthroughDiffOperation(d); err != nil {
+		return err
+	}
+
+	// Validate virtual TPM and VBS prerequisites
+	if err := resourceVSphereVirtualMachineCustomizeDiffSecurityOperation(d, client); err != nil {
+		return err
+	}
+
+	// Validate encryption settings
+	if err := resourceVSphereVirtualMachineCustomizeDiffEncryptionOperation(d, client); err != nil {
+		return err
+	}
+
+	// Make sure cloud-init keys are not being managed twice
+	if err := resourceVSphereVirtualMachineCustomizeDiffCloudInitOperation(d); err != nil {
+		return err
+	}
+
 	// Process changes to resource pool
 	if err := resourceVSphereVirtualMachineCustomizeDiffResourcePoolOperation(d); err != nil {
 		return err
@@ -744,7 +876,15 @@ func resourceVSphereVirtualMachineCustomizeDiff(d *schema.ResourceDiff, meta int
 			// flagging the imported flag to off.
 			d.SetNew("imported", false)
 		case d.Id() == "":
-			if err := vmworkflow.ValidateVirtualMachineClone(d, client); err != nil {
+			fromLibrary, err := resourceVSphereVirtualMachineIsLibraryItem(meta, d.Get("clone.0.template_uuid").(string))
+			if err != nil {
+				return err
+			}
+			if fromLibrary {
+				if err := resourceVSphereVirtualMachineCustomizeDiffLibraryItemOperation(d); err != nil {
+					return err
+				}
+			} else if err := vmworkflow.ValidateVirtualMachineClone(d, client); err != nil {
 				return err
 			}
 			fallthrough
@@ -764,6 +904,13 @@ func resourceVSphereVirtualMachineCustomizeDiff(d *schema.ResourceDiff, meta int
 			}
 		}
 	}
+	// If we are deploying from an OVF package, perform the OVF validation
+	// operations.
+	if len(d.Get("ovf_deploy").([]interface{})) > 0 {
+		if err := resourceVSphereVirtualMachineCustomizeDiffOvfDeployOperation(d); err != nil {
+			return err
+		}
+	}
 	// Validate that the config has the necessary components for vApp support.
 	// Note that for clones the data is prepopulated in
 	// ValidateVirtualMachineClone.
@@ -775,6 +922,22 @@ func resourceVSphereVirtualMachineCustomizeDiff(d *schema.ResourceDiff, meta int
 	return nil
 }
 
+// resourceVSphereVirtualMachineCustomizeDiffLibraryItemOperation validates
+// the configuration of a virtual machine cloned from a content library item.
+// The template of a library item is not a virtual machine, so most of the
+// clone validation does not apply; only settings that cannot be used with a
+// library deployment are checked.
+func resourceVSphereVirtualMachineCustomizeDiffLibraryItemOperation(d *schema.ResourceDiff) error {
+	log.Printf("[DEBUG] %s: Validating clone from content library item %q", resourceVSphereVirtualMachineIDString(d), d.Get("clone.0.template_uuid").(string))
+	if d.Get("clone.0.linked_clone").(bool) {
+		return errors.New("linked_clone cannot be used when cloning from a content library item")
+	}
+	if _, ok := d.GetOk("datastore_cluster_id"); ok {
+		return errors.New("datastore_cluster_id cannot be used when cloning from a content library item")
+	}
+	return nil
+}
+
 func resourceVSphereVirtualMachineCustomizeDiffResourcePoolOperation(d *schema.ResourceDiff) error {
 	if d.HasChange("resource_pool_id") && !d.HasChange("host_system_id") {
 		log.Printf(
@@ -788,6 +951,144 @@ func resourceVSphereVirtualMachineCustomizeDiffResourcePoolOperation(d *schema.R
 	return nil
 }
 
+// resourceVSphereVirtualMachineCustomizeDiffOvfDeployOperation validates the
+// ovf_deploy sub-resource block. Changes to the block after the virtual
+// machine has been deployed force a new resource, with the exception of
+// options that only affect the deploy process itself.
+func resourceVSphereVirtualMachineCustomizeDiffOvfDeployOperation(d *schema.ResourceDiff) error {
+	if _, ok := d.GetOk("datastore_cluster_id"); ok {
+		return errors.New("ovf_deploy cannot be used with datastore_cluster_id")
+	}
+	if d.NewValueKnown("ovf_deploy.0.local_ovf_path") && d.NewValueKnown("ovf_deploy.0.remote_ovf_url") {
+		if d.Get("ovf_deploy.0.local_ovf_path").(string) == "" && d.Get("ovf_deploy.0.remote_ovf_url").(string) == "" {
+			return errors.New("ovf_deploy: one of local_ovf_path or remote_ovf_url must be specified")
+		}
+	}
+	switch {
+	case d.Get("imported").(bool):
+		// As with clones, imported workflows need to have the configuration of
+		// the ovf_deploy sub-resource block persisted to state without forcing a
+		// new resource.
+		d.SetNew("imported", false)
+		return nil
+	case d.Id() == "":
+		return nil
+	}
+	for _, k := range d.GetChangedKeysPrefix("ovf_deploy.0") {
+		// Trim the key down to the attribute in the sub-resource so that changes
+		// to elements of ovf_network_map are flagged on the map itself.
+		k = strings.Join(strings.SplitN(k, ".", 4)[:3], ".")
+		switch k {
+		case "ovf_deploy.0.timeout", "ovf_deploy.0.allow_unverified_ssl_cert":
+			continue
+		}
+		d.ForceNew(k)
+	}
+	return nil
+}
+
+// resourceVSphereVirtualMachineCustomizeDiffSecurityOperation validates the
+// prerequisites for the virtual TPM device and virtualization-based security.
+// Both require vSphere 6.7 and EFI firmware. A virtual TPM also requires a
+// default key provider to be registered with vCenter, as the virtual machine
+// home is encrypted when the device is added.
+func resourceVSphereVirtualMachineCustomizeDiffSecurityOperation(d *schema.ResourceDiff, client *govmomi.Client) error {
+	vtpm := len(d.Get("vtpm").([]interface{})) > 0
+	vbs := d.Get("vbs_enabled").(bool)
+	if !vtpm && !vbs {
+		return nil
+	}
+	version := viapi.ParseVersionFromClient(client)
+	if version.Older(viapi.VSphereVersion{Product: version.Product, Major: 6, Minor: 7}) {
+		return errors.New("vtpm and vbs_enabled are only supported on vSphere 6.7 and higher")
+	}
+	if d.NewValueKnown("firmware") && d.Get("firmware").(string) != string(types.GuestOsDescriptorFirmwareTypeEfi) {
+		return errors.New("vtpm and vbs_enabled require firmware to be set to efi")
+	}
+	if vbs {
+		for _, k := range []string{"efi_secure_boot_enabled", "vvtd_enabled", "nested_hv_enabled"} {
+			if d.NewValueKnown(k) && !d.Get(k).(bool) {
+				return fmt.Errorf("vbs_enabled requires %s to be set to true", k)
+			}
+		}
+	}
+	if vtpm && d.HasChange("vtpm") {
+		if err := viapi.ValidateVirtualCenter(client); err != nil {
+			return errors.New("vtpm requires vCenter")
+		}
+		kp, err := cryptomanager.DefaultKeyProvider(client)
+		if err != nil {
+			return fmt.Errorf("error checking key providers for vtpm: %s", err)
+		}
+		if kp == nil {
+			return errors.New("vtpm requires a default key provider to be configured in vCenter")
+		}
+	}
+	return nil
+}
+
+// resourceVSphereVirtualMachineCustomizeDiffEncryptionOperation validates the
+// encryption sub-resource. Encryption requires vCenter 6.5 or higher, and the
+// key provider needs to be registered with vCenter, which can only be checked
+// when the key provider ID is known at plan time.
+func resourceVSphereVirtualMachineCustomizeDiffEncryptionOperation(d *schema.ResourceDiff, client *govmomi.Client) error {
+	if len(d.Get("encryption").([]interface{})) < 1 || !d.HasChange("encryption") {
+		return nil
+	}
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return errors.New("encryption requires vCenter")
+	}
+	version := viapi.ParseVersionFromClient(client)
+	if version.Older(viapi.VSphereVersion{Product: version.Product, Major: 6, Minor: 5}) {
+		return errors.New("encryption is only supported on vSphere 6.5 and higher")
+	}
+	if !d.NewValueKnown("encryption.0.key_provider_id") {
+		return nil
+	}
+	id := d.Get("encryption.0.key_provider_id").(string)
+	kp, err := cryptomanager.KeyProvider(client, id)
+	if err != nil {
+		return fmt.Errorf("error checking key provider %q: %s", id, err)
+	}
+	if kp == nil {
+		return fmt.Errorf("key provider %q is not registered with vCenter", id)
+	}
+	return nil
+}
+
+// resourceVSphereVirtualMachineCustomizeDiffCloudInitOperation checks to make
+// sure that the guestinfo keys rendered by the cloud_init sub-resource are not
+// also being set through extra_config.
+func resourceVSphereVirtualMachineCustomizeDiffCloudInitOperation(d *schema.ResourceDiff) error {
+	if len(d.Get("cloud_init").([]interface{})) < 1 {
+		return nil
+	}
+	for k := range d.Get("extra_config").(map[string]interface{}) {
+		if isCloudInitExtraConfigKey(k) {
+			return fmt.Errorf("extra_config key %q cannot be set when cloud_init is in use", k)
+		}
+	}
+	return nil
+}
+
+// resourceVSphereVirtualMachineCustomizeDiffStoragePolicyOperation checks to
+// make sure that storage policies are only being used on connections that
+// support policy based management.
+func resourceVSphereVirtualMachineCustomizeDiffStoragePolicyOperation(d *schema.ResourceDiff, meta interface{}) error {
+	if meta.(*VSphereClient).pbmClient != nil {
+		return nil
+	}
+	if d.Get("storage_policy_id").(string) != "" {
+		return errors.New("storage_policy_id requires a vCenter connection")
+	}
+	for i, v := range d.Get("disk").([]interface{}) {
+		if v.(map[string]interface{})["storage_policy_id"].(string) != "" {
+			return fmt.Errorf("disk.%d: storage_policy_id requires a vCenter connection", i)
+		}
+	}
+	return nil
+}
+
 func datastoreClusterDiffOperation(d *schema.ResourceDiff, client *govmomi.Client) error {
 	if !structure.ValuesAvailable("", []string{"datastore_cluster_id", "datastore_id"}, d) {
 		log.Printf("[DEBUG] DatastoreClusterDiffOperation: datastore_id or datastore_cluster_id value depends on a computed value from another resource. Skipping validation.")
@@ -877,33 +1178,24 @@ func resourceVSphereVirtualMachineImport(d *schema.ResourceData, meta interface{
 		return nil, fmt.Errorf("VM %q is a template and cannot be imported", name)
 	}
 
-	// Quickly walk the SCSI bus and determine the number of contiguous
-	// controllers starting from bus number 0. This becomes the current SCSI
-	// controller count. Anything past this is managed by config.
-	log.Printf("[DEBUG] Determining number of SCSI controllers for VM %q", name)
-	scsiBus := make([]bool, 4)
-	for _, device := range props.Config.Hardware.Device {
-		sc, ok := device.(types.BaseVirtualSCSIController)
-		if !ok {
-			continue
-		}
-		scsiBus[sc.GetVirtualSCSIController().BusNumber] = true
-	}
-	var ctlrCnt int
-	for _, v := range scsiBus {
-		if !v {
-			break
-		}
-		ctlrCnt++
-	}
-	if ctlrCnt < 1 {
-		return nil, fmt.Errorf("VM %q has no SCSI controllers", name)
+	// Quickly walk the SCSI, SATA, NVMe, and IDE buses and determine the number
+	// of contiguous controllers of each type starting from bus number 0. This
+	// becomes the current controller count for each type. Anything past this is
+	// managed by config.
+	log.Printf("[DEBUG] Determining number of disk controllers for VM %q", name)
+	ctlrCnts := resourceVSphereVirtualMachineImportControllerCounts(object.VirtualDeviceList(props.Config.Hardware.Device))
+	if ctlrCnts[virtualdevice.SubresourceControllerTypeSCSI] < 1 && ctlrCnts[virtualdevice.SubresourceControllerTypeSATA] < 1 && ctlrCnts[virtualdevice.SubresourceControllerTypeNVMe] < 1 {
+		return nil, fmt.Errorf("VM %q has no SCSI, SATA, or NVMe controllers", name)
 	}
-	d.Set("scsi_controller_count", ctlrCnt)
+	d.Set("scsi_controller_count", ctlrCnts[virtualdevice.SubresourceControllerTypeSCSI])
+	d.Set("sata_controller_count", ctlrCnts[virtualdevice.SubresourceControllerTypeSATA])
+	d.Set("nvme_controller_count", ctlrCnts[virtualdevice.SubresourceControllerTypeNVMe])
+	d.Set("ide_controller_count", ctlrCnts[virtualdevice.SubresourceControllerTypeIDE])
 
 	// Validate the disks in the VM to make sure that they will work with the
-	// resource. This is mainly ensuring that all disks are SCSI disks, but a
-	// Read operation is attempted as well to make sure it will survive that.
+	// resource. This is mainly ensuring that all disks are on supported
+	// controllers, but a Read operation is attempted as well to make sure it
+	// will survive that.
 	if err := virtualdevice.DiskImportOperation(d, client, object.VirtualDeviceList(props.Config.Hardware.Device)); err != nil {
 		return nil, err
 	}
@@ -921,6 +1213,11 @@ func resourceVSphereVirtualMachineImport(d *schema.ResourceData, meta interface{
 	d.Set("wait_for_guest_ip_timeout", rs["wait_for_guest_ip_timeout"].Default)
 	d.Set("wait_for_guest_net_timeout", rs["wait_for_guest_net_timeout"].Default)
 	d.Set("wait_for_guest_net_routable", rs["wait_for_guest_net_routable"].Default)
+	// The SCSI bus is not read when there are no SCSI controllers to manage.
+	if ctlrCnts[virtualdevice.SubresourceControllerTypeSCSI] < 1 {
+		d.Set("scsi_type", rs["scsi_type"].Default)
+		d.Set("scsi_bus_sharing", rs["scsi_bus_sharing"].Default)
+	}
 
 	log.Printf("[DEBUG] %s: Import complete, resource is ready for read", resourceVSphereVirtualMachineIDString(d))
 	return []*schema.ResourceData{d}, nil
@@ -978,6 +1275,9 @@ func resourceVSphereVirtualMachineCreateBare(d *schema.ResourceData, meta interf
 	if spec.DeviceChange, err = applyVirtualDevices(d, client, devices); err != nil {
 		return nil, err
 	}
+	if err = resourceVSphereVirtualMachineApplyCrypto(d, client, devices, nil, &spec); err != nil {
+		return nil, err
+	}
 
 	// Create the VM according the right API path - if we have a datastore
 	// cluster, use the SDRS API, if not, use the standard API.
@@ -1087,23 +1387,36 @@ func resourceVSphereVirtualMachineCreateClone(d *schema.ResourceData, meta inter
 		return nil, err
 	}
 
-	// Expand the clone spec. We get the source VM here too.
-	cloneSpec, srcVM, err := vmworkflow.ExpandVirtualMachineCloneSpec(d, client)
+	// If the template is a content library item, deploy it through the content
+	// library instead of cloning.
+	var vm *object.VirtualMachine
+	fromLibrary, err := resourceVSphereVirtualMachineIsLibraryItem(meta, d.Get("clone.0.template_uuid").(string))
 	if err != nil {
 		return nil, err
 	}
-
-	// Start the clone
-	name := d.Get("name").(string)
-	timeout := d.Get("clone.0.timeout").(int)
-	var vm *object.VirtualMachine
-	if _, ok := d.GetOk("datastore_cluster_id"); ok {
-		vm, err = resourceVSphereVirtualMachineCreateCloneWithSDRS(d, meta, srcVM, fo, name, cloneSpec, timeout)
+	if fromLibrary {
+		vm, err = resourceVSphereVirtualMachineDeployLibraryItem(d, meta, pool, fo)
+		if err != nil {
+			return nil, fmt.Errorf("error deploying content library item: %s", err)
+		}
 	} else {
-		vm, err = virtualmachine.Clone(client, srcVM, fo, name, cloneSpec, timeout)
-	}
-	if err != nil {
-		return nil, fmt.Errorf("error cloning virtual machine: %s", err)
+		// Expand the clone spec. We get the source VM here too.
+		cloneSpec, srcVM, err := vmworkflow.ExpandVirtualMachineCloneSpec(d, client)
+		if err != nil {
+			return nil, err
+		}
+
+		// Start the clone
+		name := d.Get("name").(string)
+		timeout := d.Get("clone.0.timeout").(int)
+		if _, ok := d.GetOk("datastore_cluster_id"); ok {
+			vm, err = resourceVSphereVirtualMachineCreateCloneWithSDRS(d, meta, srcVM, fo, name, cloneSpec, timeout)
+		} else {
+			vm, err = virtualmachine.Clone(client, srcVM, fo, name, cloneSpec, timeout)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error cloning virtual machine: %s", err)
+		}
 	}
 
 	// The VM has been created. We still need to do post-clone configuration, and
@@ -1125,13 +1438,71 @@ func resourceVSphereVirtualMachineCreateClone(d *schema.ResourceData, meta inter
 	log.Printf("[DEBUG] VM %q - UUID is %q", vm.InventoryPath, vprops.Config.Uuid)
 	d.SetId(vprops.Config.Uuid)
 
+	// Disks of content library items could not be validated at diff time, so
+	// check them now.
+	if fromLibrary {
+		if err := resourceVSphereVirtualMachineValidateDeployedDisks(d, meta, vm, vprops, "content library item"); err != nil {
+			return nil, err
+		}
+	}
+
 	// Before starting or proceeding any further, we need to normalize the
-	// configuration of the newly cloned VM. This is basically a subset of update
-	// with the stipulation that there is currently no state to help move this
-	// along.
+	// configuration of the newly cloned VM.
+	if err := resourceVSphereVirtualMachinePostDeployChanges(d, meta, vm, vprops); err != nil {
+		return nil, err
+	}
+
+	var cw *virtualMachineCustomizationWaiter
+	// Send customization spec if any has been defined.
+	if len(d.Get("clone.0.customize").([]interface{})) > 0 {
+		family, err := resourcepool.OSFamily(client, pool, d.Get("guest_id").(string))
+		if err != nil {
+			return nil, fmt.Errorf("cannot find OS family for guest ID %q: %s", d.Get("guest_id").(string), err)
+		}
+		custSpec := vmworkflow.ExpandCustomizationSpec(d, family)
+		cw = newVirtualMachineCustomizationWaiter(client, vm, d.Get("clone.0.customize.0.timeout").(int))
+		if err := virtualmachine.Customize(vm, custSpec); err != nil {
+			// Roll back the VMs as per the error handling in reconfigure.
+			if derr := resourceVSphereVirtualMachineDelete(d, meta); derr != nil {
+				return nil, fmt.Errorf(formatVirtualMachinePostCloneRollbackError, vm.InventoryPath, err, derr)
+			}
+			d.SetId("")
+			return nil, fmt.Errorf("error sending customization spec: %s", err)
+		}
+	}
+	// Finally time to power on the virtual machine!
+	if err := virtualmachine.PowerOn(vm); err != nil {
+		return nil, fmt.Errorf("error powering on virtual machine: %s", err)
+	}
+	// If we customized, wait on customization.
+	if cw != nil {
+		log.Printf("[DEBUG] %s: Waiting for VM customization to complete", resourceVSphereVirtualMachineIDString(d))
+		<-cw.Done()
+		if err := cw.Err(); err != nil {
+			return nil, fmt.Errorf(formatVirtualMachineCustomizationWaitError, vm.InventoryPath, err)
+		}
+	}
+	// Clone is complete and ready to return
+	return vm, nil
+}
+
+// resourceVSphereVirtualMachinePostDeployChanges normalizes the configuration
+// of a newly deployed virtual machine (either cloned or deployed from an OVF
+// package) to match the configuration in the resource. This is basically a
+// subset of update with the stipulation that there is currently no state to
+// help move this along.
+//
+// Any error here causes the virtual machine to be rolled back.
+func resourceVSphereVirtualMachinePostDeployChanges(
+	d *schema.ResourceData,
+	meta interface{},
+	vm *object.VirtualMachine,
+	vprops *mo.VirtualMachine,
+) error {
+	client := meta.(*VSphereClient).vimClient
 	cfgSpec, err := expandVirtualMachineConfigSpec(d, client)
 	if err != nil {
-		return nil, resourceVSphereVirtualMachineRollbackCreate(
+		return resourceVSphereVirtualMachineRollbackCreate(
 			d,
 			meta,
 			vm,
@@ -1147,47 +1518,89 @@ func resourceVSphereVirtualMachineCreateClone(d *schema.ResourceData, meta inter
 	// First check the state of our SCSI bus. Normalize it if we need to.
 	devices, delta, err = virtualdevice.NormalizeSCSIBus(devices, d.Get("scsi_type").(string), d.Get("scsi_controller_count").(int), d.Get("scsi_bus_sharing").(string))
 	if err != nil {
-		return nil, resourceVSphereVirtualMachineRollbackCreate(
+		return resourceVSphereVirtualMachineRollbackCreate(
 			d,
 			meta,
 			vm,
-			fmt.Errorf("error normalizing SCSI bus post-clone: %s", err),
+			fmt.Errorf("error normalizing SCSI bus post-deploy: %s", err),
+		)
+	}
+	cfgSpec.DeviceChange = virtualdevice.AppendDeviceChangeSpec(cfgSpec.DeviceChange, delta...)
+	// Do the same for the SATA, NVMe, and IDE buses.
+	devices, delta, err = resourceVSphereVirtualMachineNormalizeBuses(d, devices)
+	if err != nil {
+		return resourceVSphereVirtualMachineRollbackCreate(
+			d,
+			meta,
+			vm,
+			fmt.Errorf("error normalizing disk controllers post-deploy: %s", err),
 		)
 	}
 	cfgSpec.DeviceChange = virtualdevice.AppendDeviceChangeSpec(cfgSpec.DeviceChange, delta...)
 	// Disks
 	devices, delta, err = virtualdevice.DiskPostCloneOperation(d, client, devices)
 	if err != nil {
-		return nil, resourceVSphereVirtualMachineRollbackCreate(
+		return resourceVSphereVirtualMachineRollbackCreate(
 			d,
 			meta,
 			vm,
-			fmt.Errorf("error processing disk changes post-clone: %s", err),
+			fmt.Errorf("error processing disk changes post-deploy: %s", err),
 		)
 	}
 	cfgSpec.DeviceChange = virtualdevice.AppendDeviceChangeSpec(cfgSpec.DeviceChange, delta...)
 	// Network devices
 	devices, delta, err = virtualdevice.NetworkInterfacePostCloneOperation(d, client, devices)
 	if err != nil {
-		return nil, resourceVSphereVirtualMachineRollbackCreate(
+		return resourceVSphereVirtualMachineRollbackCreate(
 			d,
 			meta,
 			vm,
-			fmt.Errorf("error processing network device changes post-clone: %s", err),
+			fmt.Errorf("error processing network device changes post-deploy: %s", err),
 		)
 	}
 	cfgSpec.DeviceChange = virtualdevice.AppendDeviceChangeSpec(cfgSpec.DeviceChange, delta...)
 	// CDROM
 	devices, delta, err = virtualdevice.CdromPostCloneOperation(d, client, devices)
 	if err != nil {
-		return nil, resourceVSphereVirtualMachineRollbackCreate(
+		return resourceVSphereVirtualMachineRollbackCreate(
+			d,
+			meta,
+			vm,
+			fmt.Errorf("error processing CDROM device changes post-deploy: %s", err),
+		)
+	}
+	cfgSpec.DeviceChange = virtualdevice.AppendDeviceChangeSpec(cfgSpec.DeviceChange, delta...)
+	// PCI passthrough devices
+	devices, delta, err = virtualdevice.PciPassthroughApplyOperation(d, client, devices)
+	if err != nil {
+		return resourceVSphereVirtualMachineRollbackCreate(
+			d,
+			meta,
+			vm,
+			fmt.Errorf("error processing PCI passthrough device changes post-deploy: %s", err),
+		)
+	}
+	cfgSpec.DeviceChange = virtualdevice.AppendDeviceChangeSpec(cfgSpec.DeviceChange, delta...)
+	// Virtual TPM
+	devices, delta, err = virtualdevice.VTPMApplyOperation(d, devices)
+	if err != nil {
+		return resourceVSphereVirtualMachineRollbackCreate(
 			d,
 			meta,
 			vm,
-			fmt.Errorf("error processing CDROM device changes post-clone: %s", err),
+			fmt.Errorf("error processing virtual TPM changes post-deploy: %s", err),
 		)
 	}
 	cfgSpec.DeviceChange = virtualdevice.AppendDeviceChangeSpec(cfgSpec.DeviceChange, delta...)
+	// Encryption
+	if err = resourceVSphereVirtualMachineApplyCrypto(d, client, devices, vprops.Config.KeyId, &cfgSpec); err != nil {
+		return resourceVSphereVirtualMachineRollbackCreate(
+			d,
+			meta,
+			vm,
+			fmt.Errorf("error processing encryption changes post-deploy: %s", err),
+		)
+	}
 	log.Printf("[DEBUG] %s: Final device list: %s", resourceVSphereVirtualMachineIDString(d), virtualdevice.DeviceListString(devices))
 	log.Printf("[DEBUG] %s: Final device change cfgSpec: %s", resourceVSphereVirtualMachineIDString(d), virtualdevice.DeviceChangeString(cfgSpec.DeviceChange))
 
@@ -1198,48 +1611,177 @@ func resourceVSphereVirtualMachineCreateClone(d *schema.ResourceData, meta inter
 		err = virtualmachine.Reconfigure(vm, cfgSpec)
 	}
 	if err != nil {
-		return nil, resourceVSphereVirtualMachineRollbackCreate(
+		return resourceVSphereVirtualMachineRollbackCreate(
 			d,
 			meta,
 			vm,
 			fmt.Errorf("error reconfiguring virtual machine: %s", err),
 		)
 	}
+	return nil
+}
 
-	var cw *virtualMachineCustomizationWaiter
-	// Send customization spec if any has been defined.
-	if len(d.Get("clone.0.customize").([]interface{})) > 0 {
-		family, err := resourcepool.OSFamily(client, pool, d.Get("guest_id").(string))
-		if err != nil {
-			return nil, fmt.Errorf("cannot find OS family for guest ID %q: %s", d.Get("guest_id").(string), err)
-		}
-		custSpec := vmworkflow.ExpandCustomizationSpec(d, family)
-		cw = newVirtualMachineCustomizationWaiter(client, vm, d.Get("clone.0.customize.0.timeout").(int))
-		if err := virtualmachine.Customize(vm, custSpec); err != nil {
-			// Roll back the VMs as per the error handling in reconfigure.
-			if derr := resourceVSphereVirtualMachineDelete(d, meta); derr != nil {
-				return nil, fmt.Errorf(formatVirtualMachinePostCloneRollbackError, vm.InventoryPath, err, derr)
-			}
-			d.SetId("")
-			return nil, fmt.Errorf("error sending customization spec: %s", err)
+// resourceVSphereVirtualMachineCreateOvf contains the OVF/OVA deploy path. The
+// VM is returned.
+func resourceVSphereVirtualMachineCreateOvf(d *schema.ResourceData, meta interface{}) (*object.VirtualMachine, error) {
+	log.Printf("[DEBUG] %s: VM being created from OVF package", resourceVSphereVirtualMachineIDString(d))
+	client := meta.(*VSphereClient).vimClient
+
+	poolID := d.Get("resource_pool_id").(string)
+	pool, err := resourcepool.FromID(client, poolID)
+	if err != nil {
+		return nil, fmt.Errorf("could not find resource pool ID %q: %s", poolID, err)
+	}
+	fo, err := folder.VirtualMachineFolderFromObject(client, pool, d.Get("folder").(string))
+	if err != nil {
+		return nil, err
+	}
+	var hs *object.HostSystem
+	if v, ok := d.GetOk("host_system_id"); ok {
+		hsID := v.(string)
+		var err error
+		if hs, err = hostsystem.FromID(client, hsID); err != nil {
+			return nil, fmt.Errorf("error locating host system at ID %q: %s", hsID, err)
 		}
 	}
-	// Finally time to power on the virtual machine!
+	if err := resourcepool.ValidateHost(client, pool, hs); err != nil {
+		return nil, err
+	}
+	ds, err := datastore.FromID(client, d.Get("datastore_id").(string))
+	if err != nil {
+		return nil, fmt.Errorf("error locating datastore for VM: %s", err)
+	}
+
+	// Fetch the descriptor and create the import spec.
+	src, err := ovfdeploy.NewSource(
+		d.Get("ovf_deploy.0.local_ovf_path").(string),
+		d.Get("ovf_deploy.0.remote_ovf_url").(string),
+		d.Get("ovf_deploy.0.allow_unverified_ssl_cert").(bool),
+	)
+	if err != nil {
+		return nil, err
+	}
+	descriptor, err := src.Descriptor()
+	if err != nil {
+		return nil, err
+	}
+	params, err := vmworkflow.ExpandOvfCreateImportSpecParams(d, client)
+	if err != nil {
+		return nil, err
+	}
+	importSpec, err := ovfdeploy.CreateImportSpec(client, descriptor, pool, ds, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// Import the package and upload its files.
+	vm, err := ovfdeploy.Deploy(client, src, importSpec, pool, fo, hs, d.Get("ovf_deploy.0.timeout").(int))
+	if err != nil {
+		return nil, fmt.Errorf("error deploying OVF package: %s", err)
+	}
+
+	// The VM has been created. As with clones, any failure until the
+	// post-deploy configuration is complete rolls the VM back.
+	vprops, err := virtualmachine.Properties(vm)
+	if err != nil {
+		return nil, resourceVSphereVirtualMachineRollbackCreate(
+			d,
+			meta,
+			vm,
+			fmt.Errorf("cannot fetch properties of created virtual machine: %s", err),
+		)
+	}
+	log.Printf("[DEBUG] VM %q - UUID is %q", vm.InventoryPath, vprops.Config.Uuid)
+	d.SetId(vprops.Config.Uuid)
+
+	if err := resourceVSphereVirtualMachineValidateDeployedDisks(d, meta, vm, vprops, "OVF package"); err != nil {
+		return nil, err
+	}
+
+	// Normalize the configuration of the deployed VM. This also applies any
+	// vApp properties.
+	if err := resourceVSphereVirtualMachinePostDeployChanges(d, meta, vm, vprops); err != nil {
+		return nil, err
+	}
+
 	if err := virtualmachine.PowerOn(vm); err != nil {
 		return nil, fmt.Errorf("error powering on virtual machine: %s", err)
 	}
-	// If we customized, wait on customization.
-	if cw != nil {
-		log.Printf("[DEBUG] %s: Waiting for VM customization to complete", resourceVSphereVirtualMachineIDString(d))
-		<-cw.Done()
-		if err := cw.Err(); err != nil {
-			return nil, fmt.Errorf(formatVirtualMachineCustomizationWaitError, vm.InventoryPath, err)
-		}
-	}
-	// Clone is complete and ready to return
 	return vm, nil
 }
 
+// resourceVSphereVirtualMachineValidateDeployedDisks checks that all of the
+// disks on a newly deployed virtual machine are defined in configuration, as
+// the disk sub-resource expects. The virtual machine is rolled back if they are
+// not. source describes where the disks came from, for use in the error
+// message.
+func resourceVSphereVirtualMachineValidateDeployedDisks(
+	d *schema.ResourceData,
+	meta interface{},
+	vm *object.VirtualMachine,
+	vprops *mo.VirtualMachine,
+	source string,
+) error {
+	n := len(virtualdevice.SelectDisks(object.VirtualDeviceList(vprops.Config.Hardware.Device), virtualdevice.DiskControllerCounts(d)))
+	c := len(d.Get("disk").([]interface{}))
+	if n > c {
+		return resourceVSphereVirtualMachineRollbackCreate(
+			d,
+			meta,
+			vm,
+			fmt.Errorf("%s contains %d disks, but only %d are defined in configuration", source, n, c),
+		)
+	}
+	return nil
+}
+
+// resourceVSphereVirtualMachineIsLibraryItem checks to see if the supplied ID
+// refers to a content library item. false is returned if content libraries are
+// not available on the current connection.
+func resourceVSphereVirtualMachineIsLibraryItem(meta interface{}, id string) (bool, error) {
+	rc := meta.(*VSphereClient).restClient
+	if rc == nil || id == "" {
+		return false, nil
+	}
+	ok, err := contentlibrary.IsItem(rc, id)
+	if err != nil {
+		return false, fmt.Errorf("error checking if %q is a content library item: %s", id, err)
+	}
+	return ok, nil
+}
+
+// resourceVSphereVirtualMachineDeployLibraryItem deploys the OVF template in
+// the content library item referenced by clone.0.template_uuid into the
+// supplied resource pool and folder, and returns the new virtual machine.
+func resourceVSphereVirtualMachineDeployLibraryItem(
+	d *schema.ResourceData,
+	meta interface{},
+	pool *object.ResourcePool,
+	fo *object.Folder,
+) (*object.VirtualMachine, error) {
+	client := meta.(*VSphereClient).vimClient
+	rc := meta.(*VSphereClient).restClient
+	deploy := vcenter.Deploy{
+		DeploymentSpec: vcenter.DeploymentSpec{
+			Name:               d.Get("name").(string),
+			AcceptAllEULA:      true,
+			DefaultDatastoreID: d.Get("datastore_id").(string),
+			StorageProfileID:   d.Get("storage_policy_id").(string),
+		},
+		Target: vcenter.Target{
+			ResourcePoolID: pool.Reference().Value,
+			HostID:         d.Get("host_system_id").(string),
+			FolderID:       fo.Reference().Value,
+		},
+	}
+	timeout := time.Minute * time.Duration(d.Get("clone.0.timeout").(int))
+	ref, err := contentlibrary.DeployItem(rc, d.Get("clone.0.template_uuid").(string), deploy, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return virtualmachine.FromMOID(client, ref.Value)
+}
+
 // resourceVSphereVirtualMachineCreateCloneWithSDRS runs the clone part of
 // resourceVSphereVirtualMachineCreateClone through storage DRS. It's designed
 // to be run when a storage cluster is specified, versus simply specifying
@@ -1369,6 +1911,10 @@ func resourceVSphereVirtualMachineUpdateLocation(d *schema.ResourceData, meta in
 		spec.Host = &hsRef
 	}
 
+	if policyID := d.Get("storage_policy_id").(string); policyID != "" {
+		spec.Profile = spbm.PolicySpecByID(policyID)
+	}
+
 	spec.Disk = relocators
 
 	// Ready to perform migration
@@ -1428,6 +1974,16 @@ func applyVirtualDevices(d *schema.ResourceData, c *govmomi.Client, l object.Vir
 		d.Set("reboot_required", true)
 	}
 	spec = virtualdevice.AppendDeviceChangeSpec(spec, delta...)
+	// Do the same for the SATA, NVMe, and IDE buses.
+	l, delta, err = resourceVSphereVirtualMachineNormalizeBuses(d, l)
+	if err != nil {
+		return nil, err
+	}
+	if len(delta) > 0 {
+		log.Printf("[DEBUG] %s: Disk controllers have changed and require a VM restart", resourceVSphereVirtualMachineIDString(d))
+		d.Set("reboot_required", true)
+	}
+	spec = virtualdevice.AppendDeviceChangeSpec(spec, delta...)
 	// Disks
 	l, delta, err = virtualdevice.DiskApplyOperation(d, c, l)
 	if err != nil {
@@ -1446,13 +2002,135 @@ func applyVirtualDevices(d *schema.ResourceData, c *govmomi.Client, l object.Vir
 		return nil, err
 	}
 	spec = virtualdevice.AppendDeviceChangeSpec(spec, delta...)
+	// PCI passthrough devices. These cannot be hot-added or removed.
+	l, delta, err = virtualdevice.PciPassthroughApplyOperation(d, c, l)
+	if err != nil {
+		return nil, err
+	}
+	if len(delta) > 0 {
+		log.Printf("[DEBUG] %s: PCI passthrough devices have changed and require a VM restart", resourceVSphereVirtualMachineIDString(d))
+		d.Set("reboot_required", true)
+	}
+	spec = virtualdevice.AppendDeviceChangeSpec(spec, delta...)
+	// Virtual TPM. This cannot be hot-added or removed either.
+	l, delta, err = virtualdevice.VTPMApplyOperation(d, l)
+	if err != nil {
+		return nil, err
+	}
+	if len(delta) > 0 {
+		log.Printf("[DEBUG] %s: Virtual TPM has changed and requires a VM restart", resourceVSphereVirtualMachineIDString(d))
+		d.Set("reboot_required", true)
+	}
+	spec = virtualdevice.AppendDeviceChangeSpec(spec, delta...)
 	log.Printf("[DEBUG] %s: Final device list: %s", resourceVSphereVirtualMachineIDString(d), virtualdevice.DeviceListString(l))
 	log.Printf("[DEBUG] %s: Final device change spec: %s", resourceVSphereVirtualMachineIDString(d), virtualdevice.DeviceChangeString(spec))
 	return spec, nil
 }
 
+// resourceVSphereVirtualMachineApplyCrypto sets the crypto spec of the
+// supplied config spec from the encryption sub-resource, and applies the
+// matching crypto specs to the disks in its device change spec. current is the
+// key that the virtual machine is currently encrypted with, if any, and l is
+// the device list that the device change spec was computed from.
+func resourceVSphereVirtualMachineApplyCrypto(
+	d *schema.ResourceData,
+	c *govmomi.Client,
+	l object.VirtualDeviceList,
+	current *types.CryptoKeyId,
+	spec *types.VirtualMachineConfigSpec,
+) error {
+	vmCrypto, diskCrypto, err := expandVirtualMachineCryptoSpec(d, c, current)
+	if err != nil {
+		return fmt.Errorf("error processing encryption: %s", err)
+	}
+	spec.Crypto = vmCrypto
+	var powerOff bool
+	spec.DeviceChange, powerOff, err = applyVirtualMachineDiskCrypto(l, spec.DeviceChange, diskCrypto)
+	if err != nil {
+		return err
+	}
+	if powerOff {
+		log.Printf("[DEBUG] %s: Disk encryption has changed and requires a VM restart", resourceVSphereVirtualMachineIDString(d))
+		d.Set("reboot_required", true)
+	}
+	return nil
+}
+
 // resourceVSphereVirtualMachineIDString prints a friendly string for the
 // vsphere_virtual_machine resource.
 func resourceVSphereVirtualMachineIDString(d structure.ResourceIDStringer) string {
 	return structure.ResourceIDString(d, "vsphere_virtual_machine")
 }
+
+// resourceVSphereVirtualMachineNormalizeBuses creates any SATA, NVMe, or IDE
+// controllers that are missing on the virtual machine, up to the controller
+// counts defined in configuration. The SCSI bus is handled separately by
+// NormalizeSCSIBus.
+func resourceVSphereVirtualMachineNormalizeBuses(d *schema.ResourceData, l object.VirtualDeviceList) (object.VirtualDeviceList, []types.BaseVirtualDeviceConfigSpec, error) {
+	var spec []types.BaseVirtualDeviceConfigSpec
+	for _, ct := range []string{
+		virtualdevice.SubresourceControllerTypeSATA,
+		virtualdevice.SubresourceControllerTypeNVMe,
+		virtualdevice.SubresourceControllerTypeIDE,
+	} {
+		var delta []types.BaseVirtualDeviceConfigSpec
+		var err error
+		l, delta, err = virtualdevice.NormalizeBus(l, ct, virtualdevice.DiskControllerCounts(d)[ct])
+		if err != nil {
+			return nil, nil, fmt.Errorf("error normalizing %s bus: %s", ct, err)
+		}
+		spec = append(spec, delta...)
+	}
+	return l, spec, nil
+}
+
+// resourceVSphereVirtualMachineImportControllerCounts walks the disk
+// controllers in the supplied device list and returns the number of contiguous
+// controllers of each type, starting from bus number 0, keyed by controller
+// type. SATA, NVMe, and IDE controllers are only counted if there are disks
+// attached to controllers of that type, as virtual machines commonly carry
+// these controllers for CD-ROM devices only.
+func resourceVSphereVirtualMachineImportControllerCounts(l object.VirtualDeviceList) map[string]int {
+	buses := make(map[string][]bool)
+	ctlrTypes := make(map[int32]string)
+	for _, device := range l {
+		var ct string
+		switch device.(type) {
+		case types.BaseVirtualSCSIController:
+			ct = virtualdevice.SubresourceControllerTypeSCSI
+		case *types.VirtualAHCIController:
+			ct = virtualdevice.SubresourceControllerTypeSATA
+		case *types.VirtualNVMEController:
+			ct = virtualdevice.SubresourceControllerTypeNVMe
+		case *types.VirtualIDEController:
+			ct = virtualdevice.SubresourceControllerTypeIDE
+		default:
+			continue
+		}
+		vc := device.(types.BaseVirtualController).GetVirtualController()
+		ctlrTypes[vc.Key] = ct
+		if buses[ct] == nil {
+			buses[ct] = make([]bool, 4)
+		}
+		if vc.BusNumber >= 0 && vc.BusNumber < 4 {
+			buses[ct][vc.BusNumber] = true
+		}
+	}
+	disks := make(map[string]bool)
+	for _, device := range l.SelectByType((*types.VirtualDisk)(nil)) {
+		disks[ctlrTypes[device.GetVirtualDevice().ControllerKey]] = true
+	}
+	counts := make(map[string]int)
+	for ct, bus := range buses {
+		if ct != virtualdevice.SubresourceControllerTypeSCSI && !disks[ct] {
+			continue
+		}
+		for _, v := range bus {
+			if !v {
+				break
+			}
+			counts[ct]++
+		}
+	}
+	return counts
+}
@@ -77,6 +77,80 @@ func TestAccResourceVSphereVirtualMachine_basic(t *testing.T) {
 	})
 }
 
+func TestAccResourceVSphereVirtualMachine_storagePolicy(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfEsxi(t)
+			if os.Getenv("VSPHERE_STORAGE_POLICY_ID") == "" {
+				t.Skip("set VSPHERE_STORAGE_POLICY_ID to run vsphere_virtual_machine storage policy acceptance tests")
+			}
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigStoragePolicy(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "storage_policy_id", os.Getenv("VSPHERE_STORAGE_POLICY_ID")),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "disk.0.storage_policy_id", os.Getenv("VSPHERE_STORAGE_POLICY_ID")),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualMachine_ovfDeploy(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+			if os.Getenv("VSPHERE_OVF_URL") == "" {
+				t.Skip("set VSPHERE_OVF_URL to run vsphere_virtual_machine OVF deploy acceptance tests")
+			}
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigOvfDeploy(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "disk.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualMachine_cloneFromContentLibrary(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+			if os.Getenv("VSPHERE_OVF_URL") == "" {
+				t.Skip("set VSPHERE_OVF_URL to run vsphere_virtual_machine content library clone acceptance tests")
+			}
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigCloneFromContentLibrary(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					resource.TestCheckResourceAttrPair(
+						"vsphere_virtual_machine.vm", "clone.0.template_uuid",
+						"vsphere_content_library_item.item", "id",
+					),
+				),
+			},
+		},
+	})
+}
+
 func TestAccResourceVSphereVirtualMachine_ignoreValidationOnComputedValue(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
@@ -465,27 +539,7 @@ func TestAccResourceVSphereVirtualMachine_highDiskUnitsToRegularSingleController
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_scsiBusSharing(t *testing.T) {
-	resource.Test(t, resource.TestCase{
-		PreCheck: func() {
-			testAccPreCheck(t)
-			testAccResourceVSphereVirtualMachinePreCheck(t)
-		},
-		Providers:    testAccProviders,
-		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
-		Steps: []resource.TestStep{
-			{
-				Config: testAccResourceVSphereVirtualMachineConfigSharedSCSIBus(),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckSCSIBusSharing(string(types.VirtualSCSISharingPhysicalSharing)),
-				),
-			},
-		},
-	})
-}
-
-func TestAccResourceVSphereVirtualMachine_scsiBusSharingUpdate(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_multipleControllerTypes(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -495,24 +549,37 @@ func TestAccResourceVSphereVirtualMachine_scsiBusSharingUpdate(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
+				Config: testAccResourceVSphereVirtualMachineConfigMultipleControllerTypes(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckSCSIBusSharing(string(types.VirtualSCSISharingNoSharing)),
+					testAccResourceVSphereVirtualMachineCheckDiskController("terraform-test.vmdk", virtualdevice.SubresourceControllerTypeSCSI, 0, 0),
+					testAccResourceVSphereVirtualMachineCheckDiskController("terraform-test_1.vmdk", virtualdevice.SubresourceControllerTypeSATA, 1, 2),
+					testAccResourceVSphereVirtualMachineCheckDiskController("terraform-test_2.vmdk", virtualdevice.SubresourceControllerTypeNVMe, 0, 1),
+					testAccResourceVSphereVirtualMachineCheckDiskController("terraform-test_3.vmdk", virtualdevice.SubresourceControllerTypeIDE, 1, 0),
 				),
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigSharedSCSIBus(),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckSCSIBusSharing(string(types.VirtualSCSISharingPhysicalSharing)),
-				),
+				ResourceName:      "vsphere_virtual_machine.vm",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateVerifyIgnore: []string{
+					"disk",
+					"imported",
+				},
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					vm, err := testGetVirtualMachine(s, "vm")
+					if err != nil {
+						return "", err
+					}
+					return vm.InventoryPath, nil
+				},
+				Config: testAccResourceVSphereVirtualMachineConfigMultipleControllerTypes(),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_scsiBusSharingMultiVM(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_controllerTypeInsufficientBus(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -522,125 +589,128 @@ func TestAccResourceVSphereVirtualMachine_scsiBusSharingMultiVM(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigISCSIDatastore(),
-				Check:  resource.ComposeTestCheckFunc(),
+				Config:      testAccResourceVSphereVirtualMachineConfigControllerTypeInsufficientBus(),
+				ExpectError: regexp.MustCompile("sata_controller_count must be at least 1 to use controller_type sata on disk \"disk1\""),
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigMultipleSharedSCSIBus(),
+				Config: testAccResourceVSphereEmpty,
 				Check:  resource.ComposeTestCheckFunc(),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_disksKeepOnRemove(t *testing.T) {
-	var disks []map[string]string
+func TestAccResourceVSphereVirtualMachine_pciPassthrough(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccCheckEnvVariables(t, []string{"VSPHERE_ESXI_HOST", "VSPHERE_PCI_DEVICE_ID"})
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigKeepDisksOnRemove(),
+				Config: testAccResourceVSphereVirtualMachineConfigPciPassthrough(
+					fmt.Sprintf("pci_device_id = [%q]", os.Getenv("VSPHERE_PCI_DEVICE_ID")),
+					2048,
+				),
 				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachinePersistentDiskInfo(&disks),
 					testAccResourceVSphereVirtualMachineCheckExists(true),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "pci_device_id.#", "1"),
 				),
 			},
 			{
-				Destroy: true,
-				Config:  testAccResourceVSphereVirtualMachineConfigKeepDisksOnRemove(),
+				Config: testAccResourceVSphereVirtualMachineConfigPciPassthrough("", 2048),
 				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(false),
-					testAccResourceVSphereVirtualMachineDeletePersistentDisks(&disks),
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "pci_device_id.#", "0"),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cdromComputedValue(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_sharedPciDevice(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccCheckEnvVariables(t, []string{"VSPHERE_ESXI_HOST", "VSPHERE_VGPU_PROFILE"})
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config:             testAccResourceVSphereVirtualMachineConfigCdromComputedValue(),
-				PlanOnly:           true,
-				ExpectNonEmptyPlan: true,
+				Config: testAccResourceVSphereVirtualMachineConfigPciPassthrough(
+					fmt.Sprintf("shared_pci_device = %q", os.Getenv("VSPHERE_VGPU_PROFILE")),
+					2048,
+				),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "shared_pci_device", os.Getenv("VSPHERE_VGPU_PROFILE")),
+				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cdromIsoMapping(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_pciPassthroughMemoryReservation(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccCheckEnvVariables(t, []string{"VSPHERE_ESXI_HOST"})
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigIsoCdrom(),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckIsoCdrom(),
-				),
+				Config:      testAccResourceVSphereVirtualMachineConfigPciPassthrough(`pci_device_id = ["0000:00:00.0"]`, 1024),
+				ExpectError: regexp.MustCompile("memory_reservation must be equal to memory"),
+			},
+			{
+				Config: testAccResourceVSphereEmpty,
+				Check:  resource.ComposeTestCheckFunc(),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cdromClientMapping(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_vtpm(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfEsxi(t)
+			testAccCheckEnvVariables(t, []string{"VSPHERE_KEY_PROVIDER"})
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigClientCdrom(),
+				Config: testAccResourceVSphereVirtualMachineConfigSecurity(`
+  vtpm {
+    version = "2.0"
+  }
+`),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckClientCdrom(),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "vtpm.#", "1"),
 				),
 			},
-		},
-	})
-}
-
-func TestAccResourceVSphereVirtualMachine_vAppIsoBasic(t *testing.T) {
-	resource.Test(t, resource.TestCase{
-		PreCheck: func() {
-			testAccPreCheck(t)
-			testAccResourceVSphereVirtualMachinePreCheck(t)
-		},
-		Providers:    testAccProviders,
-		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
-		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigClientCdromCloneIsoVApp(),
+				Config: testAccResourceVSphereVirtualMachineConfigSecurity(""),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSpherevirtualMachineCheckHostname("custom-hostname"),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "vtpm.#", "0"),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_vAppIsoNoVApp(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_vbs(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -650,19 +720,23 @@ func TestAccResourceVSphereVirtualMachine_vAppIsoNoVApp(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigClientCdromClone(),
+				Config: testAccResourceVSphereVirtualMachineConfigSecurity(`
+  efi_secure_boot_enabled = true
+  vvtd_enabled            = true
+  nested_hv_enabled       = true
+  vbs_enabled             = true
+`),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "vbs_enabled", "true"),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "vvtd_enabled", "true"),
 				),
 			},
-			{
-				Config: testAccResourceVSphereVirtualMachineConfigClientCdromClone(),
-			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_vAppIsoNoCdrom(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_vbsMissingPrerequisites(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -672,8 +746,11 @@ func TestAccResourceVSphereVirtualMachine_vAppIsoNoCdrom(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config:      testAccResourceVSphereVirtualMachineConfigNoCdromCloneIsoVApp(),
-				ExpectError: regexp.MustCompile("this virtual machine requires a client CDROM device to deliver vApp properties"),
+				Config: testAccResourceVSphereVirtualMachineConfigSecurity(`
+  efi_secure_boot_enabled = true
+  vbs_enabled             = true
+`),
+				ExpectError: regexp.MustCompile("vbs_enabled requires vvtd_enabled to be set to true"),
 			},
 			{
 				Config: testAccResourceVSphereEmpty,
@@ -683,51 +760,81 @@ func TestAccResourceVSphereVirtualMachine_vAppIsoNoCdrom(t *testing.T) {
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_vAppIsoIncorrectCdromType(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_encryption(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfEsxi(t)
+			testAccCheckEnvVariables(t, []string{"VSPHERE_KEY_PROVIDER", "VSPHERE_ENCRYPTION_POLICY_ID", "VSPHERE_STORAGE_POLICY_ID"})
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config:      testAccResourceVSphereVirtualMachineConfigIsoCdromCloneIsoVApp(),
-				ExpectError: regexp.MustCompile("this virtual machine requires a client CDROM device to deliver vApp properties"),
+				Config: testAccResourceVSphereVirtualMachineConfigSecurityPolicy("", os.Getenv("VSPHERE_STORAGE_POLICY_ID")),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "encryption.#", "0"),
+					testAccResourceVSphereVirtualMachineCheckDisksEncrypted(false),
+				),
 			},
 			{
-				Config: testAccResourceVSphereEmpty,
-				Check:  resource.ComposeTestCheckFunc(),
+				Config: testAccResourceVSphereVirtualMachineConfigSecurityPolicy(testAccResourceVSphereVirtualMachineConfigEncryption(os.Getenv("VSPHERE_KEY_PROVIDER")), os.Getenv("VSPHERE_ENCRYPTION_POLICY_ID")),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "crypto_state", "unlocked"),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "encryption.0.key_provider_id", os.Getenv("VSPHERE_KEY_PROVIDER")),
+					resource.TestCheckResourceAttrSet("vsphere_virtual_machine.vm", "encryption.0.key_id"),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "storage_policy_id", os.Getenv("VSPHERE_ENCRYPTION_POLICY_ID")),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "disk.0.storage_policy_id", os.Getenv("VSPHERE_ENCRYPTION_POLICY_ID")),
+					testAccResourceVSphereVirtualMachineCheckDisksEncrypted(true),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigSecurityPolicy("", os.Getenv("VSPHERE_STORAGE_POLICY_ID")),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "encryption.#", "0"),
+					testAccResourceVSphereVirtualMachineCheckDisksEncrypted(false),
+				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_vAppIsoConfigIsoIgnored(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_encryptionRekey(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfEsxi(t)
+			testAccCheckEnvVariables(t, []string{"VSPHERE_KEY_PROVIDER", "VSPHERE_KEY_PROVIDER2", "VSPHERE_ENCRYPTION_POLICY_ID"})
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigClientCdromCloneIsoVApp(),
+				Config: testAccResourceVSphereVirtualMachineConfigSecurityPolicy(testAccResourceVSphereVirtualMachineConfigEncryption(os.Getenv("VSPHERE_KEY_PROVIDER")), os.Getenv("VSPHERE_ENCRYPTION_POLICY_ID")),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "encryption.0.key_provider_id", os.Getenv("VSPHERE_KEY_PROVIDER")),
+					testAccResourceVSphereVirtualMachineCheckDisksEncrypted(true),
 				),
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigClientCdromCloneIsoVApp(),
+				Config: testAccResourceVSphereVirtualMachineConfigSecurityPolicy(testAccResourceVSphereVirtualMachineConfigEncryption(os.Getenv("VSPHERE_KEY_PROVIDER2")), os.Getenv("VSPHERE_ENCRYPTION_POLICY_ID")),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "encryption.0.key_provider_id", os.Getenv("VSPHERE_KEY_PROVIDER2")),
+					testAccResourceVSphereVirtualMachineCheckDisksEncrypted(true),
+				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_vAppIsoChangeCdromBacking(t *testing.T) {
-	var state *terraform.State
+func TestAccResourceVSphereVirtualMachine_scsiBusSharing(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -737,25 +844,17 @@ func TestAccResourceVSphereVirtualMachine_vAppIsoChangeCdromBacking(t *testing.T
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigClientCdromCloneIsoVApp(),
-				Check: resource.ComposeTestCheckFunc(
-					copyStatePtr(&state),
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSpherevirtualMachineCheckHostname("custom-hostname"),
-				),
-			},
-			{
-				Config: testAccResourceVSphereVirtualMachineConfigClientCdromClone(),
+				Config: testAccResourceVSphereVirtualMachineConfigSharedSCSIBus(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckSCSIBusSharing(string(types.VirtualSCSISharingPhysicalSharing)),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_vAppIsoPoweredOffCdromRead(t *testing.T) {
-	var state *terraform.State
+func TestAccResourceVSphereVirtualMachine_scsiBusSharingUpdate(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -765,28 +864,24 @@ func TestAccResourceVSphereVirtualMachine_vAppIsoPoweredOffCdromRead(t *testing.
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigClientCdromCloneIsoVApp(),
+				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
 				Check: resource.ComposeTestCheckFunc(
-					copyStatePtr(&state),
 					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckSCSIBusSharing(string(types.VirtualSCSISharingNoSharing)),
 				),
 			},
 			{
-				PreConfig: func() {
-					if err := testPowerOffVM(state, "vm"); err != nil {
-						panic(err)
-					}
-				},
-				Config: testAccResourceVSphereVirtualMachineConfigClientCdromCloneIsoVApp(),
+				Config: testAccResourceVSphereVirtualMachineConfigSharedSCSIBus(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckSCSIBusSharing(string(types.VirtualSCSISharingPhysicalSharing)),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cdromClientMappingClone(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_scsiBusSharingMultiVM(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -796,17 +891,19 @@ func TestAccResourceVSphereVirtualMachine_cdromClientMappingClone(t *testing.T)
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigClientCdromClone(),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckClientCdrom(),
-				),
+				Config: testAccResourceVSphereVirtualMachineConfigISCSIDatastore(),
+				Check:  resource.ComposeTestCheckFunc(),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigMultipleSharedSCSIBus(),
+				Check:  resource.ComposeTestCheckFunc(),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cdromNoParameters(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_disksKeepOnRemove(t *testing.T) {
+	var disks []map[string]string
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -816,17 +913,25 @@ func TestAccResourceVSphereVirtualMachine_cdromNoParameters(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config:      testAccResourceVSphereVirtualMachineConfigNoCdromParameters(),
-				ExpectError: regexp.MustCompile("Either client_device or datastore_id and path must be set"),
+				Config: testAccResourceVSphereVirtualMachineConfigKeepDisksOnRemove(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachinePersistentDiskInfo(&disks),
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+				),
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigClientCdrom(),
+				Destroy: true,
+				Config:  testAccResourceVSphereVirtualMachineConfigKeepDisksOnRemove(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(false),
+					testAccResourceVSphereVirtualMachineDeletePersistentDisks(&disks),
+				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cdromConflictingParameters(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cdromComputedValue(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -836,17 +941,15 @@ func TestAccResourceVSphereVirtualMachine_cdromConflictingParameters(t *testing.
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config:      testAccResourceVSphereVirtualMachineConfigConflictingCdromParameters(),
-				ExpectError: regexp.MustCompile("Cannot have both client_device parameter and ISO file parameters"),
-			},
-			{
-				Config: testAccResourceVSphereEmpty,
+				Config:             testAccResourceVSphereVirtualMachineConfigCdromComputedValue(),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cdromChangeMapping(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cdromIsoMapping(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -862,17 +965,11 @@ func TestAccResourceVSphereVirtualMachine_cdromChangeMapping(t *testing.T) {
 					testAccResourceVSphereVirtualMachineCheckIsoCdrom(),
 				),
 			},
-			{
-				Config: testAccResourceVSphereVirtualMachineConfigClientCdrom(),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckClientCdrom(),
-				),
-			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_maximumNumberOfNICs(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cdromClientMapping(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -882,17 +979,17 @@ func TestAccResourceVSphereVirtualMachine_maximumNumberOfNICs(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigMaxNIC(),
+				Config: testAccResourceVSphereVirtualMachineConfigClientCdrom(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckNICCount(10),
+					testAccResourceVSphereVirtualMachineCheckClientCdrom(),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_upgradeCPUAndRam(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_vAppIsoBasic(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -902,27 +999,17 @@ func TestAccResourceVSphereVirtualMachine_upgradeCPUAndRam(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-				),
-			},
-			{
-				Config: testAccResourceVSphereVirtualMachineConfigBeefy(),
+				Config: testAccResourceVSphereVirtualMachineConfigClientCdromCloneIsoVApp(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckCPUMem(4, 8192),
-					// Since hot-add should be off, we expect that the VM was powered
-					// off as a part of this step. This helps check the functionality
-					// of the check for later tests as well.
-					testAccResourceVSphereVirtualMachineCheckPowerOffEvent(true),
+					testAccResourceVSpherevirtualMachineCheckHostname("custom-hostname"),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_modifyAnnotation(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_vAppIsoNoVApp(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -932,24 +1019,19 @@ func TestAccResourceVSphereVirtualMachine_modifyAnnotation(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
+				Config: testAccResourceVSphereVirtualMachineConfigClientCdromClone(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
 				),
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigBasicAnnotation(),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckAnnotation(),
-					testAccResourceVSphereVirtualMachineCheckPowerOffEvent(false),
-				),
+				Config: testAccResourceVSphereVirtualMachineConfigClientCdromClone(),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_growDisk(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_vAppIsoNoCdrom(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -959,24 +1041,18 @@ func TestAccResourceVSphereVirtualMachine_growDisk(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigGrowDisk(10),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckDiskSize(10),
-				),
+				Config:      testAccResourceVSphereVirtualMachineConfigNoCdromCloneIsoVApp(),
+				ExpectError: regexp.MustCompile("this virtual machine requires a client CDROM device to deliver vApp properties"),
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigGrowDisk(20),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckDiskSize(20),
-				),
+				Config: testAccResourceVSphereEmpty,
+				Check:  resource.ComposeTestCheckFunc(),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_swapSCSIBus(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_vAppIsoIncorrectCdromType(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -986,24 +1062,18 @@ func TestAccResourceVSphereVirtualMachine_swapSCSIBus(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckSCSIBus(virtualdevice.SubresourceControllerTypeParaVirtual),
-				),
+				Config:      testAccResourceVSphereVirtualMachineConfigIsoCdromCloneIsoVApp(),
+				ExpectError: regexp.MustCompile("this virtual machine requires a client CDROM device to deliver vApp properties"),
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigLsiLogicSAS(),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckSCSIBus(virtualdevice.SubresourceControllerTypeLsiLogicSAS),
-				),
+				Config: testAccResourceVSphereEmpty,
+				Check:  resource.ComposeTestCheckFunc(),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_extraConfig(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_vAppIsoConfigIsoIgnored(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1013,17 +1083,20 @@ func TestAccResourceVSphereVirtualMachine_extraConfig(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigExtraConfig("foo", "bar"),
+				Config: testAccResourceVSphereVirtualMachineConfigClientCdromCloneIsoVApp(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckExtraConfig("foo", "bar"),
 				),
 			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigClientCdromCloneIsoVApp(),
+			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_extraConfigSwapKeys(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_vAppIsoChangeCdromBacking(t *testing.T) {
+	var state *terraform.State
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1033,25 +1106,25 @@ func TestAccResourceVSphereVirtualMachine_extraConfigSwapKeys(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigExtraConfig("foo", "bar"),
+				Config: testAccResourceVSphereVirtualMachineConfigClientCdromCloneIsoVApp(),
 				Check: resource.ComposeTestCheckFunc(
+					copyStatePtr(&state),
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckExtraConfig("foo", "bar"),
+					testAccResourceVSpherevirtualMachineCheckHostname("custom-hostname"),
 				),
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigExtraConfig("baz", "qux"),
+				Config: testAccResourceVSphereVirtualMachineConfigClientCdromClone(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckExtraConfig("baz", "qux"),
-					testAccResourceVSphereVirtualMachineCheckExtraConfigKeyMissing("foo"),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_attachExistingVmdk(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_vAppIsoPoweredOffCdromRead(t *testing.T) {
+	var state *terraform.State
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1061,17 +1134,28 @@ func TestAccResourceVSphereVirtualMachine_attachExistingVmdk(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigExistingVmdk(),
+				Config: testAccResourceVSphereVirtualMachineConfigClientCdromCloneIsoVApp(),
+				Check: resource.ComposeTestCheckFunc(
+					copyStatePtr(&state),
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+				),
+			},
+			{
+				PreConfig: func() {
+					if err := testPowerOffVM(state, "vm"); err != nil {
+						panic(err)
+					}
+				},
+				Config: testAccResourceVSphereVirtualMachineConfigClientCdromCloneIsoVApp(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckExistingVmdk(),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_attachExistingVmdkTaint(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cdromClientMappingClone(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1081,25 +1165,17 @@ func TestAccResourceVSphereVirtualMachine_attachExistingVmdkTaint(t *testing.T)
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigExistingVmdk(),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckExistingVmdk(),
-				),
-			},
-			{
-				Taint:  []string{"vsphere_virtual_machine.vm"},
-				Config: testAccResourceVSphereVirtualMachineConfigExistingVmdk(),
+				Config: testAccResourceVSphereVirtualMachineConfigClientCdromClone(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckExistingVmdk(),
+					testAccResourceVSphereVirtualMachineCheckClientCdrom(),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_resourcePoolMove(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cdromNoParameters(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1109,24 +1185,17 @@ func TestAccResourceVSphereVirtualMachine_resourcePoolMove(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckResourcePool(fmt.Sprintf("hashi-resource-default")),
-				),
+				Config:      testAccResourceVSphereVirtualMachineConfigNoCdromParameters(),
+				ExpectError: regexp.MustCompile("Either client_device or datastore_id and path must be set"),
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigNewResourcePool(),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckResourcePool(fmt.Sprintf("terraform-test-new-resource-pool")),
-				),
+				Config: testAccResourceVSphereVirtualMachineConfigClientCdrom(),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_vAppContainerAndFolder(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cdromConflictingParameters(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1136,8 +1205,8 @@ func TestAccResourceVSphereVirtualMachine_vAppContainerAndFolder(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config:      testAccResourceVSphereVirtualMachineConfigVAppAndFolder(),
-				ExpectError: regexp.MustCompile("cannot set folder while VM is in a vApp container"),
+				Config:      testAccResourceVSphereVirtualMachineConfigConflictingCdromParameters(),
+				ExpectError: regexp.MustCompile("Cannot have both client_device parameter and ISO file parameters"),
 			},
 			{
 				Config: testAccResourceVSphereEmpty,
@@ -1146,7 +1215,7 @@ func TestAccResourceVSphereVirtualMachine_vAppContainerAndFolder(t *testing.T) {
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_vAppContainerMove(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cdromChangeMapping(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1156,31 +1225,43 @@ func TestAccResourceVSphereVirtualMachine_vAppContainerMove(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigOutOfVAppContainer(),
+				Config: testAccResourceVSphereVirtualMachineConfigIsoCdrom(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckFolder("terraform-test-vms"),
+					testAccResourceVSphereVirtualMachineCheckIsoCdrom(),
 				),
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigInVAppContainer(),
+				Config: testAccResourceVSphereVirtualMachineConfigClientCdrom(),
 				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckResourcePool(fmt.Sprintf("terraform-vapp-test")),
+					testAccResourceVSphereVirtualMachineCheckClientCdrom(),
 				),
 			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualMachine_maximumNumberOfNICs(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigOutOfVAppContainer(),
+				Config: testAccResourceVSphereVirtualMachineConfigMaxNIC(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckFolder("terraform-test-vms"),
+					testAccResourceVSphereVirtualMachineCheckNICCount(10),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_inFolder(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_upgradeCPUAndRam(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1190,17 +1271,27 @@ func TestAccResourceVSphereVirtualMachine_inFolder(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigInFolder(),
+				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckFolder("terraform-test-vms"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigBeefy(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckCPUMem(4, 8192),
+					// Since hot-add should be off, we expect that the VM was powered
+					// off as a part of this step. This helps check the functionality
+					// of the check for later tests as well.
+					testAccResourceVSphereVirtualMachineCheckPowerOffEvent(true),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_moveToFolder(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_modifyAnnotation(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1216,17 +1307,18 @@ func TestAccResourceVSphereVirtualMachine_moveToFolder(t *testing.T) {
 				),
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigInFolder(),
+				Config: testAccResourceVSphereVirtualMachineConfigBasicAnnotation(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckFolder("terraform-test-vms"),
+					testAccResourceVSphereVirtualMachineCheckAnnotation(),
+					testAccResourceVSphereVirtualMachineCheckPowerOffEvent(false),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_staticMAC(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_growDisk(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1236,17 +1328,24 @@ func TestAccResourceVSphereVirtualMachine_staticMAC(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigStaticMAC(),
+				Config: testAccResourceVSphereVirtualMachineConfigGrowDisk(10),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckStaticMACAddr(),
+					testAccResourceVSphereVirtualMachineCheckDiskSize(10),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigGrowDisk(20),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckDiskSize(20),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_singleTag(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_swapSCSIBus(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1256,17 +1355,24 @@ func TestAccResourceVSphereVirtualMachine_singleTag(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigSingleTag(),
+				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckTags("terraform-test-tag"),
+					testAccResourceVSphereVirtualMachineCheckSCSIBus(virtualdevice.SubresourceControllerTypeParaVirtual),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigLsiLogicSAS(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckSCSIBus(virtualdevice.SubresourceControllerTypeLsiLogicSAS),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_multipleTags(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_extraConfig(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1276,17 +1382,17 @@ func TestAccResourceVSphereVirtualMachine_multipleTags(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigMultiTag(),
+				Config: testAccResourceVSphereVirtualMachineConfigExtraConfig("foo", "bar"),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckTags("terraform-test-tags-alt"),
+					testAccResourceVSphereVirtualMachineCheckExtraConfig("foo", "bar"),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_switchTags(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloudInit(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1296,26 +1402,58 @@ func TestAccResourceVSphereVirtualMachine_switchTags(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigSingleTag(),
+				Config: testAccResourceVSphereVirtualMachineConfigCloudInit(`
+  cloud_init {
+    metadata       = "instance-id: terraform-test\nlocal-hostname: terraform-test\n"
+    network_config = "version: 2\nethernets:\n  ens192:\n    dhcp4: true\n"
+    userdata       = "#cloud-config\npackages:\n  - nginx\n"
+  }
+`),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckTags("terraform-test-tag"),
+					testAccResourceVSphereVirtualMachineCheckExtraConfig("guestinfo.metadata.encoding", "gzip+base64"),
+					testAccResourceVSphereVirtualMachineCheckExtraConfig("guestinfo.userdata.encoding", "gzip+base64"),
+					testAccResourceVSphereVirtualMachineCheckExtraConfig("foo", "bar"),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "extra_config.%", "1"),
 				),
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigMultiTag(),
+				Config: testAccResourceVSphereVirtualMachineConfigCloudInit(""),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckTags("terraform-test-tags-alt"),
+					testAccResourceVSphereVirtualMachineCheckExtraConfigKeyMissing("guestinfo.metadata"),
+					testAccResourceVSphereVirtualMachineCheckExtraConfigKeyMissing("guestinfo.userdata"),
+					testAccResourceVSphereVirtualMachineCheckExtraConfig("foo", "bar"),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_renamedDiskInPlaceOfExisting(t *testing.T) {
-	var state *terraform.State
+func TestAccResourceVSphereVirtualMachine_cloudInitExtraConfigConflict(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigCloudInitExtraConfigConflict(),
+				ExpectError: regexp.MustCompile(
+					"extra_config key \"guestinfo.userdata\" cannot be set when cloud_init is in use",
+				),
+			},
+			{
+				Config: testAccResourceVSphereEmpty,
+				Check:  resource.ComposeTestCheckFunc(),
+			},
+		},
+	})
+}
 
+func TestAccResourceVSphereVirtualMachine_extraConfigSwapKeys(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1325,46 +1463,25 @@ func TestAccResourceVSphereVirtualMachine_renamedDiskInPlaceOfExisting(t *testin
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
+				Config: testAccResourceVSphereVirtualMachineConfigExtraConfig("foo", "bar"),
 				Check: resource.ComposeTestCheckFunc(
-					copyStatePtr(&state),
 					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckExtraConfig("foo", "bar"),
 				),
 			},
 			{
-				PreConfig: func() {
-					if err := testRenameVMFirstDisk(state, "vm", "foobar.vmdk"); err != nil {
-						panic(err)
-					}
-				},
-				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
+				Config: testAccResourceVSphereVirtualMachineConfigExtraConfig("baz", "qux"),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					// The only real way we can check to see if this is actually
-					// functional in the current test framework is by checking that
-					// the file we renamed to was not deleted (this is due to a lack
-					// of ability to check diff in the test framework right now).
-					testCheckVMDiskFileExists("terraform-test.vmdk"),
-					testCheckVMDiskFileExists("foobar.vmdk"),
+					testAccResourceVSphereVirtualMachineCheckExtraConfig("baz", "qux"),
+					testAccResourceVSphereVirtualMachineCheckExtraConfigKeyMissing("foo"),
 				),
 			},
-			// The last step is a cleanup step. This assumes the test is
-			// functional as the orphaned disk will be now detached and not
-			// deleted when the VM is destroyed.
-			{
-				PreConfig: func() {
-					if err := testDeleteVMDisk(state, "vm", "foobar.vmdk"); err != nil {
-						panic(err)
-					}
-				},
-				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
-				Check:  resource.ComposeTestCheckFunc(),
-			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_blockComputedDiskName(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_attachExistingVmdk(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1374,19 +1491,17 @@ func TestAccResourceVSphereVirtualMachine_blockComputedDiskName(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config:      testAccResourceVSphereVirtualMachineConfigComputedDisk(),
-				ExpectError: regexp.MustCompile("disk label or name must be defined and cannot be computed"),
-				PlanOnly:    true,
-			},
-			{
-				Config: testAccResourceVSphereEmpty,
-				Check:  resource.ComposeTestCheckFunc(),
+				Config: testAccResourceVSphereVirtualMachineConfigExistingVmdk(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckExistingVmdk(),
+				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_blockVAppSettingsOnNonClones(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_attachExistingVmdkTaint(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1396,18 +1511,25 @@ func TestAccResourceVSphereVirtualMachine_blockVAppSettingsOnNonClones(t *testin
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config:      testAccResourceVSphereVirtualMachineVAppPropertiesNonClone(),
-				ExpectError: regexp.MustCompile("vApp properties can only be set on cloned virtual machines"),
+				Config: testAccResourceVSphereVirtualMachineConfigExistingVmdk(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckExistingVmdk(),
+				),
 			},
 			{
-				Config: testAccResourceVSphereEmpty,
-				Check:  resource.ComposeTestCheckFunc(),
+				Taint:  []string{"vsphere_virtual_machine.vm"},
+				Config: testAccResourceVSphereVirtualMachineConfigExistingVmdk(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckExistingVmdk(),
+				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_blockVAppSettingsOnNonClonesAfterCreation(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_resourcePoolMove(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1420,23 +1542,21 @@ func TestAccResourceVSphereVirtualMachine_blockVAppSettingsOnNonClonesAfterCreat
 				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckResourcePool(fmt.Sprintf("hashi-resource-default")),
 				),
 			},
 			{
-				Config:      testAccResourceVSphereVirtualMachineVAppPropertiesNonClone(),
-				ExpectError: regexp.MustCompile("this VM lacks a vApp configuration and cannot have vApp properties set on it"),
-			},
-			{
-				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
+				Config: testAccResourceVSphereVirtualMachineConfigNewResourcePool(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckResourcePool(fmt.Sprintf("terraform-test-new-resource-pool")),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_blockDiskLabelStartingWithOrphanedPrefix(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_vAppContainerAndFolder(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1446,19 +1566,17 @@ func TestAccResourceVSphereVirtualMachine_blockDiskLabelStartingWithOrphanedPref
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config:      testAccResourceVSphereVirtualMachineConfigBadOrphanedLabel(),
-				ExpectError: regexp.MustCompile(regexp.QuoteMeta(`disk label "orphaned_disk_0" cannot start with "orphaned_disk_"`)),
-				PlanOnly:    true,
+				Config:      testAccResourceVSphereVirtualMachineConfigVAppAndFolder(),
+				ExpectError: regexp.MustCompile("cannot set folder while VM is in a vApp container"),
 			},
 			{
 				Config: testAccResourceVSphereEmpty,
-				Check:  resource.ComposeTestCheckFunc(),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_createIntoEmptyClusterNoEnvironmentBrowser(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_vAppContainerMove(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1468,14 +1586,31 @@ func TestAccResourceVSphereVirtualMachine_createIntoEmptyClusterNoEnvironmentBro
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config:      testAccResourceVSphereVirtualMachineConfigBasicEmptyCluster(),
-				ExpectError: regexp.MustCompile("compute resource .* is missing an Environment Browser\\. Check host, cluster, and vSphere license health of all associated resources and try again"),
+				Config: testAccResourceVSphereVirtualMachineConfigOutOfVAppContainer(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckFolder("terraform-test-vms"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigInVAppContainer(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckResourcePool(fmt.Sprintf("terraform-vapp-test")),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigOutOfVAppContainer(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckFolder("terraform-test-vms"),
+				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneFromTemplate(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_inFolder(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1485,17 +1620,17 @@ func TestAccResourceVSphereVirtualMachine_cloneFromTemplate(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigClone(),
+				Config: testAccResourceVSphereVirtualMachineConfigInFolder(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "default_ip_address", os.Getenv("VSPHERE_IPV4_ADDRESS")),
+					testAccResourceVSphereVirtualMachineCheckFolder("terraform-test-vms"),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_clonePoweredOn(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_moveToFolder(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1505,16 +1640,23 @@ func TestAccResourceVSphereVirtualMachine_clonePoweredOn(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigClonePoweredOn(),
+				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigInFolder(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckFolder("terraform-test-vms"),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneNoGateway(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_staticMAC(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1524,18 +1666,17 @@ func TestAccResourceVSphereVirtualMachine_cloneNoGateway(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigCloneNoGateway(),
+				Config: testAccResourceVSphereVirtualMachineConfigStaticMAC(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "default_ip_address", os.Getenv("VSPHERE_IPV4_ADDRESS")),
-					resource.TestCheckResourceAttrSet("vsphere_virtual_machine.vm", "guest_ip_addresses.#"),
+					testAccResourceVSphereVirtualMachineCheckStaticMACAddr(),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneCustomizeWithNewResourcePool(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_singleTag(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1545,16 +1686,17 @@ func TestAccResourceVSphereVirtualMachine_cloneCustomizeWithNewResourcePool(t *t
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigCloneWithNewResourcePool(),
+				Config: testAccResourceVSphereVirtualMachineConfigSingleTag(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckTags("terraform-test-tag"),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneCustomizeComputedValue(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_multipleTags(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1564,17 +1706,17 @@ func TestAccResourceVSphereVirtualMachine_cloneCustomizeComputedValue(t *testing
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config:             testAccResourceVSphereVirtualMachineConfigCloneComputedValue(),
-				PlanOnly:           true,
-				ExpectNonEmptyPlan: true,
+				Config: testAccResourceVSphereVirtualMachineConfigMultiTag(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckTags("terraform-test-tags-alt"),
+				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneCustomizeForceNew(t *testing.T) {
-	var state *terraform.State
-
+func TestAccResourceVSphereVirtualMachine_switchTags(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1584,28 +1726,24 @@ func TestAccResourceVSphereVirtualMachine_cloneCustomizeForceNew(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigClone(),
+				Config: testAccResourceVSphereVirtualMachineConfigSingleTag(),
 				Check: resource.ComposeTestCheckFunc(
-					copyState(&state),
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "default_ip_address", os.Getenv("VSPHERE_IPV4_ADDRESS")),
+					testAccResourceVSphereVirtualMachineCheckTags("terraform-test-tag"),
 				),
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigCloneDHCP(),
+				Config: testAccResourceVSphereVirtualMachineConfigMultiTag(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					func(s *terraform.State) error {
-						oldID := state.RootModule().Resources["vsphere_virtual_machine.vm"].Primary.ID
-						return testCheckResourceNotAttr("vsphere_virtual_machine.vm", "id", oldID)(s)
-					},
+					testAccResourceVSphereVirtualMachineCheckTags("terraform-test-tags-alt"),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneCustomizeForceNewWithDatastore(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_renamedDiskInPlaceOfExisting(t *testing.T) {
 	var state *terraform.State
 
 	resource.Test(t, resource.TestCase{
@@ -1617,39 +1755,46 @@ func TestAccResourceVSphereVirtualMachine_cloneCustomizeForceNewWithDatastore(t
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigCloneParameterized(
-					os.Getenv("VSPHERE_DATASTORE"),
-					"terraform-test",
-				),
+				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
 				Check: resource.ComposeTestCheckFunc(
-					copyState(&state),
+					copyStatePtr(&state),
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckHostname("terraform-test"),
-					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(0, os.Getenv("VSPHERE_DATASTORE")),
 				),
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigCloneParameterized(
-					os.Getenv("VSPHERE_DATASTORE2"),
-					"terraform-test-renamed",
-				),
+				PreConfig: func() {
+					if err := testRenameVMFirstDisk(state, "vm", "foobar.vmdk"); err != nil {
+						panic(err)
+					}
+				},
+				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckHostname("terraform-test-renamed"),
-					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE2")),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(0, os.Getenv("VSPHERE_DATASTORE2")),
-					func(s *terraform.State) error {
-						oldID := state.RootModule().Resources["vsphere_virtual_machine.vm"].Primary.ID
-						return testCheckResourceNotAttr("vsphere_virtual_machine.vm", "id", oldID)(s)
-					},
+					// The only real way we can check to see if this is actually
+					// functional in the current test framework is by checking that
+					// the file we renamed to was not deleted (this is due to a lack
+					// of ability to check diff in the test framework right now).
+					testCheckVMDiskFileExists("terraform-test.vmdk"),
+					testCheckVMDiskFileExists("foobar.vmdk"),
 				),
 			},
+			// The last step is a cleanup step. This assumes the test is
+			// functional as the orphaned disk will be now detached and not
+			// deleted when the VM is destroyed.
+			{
+				PreConfig: func() {
+					if err := testDeleteVMDisk(state, "vm", "foobar.vmdk"); err != nil {
+						panic(err)
+					}
+				},
+				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
+				Check:  resource.ComposeTestCheckFunc(),
+			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_datastoreClusterClone(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_blockComputedDiskName(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1659,19 +1804,19 @@ func TestAccResourceVSphereVirtualMachine_datastoreClusterClone(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigCloneDatastoreCluster(),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "default_ip_address", os.Getenv("VSPHERE_IPV4_ADDRESS")),
-				),
+				Config:      testAccResourceVSphereVirtualMachineConfigComputedDisk(),
+				ExpectError: regexp.MustCompile("disk label or name must be defined and cannot be computed"),
+				PlanOnly:    true,
+			},
+			{
+				Config: testAccResourceVSphereEmpty,
+				Check:  resource.ComposeTestCheckFunc(),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneModifyDiskAndSCSITypeAtSameTime(t *testing.T) {
-	var state *terraform.State
-
+func TestAccResourceVSphereVirtualMachine_blockVAppSettingsOnNonClones(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1681,32 +1826,18 @@ func TestAccResourceVSphereVirtualMachine_cloneModifyDiskAndSCSITypeAtSameTime(t
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigCloneChangeDiskAndSCSI(),
-				Check: resource.ComposeTestCheckFunc(
-					copyStatePtr(&state),
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					func(s *terraform.State) error {
-						oldSize, _ := strconv.Atoi(state.RootModule().Resources["data.vsphere_virtual_machine.template"].Primary.Attributes["disks.0.size"])
-						newSize := oldSize * 2
-						return resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "disk.0.size", strconv.Itoa(newSize))(s)
-					},
-					func(s *terraform.State) error {
-						oldBus := state.RootModule().Resources["data.vsphere_virtual_machine.template"].Primary.Attributes["scsi_type"]
-						var expected string
-						if oldBus == virtualdevice.SubresourceControllerTypeParaVirtual {
-							expected = virtualdevice.SubresourceControllerTypeLsiLogicSAS
-						} else {
-							expected = virtualdevice.SubresourceControllerTypeParaVirtual
-						}
-						return resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "scsi_type", expected)(s)
-					},
-				),
+				Config:      testAccResourceVSphereVirtualMachineVAppPropertiesNonClone(),
+				ExpectError: regexp.MustCompile("vApp properties can only be set on cloned or OVF deployed virtual machines"),
+			},
+			{
+				Config: testAccResourceVSphereEmpty,
+				Check:  resource.ComposeTestCheckFunc(),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneMultiNICFromSingleNICTemplate(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_blockVAppSettingsOnNonClonesAfterCreation(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1716,17 +1847,26 @@ func TestAccResourceVSphereVirtualMachine_cloneMultiNICFromSingleNICTemplate(t *
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigCloneMultiNIC(),
+				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+				),
+			},
+			{
+				Config:      testAccResourceVSphereVirtualMachineVAppPropertiesNonClone(),
+				ExpectError: regexp.MustCompile("this VM lacks a vApp configuration and cannot have vApp properties set on it"),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "default_ip_address", os.Getenv("VSPHERE_IPV4_ADDRESS")),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneWithDifferentTimezone(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_blockDiskLabelStartingWithOrphanedPrefix(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1736,39 +1876,36 @@ func TestAccResourceVSphereVirtualMachine_cloneWithDifferentTimezone(t *testing.
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigCloneTimeZone("America/Vancouver"),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-				),
+				Config:      testAccResourceVSphereVirtualMachineConfigBadOrphanedLabel(),
+				ExpectError: regexp.MustCompile(regexp.QuoteMeta(`disk label "orphaned_disk_0" cannot start with "orphaned_disk_"`)),
+				PlanOnly:    true,
+			},
+			{
+				Config: testAccResourceVSphereEmpty,
+				Check:  resource.ComposeTestCheckFunc(),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneBlockESXi(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_createIntoEmptyClusterNoEnvironmentBrowser(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
-			testAccSkipIfNotEsxi(t)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config:      testAccResourceVSphereVirtualMachineConfigClone(),
-				ExpectError: regexp.MustCompile("use of the clone sub-resource block requires vCenter"),
-				PlanOnly:    true,
-			},
-			{
-				Config: testAccResourceVSphereEmpty,
-				Check:  resource.ComposeTestCheckFunc(),
+				Config:      testAccResourceVSphereVirtualMachineConfigBasicEmptyCluster(),
+				ExpectError: regexp.MustCompile("compute resource .* is missing an Environment Browser\\. Check host, cluster, and vSphere license health of all associated resources and try again"),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneWithBadTimezone(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneFromTemplate(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1778,19 +1915,17 @@ func TestAccResourceVSphereVirtualMachine_cloneWithBadTimezone(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config:      testAccResourceVSphereVirtualMachineConfigCloneTimeZone("Pacific Standard Time"),
-				ExpectError: regexp.MustCompile("must be similar to America/Los_Angeles or other Linux/Unix TZ format"),
-				PlanOnly:    true,
-			},
-			{
-				Config: testAccResourceVSphereEmpty,
-				Check:  resource.ComposeTestCheckFunc(),
+				Config: testAccResourceVSphereVirtualMachineConfigClone(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "default_ip_address", os.Getenv("VSPHERE_IPV4_ADDRESS")),
+				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneWithBadEagerlyScrubWithLinkedClone(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_clonePoweredOn(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1800,41 +1935,16 @@ func TestAccResourceVSphereVirtualMachine_cloneWithBadEagerlyScrubWithLinkedClon
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config:      testAccResourceVSphereVirtualMachineConfigBadEager(),
-				ExpectError: regexp.MustCompile("must have same value for eagerly_scrub as source when using linked_clone"),
-				PlanOnly:    true,
-			},
-			{
-				Config: testAccResourceVSphereEmpty,
-				Check:  resource.ComposeTestCheckFunc(),
+				Config: testAccResourceVSphereVirtualMachineConfigClonePoweredOn(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+				),
 			},
 		},
 	})
 }
 
-// Temporarily removed until https://github.com/hashicorp/terraform/issues/21225 is resolved.
-//func TestAccResourceVSphereVirtualMachine_cloneWithBadThinProvisionedWithLinkedClone(t *testing.T) {
-//	resource.Test(t, resource.TestCase{
-//		PreCheck: func() {
-//			testAccPreCheck(t)
-//			testAccResourceVSphereVirtualMachinePreCheck(t)
-//		},
-//		Providers: testAccProviders,
-//		Steps: []resource.TestStep{
-//			{
-//				Config:      testAccResourceVSphereVirtualMachineConfigBadThin(),
-//				ExpectError: regexp.MustCompile("must have same value for thin_provisioned as source when using linked_clone"),
-//				PlanOnly:    true,
-//			},
-//			{
-//				Config: testAccResourceVSphereEmpty,
-//				Check:  resource.ComposeTestCheckFunc(),
-//			},
-//		},
-//	})
-//}
-
-func TestAccResourceVSphereVirtualMachine_cloneWithBadSizeWithLinkedClone(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneNoGateway(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1844,19 +1954,18 @@ func TestAccResourceVSphereVirtualMachine_cloneWithBadSizeWithLinkedClone(t *tes
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config:      testAccResourceVSphereVirtualMachineConfigBadSizeLinked(),
-				ExpectError: regexp.MustCompile("must be the exact size of source when using linked_clone"),
-				PlanOnly:    true,
-			},
-			{
-				Config: testAccResourceVSphereEmpty,
-				Check:  resource.ComposeTestCheckFunc(),
+				Config: testAccResourceVSphereVirtualMachineConfigCloneNoGateway(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "default_ip_address", os.Getenv("VSPHERE_IPV4_ADDRESS")),
+					resource.TestCheckResourceAttrSet("vsphere_virtual_machine.vm", "guest_ip_addresses.#"),
+				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneWithBadSizeWithoutLinkedClone(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneCustomizeWithNewResourcePool(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1866,19 +1975,16 @@ func TestAccResourceVSphereVirtualMachine_cloneWithBadSizeWithoutLinkedClone(t *
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config:      testAccResourceVSphereVirtualMachineConfigBadSizeUnlinked(),
-				ExpectError: regexp.MustCompile("must be at least the same size of source when cloning"),
-				PlanOnly:    true,
-			},
-			{
-				Config: testAccResourceVSphereEmpty,
-				Check:  resource.ComposeTestCheckFunc(),
+				Config: testAccResourceVSphereVirtualMachineConfigCloneWithNewResourcePool(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneUnsupportedVAppPropertiesOnCreate(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneCustomizeComputedValue(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1888,14 +1994,17 @@ func TestAccResourceVSphereVirtualMachine_cloneUnsupportedVAppPropertiesOnCreate
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config:      testAccResourceVSphereVirtualMachineConfigCloneBadVAppSettings(),
-				ExpectError: regexp.MustCompile("this VM lacks a vApp configuration and cannot have vApp properties set on it"),
+				Config:             testAccResourceVSphereVirtualMachineConfigCloneComputedValue(),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneUnsupportedVAppPropertiesOnUpdate(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneCustomizeForceNew(t *testing.T) {
+	var state *terraform.State
+
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1907,18 +2016,28 @@ func TestAccResourceVSphereVirtualMachine_cloneUnsupportedVAppPropertiesOnUpdate
 			{
 				Config: testAccResourceVSphereVirtualMachineConfigClone(),
 				Check: resource.ComposeTestCheckFunc(
+					copyState(&state),
 					testAccResourceVSphereVirtualMachineCheckExists(true),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "default_ip_address", os.Getenv("VSPHERE_IPV4_ADDRESS")),
 				),
 			},
 			{
-				Config:      testAccResourceVSphereVirtualMachineConfigCloneBadVAppSettings(),
-				ExpectError: regexp.MustCompile("this VM lacks a vApp configuration and cannot have vApp properties set on it"),
+				Config: testAccResourceVSphereVirtualMachineConfigCloneDHCP(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					func(s *terraform.State) error {
+						oldID := state.RootModule().Resources["vsphere_virtual_machine.vm"].Primary.ID
+						return testCheckResourceNotAttr("vsphere_virtual_machine.vm", "id", oldID)(s)
+					},
+				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneIntoEmptyCluster(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneCustomizeForceNewWithDatastore(t *testing.T) {
+	var state *terraform.State
+
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1928,19 +2047,39 @@ func TestAccResourceVSphereVirtualMachine_cloneIntoEmptyCluster(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config:      testAccResourceVSphereVirtualMachineConfigCloneEmptyCluster(),
-				ExpectError: regexp.MustCompile("compute resource .* is missing an Environment Browser\\. Check host, cluster, and vSphere license health of all associated resources and try again"),
-				PlanOnly:    true,
+				Config: testAccResourceVSphereVirtualMachineConfigCloneParameterized(
+					os.Getenv("VSPHERE_DATASTORE"),
+					"terraform-test",
+				),
+				Check: resource.ComposeTestCheckFunc(
+					copyState(&state),
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckHostname("terraform-test"),
+					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(0, os.Getenv("VSPHERE_DATASTORE")),
+				),
 			},
 			{
-				Config: testAccResourceVSphereEmpty,
-				Check:  resource.ComposeTestCheckFunc(),
+				Config: testAccResourceVSphereVirtualMachineConfigCloneParameterized(
+					os.Getenv("VSPHERE_DATASTORE2"),
+					"terraform-test-renamed",
+				),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckHostname("terraform-test-renamed"),
+					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE2")),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(0, os.Getenv("VSPHERE_DATASTORE2")),
+					func(s *terraform.State) error {
+						oldID := state.RootModule().Resources["vsphere_virtual_machine.vm"].Primary.ID
+						return testCheckResourceNotAttr("vsphere_virtual_machine.vm", "id", oldID)(s)
+					},
+				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneWithDifferentHostname(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_datastoreClusterClone(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1950,17 +2089,19 @@ func TestAccResourceVSphereVirtualMachine_cloneWithDifferentHostname(t *testing.
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigCloneHostname(),
+				Config: testAccResourceVSphereVirtualMachineConfigCloneDatastoreCluster(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckHostname("terraform-test-renamed"),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "default_ip_address", os.Getenv("VSPHERE_IPV4_ADDRESS")),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneWithExtraDisks(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneModifyDiskAndSCSITypeAtSameTime(t *testing.T) {
+	var state *terraform.State
+
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1970,17 +2111,32 @@ func TestAccResourceVSphereVirtualMachine_cloneWithExtraDisks(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigCloneExtraDisks(),
+				Config: testAccResourceVSphereVirtualMachineConfigCloneChangeDiskAndSCSI(),
 				Check: resource.ComposeTestCheckFunc(
+					copyStatePtr(&state),
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckExtraDisks(),
+					func(s *terraform.State) error {
+						oldSize, _ := strconv.Atoi(state.RootModule().Resources["data.vsphere_virtual_machine.template"].Primary.Attributes["disks.0.size"])
+						newSize := oldSize * 2
+						return resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "disk.0.size", strconv.Itoa(newSize))(s)
+					},
+					func(s *terraform.State) error {
+						oldBus := state.RootModule().Resources["data.vsphere_virtual_machine.template"].Primary.Attributes["scsi_type"]
+						var expected string
+						if oldBus == virtualdevice.SubresourceControllerTypeParaVirtual {
+							expected = virtualdevice.SubresourceControllerTypeLsiLogicSAS
+						} else {
+							expected = virtualdevice.SubresourceControllerTypeParaVirtual
+						}
+						return resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "scsi_type", expected)(s)
+					},
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneWithCdrom(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneMultiNICFromSingleNICTemplate(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1990,17 +2146,17 @@ func TestAccResourceVSphereVirtualMachine_cloneWithCdrom(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigCloneWithCdrom(),
+				Config: testAccResourceVSphereVirtualMachineConfigCloneMultiNIC(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckIsoCdrom(),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "default_ip_address", os.Getenv("VSPHERE_IPV4_ADDRESS")),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneWithVAppProperties(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneWithDifferentTimezone(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -2010,53 +2166,39 @@ func TestAccResourceVSphereVirtualMachine_cloneWithVAppProperties(t *testing.T)
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigCloneWithVAppProperties(),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.hostname", "terraform-test.test.internal"),
-					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.dns.server.0", os.Getenv("VSPHERE_DNS")),
-				),
-			},
-			{
-				Config: testAccResourceVSphereVirtualMachineConfigCloneUpdatingVAppProperties(),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.hostname", "terraform-test.test.internal"),
-					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.dns.server.0", os.Getenv("VSPHERE_DNS")),
-					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.dns.server.1", "8.8.8.8"),
-				),
-			},
-			{
-				Config: testAccResourceVSphereVirtualMachineConfigCloneWithVAppProperties(),
+				Config: testAccResourceVSphereVirtualMachineConfigCloneTimeZone("America/Vancouver"),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.hostname", "terraform-test.test.internal"),
-					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.dns.server.0", os.Getenv("VSPHERE_DNS")),
-					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.dns.server.1", ""),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneWithNonUserVAppPropertyNotSet(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneBlockESXi(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfNotEsxi(t)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigCloneVAppPropertiesNonUserNotSet(),
-				Check:  testAccResourceVSphereVirtualMachineCheckExists(true),
+				Config:      testAccResourceVSphereVirtualMachineConfigClone(),
+				ExpectError: regexp.MustCompile("use of the clone sub-resource block requires vCenter"),
+				PlanOnly:    true,
+			},
+			{
+				Config: testAccResourceVSphereEmpty,
+				Check:  resource.ComposeTestCheckFunc(),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneWithNonUserVAppPropertySet(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneWithBadTimezone(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -2066,8 +2208,9 @@ func TestAccResourceVSphereVirtualMachine_cloneWithNonUserVAppPropertySet(t *tes
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config:      testAccResourceVSphereVirtualMachineConfigCloneVAppPropertiesNonUserSet(),
-				ExpectError: regexp.MustCompile(regexp.QuoteMeta("error in virtual machine configuration: vApp property with userConfigurable=false specified in vapp.properties: [nonuser]")),
+				Config:      testAccResourceVSphereVirtualMachineConfigCloneTimeZone("Pacific Standard Time"),
+				ExpectError: regexp.MustCompile("must be similar to America/Los_Angeles or other Linux/Unix TZ format"),
+				PlanOnly:    true,
 			},
 			{
 				Config: testAccResourceVSphereEmpty,
@@ -2077,7 +2220,7 @@ func TestAccResourceVSphereVirtualMachine_cloneWithNonUserVAppPropertySet(t *tes
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneWithBadVAppPropertyOnCreate(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneWithBadEagerlyScrubWithLinkedClone(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -2087,8 +2230,9 @@ func TestAccResourceVSphereVirtualMachine_cloneWithBadVAppPropertyOnCreate(t *te
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config:      testAccResourceVSphereVirtualMachineConfigCloneVAppPropertiesBadKey(),
-				ExpectError: regexp.MustCompile(regexp.QuoteMeta("unsupported vApp properties in vapp.properties: [foo]")),
+				Config:      testAccResourceVSphereVirtualMachineConfigBadEager(),
+				ExpectError: regexp.MustCompile("must have same value for eagerly_scrub as source when using linked_clone"),
+				PlanOnly:    true,
 			},
 			{
 				Config: testAccResourceVSphereEmpty,
@@ -2098,32 +2242,51 @@ func TestAccResourceVSphereVirtualMachine_cloneWithBadVAppPropertyOnCreate(t *te
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneWithBadVAppPropertyOnUpdate(t *testing.T) {
-	resource.Test(t, resource.TestCase{
-		PreCheck: func() {
-			testAccPreCheck(t)
-			testAccResourceVSphereVirtualMachinePreCheck(t)
-		},
-		Providers:    testAccProviders,
-		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
-		Steps: []resource.TestStep{
-			{
-				Config: testAccResourceVSphereVirtualMachineConfigCloneWithVAppProperties(),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.hostname", "terraform-test.test.internal"),
-					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.dns.server.0", os.Getenv("VSPHERE_DNS")),
-				),
+// Temporarily removed until https://github.com/hashicorp/terraform/issues/21225 is resolved.
+//func TestAccResourceVSphereVirtualMachine_cloneWithBadThinProvisionedWithLinkedClone(t *testing.T) {
+//	resource.Test(t, resource.TestCase{
+//		PreCheck: func() {
+//			testAccPreCheck(t)
+//			testAccResourceVSphereVirtualMachinePreCheck(t)
+//		},
+//		Providers: testAccProviders,
+//		Steps: []resource.TestStep{
+//			{
+//				Config:      testAccResourceVSphereVirtualMachineConfigBadThin(),
+//				ExpectError: regexp.MustCompile("must have same value for thin_provisioned as source when using linked_clone"),
+//				PlanOnly:    true,
+//			},
+//			{
+//				Config: testAccResourceVSphereEmpty,
+//				Check:  resource.ComposeTestCheckFunc(),
+//			},
+//		},
+//	})
+//}
+
+func TestAccResourceVSphereVirtualMachine_cloneWithBadSizeWithLinkedClone(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccResourceVSphereVirtualMachineConfigBadSizeLinked(),
+				ExpectError: regexp.MustCompile("must be the exact size of source when using linked_clone"),
+				PlanOnly:    true,
 			},
 			{
-				Config:      testAccResourceVSphereVirtualMachineConfigCloneVAppPropertiesBadKey(),
-				ExpectError: regexp.MustCompile(regexp.QuoteMeta("unsupported vApp properties in vapp.properties: [foo]")),
+				Config: testAccResourceVSphereEmpty,
+				Check:  resource.ComposeTestCheckFunc(),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cpuHotAdd(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneWithBadSizeWithoutLinkedClone(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -2133,27 +2296,19 @@ func TestAccResourceVSphereVirtualMachine_cpuHotAdd(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				// Starting config
-				Config: testAccResourceVSphereVirtualMachineConfigWithHotAdd(2, 2048, true, false, true),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckCPUMem(2, 2048),
-				),
+				Config:      testAccResourceVSphereVirtualMachineConfigBadSizeUnlinked(),
+				ExpectError: regexp.MustCompile("must be at least the same size of source when cloning"),
+				PlanOnly:    true,
 			},
 			{
-				// Add CPU w/hot-add
-				Config: testAccResourceVSphereVirtualMachineConfigWithHotAdd(4, 2048, true, false, true),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckCPUMem(4, 2048),
-					testAccResourceVSphereVirtualMachineCheckPowerOffEvent(false),
-				),
+				Config: testAccResourceVSphereEmpty,
+				Check:  resource.ComposeTestCheckFunc(),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_memoryHotAdd(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneUnsupportedVAppPropertiesOnCreate(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -2163,27 +2318,14 @@ func TestAccResourceVSphereVirtualMachine_memoryHotAdd(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				// Starting config
-				Config: testAccResourceVSphereVirtualMachineConfigWithHotAdd(2, 2048, true, false, true),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckCPUMem(2, 2048),
-				),
-			},
-			{
-				// Add memory with hot-add
-				Config: testAccResourceVSphereVirtualMachineConfigWithHotAdd(2, 3072, true, false, true),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckCPUMem(2, 3072),
-					testAccResourceVSphereVirtualMachineCheckPowerOffEvent(false),
-				),
+				Config:      testAccResourceVSphereVirtualMachineConfigCloneBadVAppSettings(),
+				ExpectError: regexp.MustCompile("this VM lacks a vApp configuration and cannot have vApp properties set on it"),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_dualStackIPv4AndIPv6(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneUnsupportedVAppPropertiesOnUpdate(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -2193,23 +2335,20 @@ func TestAccResourceVSphereVirtualMachine_dualStackIPv4AndIPv6(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigDualStack(),
+				Config: testAccResourceVSphereVirtualMachineConfigClone(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckNet("fd00::2", "32", "fd00::1"),
-					testAccResourceVSphereVirtualMachineCheckNet(
-						os.Getenv("VSPHERE_IPV4_ADDRESS"),
-						os.Getenv("VSPHERE_IPV4_PREFIX"),
-						os.Getenv("VSPHERE_IPV4_GATEWAY"),
-					),
-					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "default_ip_address", os.Getenv("VSPHERE_IPV4_ADDRESS")),
 				),
 			},
+			{
+				Config:      testAccResourceVSphereVirtualMachineConfigCloneBadVAppSettings(),
+				ExpectError: regexp.MustCompile("this VM lacks a vApp configuration and cannot have vApp properties set on it"),
+			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_IPv6Only(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneIntoEmptyCluster(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -2219,20 +2358,19 @@ func TestAccResourceVSphereVirtualMachine_IPv6Only(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigIPv6Only(),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckNet("fd00::2", "32", "fd00::1"),
-					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "default_ip_address", "fd00::2"),
-				),
+				Config:      testAccResourceVSphereVirtualMachineConfigCloneEmptyCluster(),
+				ExpectError: regexp.MustCompile("compute resource .* is missing an Environment Browser\\. Check host, cluster, and vSphere license health of all associated resources and try again"),
+				PlanOnly:    true,
+			},
+			{
+				Config: testAccResourceVSphereEmpty,
+				Check:  resource.ComposeTestCheckFunc(),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_windowsTemplateCustomizationEventsAndProperIP(t *testing.T) {
-	var state *terraform.State
-
+func TestAccResourceVSphereVirtualMachine_cloneWithDifferentHostname(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -2242,23 +2380,17 @@ func TestAccResourceVSphereVirtualMachine_windowsTemplateCustomizationEventsAndP
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigWindows(),
+				Config: testAccResourceVSphereVirtualMachineConfigCloneHostname(),
 				Check: resource.ComposeTestCheckFunc(
-					copyStatePtr(&state),
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckCustomizationSucceeded(),
-					testAccResourceVSphereVirtualMachineCheckNet(
-						os.Getenv("VSPHERE_IPV4_ADDRESS"),
-						os.Getenv("VSPHERE_IPV4_PREFIX"),
-						os.Getenv("VSPHERE_IPV4_GATEWAY"),
-					),
+					testAccResourceVSphereVirtualMachineCheckHostname("terraform-test-renamed"),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_hostCheck(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneWithExtraDisks(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -2268,27 +2400,17 @@ func TestAccResourceVSphereVirtualMachine_hostCheck(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigHostCheck(os.Getenv("VSPHERE_ESXI_HOST")),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckHost(os.Getenv("VSPHERE_ESXI_HOST")),
-				),
-			},
-			{
-				Config: testAccResourceVSphereEmpty,
-			},
-			{
-				Config: testAccResourceVSphereVirtualMachineConfigHostCheck(os.Getenv("VSPHERE_ESXI_HOST2")),
+				Config: testAccResourceVSphereVirtualMachineConfigCloneExtraDisks(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckHost(os.Getenv("VSPHERE_ESXI_HOST2")),
+					testAccResourceVSphereVirtualMachineCheckExtraDisks(),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_hostVMotion(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneWithCdrom(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -2298,24 +2420,17 @@ func TestAccResourceVSphereVirtualMachine_hostVMotion(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigHostVMotion(os.Getenv("VSPHERE_ESXI_HOST")),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckHost(os.Getenv("VSPHERE_ESXI_HOST")),
-				),
-			},
-			{
-				Config: testAccResourceVSphereVirtualMachineConfigHostVMotion(os.Getenv("VSPHERE_ESXI_HOST2")),
+				Config: testAccResourceVSphereVirtualMachineConfigCloneWithCdrom(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckHost(os.Getenv("VSPHERE_ESXI_HOST2")),
+					testAccResourceVSphereVirtualMachineCheckIsoCdrom(),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_resourcePoolVMotion(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneWithVAppProperties(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -2325,24 +2440,36 @@ func TestAccResourceVSphereVirtualMachine_resourcePoolVMotion(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigResourcePoolVMotion(os.Getenv("VSPHERE_RESOURCE_POOL")),
+				Config: testAccResourceVSphereVirtualMachineConfigCloneWithVAppProperties(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckResourcePool(os.Getenv("VSPHERE_RESOURCE_POOL")),
+					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.hostname", "terraform-test.test.internal"),
+					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.dns.server.0", os.Getenv("VSPHERE_DNS")),
 				),
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigResourcePoolVMotion(fmt.Sprintf("%s/Resources", os.Getenv("VSPHERE_CLUSTER"))),
+				Config: testAccResourceVSphereVirtualMachineConfigCloneUpdatingVAppProperties(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckResourcePool(fmt.Sprintf("%s/Resources", os.Getenv("VSPHERE_CLUSTER"))),
+					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.hostname", "terraform-test.test.internal"),
+					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.dns.server.0", os.Getenv("VSPHERE_DNS")),
+					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.dns.server.1", "8.8.8.8"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigCloneWithVAppProperties(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.hostname", "terraform-test.test.internal"),
+					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.dns.server.0", os.Getenv("VSPHERE_DNS")),
+					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.dns.server.1", ""),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_clusterVMotion(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneWithNonUserVAppPropertyNotSet(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -2352,24 +2479,14 @@ func TestAccResourceVSphereVirtualMachine_clusterVMotion(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigResourcePoolVMotion(os.Getenv("VSPHERE_RESOURCE_POOL")),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckResourcePool(os.Getenv("VSPHERE_RESOURCE_POOL")),
-				),
-			},
-			{
-				Config: testAccResourceVSphereVirtualMachineConfigResourcePoolVMotion(fmt.Sprintf("%s/Resources", os.Getenv("VSPHERE_ESXI_HOST5"))),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckResourcePool(fmt.Sprintf("%s/Resources", os.Getenv("VSPHERE_ESXI_HOST5"))),
-				),
+				Config: testAccResourceVSphereVirtualMachineConfigCloneVAppPropertiesNonUserNotSet(),
+				Check:  testAccResourceVSphereVirtualMachineCheckExists(true),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_storageVMotionGlobalSetting(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneWithNonUserVAppPropertySet(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -2379,26 +2496,18 @@ func TestAccResourceVSphereVirtualMachine_storageVMotionGlobalSetting(t *testing
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionGlobal(os.Getenv("VSPHERE_DATASTORE")),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(0, os.Getenv("VSPHERE_DATASTORE")),
-				),
+				Config:      testAccResourceVSphereVirtualMachineConfigCloneVAppPropertiesNonUserSet(),
+				ExpectError: regexp.MustCompile(regexp.QuoteMeta("error in virtual machine configuration: vApp property with userConfigurable=false specified in vapp.properties: [nonuser]")),
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionGlobal(os.Getenv("VSPHERE_DATASTORE2")),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE2")),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(0, os.Getenv("VSPHERE_DATASTORE2")),
-				),
+				Config: testAccResourceVSphereEmpty,
+				Check:  resource.ComposeTestCheckFunc(),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_storageVMotionSingleDisk(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneWithBadVAppPropertyOnCreate(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -2408,28 +2517,18 @@ func TestAccResourceVSphereVirtualMachine_storageVMotionSingleDisk(t *testing.T)
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionSingleDisk(os.Getenv("VSPHERE_DATASTORE")),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(0, os.Getenv("VSPHERE_DATASTORE")),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(1, os.Getenv("VSPHERE_DATASTORE")),
-				),
+				Config:      testAccResourceVSphereVirtualMachineConfigCloneVAppPropertiesBadKey(),
+				ExpectError: regexp.MustCompile(regexp.QuoteMeta("unsupported vApp properties in vapp.properties: [foo]")),
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionSingleDisk(os.Getenv("VSPHERE_DATASTORE2")),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(0, os.Getenv("VSPHERE_DATASTORE")),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(1, os.Getenv("VSPHERE_DATASTORE2")),
-				),
+				Config: testAccResourceVSphereEmpty,
+				Check:  resource.ComposeTestCheckFunc(),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_storageVMotionPinDatastore(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneWithBadVAppPropertyOnUpdate(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -2439,28 +2538,22 @@ func TestAccResourceVSphereVirtualMachine_storageVMotionPinDatastore(t *testing.
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionPinDatastore(os.Getenv("VSPHERE_DATASTORE")),
+				Config: testAccResourceVSphereVirtualMachineConfigCloneWithVAppProperties(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(0, os.Getenv("VSPHERE_DATASTORE")),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(1, os.Getenv("VSPHERE_DATASTORE")),
+					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.hostname", "terraform-test.test.internal"),
+					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.dns.server.0", os.Getenv("VSPHERE_DNS")),
 				),
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionPinDatastore(os.Getenv("VSPHERE_DATASTORE2")),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE2")),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(0, os.Getenv("VSPHERE_DATASTORE2")),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(1, os.Getenv("VSPHERE_DATASTORE")),
-				),
+				Config:      testAccResourceVSphereVirtualMachineConfigCloneVAppPropertiesBadKey(),
+				ExpectError: regexp.MustCompile(regexp.QuoteMeta("unsupported vApp properties in vapp.properties: [foo]")),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_storageVMotionRenamedVirtualMachine(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cpuHotAdd(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -2470,36 +2563,27 @@ func TestAccResourceVSphereVirtualMachine_storageVMotionRenamedVirtualMachine(t
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionRename("terraform-test", os.Getenv("VSPHERE_DATASTORE")),
+				// Starting config
+				Config: testAccResourceVSphereVirtualMachineConfigWithHotAdd(2, 2048, true, false, true),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(0, os.Getenv("VSPHERE_DATASTORE")),
-				),
-			},
-			{
-				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionRename("foobar-test", os.Getenv("VSPHERE_DATASTORE")),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(0, os.Getenv("VSPHERE_DATASTORE")),
+					testAccResourceVSphereVirtualMachineCheckCPUMem(2, 2048),
 				),
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionRename("foobar-test", os.Getenv("VSPHERE_DATASTORE2")),
+				// Add CPU w/hot-add
+				Config: testAccResourceVSphereVirtualMachineConfigWithHotAdd(4, 2048, true, false, true),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE2")),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(0, os.Getenv("VSPHERE_DATASTORE2")),
+					testAccResourceVSphereVirtualMachineCheckCPUMem(4, 2048),
+					testAccResourceVSphereVirtualMachineCheckPowerOffEvent(false),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_storageVMotionLinkedClones(t *testing.T) {
-	var state *terraform.State
-
+func TestAccResourceVSphereVirtualMachine_memoryHotAdd(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -2509,29 +2593,27 @@ func TestAccResourceVSphereVirtualMachine_storageVMotionLinkedClones(t *testing.
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionLinkedClone(os.Getenv("VSPHERE_DATASTORE")),
+				// Starting config
+				Config: testAccResourceVSphereVirtualMachineConfigWithHotAdd(2, 2048, true, false, true),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(0, os.Getenv("VSPHERE_DATASTORE")),
+					testAccResourceVSphereVirtualMachineCheckCPUMem(2, 2048),
 				),
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionLinkedClone(os.Getenv("VSPHERE_DATASTORE2")),
+				// Add memory with hot-add
+				Config: testAccResourceVSphereVirtualMachineConfigWithHotAdd(2, 3072, true, false, true),
 				Check: resource.ComposeTestCheckFunc(
-					copyStatePtr(&state),
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE2")),
-					func(s *terraform.State) error {
-						return testAccResourceVSphereVirtualMachineCheckVmdkDatastore(0, os.Getenv("VSPHERE_DATASTORE2"))(s)
-					},
+					testAccResourceVSphereVirtualMachineCheckCPUMem(2, 3072),
+					testAccResourceVSphereVirtualMachineCheckPowerOffEvent(false),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_storageVMotionBlockExternallyAttachedDisks(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_dualStackIPv4AndIPv6(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -2541,24 +2623,23 @@ func TestAccResourceVSphereVirtualMachine_storageVMotionBlockExternallyAttachedD
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionAttachedDisk(os.Getenv("VSPHERE_DATASTORE")),
+				Config: testAccResourceVSphereVirtualMachineConfigDualStack(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(0, os.Getenv("VSPHERE_DATASTORE")),
+					testAccResourceVSphereVirtualMachineCheckNet("fd00::2", "32", "fd00::1"),
+					testAccResourceVSphereVirtualMachineCheckNet(
+						os.Getenv("VSPHERE_IPV4_ADDRESS"),
+						os.Getenv("VSPHERE_IPV4_PREFIX"),
+						os.Getenv("VSPHERE_IPV4_GATEWAY"),
+					),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "default_ip_address", os.Getenv("VSPHERE_IPV4_ADDRESS")),
 				),
 			},
-			{
-				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionAttachedDisk(os.Getenv("VSPHERE_DATASTORE2")),
-				ExpectError: regexp.MustCompile(regexp.QuoteMeta(
-					fmt.Sprintf("externally attached disk %q cannot be migrated", testAccResourceVSphereVirtualMachineDiskNameExtraVmdk),
-				)),
-			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_storageVMotionDatastoreCluster(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_IPv6Only(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -2568,38 +2649,20 @@ func TestAccResourceVSphereVirtualMachine_storageVMotionDatastoreCluster(t *test
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionDatastoreCluster(
-					testAccResourceVSphereVirtualMachineDatastoreCluster,
-				),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVmxDatastoreCluster(
-						testAccResourceVSphereVirtualMachineDatastoreCluster,
-					),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastoreCluster(
-						0, testAccResourceVSphereVirtualMachineDatastoreCluster,
-					),
-				),
-			},
-			{
-				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionDatastoreCluster(
-					testAccResourceVSphereVirtualMachineDatastoreClusterAlt,
-				),
+				Config: testAccResourceVSphereVirtualMachineConfigIPv6Only(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVmxDatastoreCluster(
-						testAccResourceVSphereVirtualMachineDatastoreClusterAlt,
-					),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastoreCluster(
-						0, testAccResourceVSphereVirtualMachineDatastoreClusterAlt,
-					),
+					testAccResourceVSphereVirtualMachineCheckNet("fd00::2", "32", "fd00::1"),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "default_ip_address", "fd00::2"),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_hostVMotionDatastoreCluster(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_windowsTemplateCustomizationEventsAndProperIP(t *testing.T) {
+	var state *terraform.State
+
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -2609,24 +2672,23 @@ func TestAccResourceVSphereVirtualMachine_hostVMotionDatastoreCluster(t *testing
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigHostVMotionDatastoreCluster(os.Getenv("VSPHERE_ESXI_HOST")),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckHost(os.Getenv("VSPHERE_ESXI_HOST")),
-				),
-			},
-			{
-				Config: testAccResourceVSphereVirtualMachineConfigHostVMotionDatastoreCluster(os.Getenv("VSPHERE_ESXI_HOST2")),
+				Config: testAccResourceVSphereVirtualMachineConfigWindows(),
 				Check: resource.ComposeTestCheckFunc(
+					copyStatePtr(&state),
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckHost(os.Getenv("VSPHERE_ESXI_HOST2")),
+					testAccResourceVSphereVirtualMachineCheckCustomizationSucceeded(),
+					testAccResourceVSphereVirtualMachineCheckNet(
+						os.Getenv("VSPHERE_IPV4_ADDRESS"),
+						os.Getenv("VSPHERE_IPV4_PREFIX"),
+						os.Getenv("VSPHERE_IPV4_GATEWAY"),
+					),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_storageVMotionDatastoreClusterSingleDisk(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_hostCheck(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -2636,55 +2698,27 @@ func TestAccResourceVSphereVirtualMachine_storageVMotionDatastoreClusterSingleDi
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionDatastoreClusterSingleDiskStep0(),
+				Config: testAccResourceVSphereVirtualMachineConfigHostCheck(os.Getenv("VSPHERE_ESXI_HOST")),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVmxDatastoreCluster(
-						testAccResourceVSphereVirtualMachineDatastoreCluster,
-					),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastoreCluster(
-						0, testAccResourceVSphereVirtualMachineDatastoreCluster,
-					),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastoreCluster(
-						1, testAccResourceVSphereVirtualMachineDatastoreCluster,
-					),
+					testAccResourceVSphereVirtualMachineCheckHost(os.Getenv("VSPHERE_ESXI_HOST")),
 				),
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionDatastoreClusterSingleDiskStep1(
-					os.Getenv("VSPHERE_DATASTORE"),
-				),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVmxDatastoreCluster(
-						testAccResourceVSphereVirtualMachineDatastoreCluster,
-					),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastoreCluster(
-						0, testAccResourceVSphereVirtualMachineDatastoreCluster,
-					),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(1, os.Getenv("VSPHERE_DATASTORE")),
-				),
+				Config: testAccResourceVSphereEmpty,
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionDatastoreClusterSingleDiskStep0(),
+				Config: testAccResourceVSphereVirtualMachineConfigHostCheck(os.Getenv("VSPHERE_ESXI_HOST2")),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVmxDatastoreCluster(
-						testAccResourceVSphereVirtualMachineDatastoreCluster,
-					),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastoreCluster(
-						0, testAccResourceVSphereVirtualMachineDatastoreCluster,
-					),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastoreCluster(
-						1, testAccResourceVSphereVirtualMachineDatastoreCluster,
-					),
+					testAccResourceVSphereVirtualMachineCheckHost(os.Getenv("VSPHERE_ESXI_HOST2")),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_singleCustomAttribute(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_hostVMotion(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -2694,17 +2728,24 @@ func TestAccResourceVSphereVirtualMachine_singleCustomAttribute(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigWithCustomAttribute(),
+				Config: testAccResourceVSphereVirtualMachineConfigHostVMotion(os.Getenv("VSPHERE_ESXI_HOST")),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckCustomAttributes(),
+					testAccResourceVSphereVirtualMachineCheckHost(os.Getenv("VSPHERE_ESXI_HOST")),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigHostVMotion(os.Getenv("VSPHERE_ESXI_HOST2")),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckHost(os.Getenv("VSPHERE_ESXI_HOST2")),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_multiCustomAttribute(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_resourcePoolVMotion(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -2714,17 +2755,24 @@ func TestAccResourceVSphereVirtualMachine_multiCustomAttribute(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigWithMultiCustomAttribute(),
+				Config: testAccResourceVSphereVirtualMachineConfigResourcePoolVMotion(os.Getenv("VSPHERE_RESOURCE_POOL")),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckCustomAttributes(),
+					testAccResourceVSphereVirtualMachineCheckResourcePool(os.Getenv("VSPHERE_RESOURCE_POOL")),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigResourcePoolVMotion(fmt.Sprintf("%s/Resources", os.Getenv("VSPHERE_CLUSTER"))),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckResourcePool(fmt.Sprintf("%s/Resources", os.Getenv("VSPHERE_CLUSTER"))),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_switchCustomAttribute(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_clusterVMotion(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -2734,27 +2782,24 @@ func TestAccResourceVSphereVirtualMachine_switchCustomAttribute(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigWithCustomAttribute(),
+				Config: testAccResourceVSphereVirtualMachineConfigResourcePoolVMotion(os.Getenv("VSPHERE_RESOURCE_POOL")),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckCustomAttributes(),
+					testAccResourceVSphereVirtualMachineCheckResourcePool(os.Getenv("VSPHERE_RESOURCE_POOL")),
 				),
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigWithMultiCustomAttribute(),
+				Config: testAccResourceVSphereVirtualMachineConfigResourcePoolVMotion(fmt.Sprintf("%s/Resources", os.Getenv("VSPHERE_ESXI_HOST5"))),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckCustomAttributes(),
+					testAccResourceVSphereVirtualMachineCheckResourcePool(fmt.Sprintf("%s/Resources", os.Getenv("VSPHERE_ESXI_HOST5"))),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_transitionToLabel(t *testing.T) {
-	// TODO: Remove this test in 2.0
-	var state *terraform.State
-
+func TestAccResourceVSphereVirtualMachine_storageVMotionGlobalSetting(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -2764,30 +2809,26 @@ func TestAccResourceVSphereVirtualMachine_transitionToLabel(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigBasicDiskNameOrLabel("name"),
+				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionGlobal(os.Getenv("VSPHERE_DATASTORE")),
 				Check: resource.ComposeTestCheckFunc(
-					copyState(&state),
 					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(0, os.Getenv("VSPHERE_DATASTORE")),
 				),
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigBasicDiskNameOrLabel("label"),
+				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionGlobal(os.Getenv("VSPHERE_DATASTORE2")),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					func(s *terraform.State) error {
-						uuid := state.RootModule().Resources["vsphere_virtual_machine.vm"].Primary.Attributes["disk.0.uuid"]
-						return resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "disk.0.uuid", uuid)(s)
-					},
+					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE2")),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(0, os.Getenv("VSPHERE_DATASTORE2")),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_preventRevertToName(t *testing.T) {
-	// TODO: Remove this test in 2.0
-	var state *terraform.State
-
+func TestAccResourceVSphereVirtualMachine_storageVMotionSingleDisk(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -2797,34 +2838,28 @@ func TestAccResourceVSphereVirtualMachine_preventRevertToName(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigBasicDiskNameOrLabel("name"),
+				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionSingleDisk(os.Getenv("VSPHERE_DATASTORE")),
 				Check: resource.ComposeTestCheckFunc(
-					copyState(&state),
 					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(0, os.Getenv("VSPHERE_DATASTORE")),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(1, os.Getenv("VSPHERE_DATASTORE")),
 				),
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigBasicDiskNameOrLabel("label"),
+				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionSingleDisk(os.Getenv("VSPHERE_DATASTORE2")),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					func(s *terraform.State) error {
-						uuid := state.RootModule().Resources["vsphere_virtual_machine.vm"].Primary.Attributes["disk.0.uuid"]
-						return resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "disk.0.uuid", uuid)(s)
-					},
+					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(0, os.Getenv("VSPHERE_DATASTORE")),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(1, os.Getenv("VSPHERE_DATASTORE2")),
 				),
 			},
-			{
-				Config:      testAccResourceVSphereVirtualMachineConfigBasicDiskNameOrLabel("name"),
-				ExpectError: regexp.MustCompile("cannot migrate from label to name"),
-			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_transitionToLabelAttachedDisk(t *testing.T) {
-	// TODO: Remove this test in 2.0
-	var state *terraform.State
-
+func TestAccResourceVSphereVirtualMachine_storageVMotionPinDatastore(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -2834,30 +2869,28 @@ func TestAccResourceVSphereVirtualMachine_transitionToLabelAttachedDisk(t *testi
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigExistingVmdkWithName(),
+				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionPinDatastore(os.Getenv("VSPHERE_DATASTORE")),
 				Check: resource.ComposeTestCheckFunc(
-					copyState(&state),
 					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(0, os.Getenv("VSPHERE_DATASTORE")),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(1, os.Getenv("VSPHERE_DATASTORE")),
 				),
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigExistingVmdkWithLabel(),
+				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionPinDatastore(os.Getenv("VSPHERE_DATASTORE2")),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					func(s *terraform.State) error {
-						uuid := state.RootModule().Resources["vsphere_virtual_machine.vm"].Primary.Attributes["disk.1.uuid"]
-						if err := resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "disk.1.uuid", uuid)(s); err != nil {
-							return err
-						}
-						return resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "disk.1.attach", "true")(s)
-					},
+					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE2")),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(0, os.Getenv("VSPHERE_DATASTORE2")),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(1, os.Getenv("VSPHERE_DATASTORE")),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_multipleDisksAtDifferentSCSISlotsImport(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_storageVMotionRenamedVirtualMachine(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -2867,36 +2900,36 @@ func TestAccResourceVSphereVirtualMachine_multipleDisksAtDifferentSCSISlotsImpor
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigMultiHighBus(),
+				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionRename("terraform-test", os.Getenv("VSPHERE_DATASTORE")),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(0, os.Getenv("VSPHERE_DATASTORE")),
 				),
 			},
 			{
-				ResourceName:      "vsphere_virtual_machine.vm",
-				ImportState:       true,
-				ImportStateVerify: true,
-				ImportStateVerifyIgnore: []string{
-					"disk",
-					"imported",
-				},
-				ImportStateIdFunc: func(s *terraform.State) (string, error) {
-					vm, err := testGetVirtualMachine(s, "vm")
-					if err != nil {
-						return "", err
-					}
-					return vm.InventoryPath, nil
-				},
-				Config: testAccResourceVSphereVirtualMachineConfigMultiHighBus(),
+				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionRename("foobar-test", os.Getenv("VSPHERE_DATASTORE")),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(0, os.Getenv("VSPHERE_DATASTORE")),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionRename("foobar-test", os.Getenv("VSPHERE_DATASTORE2")),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE2")),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(0, os.Getenv("VSPHERE_DATASTORE2")),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneImport(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_storageVMotionLinkedClones(t *testing.T) {
+	var state *terraform.State
+
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -2906,71 +2939,97 @@ func TestAccResourceVSphereVirtualMachine_cloneImport(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigClone(),
+				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionLinkedClone(os.Getenv("VSPHERE_DATASTORE")),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(0, os.Getenv("VSPHERE_DATASTORE")),
 				),
 			},
 			{
-				ResourceName:      "vsphere_virtual_machine.vm",
-				ImportState:       true,
-				ImportStateVerify: true,
-				ImportStateVerifyIgnore: []string{
-					"disk",
-					"imported",
-					"clone",
-				},
-				ImportStateIdFunc: func(s *terraform.State) (string, error) {
-					vm, err := testGetVirtualMachine(s, "vm")
-					if err != nil {
-						return "", err
-					}
-					return vm.InventoryPath, nil
-				},
-				Config: testAccResourceVSphereVirtualMachineConfigClone(),
+				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionLinkedClone(os.Getenv("VSPHERE_DATASTORE2")),
 				Check: resource.ComposeTestCheckFunc(
+					copyStatePtr(&state),
 					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE2")),
+					func(s *terraform.State) error {
+						return testAccResourceVSphereVirtualMachineCheckVmdkDatastore(0, os.Getenv("VSPHERE_DATASTORE2"))(s)
+					},
 				),
 			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualMachine_storageVMotionBlockExternallyAttachedDisks(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigClone(),
+				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionAttachedDisk(os.Getenv("VSPHERE_DATASTORE")),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					func(s *terraform.State) error {
-						// This simulates an import scenario, as ImportStateVerify does not
-						// actually do a full TF run after import, and hence the above import
-						// check does not actually test to see Terraform will be able to
-						// plan. Hence we actually remove the clone configuration from the
-						// state and ensure that imported is flagged. This allows the next
-						// step to properly simulate the post-imported state.
-						rs, ok := s.RootModule().Resources["vsphere_virtual_machine.vm"]
-						if !ok {
-							return errors.New("vsphere_virtual_machine.vm not found in state")
-						}
-						for k := range rs.Primary.Attributes {
-							if strings.HasPrefix(k, "clone") {
-								delete(rs.Primary.Attributes, k)
-							}
-						}
-						rs.Primary.Attributes["imported"] = "true"
+					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(0, os.Getenv("VSPHERE_DATASTORE")),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionAttachedDisk(os.Getenv("VSPHERE_DATASTORE2")),
+				ExpectError: regexp.MustCompile(regexp.QuoteMeta(
+					fmt.Sprintf("externally attached disk %q cannot be migrated", testAccResourceVSphereVirtualMachineDiskNameExtraVmdk),
+				)),
+			},
+		},
+	})
+}
 
-						return nil
-					},
+func TestAccResourceVSphereVirtualMachine_storageVMotionDatastoreCluster(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionDatastoreCluster(
+					testAccResourceVSphereVirtualMachineDatastoreCluster,
+				),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckVmxDatastoreCluster(
+						testAccResourceVSphereVirtualMachineDatastoreCluster,
+					),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastoreCluster(
+						0, testAccResourceVSphereVirtualMachineDatastoreCluster,
+					),
 				),
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigClone(),
+				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionDatastoreCluster(
+					testAccResourceVSphereVirtualMachineDatastoreClusterAlt,
+				),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckVmxDatastoreCluster(
+						testAccResourceVSphereVirtualMachineDatastoreClusterAlt,
+					),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastoreCluster(
+						0, testAccResourceVSphereVirtualMachineDatastoreClusterAlt,
+					),
 				),
 			},
 		},
 	})
 }
 
-// testAccResourceVSphereVirtualMachineReadVappChildResourcePool
-// TestAccResourceVSphereVirtualMachine_importClone
-func TestAccResourceVSphereVirtualMachine_readVappChildResourcePool(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_hostVMotionDatastoreCluster(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -2980,1077 +3039,2015 @@ func TestAccResourceVSphereVirtualMachine_readVappChildResourcePool(t *testing.T
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineReadVappChildResourcePool(),
+				Config: testAccResourceVSphereVirtualMachineConfigHostVMotionDatastoreCluster(os.Getenv("VSPHERE_ESXI_HOST")),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckHost(os.Getenv("VSPHERE_ESXI_HOST")),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigHostVMotionDatastoreCluster(os.Getenv("VSPHERE_ESXI_HOST2")),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckHost(os.Getenv("VSPHERE_ESXI_HOST2")),
+				),
 			},
 		},
 	})
 }
-func testAccResourceVSphereVirtualMachinePreCheck(t *testing.T) {
-	// Note that VSPHERE_USE_LINKED_CLONE is also a variable and its presence
-	// speeds up tests greatly, but it's not a necessary variable, so we don't
-	// enforce it here.
-	if os.Getenv("VSPHERE_DATACENTER") == "" {
-		t.Skip("set VSPHERE_DATACENTER to run vsphere_virtual_machine acceptance tests")
-	}
-	if os.Getenv("VSPHERE_CLUSTER") == "" {
-		t.Skip("set VSPHERE_CLUSTER to run vsphere_virtual_machine acceptance tests")
-	}
-	if os.Getenv("VSPHERE_RESOURCE_POOL") == "" {
-		t.Skip("set VSPHERE_RESOURCE_POOL to run vsphere_virtual_machine acceptance tests")
-	}
-	if os.Getenv("VSPHERE_NETWORK_LABEL") == "" {
-		t.Skip("set VSPHERE_NETWORK_LABEL to run vsphere_virtual_machine acceptance tests")
-	}
-	if os.Getenv("VSPHERE_NETWORK_LABEL_PXE") == "" {
-		t.Skip("set VSPHERE_NETWORK_LABEL_PXE to run vsphere_virtual_machine acceptance tests")
-	}
-	if os.Getenv("VSPHERE_IPV4_ADDRESS") == "" {
-		t.Skip("set VSPHERE_IPV4_ADDRESS to run vsphere_virtual_machine acceptance tests")
-	}
-	if os.Getenv("VSPHERE_IPV4_PREFIX") == "" {
-		t.Skip("set VSPHERE_IPV4_PREFIX to run vsphere_virtual_machine acceptance tests")
-	}
-	if os.Getenv("VSPHERE_IPV4_GATEWAY") == "" {
-		t.Skip("set VSPHERE_IPV4_GATEWAY to run vsphere_virtual_machine acceptance tests")
-	}
-	if os.Getenv("VSPHERE_DNS") == "" {
-		t.Skip("set VSPHERE_DNS to run vsphere_virtual_machine acceptance tests")
-	}
-	if os.Getenv("VSPHERE_DATASTORE") == "" {
-		t.Skip("set VSPHERE_DATASTORE to run vsphere_virtual_machine acceptance tests")
-	}
-	if os.Getenv("VSPHERE_DATASTORE2") == "" {
-		t.Skip("set VSPHERE_DATASTORE2 to run vsphere_virtual_machine acceptance tests")
-	}
-	if os.Getenv("VSPHERE_TEMPLATE") == "" {
-		t.Skip("set VSPHERE_TEMPLATE to run vsphere_virtual_machine acceptance tests")
-	}
-	if os.Getenv("VSPHERE_TEMPLATE_ISO_TRANSPORT") == "" {
-		t.Skip("set VSPHERE_TEMPLATE_ISO_TRANSPORT to run vsphere_virtual_machine acceptance tests")
-	}
-	if os.Getenv("VSPHERE_TEMPLATE_WINDOWS") == "" {
-		t.Skip("set VSPHERE_TEMPLATE_WINDOWS to run vsphere_virtual_machine acceptance tests")
-	}
-	if os.Getenv("VSPHERE_TEMPLATE_NONUSER_VAPP") == "" {
-		t.Skip("set VSPHERE_TEMPLATE_NONUSER_VAPP to run vsphere_virtual_machine acceptance tests")
-	}
-	if os.Getenv("VSPHERE_TEMPLATE_COREOS") == "" {
-		t.Skip("set VSPHERE_TEMPLATE_COREOS to run vsphere_virtual_machine acceptance tests")
-	}
-	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
-		t.Skip("set VSPHERE_ESXI_HOST to run vsphere_virtual_machine acceptance tests")
-	}
-	if os.Getenv("VSPHERE_ESXI_HOST2") == "" {
-		t.Skip("set VSPHERE_ESXI_HOST2 to run vsphere_virtual_machine acceptance tests")
-	}
-	if os.Getenv("VSPHERE_ESXI_HOST3") == "" {
-		t.Skip("set VSPHERE_ESXI_HOST3 to run vsphere_virtual_machine acceptance tests")
-	}
-	if os.Getenv("VSPHERE_HOST_NIC0") == "" {
-		t.Skip("set VSPHERE_HOST_NIC0 to run vsphere_virtual_machine acceptance tests")
-	}
-	if os.Getenv("VSPHERE_DS_VMFS_DISK0") == "" {
-		t.Skip("set VSPHERE_DS_VMFS_DISK0 to run vsphere_virtual_machine acceptance tests")
-	}
-	if os.Getenv("VSPHERE_DS_VMFS_DISK1") == "" {
-		t.Skip("set VSPHERE_DS_VMFS_DISK1 to run vsphere_virtual_machine acceptance tests")
-	}
-	if os.Getenv("VSPHERE_DS_VMFS_DISK2") == "" {
-		t.Skip("set VSPHERE_DS_VMFS_DISK2 to run vsphere_virtual_machine acceptance tests")
-	}
-	if os.Getenv("VSPHERE_NAS_HOST") == "" {
-		t.Skip("set VSPHERE_NAS_HOST to run vsphere_virtual_machine acceptance tests")
-	}
-	if os.Getenv("VSPHERE_NFS_PATH") == "" {
-		t.Skip("set VSPHERE_NFS_PATH to run vsphere_virtual_machine acceptance tests")
-	}
-	if os.Getenv("VSPHERE_NFS_PATH2") == "" {
-		t.Skip("set VSPHERE_NFS_PATH2 to run vsphere_virtual_machine acceptance tests")
-	}
-}
-
-func testAccResourceVSphereVirtualMachineCheckExists(expected bool) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		_, err := testGetVirtualMachine(s, "vm")
-		if err != nil {
-			missingState, _ := regexp.MatchString("not found in state", err.Error())
-			missingVSphere, _ := regexp.MatchString("virtual machine with UUID \"[-a-f0-9]+\" not found", err.Error())
-			if missingState && !expected || missingVSphere && !expected {
-				// Expected missing
-				return nil
-			}
-			return err
-		}
-		if !expected {
-			return errors.New("expected VM to be missing")
-		}
-		return nil
-	}
-}
 
-func testAccResourceVSphereVirtualMachineCheckVAppConfigKey(key, value string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-		actual := props.Config.VAppConfig.GetVmConfigInfo().Property
-		for _, prop := range actual {
+func TestAccResourceVSphereVirtualMachine_storageVMotionDatastoreClusterSingleDisk(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionDatastoreClusterSingleDiskStep0(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckVmxDatastoreCluster(
+						testAccResourceVSphereVirtualMachineDatastoreCluster,
+					),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastoreCluster(
+						0, testAccResourceVSphereVirtualMachineDatastoreCluster,
+					),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastoreCluster(
+						1, testAccResourceVSphereVirtualMachineDatastoreCluster,
+					),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionDatastoreClusterSingleDiskStep1(
+					os.Getenv("VSPHERE_DATASTORE"),
+				),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckVmxDatastoreCluster(
+						testAccResourceVSphereVirtualMachineDatastoreCluster,
+					),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastoreCluster(
+						0, testAccResourceVSphereVirtualMachineDatastoreCluster,
+					),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastore(1, os.Getenv("VSPHERE_DATASTORE")),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionDatastoreClusterSingleDiskStep0(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckVmxDatastoreCluster(
+						testAccResourceVSphereVirtualMachineDatastoreCluster,
+					),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastoreCluster(
+						0, testAccResourceVSphereVirtualMachineDatastoreCluster,
+					),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastoreCluster(
+						1, testAccResourceVSphereVirtualMachineDatastoreCluster,
+					),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualMachine_singleCustomAttribute(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigWithCustomAttribute(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckCustomAttributes(),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualMachine_multiCustomAttribute(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigWithMultiCustomAttribute(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckCustomAttributes(),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualMachine_switchCustomAttribute(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigWithCustomAttribute(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckCustomAttributes(),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigWithMultiCustomAttribute(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckCustomAttributes(),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualMachine_transitionToLabel(t *testing.T) {
+	// TODO: Remove this test in 2.0
+	var state *terraform.State
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigBasicDiskNameOrLabel("name"),
+				Check: resource.ComposeTestCheckFunc(
+					copyState(&state),
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigBasicDiskNameOrLabel("label"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					func(s *terraform.State) error {
+						uuid := state.RootModule().Resources["vsphere_virtual_machine.vm"].Primary.Attributes["disk.0.uuid"]
+						return resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "disk.0.uuid", uuid)(s)
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualMachine_preventRevertToName(t *testing.T) {
+	// TODO: Remove this test in 2.0
+	var state *terraform.State
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigBasicDiskNameOrLabel("name"),
+				Check: resource.ComposeTestCheckFunc(
+					copyState(&state),
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigBasicDiskNameOrLabel("label"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					func(s *terraform.State) error {
+						uuid := state.RootModule().Resources["vsphere_virtual_machine.vm"].Primary.Attributes["disk.0.uuid"]
+						return resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "disk.0.uuid", uuid)(s)
+					},
+				),
+			},
+			{
+				Config:      testAccResourceVSphereVirtualMachineConfigBasicDiskNameOrLabel("name"),
+				ExpectError: regexp.MustCompile("cannot migrate from label to name"),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualMachine_transitionToLabelAttachedDisk(t *testing.T) {
+	// TODO: Remove this test in 2.0
+	var state *terraform.State
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigExistingVmdkWithName(),
+				Check: resource.ComposeTestCheckFunc(
+					copyState(&state),
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigExistingVmdkWithLabel(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					func(s *terraform.State) error {
+						uuid := state.RootModule().Resources["vsphere_virtual_machine.vm"].Primary.Attributes["disk.1.uuid"]
+						if err := resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "disk.1.uuid", uuid)(s); err != nil {
+							return err
+						}
+						return resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "disk.1.attach", "true")(s)
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualMachine_multipleDisksAtDifferentSCSISlotsImport(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigMultiHighBus(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+				),
+			},
+			{
+				ResourceName:      "vsphere_virtual_machine.vm",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateVerifyIgnore: []string{
+					"disk",
+					"imported",
+				},
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					vm, err := testGetVirtualMachine(s, "vm")
+					if err != nil {
+						return "", err
+					}
+					return vm.InventoryPath, nil
+				},
+				Config: testAccResourceVSphereVirtualMachineConfigMultiHighBus(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualMachine_cloneImport(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigClone(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+				),
+			},
+			{
+				ResourceName:      "vsphere_virtual_machine.vm",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateVerifyIgnore: []string{
+					"disk",
+					"imported",
+					"clone",
+				},
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					vm, err := testGetVirtualMachine(s, "vm")
+					if err != nil {
+						return "", err
+					}
+					return vm.InventoryPath, nil
+				},
+				Config: testAccResourceVSphereVirtualMachineConfigClone(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigClone(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					func(s *terraform.State) error {
+						// This simulates an import scenario, as ImportStateVerify does not
+						// actually do a full TF run after import, and hence the above import
+						// check does not actually test to see Terraform will be able to
+						// plan. Hence we actually remove the clone configuration from the
+						// state and ensure that imported is flagged. This allows the next
+						// step to properly simulate the post-imported state.
+						rs, ok := s.RootModule().Resources["vsphere_virtual_machine.vm"]
+						if !ok {
+							return errors.New("vsphere_virtual_machine.vm not found in state")
+						}
+						for k := range rs.Primary.Attributes {
+							if strings.HasPrefix(k, "clone") {
+								delete(rs.Primary.Attributes, k)
+							}
+						}
+						rs.Primary.Attributes["imported"] = "true"
+
+						return nil
+					},
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigClone(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+				),
+			},
+		},
+	})
+}
+
+// testAccResourceVSphereVirtualMachineReadVappChildResourcePool
+// TestAccResourceVSphereVirtualMachine_importClone
+func TestAccResourceVSphereVirtualMachine_readVappChildResourcePool(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineReadVappChildResourcePool(),
+			},
+		},
+	})
+}
+func testAccResourceVSphereVirtualMachinePreCheck(t *testing.T) {
+	// Note that VSPHERE_USE_LINKED_CLONE is also a variable and its presence
+	// speeds up tests greatly, but it's not a necessary variable, so we don't
+	// enforce it here.
+	if os.Getenv("VSPHERE_DATACENTER") == "" {
+		t.Skip("set VSPHERE_DATACENTER to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_CLUSTER") == "" {
+		t.Skip("set VSPHERE_CLUSTER to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_RESOURCE_POOL") == "" {
+		t.Skip("set VSPHERE_RESOURCE_POOL to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_NETWORK_LABEL") == "" {
+		t.Skip("set VSPHERE_NETWORK_LABEL to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_NETWORK_LABEL_PXE") == "" {
+		t.Skip("set VSPHERE_NETWORK_LABEL_PXE to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_IPV4_ADDRESS") == "" {
+		t.Skip("set VSPHERE_IPV4_ADDRESS to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_IPV4_PREFIX") == "" {
+		t.Skip("set VSPHERE_IPV4_PREFIX to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_IPV4_GATEWAY") == "" {
+		t.Skip("set VSPHERE_IPV4_GATEWAY to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_DNS") == "" {
+		t.Skip("set VSPHERE_DNS to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_DATASTORE") == "" {
+		t.Skip("set VSPHERE_DATASTORE to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_DATASTORE2") == "" {
+		t.Skip("set VSPHERE_DATASTORE2 to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_TEMPLATE") == "" {
+		t.Skip("set VSPHERE_TEMPLATE to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_TEMPLATE_ISO_TRANSPORT") == "" {
+		t.Skip("set VSPHERE_TEMPLATE_ISO_TRANSPORT to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_TEMPLATE_WINDOWS") == "" {
+		t.Skip("set VSPHERE_TEMPLATE_WINDOWS to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_TEMPLATE_NONUSER_VAPP") == "" {
+		t.Skip("set VSPHERE_TEMPLATE_NONUSER_VAPP to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_TEMPLATE_COREOS") == "" {
+		t.Skip("set VSPHERE_TEMPLATE_COREOS to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_ESXI_HOST2") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST2 to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_ESXI_HOST3") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST3 to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_HOST_NIC0") == "" {
+		t.Skip("set VSPHERE_HOST_NIC0 to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_DS_VMFS_DISK0") == "" {
+		t.Skip("set VSPHERE_DS_VMFS_DISK0 to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_DS_VMFS_DISK1") == "" {
+		t.Skip("set VSPHERE_DS_VMFS_DISK1 to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_DS_VMFS_DISK2") == "" {
+		t.Skip("set VSPHERE_DS_VMFS_DISK2 to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_NAS_HOST") == "" {
+		t.Skip("set VSPHERE_NAS_HOST to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_NFS_PATH") == "" {
+		t.Skip("set VSPHERE_NFS_PATH to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_NFS_PATH2") == "" {
+		t.Skip("set VSPHERE_NFS_PATH2 to run vsphere_virtual_machine acceptance tests")
+	}
+}
+
+func testAccResourceVSphereVirtualMachineCheckExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		_, err := testGetVirtualMachine(s, "vm")
+		if err != nil {
+			missingState, _ := regexp.MatchString("not found in state", err.Error())
+			missingVSphere, _ := regexp.MatchString("virtual machine with UUID \"[-a-f0-9]+\" not found", err.Error())
+			if missingState && !expected || missingVSphere && !expected {
+				// Expected missing
+				return nil
+			}
+			return err
+		}
+		if !expected {
+			return errors.New("expected VM to be missing")
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereVirtualMachineCheckVAppConfigKey(key, value string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		actual := props.Config.VAppConfig.GetVmConfigInfo().Property
+		for _, prop := range actual {
 			if prop.Id == key && prop.Value != value {
 				return fmt.Errorf("expected vAppConfig property %s to have value %s, got %s", key, value, prop.Value)
 			}
 		}
-		return nil
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckPowerState is a check to check for
+// a VirtualMachine's power state.
+func testAccResourceVSphereVirtualMachineCheckPowerState(expected types.VirtualMachinePowerState) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		actual := props.Runtime.PowerState
+		if expected != actual {
+			return fmt.Errorf("expected power state to be %s, got %s", expected, actual)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckHostname is a check to check for a
+// VirtualMachine's hostname. The check uses guest info, so VMware tools needs
+// to be installed.
+func testAccResourceVSphereVirtualMachineCheckHostname(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		actual := props.Guest.HostName
+		if expected != actual {
+			return fmt.Errorf("expected hostname to be %s, got %s", expected, actual)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckExtraDisks is a check for proper
+// parameters on the vsphere_virtual_machine extra disks test. This is a very
+// specific check that checks for the specific disk devices and respective
+// backings, and expects them in the exact order outlined in the function.
+func testAccResourceVSphereVirtualMachineCheckExtraDisks() resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+
+		var foundEager, foundLazy, foundThin bool
+
+		for _, dev := range props.Config.Hardware.Device {
+			if disk, ok := dev.(*types.VirtualDisk); ok {
+				if info, ok := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo); ok {
+					var eager bool
+					if info.EagerlyScrub != nil {
+						eager = *info.EagerlyScrub
+					}
+					switch {
+					case strings.HasSuffix(info.FileName, testAccResourceVSphereVirtualMachineDiskNameEager) && eager:
+						foundEager = true
+					case strings.HasSuffix(info.FileName, testAccResourceVSphereVirtualMachineDiskNameLazy) && !eager:
+						foundLazy = true
+					case strings.HasSuffix(info.FileName, testAccResourceVSphereVirtualMachineDiskNameThin) && *info.ThinProvisioned:
+						foundThin = true
+					}
+				}
+			}
+		}
+
+		if !foundEager {
+			return fmt.Errorf("could not locate disk: %s", testAccResourceVSphereVirtualMachineDiskNameEager)
+		}
+		if !foundLazy {
+			return fmt.Errorf("could not locate disk: %s", testAccResourceVSphereVirtualMachineDiskNameLazy)
+		}
+		if !foundThin {
+			return fmt.Errorf("could not locate disk: %s", testAccResourceVSphereVirtualMachineDiskNameThin)
+		}
+
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckDiskBus is a check that looks for a
+// disk with a specific name at a specific SCSI bus number and unit number.
+func testAccResourceVSphereVirtualMachineCheckDiskBus(name string, expectedBus, expectedUnit int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+
+		for _, dev := range props.Config.Hardware.Device {
+			if disk, ok := dev.(*types.VirtualDisk); ok {
+				if info, ok := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo); ok {
+					dp := new(object.DatastorePath)
+					if ok := dp.FromString(info.FileName); !ok {
+						return fmt.Errorf("could not parse datastore path %q", info.FileName)
+					}
+					if path.Base(dp.Path) != name {
+						continue
+					}
+					l := object.VirtualDeviceList(props.Config.Hardware.Device)
+					ctlr := l.FindByKey(disk.ControllerKey)
+					if ctlr == nil {
+						return fmt.Errorf("could not find controller with key %d for disk %q", disk.ControllerKey, name)
+					}
+					sc, ok := ctlr.(types.BaseVirtualSCSIController)
+					if !ok {
+						return fmt.Errorf("disk %q not attached to a SCSI controller (actual: %T)", name, ctlr)
+					}
+					if sc.GetVirtualSCSIController().BusNumber != int32(expectedBus) {
+						return fmt.Errorf("disk %q: Expected controller bus to be %d, got %d", name, expectedBus, sc.GetVirtualSCSIController().BusNumber)
+					}
+					if disk.UnitNumber == nil {
+						return fmt.Errorf("disk %q has no unit number", name)
+					}
+					if *disk.UnitNumber != int32(expectedUnit) {
+						return fmt.Errorf("disk %q: Expected unit number to be %d, got %d", name, expectedUnit, *disk.UnitNumber)
+					}
+					return nil
+				}
+			}
+		}
+
+		return fmt.Errorf("could not find disk path %q", name)
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckDiskController checks to make sure
+// that the disk with the supplied file name is attached to a controller of the
+// expected type, at the expected bus and unit number.
+func testAccResourceVSphereVirtualMachineCheckDiskController(name, expectedType string, expectedBus, expectedUnit int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+
+		l := object.VirtualDeviceList(props.Config.Hardware.Device)
+		for _, dev := range l.SelectByType((*types.VirtualDisk)(nil)) {
+			disk := dev.(*types.VirtualDisk)
+			info, ok := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo)
+			if !ok {
+				continue
+			}
+			dp := new(object.DatastorePath)
+			if ok := dp.FromString(info.FileName); !ok {
+				return fmt.Errorf("could not parse datastore path %q", info.FileName)
+			}
+			if path.Base(dp.Path) != name {
+				continue
+			}
+			ctlr, ok := l.FindByKey(disk.ControllerKey).(types.BaseVirtualController)
+			if !ok {
+				return fmt.Errorf("could not find controller with key %d for disk %q", disk.ControllerKey, name)
+			}
+			var actualType string
+			switch ctlr.(type) {
+			case types.BaseVirtualSCSIController:
+				actualType = virtualdevice.SubresourceControllerTypeSCSI
+			case *types.VirtualAHCIController:
+				actualType = virtualdevice.SubresourceControllerTypeSATA
+			case *types.VirtualNVMEController:
+				actualType = virtualdevice.SubresourceControllerTypeNVMe
+			case *types.VirtualIDEController:
+				actualType = virtualdevice.SubresourceControllerTypeIDE
+			}
+			if actualType != expectedType {
+				return fmt.Errorf("disk %q: Expected controller type to be %s, got %T", name, expectedType, ctlr)
+			}
+			if ctlr.GetVirtualController().BusNumber != int32(expectedBus) {
+				return fmt.Errorf("disk %q: Expected controller bus to be %d, got %d", name, expectedBus, ctlr.GetVirtualController().BusNumber)
+			}
+			if disk.UnitNumber == nil {
+				return fmt.Errorf("disk %q has no unit number", name)
+			}
+			if *disk.UnitNumber != int32(expectedUnit) {
+				return fmt.Errorf("disk %q: Expected unit number to be %d, got %d", name, expectedUnit, *disk.UnitNumber)
+			}
+			return nil
+		}
+
+		return fmt.Errorf("could not find disk path %q", name)
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckDisksEncrypted checks to make sure
+// that all disks on the virtual machine are either encrypted or not.
+func testAccResourceVSphereVirtualMachineCheckDisksEncrypted(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		for _, dev := range object.VirtualDeviceList(props.Config.Hardware.Device).SelectByType((*types.VirtualDisk)(nil)) {
+			backing, ok := dev.(*types.VirtualDisk).Backing.(*types.VirtualDiskFlatVer2BackingInfo)
+			if !ok {
+				continue
+			}
+			if actual := backing.KeyId != nil; actual != expected {
+				return fmt.Errorf("disk %q: expected encrypted to be %t, got %t", backing.FileName, expected, actual)
+			}
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckFolder checks to make sure a
+// virtual machine's folder matches the folder supplied with expected.
+func testAccResourceVSphereVirtualMachineCheckFolder(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		vm, err := testGetVirtualMachine(s, "vm")
+		if err != nil {
+			return fmt.Errorf("bad: %s", err)
+		}
+		expected, err := folder.RootPathParticleVM.PathFromNewRoot(vm.InventoryPath, folder.RootPathParticleVM, expected)
+		actual := path.Dir(vm.InventoryPath)
+		if err != nil {
+			return fmt.Errorf("bad: %s", err)
+		}
+		if expected != actual {
+			return fmt.Errorf("expected path to be %s, got %s", expected, actual)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckExistingVmdk is a check to make
+// sure that the appropriate disk is attached in the existing VMDK test.
+func testAccResourceVSphereVirtualMachineCheckExistingVmdk() resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+
+		expected := testAccResourceVSphereVirtualMachineDiskNameExtraVmdk
+
+		for _, dev := range props.Config.Hardware.Device {
+			if disk, ok := dev.(*types.VirtualDisk); ok {
+				if info, ok := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo); ok {
+					if strings.HasSuffix(info.FileName, expected) {
+						return nil
+					}
+				}
+			}
+		}
+
+		return fmt.Errorf("could not find attached disk matching %q", expected)
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckCPUMem checks the CPU and RAM for a
+// VM.
+func testAccResourceVSphereVirtualMachineCheckCPUMem(expectedCPU, expectedMem int32) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		actualCPU := props.Summary.Config.NumCpu
+		actualMem := props.Summary.Config.MemorySizeMB
+		if expectedCPU != actualCPU {
+			return fmt.Errorf("expected CPU count to be %d, got %d", expectedCPU, actualCPU)
+		}
+		if expectedMem != actualMem {
+			return fmt.Errorf("expected memory size to be %d, got %d", expectedMem, actualMem)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckNet checks to make sure a virtual
+// machine's primary NIC has the given IP address and netmask assigned to it,
+// and that the appropriate gateway is present.
+//
+// This uses VMware tools to check this, so it needs to be installed on the
+// guest.
+func testAccResourceVSphereVirtualMachineCheckNet(expectedAddr, expectedPrefix, expectedGW string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		res, err := strconv.Atoi(expectedPrefix)
+		if err != nil {
+			return fmt.Errorf("bad: %s", err)
+		}
+		expectedPrefixInt := int32(res)
+
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		var v4gw, v6gw net.IP
+		for _, s := range props.Guest.IpStack {
+			if s.IpRouteConfig != nil {
+				for _, r := range s.IpRouteConfig.IpRoute {
+					switch r.Network {
+					case "0.0.0.0":
+						v4gw = net.ParseIP(r.Gateway.IpAddress)
+					case "::":
+						v6gw = net.ParseIP(r.Gateway.IpAddress)
+					}
+				}
+			}
+		}
+		for _, n := range props.Guest.Net {
+			if n.IpConfig != nil {
+				for _, addr := range n.IpConfig.IpAddress {
+					ip := net.ParseIP(addr.IpAddress)
+					if !ip.Equal(net.ParseIP(expectedAddr)) {
+						continue
+					}
+					if addr.PrefixLength != expectedPrefixInt {
+						continue
+					}
+					var mask net.IPMask
+					if ip.To4() != nil {
+						mask = net.CIDRMask(int(addr.PrefixLength), 32)
+					} else {
+						mask = net.CIDRMask(int(addr.PrefixLength), 128)
+					}
+					switch {
+					case ip.Mask(mask).Equal(v4gw.Mask(mask)):
+						if net.ParseIP(expectedGW).Equal(v4gw) {
+							return nil
+						}
+					case ip.Mask(mask).Equal(v6gw.Mask(mask)):
+						if net.ParseIP(expectedGW).Equal(v6gw) {
+							return nil
+						}
+					}
+				}
+			}
+		}
+
+		return fmt.Errorf("could not find IP %s/%s, gateway %s", expectedAddr, expectedPrefix, expectedGW)
+	}
+}
+
+func testAccResourceVSpherevirtualMachineCheckHostname(hostname string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		if props.Guest.HostName != hostname {
+			return fmt.Errorf("expected host name: %s, got %s", hostname, props.Guest.HostName)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckStaticMACAddr is a check to look
+// for the MAC address defined in the static MAC address test on the first
+// network interface.
+func testAccResourceVSphereVirtualMachineCheckStaticMACAddr() resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		l := object.VirtualDeviceList(props.Config.Hardware.Device)
+		devices := l.Select(func(device types.BaseVirtualDevice) bool {
+			if _, ok := device.(types.BaseVirtualEthernetCard); ok {
+				return true
+			}
+			return false
+		})
+		if devices[0].(types.BaseVirtualEthernetCard).GetVirtualEthernetCard().AddressType != string(types.VirtualEthernetCardMacTypeManual) {
+			return fmt.Errorf("first network interface is not set to manual address type")
+		}
+		actual := devices[0].(types.BaseVirtualEthernetCard).GetVirtualEthernetCard().MacAddress
+		expected := testAccResourceVSphereVirtualMachineStaticMacAddr
+		if expected != actual {
+			return fmt.Errorf("expected MAC address to be %q, got %q", expected, actual)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckAnnotation is a check to ensure
+// that a VM's annotation is correctly set in the annotation test.
+func testAccResourceVSphereVirtualMachineCheckAnnotation() resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		expected := testAccResourceVSphereVirtualMachineAnnotation
+		actual := props.Config.Annotation
+		if expected != actual {
+			return fmt.Errorf("expected annotation to be %q, got %q", expected, actual)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckCustomizationSucceeded is a check
+// to ensure that events have been received for customization success on a VM.
+func testAccResourceVSphereVirtualMachineCheckCustomizationSucceeded() resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		vm, err := testGetVirtualMachine(s, "vm")
+		if err != nil {
+			return err
+		}
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		actual, err := selectEventsForReference(client, vm.Reference(), []string{eventTypeCustomizationSucceeded})
+		if err != nil {
+			return err
+		}
+		if len(actual) < 1 {
+			return errors.New("customization success event was not received")
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckTags is a check to ensure that any
+// tags that have been created with supplied resource name have been attached
+// to the virtual machine.
+func testAccResourceVSphereVirtualMachineCheckTags(tagResName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		vm, err := testGetVirtualMachine(s, "vm")
+		if err != nil {
+			return err
+		}
+		tagsClient, err := testAccProvider.Meta().(*VSphereClient).TagsManager()
+		if err != nil {
+			return err
+		}
+		return testObjectHasTags(s, tagsClient, vm, tagResName)
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckMultiDevice is a check for proper
+// parameters on the vsphere_virtual_machine multi-device test. This is a very
+// specific check that checks for the specific disk and network devices. The
+// configuration that this test asserts should be in the
+// testAccResourceVSphereVirtualMachineConfigMultiDevice resource.
+func testAccResourceVSphereVirtualMachineCheckMultiDevice(expectedD, expectedN []bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+
+		actualD := make([]bool, 3)
+		actualN := make([]bool, 3)
+		expectedDisk0Size := structure.GiBToByte(20)
+		expectedDisk1Size := structure.GiBToByte(10)
+		expectedDisk2Size := structure.GiBToByte(5)
+		expectedNet0Level := types.SharesLevelNormal
+		expectedNet1Level := types.SharesLevelHigh
+		expectedNet2Level := types.SharesLevelLow
+
+		for _, dev := range props.Config.Hardware.Device {
+			if disk, ok := dev.(*types.VirtualDisk); ok {
+				switch {
+				case disk.CapacityInBytes == expectedDisk0Size:
+					actualD[0] = true
+				case disk.CapacityInBytes == expectedDisk1Size:
+					actualD[1] = true
+				case disk.CapacityInBytes == expectedDisk2Size:
+					actualD[2] = true
+				}
+			}
+			if bvec, ok := dev.(types.BaseVirtualEthernetCard); ok {
+				card := bvec.GetVirtualEthernetCard()
+				switch {
+				case card.ResourceAllocation.Share.Level == expectedNet0Level:
+					actualN[0] = true
+				case card.ResourceAllocation.Share.Level == expectedNet1Level:
+					actualN[1] = true
+				case card.ResourceAllocation.Share.Level == expectedNet2Level:
+					actualN[2] = true
+				}
+			}
+		}
+
+		for n, actual := range actualD {
+			if actual != expectedD[n] {
+				return fmt.Errorf("expected disk at index %d to be %t, got %t", n, expectedD[n], actual)
+			}
+		}
+		for n, actual := range actualN {
+			if actual != expectedN[n] {
+				return fmt.Errorf("expected network interface at index %d to be %t, got %t", n, expectedN[n], actual)
+			}
+		}
+
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckIsoCdrom checks to make sure that the
+// subject VM has a CDROM device configured with iso backing and is connected.
+func testAccResourceVSphereVirtualMachineCheckIsoCdrom() resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+
+		for _, dev := range props.Config.Hardware.Device {
+			if cdrom, ok := dev.(*types.VirtualCdrom); ok {
+				if !cdrom.Connectable.Connected {
+					return fmt.Errorf("expected CDROM device to be connected")
+				}
+				if backing, ok := cdrom.Backing.(*types.VirtualCdromIsoBackingInfo); ok {
+					expected := &object.DatastorePath{
+						Datastore: os.Getenv("VSPHERE_ISO_DATASTORE"),
+						Path:      os.Getenv("VSPHERE_ISO_FILE"),
+					}
+					actual := new(object.DatastorePath)
+					actual.FromString(backing.FileName)
+					if !reflect.DeepEqual(expected, actual) {
+						return fmt.Errorf("expected %#v, got %#v", expected, actual)
+					}
+					return nil
+				}
+				return errors.New("could not locate proper backing file on CDROM device")
+			}
+		}
+		return errors.New("could not locate CDROM device on VM")
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckClientCdrom checks to make sure that the
+// subject VM has a CDROM device mapped to a client device.
+func testAccResourceVSphereVirtualMachineCheckClientCdrom() resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+
+		for _, dev := range props.Config.Hardware.Device {
+			if cdrom, ok := dev.(*types.VirtualCdrom); ok {
+				if backing, ok := cdrom.Backing.(*types.VirtualCdromRemoteAtapiBackingInfo); ok {
+					useAutoDetect := false
+					expected := &types.VirtualCdromRemoteAtapiBackingInfo{
+						VirtualDeviceRemoteDeviceBackingInfo: types.VirtualDeviceRemoteDeviceBackingInfo{
+							UseAutoDetect: &useAutoDetect,
+							DeviceName:    "",
+						},
+					}
+					if !reflect.DeepEqual(expected, backing) {
+						return fmt.Errorf("expected %#v, got %#v", expected, backing)
+					}
+					return nil
+				}
+				return errors.New("could not find CDROM with correct backing device")
+			}
+		}
+		return errors.New("could not locate CDROM device on VM")
+	}
+}
+
+// testAccResourceVSphereVirtualMachinePersistentDiskInfo goes through the
+// current state and creates a slice of maps containing information on disks
+// which have `keep_on_remove` set to true.  This list can later be used to
+// examine disks that have been removed from the virtual machine configuration.
+func testAccResourceVSphereVirtualMachinePersistentDiskInfo(disks *[]map[string]string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		vms := s.RootModule().Resources["vsphere_virtual_machine.vm"].Primary.Attributes
+		dn, err := strconv.Atoi(vms["disk.#"])
+		if err != nil {
+			return err
+		}
+		for i := 0; i < dn; i++ {
+			if vms[fmt.Sprintf("disk.%s.keep_on_remove", strconv.Itoa(i))] == "true" {
+				disk := map[string]string{
+					"path":         vms[fmt.Sprintf("disk.%s.path", strconv.Itoa(i))],
+					"datastore_id": vms[fmt.Sprintf("disk.%s.datastore_id", strconv.Itoa(i))],
+				}
+				*disks = append(*disks, disk)
+			}
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineDeletePersistentDisks goes through a
+// list of disks and deletes the files backing those disks. This process also
+// checks that the backing files exist in the deletion process. If the files
+// don't exist, an error will be raised during deletion. The folder containing
+// the disks will also be deleted. If the folder is not empty, all remaining
+// files will be deleted.
+func testAccResourceVSphereVirtualMachineDeletePersistentDisks(disks *[]map[string]string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		reFlat := regexp.MustCompile("\\.vmdk$")
+		reVM := regexp.MustCompile("\\/.*?\\.vmdk$")
+		var vmFolder string
+		var dsID string
+		for _, disk := range *disks {
+			ds, err := datastore.FromID(client, disk["datastore_id"])
+			if err != nil {
+				return err
+			}
+			path := fmt.Sprintf("[%s] %s", ds.Name(), disk["path"])
+			flat := reFlat.ReplaceAllString(path, "-flat.vmdk")
+			vmFolder = reVM.ReplaceAllString(path, "")
+			dsID = disk["datastore_id"]
+			err = testDeleteDatastoreFile(client, dsID, path)
+			if err != nil {
+				return err
+			}
+			// Ignore errors here as the _flat files only exist for thin provisioned
+			// disks.
+			_ = testDeleteDatastoreFile(client, dsID, flat)
+		}
+		// Delete the VM folder now that we've checked and cleaned up the disk.
+		err := testDeleteDatastoreFile(client, dsID, vmFolder)
+		if err != nil {
+			return err
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckPowerOffEvent is a check to see if
+// the VM has been powered off at any point in time.
+func testAccResourceVSphereVirtualMachineCheckPowerOffEvent(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		vm, err := testGetVirtualMachine(s, "vm")
+		if err != nil {
+			return err
+		}
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		actual, err := selectEventsForReference(client, vm.Reference(), []string{eventTypeVmPoweredOffEvent})
+		if err != nil {
+			return err
+		}
+		switch {
+		case len(actual) < 1 && expected:
+			return errors.New("expected power off, VM was not powered off")
+		case len(actual) > 1 && !expected:
+			return errors.New("VM was powered off when it should not have been")
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckDiskSize checks the first
+// VirtualDisk it encounters for a specific size in GiB. It should only be used
+// with test configurations with a single disk attached.
+func testAccResourceVSphereVirtualMachineCheckDiskSize(expected int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+
+		expectedBytes := structure.GiBToByte(expected)
+
+		for _, dev := range props.Config.Hardware.Device {
+			if disk, ok := dev.(*types.VirtualDisk); ok {
+				if expectedBytes != disk.CapacityInBytes {
+					return fmt.Errorf("expected disk size to be %d, got %d", expectedBytes, disk.CapacityInBytes)
+				}
+			}
+		}
+
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckSCSIBus checks to make sure the
+// test VM's SCSI bus is all of the specified SCSI type.
+func testAccResourceVSphereVirtualMachineCheckSCSIBus(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		actual, err := testGetVirtualMachineSCSIBusType(s, "vm")
+		if err != nil {
+			return fmt.Errorf("bad: %s", err)
+		}
+		if expected != actual {
+			return fmt.Errorf("expected SCSI bus to be %s, got %s", expected, actual)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckSCSIBusSharing checks to make sure the
+// test VM's SCSI bus is all of the specified sharing type.
+func testAccResourceVSphereVirtualMachineCheckSCSIBusSharing(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		actual, err := testGetVirtualMachineSCSIBusSharing(s, "vm")
+		if err != nil {
+			return fmt.Errorf("bad: %s", err)
+		}
+		if expected != actual {
+			return fmt.Errorf("expected SCSI bus sharing to be %s, got %s", expected, actual)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckHost checks to make sure the
+// test VM is currently located on a specific host.
+func testAccResourceVSphereVirtualMachineCheckHost(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		hs, err := testGetVirtualMachineHost(s, "vm")
+		if err != nil {
+			return err
+		}
+		actual := hs.Name()
+		if expected != actual {
+			return fmt.Errorf("expected host to be %s, got %s", expected, actual)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckResourcePool checks to make sure the
+// test VM is currently located in a specific resource pool.
+func testAccResourceVSphereVirtualMachineCheckResourcePool(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		pool, err := testGetVirtualMachineResourcePool(s, "vm")
+		if err != nil {
+			return err
+		}
+
+		actual := pool.Name()
+		if actual == "Resources" && path.Base(expected) == "Resources" {
+			client := testAccProvider.Meta().(*VSphereClient).vimClient
+			expectedCluster, err := computeresource.BaseFromPath(client, path.Dir(expected))
+			if err != nil {
+				return err
+			}
+			pprops, err := resourcepool.Properties(pool)
+			if err != nil {
+				return err
+			}
+			actualCluster, err := computeresource.BaseFromReference(client, *pprops.Parent)
+			if err != nil {
+				return err
+			}
+			if expectedCluster.Reference().Value != actualCluster.Reference().Value {
+				return fmt.Errorf("expected default resource pool of %q, got default resource pool of %q", expectedCluster.Reference().Value, actualCluster.Reference().Value)
+			}
+			return nil
+		}
+		if expected != actual {
+			return fmt.Errorf("expected resource pool or to be %q, got %q", expected, actual)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckExtraConfig checks a key/expected
+// value combination in a VM's config.
+func testAccResourceVSphereVirtualMachineCheckExtraConfig(key, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		for _, bov := range props.Config.ExtraConfig {
+			ov := bov.GetOptionValue()
+			if ov.Key == key {
+				if ov.Value.(string) == expected {
+					return nil
+				}
+				return fmt.Errorf("expected key %s to be %s, got %s", key, expected, ov.Value.(string))
+			}
+		}
+		return fmt.Errorf("key %s not found", key)
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckExtraConfigKeyMissing checks to
+// make sure that a key is missing in the VM's extraConfig.
+func testAccResourceVSphereVirtualMachineCheckExtraConfigKeyMissing(key string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		for _, bov := range props.Config.ExtraConfig {
+			ov := bov.GetOptionValue()
+			if ov.Key == key {
+				return fmt.Errorf("expected key %s to be missing", key)
+			}
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckVmxDatastore checks the datastore
+// that the virtual machine's configuration is currently located.
+func testAccResourceVSphereVirtualMachineCheckVmxDatastore(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		var dsPath object.DatastorePath
+		if ok := dsPath.FromString(props.Config.Files.VmPathName); !ok {
+			return fmt.Errorf("could not parse datastore path %q", props.Config.Files.VmPathName)
+		}
+		actual := dsPath.Datastore
+		if expected != actual {
+			return fmt.Errorf("expected VM configuration to be in datastore %s, got %s", expected, actual)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckVmdkDatastore checks the datastore
+// that a specific VMDK file is in.
+func testAccResourceVSphereVirtualMachineCheckVmdkDatastore(diskIndex int, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		tVars, err := testClientVariablesForResource(s, "vsphere_virtual_machine.vm")
+		if err != nil {
+			return err
+		}
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		name := tVars.resourceAttributes[fmt.Sprintf("disk.%d.path", diskIndex)]
+		for _, dev := range props.Config.Hardware.Device {
+			if disk, ok := dev.(*types.VirtualDisk); ok {
+				if info, ok := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo); ok {
+					var dsPath object.DatastorePath
+					if ok := dsPath.FromString(info.FileName); !ok {
+						return fmt.Errorf("could not parse datastore path %q", info.FileName)
+					}
+					if dsPath.Path == name {
+						actual := dsPath.Datastore
+						if expected == actual {
+							return nil
+						}
+						return fmt.Errorf("expected disk name %q to be on datastore %q, got %q", name, expected, actual)
+					}
+				}
+			}
+		}
+		return fmt.Errorf("could not find disk %q", name)
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckVmxDatastoreCluster checks the
+// datastore cluster that the virtual machine's configuration is currently
+// located.
+func testAccResourceVSphereVirtualMachineCheckVmxDatastoreCluster(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		tVars, err := testClientVariablesForResource(s, "vsphere_virtual_machine.vm")
+		if err != nil {
+			return err
+		}
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		var dsPath object.DatastorePath
+		if ok := dsPath.FromString(props.Config.Files.VmPathName); !ok {
+			return fmt.Errorf("could not parse datastore path %q", props.Config.Files.VmPathName)
+		}
+		dc, err := getDatacenter(tVars.client, os.Getenv("VSPHERE_DATACENTER"))
+		if err != nil {
+			return fmt.Errorf("could not get datacenter by ID %q: %s", tVars.resourceID, err)
+		}
+		ds, err := datastore.FromPath(tVars.client, dsPath.Datastore, dc)
+		if err != nil {
+			return fmt.Errorf("could not get datastore %q: %s", dsPath.Datastore, err)
+		}
+		pod, err := storagepod.FromPath(tVars.client, expected, dc)
+		if err != nil {
+			return fmt.Errorf("could not get storage pod %q: %s", expected, err)
+		}
+
+		isMember, err := storagepod.IsMember(pod, ds)
+		if err != nil {
+			return fmt.Errorf("could not check storage pod membership: %s", err)
+		}
+
+		if !isMember {
+			return fmt.Errorf("datastore %q is not a member of datastore cluster %q", dsPath.Datastore, expected)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckVmdkDatastoreCluster checks the
+// datastore cluster that a specific VMDK file is in.
+func testAccResourceVSphereVirtualMachineCheckVmdkDatastoreCluster(diskIndex int, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		tVars, err := testClientVariablesForResource(s, "vsphere_virtual_machine.vm")
+		if err != nil {
+			return err
+		}
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		name := tVars.resourceAttributes[fmt.Sprintf("disk.%d.path", diskIndex)]
+		for _, dev := range props.Config.Hardware.Device {
+			if disk, ok := dev.(*types.VirtualDisk); ok {
+				if info, ok := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo); ok {
+					var dsPath object.DatastorePath
+					if ok := dsPath.FromString(info.FileName); !ok {
+						return fmt.Errorf("could not parse datastore path %q", info.FileName)
+					}
+					if dsPath.Path == name {
+						dc, err := getDatacenter(tVars.client, os.Getenv("VSPHERE_DATACENTER"))
+						if err != nil {
+							return fmt.Errorf("could not get datacenter by ID %q: %s", tVars.resourceID, err)
+						}
+						ds, err := datastore.FromPath(tVars.client, dsPath.Datastore, dc)
+						if err != nil {
+							return fmt.Errorf("could not get datastore %q: %s", dsPath.Datastore, err)
+						}
+						pod, err := storagepod.FromPath(tVars.client, expected, dc)
+						if err != nil {
+							return fmt.Errorf("could not get storage pod %q: %s", expected, err)
+						}
+
+						isMember, err := storagepod.IsMember(pod, ds)
+						if err != nil {
+							return fmt.Errorf("could not check storage pod membership: %s", err)
+						}
+
+						if isMember {
+							return nil
+						}
+						return fmt.Errorf("datastore %q is not a member of datastore cluster %q", dsPath.Datastore, expected)
+					}
+				}
+			}
+		}
+		return fmt.Errorf("could not find disk %q", name)
 	}
 }
 
-// testAccResourceVSphereVirtualMachineCheckPowerState is a check to check for
-// a VirtualMachine's power state.
-func testAccResourceVSphereVirtualMachineCheckPowerState(expected types.VirtualMachinePowerState) resource.TestCheckFunc {
+// testAccResourceVSphereVirtualMachineCheckNICCount checks the number of NICs
+// on the virtual machine.
+func testAccResourceVSphereVirtualMachineCheckNICCount(expected int) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		props, err := testGetVirtualMachineProperties(s, "vm")
 		if err != nil {
 			return err
 		}
-		actual := props.Runtime.PowerState
+
+		var actual int
+		for _, dev := range props.Config.Hardware.Device {
+			if _, ok := dev.(types.BaseVirtualEthernetCard); ok {
+				actual++
+			}
+		}
 		if expected != actual {
-			return fmt.Errorf("expected power state to be %s, got %s", expected, actual)
+			return fmt.Errorf("expected %d number of NICs, got %d", expected, actual)
 		}
 		return nil
 	}
 }
 
-// testAccResourceVSphereVirtualMachineCheckHostname is a check to check for a
-// VirtualMachine's hostname. The check uses guest info, so VMware tools needs
-// to be installed.
-func testAccResourceVSphereVirtualMachineCheckHostname(expected string) resource.TestCheckFunc {
+// testCheckVMDiskFileExists takes a VMDK filename and checks to see if it
+// exists within the same directory as the virtual machine's VMX file.
+func testCheckVMDiskFileExists(name string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
+		tVars, err := testClientVariablesForResource(s, "vsphere_virtual_machine.vm")
+		if err != nil {
+			return err
+		}
+		vm, err := testGetVirtualMachine(s, "vm")
+		if err != nil {
+			return err
+		}
 		props, err := testGetVirtualMachineProperties(s, "vm")
 		if err != nil {
 			return err
 		}
-		actual := props.Guest.HostName
+		vmxPath, success := virtualdisk.DatastorePathFromString(props.Config.Files.VmPathName)
+		if !success {
+			return fmt.Errorf("could not parse VMX path %q", props.Config.Files.VmPathName)
+		}
+		dcp, err := folder.RootPathParticleVM.SplitDatacenter(vm.InventoryPath)
+		if err != nil {
+			return err
+		}
+		dc, err := getDatacenter(tVars.client, dcp)
+		if err != nil {
+			return err
+		}
+		ds, err := datastore.FromPath(tVars.client, vmxPath.Datastore, dc)
+		if err != nil {
+			return err
+		}
+		p := path.Join(path.Dir(vmxPath.Path), name)
+		exists, err := datastore.FileExists(ds, p)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("file %q does not exist in datastore %q", p, ds.Name())
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereVirtualMachineCheckCustomAttributes() resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		return testResourceHasCustomAttributeValues(s, "vsphere_virtual_machine", "vm", props.Entity())
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckLatencySensitivity checks a virtual
+// machine's latency sensitivity value.
+func testAccResourceVSphereVirtualMachineCheckLatencySensitivity(
+	expected types.LatencySensitivitySensitivityLevel,
+) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		actual := props.Config.LatencySensitivity.Level
 		if expected != actual {
-			return fmt.Errorf("expected hostname to be %s, got %s", expected, actual)
+			return fmt.Errorf("expected latency sensitivity to be %s, got %s", expected, actual)
 		}
 		return nil
 	}
 }
 
-// testAccResourceVSphereVirtualMachineCheckExtraDisks is a check for proper
-// parameters on the vsphere_virtual_machine extra disks test. This is a very
-// specific check that checks for the specific disk devices and respective
-// backings, and expects them in the exact order outlined in the function.
-func testAccResourceVSphereVirtualMachineCheckExtraDisks() resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
+func testAccResourceVSphereVirtualMachineConfigComputedValue() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_resource_pool" "pool" {
+  name = "test_pool"
+	parent_resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name             = "terraform-test"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  datastore_id     = "${vsphere_resource_pool.pool.id}"
+
+  num_cpus = 2
+  memory   = 2048
+  guest_id = "other3xLinux64Guest"
+
+  network_interface {
+    network_id = "${data.vsphere_network.network.id}"
+  }
+
+  disk {
+    label = "disk0"
+    size  = 20
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
+		os.Getenv("VSPHERE_DATASTORE"),
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigBasic() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name             = "terraform-test"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  datastore_id     = "${data.vsphere_datastore.datastore.id}"
+
+  num_cpus = 2
+  memory   = 2048
+  guest_id = "other3xLinux64Guest"
+
+  network_interface {
+    network_id = "${data.vsphere_network.network.id}"
+  }
+
+  disk {
+    label = "disk0"
+    size  = 20
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
+		os.Getenv("VSPHERE_DATASTORE"),
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigOvfDeploy() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
 
-		var foundEager, foundLazy, foundThin bool
+variable "resource_pool" {
+  default = "%s"
+}
 
-		for _, dev := range props.Config.Hardware.Device {
-			if disk, ok := dev.(*types.VirtualDisk); ok {
-				if info, ok := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo); ok {
-					var eager bool
-					if info.EagerlyScrub != nil {
-						eager = *info.EagerlyScrub
-					}
-					switch {
-					case strings.HasSuffix(info.FileName, testAccResourceVSphereVirtualMachineDiskNameEager) && eager:
-						foundEager = true
-					case strings.HasSuffix(info.FileName, testAccResourceVSphereVirtualMachineDiskNameLazy) && !eager:
-						foundLazy = true
-					case strings.HasSuffix(info.FileName, testAccResourceVSphereVirtualMachineDiskNameThin) && *info.ThinProvisioned:
-						foundThin = true
-					}
-				}
-			}
-		}
+variable "network_label" {
+  default = "%s"
+}
 
-		if !foundEager {
-			return fmt.Errorf("could not locate disk: %s", testAccResourceVSphereVirtualMachineDiskNameEager)
-		}
-		if !foundLazy {
-			return fmt.Errorf("could not locate disk: %s", testAccResourceVSphereVirtualMachineDiskNameLazy)
-		}
-		if !foundThin {
-			return fmt.Errorf("could not locate disk: %s", testAccResourceVSphereVirtualMachineDiskNameThin)
-		}
+variable "datastore" {
+  default = "%s"
+}
 
-		return nil
-	}
+variable "ovf_url" {
+  default = "%s"
 }
 
-// testAccResourceVSphereVirtualMachineCheckDiskBus is a check that looks for a
-// disk with a specific name at a specific SCSI bus number and unit number.
-func testAccResourceVSphereVirtualMachineCheckDiskBus(name string, expectedBus, expectedUnit int) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
 
-		for _, dev := range props.Config.Hardware.Device {
-			if disk, ok := dev.(*types.VirtualDisk); ok {
-				if info, ok := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo); ok {
-					dp := new(object.DatastorePath)
-					if ok := dp.FromString(info.FileName); !ok {
-						return fmt.Errorf("could not parse datastore path %q", info.FileName)
-					}
-					if path.Base(dp.Path) != name {
-						continue
-					}
-					l := object.VirtualDeviceList(props.Config.Hardware.Device)
-					ctlr := l.FindByKey(disk.ControllerKey)
-					if ctlr == nil {
-						return fmt.Errorf("could not find controller with key %d for disk %q", disk.ControllerKey, name)
-					}
-					sc, ok := ctlr.(types.BaseVirtualSCSIController)
-					if !ok {
-						return fmt.Errorf("disk %q not attached to a SCSI controller (actual: %T)", name, ctlr)
-					}
-					if sc.GetVirtualSCSIController().BusNumber != int32(expectedBus) {
-						return fmt.Errorf("disk %q: Expected controller bus to be %d, got %d", name, expectedBus, sc.GetVirtualSCSIController().BusNumber)
-					}
-					if disk.UnitNumber == nil {
-						return fmt.Errorf("disk %q has no unit number", name)
-					}
-					if *disk.UnitNumber != int32(expectedUnit) {
-						return fmt.Errorf("disk %q: Expected unit number to be %d, got %d", name, expectedUnit, *disk.UnitNumber)
-					}
-					return nil
-				}
-			}
-		}
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
 
-		return fmt.Errorf("could not find disk path %q", name)
-	}
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
-// testAccResourceVSphereVirtualMachineCheckFolder checks to make sure a
-// virtual machine's folder matches the folder supplied with expected.
-func testAccResourceVSphereVirtualMachineCheckFolder(expected string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		vm, err := testGetVirtualMachine(s, "vm")
-		if err != nil {
-			return fmt.Errorf("bad: %s", err)
-		}
-		expected, err := folder.RootPathParticleVM.PathFromNewRoot(vm.InventoryPath, folder.RootPathParticleVM, expected)
-		actual := path.Dir(vm.InventoryPath)
-		if err != nil {
-			return fmt.Errorf("bad: %s", err)
-		}
-		if expected != actual {
-			return fmt.Errorf("expected path to be %s, got %s", expected, actual)
-		}
-		return nil
-	}
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
-// testAccResourceVSphereVirtualMachineCheckExistingVmdk is a check to make
-// sure that the appropriate disk is attached in the existing VMDK test.
-func testAccResourceVSphereVirtualMachineCheckExistingVmdk() resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
+resource "vsphere_virtual_machine" "vm" {
+  name             = "terraform-test-ovf"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  datastore_id     = "${data.vsphere_datastore.datastore.id}"
 
-		expected := testAccResourceVSphereVirtualMachineDiskNameExtraVmdk
+  num_cpus = 2
+  memory   = 1024
+  guest_id = "other3xLinux64Guest"
 
-		for _, dev := range props.Config.Hardware.Device {
-			if disk, ok := dev.(*types.VirtualDisk); ok {
-				if info, ok := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo); ok {
-					if strings.HasSuffix(info.FileName, expected) {
-						return nil
-					}
-				}
-			}
-		}
+  wait_for_guest_net_timeout = 0
 
-		return fmt.Errorf("could not find attached disk matching %q", expected)
-	}
-}
+  network_interface {
+    network_id = "${data.vsphere_network.network.id}"
+  }
 
-// testAccResourceVSphereVirtualMachineCheckCPUMem checks the CPU and RAM for a
-// VM.
-func testAccResourceVSphereVirtualMachineCheckCPUMem(expectedCPU, expectedMem int32) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-		actualCPU := props.Summary.Config.NumCpu
-		actualMem := props.Summary.Config.MemorySizeMB
-		if expectedCPU != actualCPU {
-			return fmt.Errorf("expected CPU count to be %d, got %d", expectedCPU, actualCPU)
-		}
-		if expectedMem != actualMem {
-			return fmt.Errorf("expected memory size to be %d, got %d", expectedMem, actualMem)
-		}
-		return nil
-	}
+  disk {
+    label = "disk0"
+    size  = 20
+  }
+
+  ovf_deploy {
+    remote_ovf_url    = "${var.ovf_url}"
+    disk_provisioning = "thin"
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_OVF_URL"),
+	)
 }
 
-// testAccResourceVSphereVirtualMachineCheckNet checks to make sure a virtual
-// machine's primary NIC has the given IP address and netmask assigned to it,
-// and that the appropriate gateway is present.
-//
-// This uses VMware tools to check this, so it needs to be installed on the
-// guest.
-func testAccResourceVSphereVirtualMachineCheckNet(expectedAddr, expectedPrefix, expectedGW string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		res, err := strconv.Atoi(expectedPrefix)
-		if err != nil {
-			return fmt.Errorf("bad: %s", err)
-		}
-		expectedPrefixInt := int32(res)
+func testAccResourceVSphereVirtualMachineConfigCloneFromContentLibrary() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
 
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-		var v4gw, v6gw net.IP
-		for _, s := range props.Guest.IpStack {
-			if s.IpRouteConfig != nil {
-				for _, r := range s.IpRouteConfig.IpRoute {
-					switch r.Network {
-					case "0.0.0.0":
-						v4gw = net.ParseIP(r.Gateway.IpAddress)
-					case "::":
-						v6gw = net.ParseIP(r.Gateway.IpAddress)
-					}
-				}
-			}
-		}
-		for _, n := range props.Guest.Net {
-			if n.IpConfig != nil {
-				for _, addr := range n.IpConfig.IpAddress {
-					ip := net.ParseIP(addr.IpAddress)
-					if !ip.Equal(net.ParseIP(expectedAddr)) {
-						continue
-					}
-					if addr.PrefixLength != expectedPrefixInt {
-						continue
-					}
-					var mask net.IPMask
-					if ip.To4() != nil {
-						mask = net.CIDRMask(int(addr.PrefixLength), 32)
-					} else {
-						mask = net.CIDRMask(int(addr.PrefixLength), 128)
-					}
-					switch {
-					case ip.Mask(mask).Equal(v4gw.Mask(mask)):
-						if net.ParseIP(expectedGW).Equal(v4gw) {
-							return nil
-						}
-					case ip.Mask(mask).Equal(v6gw.Mask(mask)):
-						if net.ParseIP(expectedGW).Equal(v6gw) {
-							return nil
-						}
-					}
-				}
-			}
-		}
+variable "resource_pool" {
+  default = "%s"
+}
 
-		return fmt.Errorf("could not find IP %s/%s, gateway %s", expectedAddr, expectedPrefix, expectedGW)
-	}
+variable "network_label" {
+  default = "%s"
 }
 
-func testAccResourceVSpherevirtualMachineCheckHostname(hostname string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-		if props.Guest.HostName != hostname {
-			return fmt.Errorf("expected host name: %s, got %s", hostname, props.Guest.HostName)
-		}
-		return nil
-	}
+variable "datastore" {
+  default = "%s"
 }
 
-// testAccResourceVSphereVirtualMachineCheckStaticMACAddr is a check to look
-// for the MAC address defined in the static MAC address test on the first
-// network interface.
-func testAccResourceVSphereVirtualMachineCheckStaticMACAddr() resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-		l := object.VirtualDeviceList(props.Config.Hardware.Device)
-		devices := l.Select(func(device types.BaseVirtualDevice) bool {
-			if _, ok := device.(types.BaseVirtualEthernetCard); ok {
-				return true
-			}
-			return false
-		})
-		if devices[0].(types.BaseVirtualEthernetCard).GetVirtualEthernetCard().AddressType != string(types.VirtualEthernetCardMacTypeManual) {
-			return fmt.Errorf("first network interface is not set to manual address type")
-		}
-		actual := devices[0].(types.BaseVirtualEthernetCard).GetVirtualEthernetCard().MacAddress
-		expected := testAccResourceVSphereVirtualMachineStaticMacAddr
-		if expected != actual {
-			return fmt.Errorf("expected MAC address to be %q, got %q", expected, actual)
-		}
-		return nil
-	}
+variable "ovf_url" {
+  default = "%s"
 }
 
-// testAccResourceVSphereVirtualMachineCheckAnnotation is a check to ensure
-// that a VM's annotation is correctly set in the annotation test.
-func testAccResourceVSphereVirtualMachineCheckAnnotation() resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-		expected := testAccResourceVSphereVirtualMachineAnnotation
-		actual := props.Config.Annotation
-		if expected != actual {
-			return fmt.Errorf("expected annotation to be %q, got %q", expected, actual)
-		}
-		return nil
-	}
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
 }
 
-// testAccResourceVSphereVirtualMachineCheckCustomizationSucceeded is a check
-// to ensure that events have been received for customization success on a VM.
-func testAccResourceVSphereVirtualMachineCheckCustomizationSucceeded() resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		vm, err := testGetVirtualMachine(s, "vm")
-		if err != nil {
-			return err
-		}
-		client := testAccProvider.Meta().(*VSphereClient).vimClient
-		actual, err := selectEventsForReference(client, vm.Reference(), []string{eventTypeCustomizationSucceeded})
-		if err != nil {
-			return err
-		}
-		if len(actual) < 1 {
-			return errors.New("customization success event was not received")
-		}
-		return nil
-	}
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
-// testAccResourceVSphereVirtualMachineCheckTags is a check to ensure that any
-// tags that have been created with supplied resource name have been attached
-// to the virtual machine.
-func testAccResourceVSphereVirtualMachineCheckTags(tagResName string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		vm, err := testGetVirtualMachine(s, "vm")
-		if err != nil {
-			return err
-		}
-		tagsClient, err := testAccProvider.Meta().(*VSphereClient).TagsManager()
-		if err != nil {
-			return err
-		}
-		return testObjectHasTags(s, tagsClient, vm, tagResName)
-	}
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
-// testAccResourceVSphereVirtualMachineCheckMultiDevice is a check for proper
-// parameters on the vsphere_virtual_machine multi-device test. This is a very
-// specific check that checks for the specific disk and network devices. The
-// configuration that this test asserts should be in the
-// testAccResourceVSphereVirtualMachineConfigMultiDevice resource.
-func testAccResourceVSphereVirtualMachineCheckMultiDevice(expectedD, expectedN []bool) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
 
-		actualD := make([]bool, 3)
-		actualN := make([]bool, 3)
-		expectedDisk0Size := structure.GiBToByte(20)
-		expectedDisk1Size := structure.GiBToByte(10)
-		expectedDisk2Size := structure.GiBToByte(5)
-		expectedNet0Level := types.SharesLevelNormal
-		expectedNet1Level := types.SharesLevelHigh
-		expectedNet2Level := types.SharesLevelLow
+resource "vsphere_content_library" "library" {
+  name            = "terraform-test-library"
+  storage_backing = ["${data.vsphere_datastore.datastore.id}"]
+}
 
-		for _, dev := range props.Config.Hardware.Device {
-			if disk, ok := dev.(*types.VirtualDisk); ok {
-				switch {
-				case disk.CapacityInBytes == expectedDisk0Size:
-					actualD[0] = true
-				case disk.CapacityInBytes == expectedDisk1Size:
-					actualD[1] = true
-				case disk.CapacityInBytes == expectedDisk2Size:
-					actualD[2] = true
-				}
-			}
-			if bvec, ok := dev.(types.BaseVirtualEthernetCard); ok {
-				card := bvec.GetVirtualEthernetCard()
-				switch {
-				case card.ResourceAllocation.Share.Level == expectedNet0Level:
-					actualN[0] = true
-				case card.ResourceAllocation.Share.Level == expectedNet1Level:
-					actualN[1] = true
-				case card.ResourceAllocation.Share.Level == expectedNet2Level:
-					actualN[2] = true
-				}
-			}
-		}
+resource "vsphere_content_library_item" "item" {
+  name       = "terraform-test-item"
+  library_id = "${vsphere_content_library.library.id}"
+  file_url   = "${var.ovf_url}"
+}
 
-		for n, actual := range actualD {
-			if actual != expectedD[n] {
-				return fmt.Errorf("expected disk at index %d to be %t, got %t", n, expectedD[n], actual)
-			}
-		}
-		for n, actual := range actualN {
-			if actual != expectedN[n] {
-				return fmt.Errorf("expected network interface at index %d to be %t, got %t", n, expectedN[n], actual)
-			}
-		}
+resource "vsphere_virtual_machine" "vm" {
+  name             = "terraform-test-library-clone"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  datastore_id     = "${data.vsphere_datastore.datastore.id}"
 
-		return nil
-	}
+  num_cpus = 2
+  memory   = 1024
+  guest_id = "other3xLinux64Guest"
+
+  wait_for_guest_net_timeout = 0
+
+  network_interface {
+    network_id = "${data.vsphere_network.network.id}"
+  }
+
+  disk {
+    label = "disk0"
+    size  = 20
+  }
+
+  clone {
+    template_uuid = "${vsphere_content_library_item.item.id}"
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_OVF_URL"),
+	)
 }
 
-// testAccResourceVSphereVirtualMachineCheckIsoCdrom checks to make sure that the
-// subject VM has a CDROM device configured with iso backing and is connected.
-func testAccResourceVSphereVirtualMachineCheckIsoCdrom() resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
+func testAccResourceVSphereVirtualMachineConfigStoragePolicy() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
 
-		for _, dev := range props.Config.Hardware.Device {
-			if cdrom, ok := dev.(*types.VirtualCdrom); ok {
-				if !cdrom.Connectable.Connected {
-					return fmt.Errorf("expected CDROM device to be connected")
-				}
-				if backing, ok := cdrom.Backing.(*types.VirtualCdromIsoBackingInfo); ok {
-					expected := &object.DatastorePath{
-						Datastore: os.Getenv("VSPHERE_ISO_DATASTORE"),
-						Path:      os.Getenv("VSPHERE_ISO_FILE"),
-					}
-					actual := new(object.DatastorePath)
-					actual.FromString(backing.FileName)
-					if !reflect.DeepEqual(expected, actual) {
-						return fmt.Errorf("expected %#v, got %#v", expected, actual)
-					}
-					return nil
-				}
-				return errors.New("could not locate proper backing file on CDROM device")
-			}
-		}
-		return errors.New("could not locate CDROM device on VM")
-	}
+variable "resource_pool" {
+  default = "%s"
 }
 
-// testAccResourceVSphereVirtualMachineCheckClientCdrom checks to make sure that the
-// subject VM has a CDROM device mapped to a client device.
-func testAccResourceVSphereVirtualMachineCheckClientCdrom() resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
+variable "network_label" {
+  default = "%s"
+}
 
-		for _, dev := range props.Config.Hardware.Device {
-			if cdrom, ok := dev.(*types.VirtualCdrom); ok {
-				if backing, ok := cdrom.Backing.(*types.VirtualCdromRemoteAtapiBackingInfo); ok {
-					useAutoDetect := false
-					expected := &types.VirtualCdromRemoteAtapiBackingInfo{
-						VirtualDeviceRemoteDeviceBackingInfo: types.VirtualDeviceRemoteDeviceBackingInfo{
-							UseAutoDetect: &useAutoDetect,
-							DeviceName:    "",
-						},
-					}
-					if !reflect.DeepEqual(expected, backing) {
-						return fmt.Errorf("expected %#v, got %#v", expected, backing)
-					}
-					return nil
-				}
-				return errors.New("could not find CDROM with correct backing device")
-			}
-		}
-		return errors.New("could not locate CDROM device on VM")
-	}
+variable "datastore" {
+  default = "%s"
 }
 
-// testAccResourceVSphereVirtualMachinePersistentDiskInfo goes through the
-// current state and creates a slice of maps containing information on disks
-// which have `keep_on_remove` set to true.  This list can later be used to
-// examine disks that have been removed from the virtual machine configuration.
-func testAccResourceVSphereVirtualMachinePersistentDiskInfo(disks *[]map[string]string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		vms := s.RootModule().Resources["vsphere_virtual_machine.vm"].Primary.Attributes
-		dn, err := strconv.Atoi(vms["disk.#"])
-		if err != nil {
-			return err
-		}
-		for i := 0; i < dn; i++ {
-			if vms[fmt.Sprintf("disk.%s.keep_on_remove", strconv.Itoa(i))] == "true" {
-				disk := map[string]string{
-					"path":         vms[fmt.Sprintf("disk.%s.path", strconv.Itoa(i))],
-					"datastore_id": vms[fmt.Sprintf("disk.%s.datastore_id", strconv.Itoa(i))],
-				}
-				*disks = append(*disks, disk)
-			}
-		}
-		return nil
-	}
+variable "storage_policy_id" {
+  default = "%s"
 }
 
-// testAccResourceVSphereVirtualMachineDeletePersistentDisks goes through a
-// list of disks and deletes the files backing those disks. This process also
-// checks that the backing files exist in the deletion process. If the files
-// don't exist, an error will be raised during deletion. The folder containing
-// the disks will also be deleted. If the folder is not empty, all remaining
-// files will be deleted.
-func testAccResourceVSphereVirtualMachineDeletePersistentDisks(disks *[]map[string]string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		client := testAccProvider.Meta().(*VSphereClient).vimClient
-		reFlat := regexp.MustCompile("\\.vmdk$")
-		reVM := regexp.MustCompile("\\/.*?\\.vmdk$")
-		var vmFolder string
-		var dsID string
-		for _, disk := range *disks {
-			ds, err := datastore.FromID(client, disk["datastore_id"])
-			if err != nil {
-				return err
-			}
-			path := fmt.Sprintf("[%s] %s", ds.Name(), disk["path"])
-			flat := reFlat.ReplaceAllString(path, "-flat.vmdk")
-			vmFolder = reVM.ReplaceAllString(path, "")
-			dsID = disk["datastore_id"]
-			err = testDeleteDatastoreFile(client, dsID, path)
-			if err != nil {
-				return err
-			}
-			// Ignore errors here as the _flat files only exist for thin provisioned
-			// disks.
-			_ = testDeleteDatastoreFile(client, dsID, flat)
-		}
-		// Delete the VM folder now that we've checked and cleaned up the disk.
-		err := testDeleteDatastoreFile(client, dsID, vmFolder)
-		if err != nil {
-			return err
-		}
-		return nil
-	}
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
 }
 
-// testAccResourceVSphereVirtualMachineCheckPowerOffEvent is a check to see if
-// the VM has been powered off at any point in time.
-func testAccResourceVSphereVirtualMachineCheckPowerOffEvent(expected bool) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		vm, err := testGetVirtualMachine(s, "vm")
-		if err != nil {
-			return err
-		}
-		client := testAccProvider.Meta().(*VSphereClient).vimClient
-		actual, err := selectEventsForReference(client, vm.Reference(), []string{eventTypeVmPoweredOffEvent})
-		if err != nil {
-			return err
-		}
-		switch {
-		case len(actual) < 1 && expected:
-			return errors.New("expected power off, VM was not powered off")
-		case len(actual) > 1 && !expected:
-			return errors.New("VM was powered off when it should not have been")
-		}
-		return nil
-	}
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
-// testAccResourceVSphereVirtualMachineCheckDiskSize checks the first
-// VirtualDisk it encounters for a specific size in GiB. It should only be used
-// with test configurations with a single disk attached.
-func testAccResourceVSphereVirtualMachineCheckDiskSize(expected int) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
 
-		expectedBytes := structure.GiBToByte(expected)
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
 
-		for _, dev := range props.Config.Hardware.Device {
-			if disk, ok := dev.(*types.VirtualDisk); ok {
-				if expectedBytes != disk.CapacityInBytes {
-					return fmt.Errorf("expected disk size to be %d, got %d", expectedBytes, disk.CapacityInBytes)
-				}
-			}
-		}
+resource "vsphere_virtual_machine" "vm" {
+  name              = "terraform-test"
+  resource_pool_id  = "${data.vsphere_resource_pool.pool.id}"
+  datastore_id      = "${data.vsphere_datastore.datastore.id}"
+  storage_policy_id = "${var.storage_policy_id}"
 
-		return nil
-	}
-}
+  num_cpus = 2
+  memory   = 2048
+  guest_id = "other3xLinux64Guest"
 
-// testAccResourceVSphereVirtualMachineCheckSCSIBus checks to make sure the
-// test VM's SCSI bus is all of the specified SCSI type.
-func testAccResourceVSphereVirtualMachineCheckSCSIBus(expected string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		actual, err := testGetVirtualMachineSCSIBusType(s, "vm")
-		if err != nil {
-			return fmt.Errorf("bad: %s", err)
-		}
-		if expected != actual {
-			return fmt.Errorf("expected SCSI bus to be %s, got %s", expected, actual)
-		}
-		return nil
-	}
+  network_interface {
+    network_id = "${data.vsphere_network.network.id}"
+  }
+
+  disk {
+    label             = "disk0"
+    size              = 20
+    storage_policy_id = "${var.storage_policy_id}"
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_STORAGE_POLICY_ID"),
+	)
 }
 
-// testAccResourceVSphereVirtualMachineCheckSCSIBusSharing checks to make sure the
-// test VM's SCSI bus is all of the specified sharing type.
-func testAccResourceVSphereVirtualMachineCheckSCSIBusSharing(expected string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		actual, err := testGetVirtualMachineSCSIBusSharing(s, "vm")
-		if err != nil {
-			return fmt.Errorf("bad: %s", err)
-		}
-		if expected != actual {
-			return fmt.Errorf("expected SCSI bus sharing to be %s, got %s", expected, actual)
-		}
-		return nil
-	}
+func testAccResourceVSphereVirtualMachineConfigSharedSCSIBus() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
 }
 
-// testAccResourceVSphereVirtualMachineCheckHost checks to make sure the
-// test VM is currently located on a specific host.
-func testAccResourceVSphereVirtualMachineCheckHost(expected string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		hs, err := testGetVirtualMachineHost(s, "vm")
-		if err != nil {
-			return err
-		}
-		actual := hs.Name()
-		if expected != actual {
-			return fmt.Errorf("expected host to be %s, got %s", expected, actual)
-		}
-		return nil
-	}
+variable "resource_pool" {
+  default = "%s"
 }
 
-// testAccResourceVSphereVirtualMachineCheckResourcePool checks to make sure the
-// test VM is currently located in a specific resource pool.
-func testAccResourceVSphereVirtualMachineCheckResourcePool(expected string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		pool, err := testGetVirtualMachineResourcePool(s, "vm")
-		if err != nil {
-			return err
-		}
+variable "network_label" {
+  default = "%s"
+}
 
-		actual := pool.Name()
-		if actual == "Resources" && path.Base(expected) == "Resources" {
-			client := testAccProvider.Meta().(*VSphereClient).vimClient
-			expectedCluster, err := computeresource.BaseFromPath(client, path.Dir(expected))
-			if err != nil {
-				return err
-			}
-			pprops, err := resourcepool.Properties(pool)
-			if err != nil {
-				return err
-			}
-			actualCluster, err := computeresource.BaseFromReference(client, *pprops.Parent)
-			if err != nil {
-				return err
-			}
-			if expectedCluster.Reference().Value != actualCluster.Reference().Value {
-				return fmt.Errorf("expected default resource pool of %q, got default resource pool of %q", expectedCluster.Reference().Value, actualCluster.Reference().Value)
-			}
-			return nil
-		}
-		if expected != actual {
-			return fmt.Errorf("expected resource pool or to be %q, got %q", expected, actual)
-		}
-		return nil
-	}
+variable "datastore" {
+  default = "%s"
 }
 
-// testAccResourceVSphereVirtualMachineCheckExtraConfig checks a key/expected
-// value combination in a VM's config.
-func testAccResourceVSphereVirtualMachineCheckExtraConfig(key, expected string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-		for _, bov := range props.Config.ExtraConfig {
-			ov := bov.GetOptionValue()
-			if ov.Key == key {
-				if ov.Value.(string) == expected {
-					return nil
-				}
-				return fmt.Errorf("expected key %s to be %s, got %s", key, expected, ov.Value.(string))
-			}
-		}
-		return fmt.Errorf("key %s not found", key)
-	}
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
 }
 
-// testAccResourceVSphereVirtualMachineCheckExtraConfigKeyMissing checks to
-// make sure that a key is missing in the VM's extraConfig.
-func testAccResourceVSphereVirtualMachineCheckExtraConfigKeyMissing(key string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-		for _, bov := range props.Config.ExtraConfig {
-			ov := bov.GetOptionValue()
-			if ov.Key == key {
-				return fmt.Errorf("expected key %s to be missing", key)
-			}
-		}
-		return nil
-	}
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
-// testAccResourceVSphereVirtualMachineCheckVmxDatastore checks the datastore
-// that the virtual machine's configuration is currently located.
-func testAccResourceVSphereVirtualMachineCheckVmxDatastore(expected string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-		var dsPath object.DatastorePath
-		if ok := dsPath.FromString(props.Config.Files.VmPathName); !ok {
-			return fmt.Errorf("could not parse datastore path %q", props.Config.Files.VmPathName)
-		}
-		actual := dsPath.Datastore
-		if expected != actual {
-			return fmt.Errorf("expected VM configuration to be in datastore %s, got %s", expected, actual)
-		}
-		return nil
-	}
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
-// testAccResourceVSphereVirtualMachineCheckVmdkDatastore checks the datastore
-// that a specific VMDK file is in.
-func testAccResourceVSphereVirtualMachineCheckVmdkDatastore(diskIndex int, expected string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		tVars, err := testClientVariablesForResource(s, "vsphere_virtual_machine.vm")
-		if err != nil {
-			return err
-		}
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-		name := tVars.resourceAttributes[fmt.Sprintf("disk.%d.path", diskIndex)]
-		for _, dev := range props.Config.Hardware.Device {
-			if disk, ok := dev.(*types.VirtualDisk); ok {
-				if info, ok := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo); ok {
-					var dsPath object.DatastorePath
-					if ok := dsPath.FromString(info.FileName); !ok {
-						return fmt.Errorf("could not parse datastore path %q", info.FileName)
-					}
-					if dsPath.Path == name {
-						actual := dsPath.Datastore
-						if expected == actual {
-							return nil
-						}
-						return fmt.Errorf("expected disk name %q to be on datastore %q, got %q", name, expected, actual)
-					}
-				}
-			}
-		}
-		return fmt.Errorf("could not find disk %q", name)
-	}
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
-// testAccResourceVSphereVirtualMachineCheckVmxDatastoreCluster checks the
-// datastore cluster that the virtual machine's configuration is currently
-// located.
-func testAccResourceVSphereVirtualMachineCheckVmxDatastoreCluster(expected string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		tVars, err := testClientVariablesForResource(s, "vsphere_virtual_machine.vm")
-		if err != nil {
-			return err
-		}
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-		var dsPath object.DatastorePath
-		if ok := dsPath.FromString(props.Config.Files.VmPathName); !ok {
-			return fmt.Errorf("could not parse datastore path %q", props.Config.Files.VmPathName)
-		}
-		dc, err := getDatacenter(tVars.client, os.Getenv("VSPHERE_DATACENTER"))
-		if err != nil {
-			return fmt.Errorf("could not get datacenter by ID %q: %s", tVars.resourceID, err)
-		}
-		ds, err := datastore.FromPath(tVars.client, dsPath.Datastore, dc)
-		if err != nil {
-			return fmt.Errorf("could not get datastore %q: %s", dsPath.Datastore, err)
-		}
-		pod, err := storagepod.FromPath(tVars.client, expected, dc)
-		if err != nil {
-			return fmt.Errorf("could not get storage pod %q: %s", expected, err)
-		}
+resource "vsphere_virtual_machine" "vm" {
+  name             = "terraform-test"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  datastore_id     = "${data.vsphere_datastore.datastore.id}"
 
-		isMember, err := storagepod.IsMember(pod, ds)
-		if err != nil {
-			return fmt.Errorf("could not check storage pod membership: %s", err)
-		}
+  num_cpus                   = 2
+  memory                     = 2048
+  guest_id                   = "other3xLinux64Guest"
+  wait_for_guest_net_timeout = -1
 
-		if !isMember {
-			return fmt.Errorf("datastore %q is not a member of datastore cluster %q", dsPath.Datastore, expected)
-		}
-		return nil
-	}
+  scsi_bus_sharing = "physicalSharing"
+
+  network_interface {
+    network_id = "${data.vsphere_network.network.id}"
+  }
+
+  disk {
+    label = "disk0"
+    size  = 20
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
+		os.Getenv("VSPHERE_DATASTORE"),
+	)
 }
 
-// testAccResourceVSphereVirtualMachineCheckVmdkDatastoreCluster checks the
-// datastore cluster that a specific VMDK file is in.
-func testAccResourceVSphereVirtualMachineCheckVmdkDatastoreCluster(diskIndex int, expected string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		tVars, err := testClientVariablesForResource(s, "vsphere_virtual_machine.vm")
-		if err != nil {
-			return err
-		}
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-		name := tVars.resourceAttributes[fmt.Sprintf("disk.%d.path", diskIndex)]
-		for _, dev := range props.Config.Hardware.Device {
-			if disk, ok := dev.(*types.VirtualDisk); ok {
-				if info, ok := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo); ok {
-					var dsPath object.DatastorePath
-					if ok := dsPath.FromString(info.FileName); !ok {
-						return fmt.Errorf("could not parse datastore path %q", info.FileName)
-					}
-					if dsPath.Path == name {
-						dc, err := getDatacenter(tVars.client, os.Getenv("VSPHERE_DATACENTER"))
-						if err != nil {
-							return fmt.Errorf("could not get datacenter by ID %q: %s", tVars.resourceID, err)
-						}
-						ds, err := datastore.FromPath(tVars.client, dsPath.Datastore, dc)
-						if err != nil {
-							return fmt.Errorf("could not get datastore %q: %s", dsPath.Datastore, err)
-						}
-						pod, err := storagepod.FromPath(tVars.client, expected, dc)
-						if err != nil {
-							return fmt.Errorf("could not get storage pod %q: %s", expected, err)
-						}
+func testAccResourceVSphereVirtualMachineConfigISCSIDatastore() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
 
-						isMember, err := storagepod.IsMember(pod, ds)
-						if err != nil {
-							return fmt.Errorf("could not check storage pod membership: %s", err)
-						}
+variable "resource_pool" {
+  default = "%s"
+}
 
-						if isMember {
-							return nil
-						}
-						return fmt.Errorf("datastore %q is not a member of datastore cluster %q", dsPath.Datastore, expected)
-					}
-				}
-			}
-		}
-		return fmt.Errorf("could not find disk %q", name)
-	}
+variable "network_label" {
+  default = "%s"
 }
 
-// testAccResourceVSphereVirtualMachineCheckNICCount checks the number of NICs
-// on the virtual machine.
-func testAccResourceVSphereVirtualMachineCheckNICCount(expected int) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
+variable "shared_disk" {
+  default = "%s"
+}
 
-		var actual int
-		for _, dev := range props.Config.Hardware.Device {
-			if _, ok := dev.(types.BaseVirtualEthernetCard); ok {
-				actual++
-			}
-		}
-		if expected != actual {
-			return fmt.Errorf("expected %d number of NICs, got %d", expected, actual)
-		}
-		return nil
-	}
+variable "host" {
+  default = "%s"
 }
 
-// testCheckVMDiskFileExists takes a VMDK filename and checks to see if it
-// exists within the same directory as the virtual machine's VMX file.
-func testCheckVMDiskFileExists(name string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		tVars, err := testClientVariablesForResource(s, "vsphere_virtual_machine.vm")
-		if err != nil {
-			return err
-		}
-		vm, err := testGetVirtualMachine(s, "vm")
-		if err != nil {
-			return err
-		}
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-		vmxPath, success := virtualdisk.DatastorePathFromString(props.Config.Files.VmPathName)
-		if !success {
-			return fmt.Errorf("could not parse VMX path %q", props.Config.Files.VmPathName)
-		}
-		dcp, err := folder.RootPathParticleVM.SplitDatacenter(vm.InventoryPath)
-		if err != nil {
-			return err
-		}
-		dc, err := getDatacenter(tVars.client, dcp)
-		if err != nil {
-			return err
-		}
-		ds, err := datastore.FromPath(tVars.client, vmxPath.Datastore, dc)
-		if err != nil {
-			return err
-		}
-		p := path.Join(path.Dir(vmxPath.Path), name)
-		exists, err := datastore.FileExists(ds, p)
-		if err != nil {
-			return err
-		}
-		if !exists {
-			return fmt.Errorf("file %q does not exist in datastore %q", p, ds.Name())
-		}
-		return nil
-	}
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_host" "host" {
+  name          = "${var.host}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
-func testAccResourceVSphereVirtualMachineCheckCustomAttributes() resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-		return testResourceHasCustomAttributeValues(s, "vsphere_virtual_machine", "vm", props.Entity())
-	}
+data "vsphere_vmfs_disks" "disk" {
+  host_system_id = "${data.vsphere_host.host.id}"
+  rescan         = true
+  filter         = "${var.shared_disk}"
 }
 
-// testAccResourceVSphereVirtualMachineCheckLatencySensitivity checks a virtual
-// machine's latency sensitivity value.
-func testAccResourceVSphereVirtualMachineCheckLatencySensitivity(
-	expected types.LatencySensitivitySensitivityLevel,
-) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-		actual := props.Config.LatencySensitivity.Level
-		if expected != actual {
-			return fmt.Errorf("expected latency sensitivity to be %s, got %s", expected, actual)
-		}
-		return nil
-	}
+resource "vsphere_vmfs_datastore" "datastore" {
+  name           = "terraform-test-shared-datastore"
+  host_system_id = "${data.vsphere_host.host.id}"
+  disks          = "${data.vsphere_vmfs_disks.disk.disks}"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
+		os.Getenv("VSPHERE_DS_VMFS_DISK0"),
+		os.Getenv("VSPHERE_ESXI_HOST"),
+	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigComputedValue() string {
+func testAccResourceVSphereVirtualMachineConfigMultipleSharedSCSIBus() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -4064,7 +5061,11 @@ variable "network_label" {
   default = "%s"
 }
 
-variable "datastore" {
+variable "shared_disk" {
+  default = "%s"
+}
+
+variable "host" {
   default = "%s"
 }
 
@@ -4072,8 +5073,8 @@ data "vsphere_datacenter" "dc" {
   name = "${var.datacenter}"
 }
 
-data "vsphere_datastore" "datastore" {
-  name          = "${var.datastore}"
+data "vsphere_host" "host" {
+  name          = "${var.host}"
   datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
@@ -4087,38 +5088,105 @@ data "vsphere_network" "network" {
   datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
-resource "vsphere_resource_pool" "pool" {
-  name = "test_pool"
-	parent_resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+data "vsphere_vmfs_disks" "disk" {
+  host_system_id = "${data.vsphere_host.host.id}"
+  rescan         = true
+  filter         = "${var.shared_disk}"
 }
 
-resource "vsphere_virtual_machine" "vm" {
-  name             = "terraform-test"
+data "vsphere_datastore" "ds" {
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+  name          = "${vsphere_vmfs_datastore.datastore.name}"
+}
+
+resource "vsphere_vmfs_datastore" "datastore" {
+  name           = "terraform-test-shared-datastore"
+  host_system_id = "${data.vsphere_host.host.id}"
+  disks          = "${data.vsphere_vmfs_disks.disk.disks}"
+}
+
+resource "vsphere_virtual_disk" "disk" {
+  vmdk_path = "terraform-test-shared-disk.vmdk"
+  datastore = "${vsphere_vmfs_datastore.datastore.name}"
+  size      = 1
+  type      = "eagerZeroedThick"
+}
+
+resource "vsphere_virtual_machine" "vm1" {
+  name             = "terraform-test1"
   resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
-  datastore_id     = "${vsphere_resource_pool.pool.id}"
+  datastore_id     = "${vsphere_vmfs_datastore.datastore.id}"
 
-  num_cpus = 2
-  memory   = 2048
-  guest_id = "other3xLinux64Guest"
+  num_cpus                   = 2
+  memory                     = 2048
+  guest_id                   = "other3xLinux64Guest"
+  wait_for_guest_net_timeout = -1
+
+  scsi_bus_sharing = "physicalSharing"
 
   network_interface {
     network_id = "${data.vsphere_network.network.id}"
   }
 
   disk {
-    label = "disk0"
-    size  = 20
+    label            = "disk0"
+    size             = 1
+    thin_provisioned = false
+    eagerly_scrub    = true
+  }
+
+  disk {
+    label        = "disk1"
+    disk_sharing = "sharingMultiWriter"
+    unit_number  = 1
+    attach       = true
+    path         = "${vsphere_virtual_disk.disk.vmdk_path}"
+    datastore_id = "${data.vsphere_datastore.ds.id}"
+  }
+}
+
+resource "vsphere_virtual_machine" "vm2" {
+  name             = "terraform-test2"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  datastore_id     = "${vsphere_vmfs_datastore.datastore.id}"
+
+  num_cpus                   = 2
+  memory                     = 2048
+  guest_id                   = "other3xLinux64Guest"
+  wait_for_guest_net_timeout = -1
+
+  scsi_bus_sharing = "physicalSharing"
+
+  network_interface {
+    network_id = "${data.vsphere_network.network.id}"
+  }
+
+  disk {
+    label            = "disk0"
+    size             = 1
+    thin_provisioned = false
+    eagerly_scrub    = true
+  }
+
+  disk {
+    label        = "disk1"
+    disk_sharing = "sharingMultiWriter"
+    unit_number  = 1
+    attach       = true
+    path         = "${vsphere_virtual_disk.disk.vmdk_path}"
+    datastore_id = "${data.vsphere_datastore.ds.id}"
   }
 }
 `,
 		os.Getenv("VSPHERE_DATACENTER"),
 		os.Getenv("VSPHERE_RESOURCE_POOL"),
 		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
-		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_DS_VMFS_DISK0"),
+		os.Getenv("VSPHERE_ESXI_HOST"),
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigBasic() string {
+func testAccResourceVSphereVirtualMachineConfigKeepDisksOnRemove() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -4132,10 +5200,34 @@ variable "network_label" {
   default = "%s"
 }
 
+variable "ipv4_address" {
+  default = "%s"
+}
+
+variable "ipv4_netmask" {
+  default = "%s"
+}
+
+variable "ipv4_gateway" {
+  default = "%s"
+}
+
+variable "dns_server" {
+  default = "%s"
+}
+
 variable "datastore" {
   default = "%s"
 }
 
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
 data "vsphere_datacenter" "dc" {
   name = "${var.datacenter}"
 }
@@ -4155,6 +5247,11 @@ data "vsphere_network" "network" {
   datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
+data "vsphere_virtual_machine" "template" {
+  name          = "${var.template}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
 resource "vsphere_virtual_machine" "vm" {
   name             = "terraform-test"
   resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
@@ -4162,35 +5259,76 @@ resource "vsphere_virtual_machine" "vm" {
 
   num_cpus = 2
   memory   = 2048
-  guest_id = "other3xLinux64Guest"
+  guest_id = "${data.vsphere_virtual_machine.template.guest_id}"
 
   network_interface {
-    network_id = "${data.vsphere_network.network.id}"
+    network_id   = "${data.vsphere_network.network.id}"
+    adapter_type = "${data.vsphere_virtual_machine.template.network_interface_types[0]}"
   }
 
+  wait_for_guest_net_timeout = -1
+
   disk {
-    label = "disk0"
-    size  = 20
+    label            = "disk0"
+    unit_number      = 0
+    size             = "${data.vsphere_virtual_machine.template.disks.0.size}"
+    eagerly_scrub    = "${data.vsphere_virtual_machine.template.disks.0.eagerly_scrub}"
+    thin_provisioned = "${data.vsphere_virtual_machine.template.disks.0.thin_provisioned}"
+  }
+
+  disk {
+    label            = "disk1"
+    unit_number      = 1
+    size             = 1
+    thin_provisioned = true
+    keep_on_remove   = true
+  }
+
+  disk {
+    label            = "disk2"
+    unit_number      = 2
+    size             = 1
+    thin_provisioned = true
+    keep_on_remove   = true
+  }
+
+  clone {
+    template_uuid = "${data.vsphere_virtual_machine.template.id}"
+    linked_clone  = "${var.linked_clone != "" ? "true" : "false" }"
+
+    customize {
+      linux_options {
+        host_name = "terraform-test"
+        domain    = "test.internal"
+      }
+
+      network_interface {
+        ipv4_address = "${var.ipv4_address}"
+        ipv4_netmask = "${var.ipv4_netmask}"
+      }
+
+      ipv4_gateway    = "${var.ipv4_gateway}"
+      dns_server_list = ["${var.dns_server}"]
+      dns_suffix_list = ["test.internal"]
+    }
   }
 }
 `,
 		os.Getenv("VSPHERE_DATACENTER"),
 		os.Getenv("VSPHERE_RESOURCE_POOL"),
-		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_IPV4_PREFIX"),
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		os.Getenv("VSPHERE_DNS"),
 		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigSharedSCSIBus() string {
+func testAccResourceVSphereVirtualMachineConfigBasicESXiOnly() string {
 	return fmt.Sprintf(`
-variable "datacenter" {
-  default = "%s"
-}
-
-variable "resource_pool" {
-  default = "%s"
-}
-
 variable "network_label" {
   default = "%s"
 }
@@ -4199,20 +5337,15 @@ variable "datastore" {
   default = "%s"
 }
 
-data "vsphere_datacenter" "dc" {
-  name = "${var.datacenter}"
-}
+data "vsphere_datacenter" "dc" {}
 
 data "vsphere_datastore" "datastore" {
-  name          = "${var.datastore}"
-  datacenter_id = "${data.vsphere_datacenter.dc.id}"
-}
-
-data "vsphere_resource_pool" "pool" {
-  name          = "${var.resource_pool}"
+  name          = "${var.datastore}"
   datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
+data "vsphere_resource_pool" "pool" {}
+
 data "vsphere_network" "network" {
   name          = "${var.network_label}"
   datacenter_id = "${data.vsphere_datacenter.dc.id}"
@@ -4223,12 +5356,9 @@ resource "vsphere_virtual_machine" "vm" {
   resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
   datastore_id     = "${data.vsphere_datastore.datastore.id}"
 
-  num_cpus                   = 2
-  memory                     = 2048
-  guest_id                   = "other3xLinux64Guest"
-  wait_for_guest_net_timeout = -1
-
-  scsi_bus_sharing = "physicalSharing"
+  num_cpus = 2
+  memory   = 2048
+  guest_id = "other3xLinux64Guest"
 
   network_interface {
     network_id = "${data.vsphere_network.network.id}"
@@ -4240,14 +5370,12 @@ resource "vsphere_virtual_machine" "vm" {
   }
 }
 `,
-		os.Getenv("VSPHERE_DATACENTER"),
-		os.Getenv("VSPHERE_RESOURCE_POOL"),
 		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
 		os.Getenv("VSPHERE_DATASTORE"),
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigISCSIDatastore() string {
+func testAccResourceVSphereVirtualMachineConfigMultiDevice() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -4261,11 +5389,7 @@ variable "network_label" {
   default = "%s"
 }
 
-variable "shared_disk" {
-  default = "%s"
-}
-
-variable "host" {
+variable "datastore" {
   default = "%s"
 }
 
@@ -4273,8 +5397,8 @@ data "vsphere_datacenter" "dc" {
   name = "${var.datacenter}"
 }
 
-data "vsphere_host" "host" {
-  name          = "${var.host}"
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
   datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
@@ -4288,27 +5412,56 @@ data "vsphere_network" "network" {
   datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
-data "vsphere_vmfs_disks" "disk" {
-  host_system_id = "${data.vsphere_host.host.id}"
-  rescan         = true
-  filter         = "${var.shared_disk}"
-}
+resource "vsphere_virtual_machine" "vm" {
+  name             = "terraform-test"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  datastore_id     = "${data.vsphere_datastore.datastore.id}"
 
-resource "vsphere_vmfs_datastore" "datastore" {
-  name           = "terraform-test-shared-datastore"
-  host_system_id = "${data.vsphere_host.host.id}"
-  disks          = "${data.vsphere_vmfs_disks.disk.disks}"
+  num_cpus = 2
+  memory   = 2048
+  guest_id = "other3xLinux64Guest"
+
+  network_interface {
+    network_id            = "${data.vsphere_network.network.id}"
+    bandwidth_share_level = "normal"
+  }
+
+  network_interface {
+    network_id            = "${data.vsphere_network.network.id}"
+    bandwidth_share_level = "high"
+  }
+
+  network_interface {
+    network_id            = "${data.vsphere_network.network.id}"
+    bandwidth_share_level = "low"
+  }
+
+  disk {
+    label = "disk0"
+    size  = 20
+  }
+
+  disk {
+    label       = "disk1"
+    unit_number = 1
+    size        = 10
+  }
+
+  disk {
+    label       = "disk2"
+    unit_number = 2
+    size        = 5
+  }
 }
 `,
 		os.Getenv("VSPHERE_DATACENTER"),
 		os.Getenv("VSPHERE_RESOURCE_POOL"),
 		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
-		os.Getenv("VSPHERE_DS_VMFS_DISK0"),
-		os.Getenv("VSPHERE_ESXI_HOST"),
+		os.Getenv("VSPHERE_DATASTORE"),
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigMultipleSharedSCSIBus() string {
+func testAccResourceVSphereVirtualMachineConfigMultiHighBusInsufficientBus() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -4322,11 +5475,7 @@ variable "network_label" {
   default = "%s"
 }
 
-variable "shared_disk" {
-  default = "%s"
-}
-
-variable "host" {
+variable "datastore" {
   default = "%s"
 }
 
@@ -4334,8 +5483,8 @@ data "vsphere_datacenter" "dc" {
   name = "${var.datacenter}"
 }
 
-data "vsphere_host" "host" {
-  name          = "${var.host}"
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
   datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
@@ -4349,105 +5498,58 @@ data "vsphere_network" "network" {
   datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
-data "vsphere_vmfs_disks" "disk" {
-  host_system_id = "${data.vsphere_host.host.id}"
-  rescan         = true
-  filter         = "${var.shared_disk}"
-}
-
-data "vsphere_datastore" "ds" {
-  datacenter_id = "${data.vsphere_datacenter.dc.id}"
-  name          = "${vsphere_vmfs_datastore.datastore.name}"
-}
-
-resource "vsphere_vmfs_datastore" "datastore" {
-  name           = "terraform-test-shared-datastore"
-  host_system_id = "${data.vsphere_host.host.id}"
-  disks          = "${data.vsphere_vmfs_disks.disk.disks}"
-}
-
-resource "vsphere_virtual_disk" "disk" {
-  vmdk_path = "terraform-test-shared-disk.vmdk"
-  datastore = "${vsphere_vmfs_datastore.datastore.name}"
-  size      = 1
-  type      = "eagerZeroedThick"
-}
-
-resource "vsphere_virtual_machine" "vm1" {
-  name             = "terraform-test1"
+resource "vsphere_virtual_machine" "vm" {
+  name             = "terraform-test"
   resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
-  datastore_id     = "${vsphere_vmfs_datastore.datastore.id}"
+  datastore_id     = "${data.vsphere_datastore.datastore.id}"
 
-  num_cpus                   = 2
-  memory                     = 2048
-  guest_id                   = "other3xLinux64Guest"
-  wait_for_guest_net_timeout = -1
+  scsi_controller_count = 1
 
-  scsi_bus_sharing = "physicalSharing"
+  num_cpus = 2
+  memory   = 2048
+  guest_id = "other3xLinux64Guest"
 
   network_interface {
-    network_id = "${data.vsphere_network.network.id}"
+    network_id            = "${data.vsphere_network.network.id}"
+    bandwidth_share_level = "normal"
   }
 
-  disk {
-    label            = "disk0"
-    size             = 1
-    thin_provisioned = false
-    eagerly_scrub    = true
+  network_interface {
+    network_id            = "${data.vsphere_network.network.id}"
+    bandwidth_share_level = "high"
   }
 
-  disk {
-    label        = "disk1"
-    disk_sharing = "sharingMultiWriter"
-    unit_number  = 1
-    attach       = true
-    path         = "${vsphere_virtual_disk.disk.vmdk_path}"
-    datastore_id = "${data.vsphere_datastore.ds.id}"
+  network_interface {
+    network_id            = "${data.vsphere_network.network.id}"
+    bandwidth_share_level = "low"
   }
-}
-
-resource "vsphere_virtual_machine" "vm2" {
-  name             = "terraform-test2"
-  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
-  datastore_id     = "${vsphere_vmfs_datastore.datastore.id}"
-
-  num_cpus                   = 2
-  memory                     = 2048
-  guest_id                   = "other3xLinux64Guest"
-  wait_for_guest_net_timeout = -1
 
-  scsi_bus_sharing = "physicalSharing"
-
-  network_interface {
-    network_id = "${data.vsphere_network.network.id}"
+  disk {
+    label = "disk0"
+    size  = 20
   }
 
   disk {
-    label            = "disk0"
-    size             = 1
-    thin_provisioned = false
-    eagerly_scrub    = true
+    label       = "disk1"
+    unit_number = 15
+    size        = 10
   }
 
   disk {
-    label        = "disk1"
-    disk_sharing = "sharingMultiWriter"
-    unit_number  = 1
-    attach       = true
-    path         = "${vsphere_virtual_disk.disk.vmdk_path}"
-    datastore_id = "${data.vsphere_datastore.ds.id}"
+    label       = "disk2"
+    unit_number = 31
+    size        = 5
   }
 }
 `,
 		os.Getenv("VSPHERE_DATACENTER"),
 		os.Getenv("VSPHERE_RESOURCE_POOL"),
 		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
-		os.Getenv("VSPHERE_DS_VMFS_DISK0"),
-		os.Getenv("VSPHERE_ESXI_HOST"),
+		os.Getenv("VSPHERE_DATASTORE"),
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigKeepDisksOnRemove() string {
+func testAccResourceVSphereVirtualMachineConfigMultipleControllerTypes() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -4461,34 +5563,10 @@ variable "network_label" {
   default = "%s"
 }
 
-variable "ipv4_address" {
-  default = "%s"
-}
-
-variable "ipv4_netmask" {
-  default = "%s"
-}
-
-variable "ipv4_gateway" {
-  default = "%s"
-}
-
-variable "dns_server" {
-  default = "%s"
-}
-
 variable "datastore" {
   default = "%s"
 }
 
-variable "template" {
-  default = "%s"
-}
-
-variable "linked_clone" {
-  default = "%s"
-}
-
 data "vsphere_datacenter" "dc" {
   name = "${var.datacenter}"
 }
@@ -4508,88 +5586,67 @@ data "vsphere_network" "network" {
   datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
-data "vsphere_virtual_machine" "template" {
-  name          = "${var.template}"
-  datacenter_id = "${data.vsphere_datacenter.dc.id}"
-}
-
 resource "vsphere_virtual_machine" "vm" {
   name             = "terraform-test"
   resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
   datastore_id     = "${data.vsphere_datastore.datastore.id}"
 
+  sata_controller_count = 2
+  nvme_controller_count = 1
+  ide_controller_count  = 2
+
   num_cpus = 2
   memory   = 2048
-  guest_id = "${data.vsphere_virtual_machine.template.guest_id}"
+  guest_id = "other3xLinux64Guest"
 
   network_interface {
-    network_id   = "${data.vsphere_network.network.id}"
-    adapter_type = "${data.vsphere_virtual_machine.template.network_interface_types[0]}"
-  }
-
-  wait_for_guest_net_timeout = -1
-
-  disk {
-    label            = "disk0"
-    unit_number      = 0
-    size             = "${data.vsphere_virtual_machine.template.disks.0.size}"
-    eagerly_scrub    = "${data.vsphere_virtual_machine.template.disks.0.eagerly_scrub}"
-    thin_provisioned = "${data.vsphere_virtual_machine.template.disks.0.thin_provisioned}"
-  }
-
-  disk {
-    label            = "disk1"
-    unit_number      = 1
-    size             = 1
-    thin_provisioned = true
-    keep_on_remove   = true
+    network_id = "${data.vsphere_network.network.id}"
   }
 
   disk {
-    label            = "disk2"
-    unit_number      = 2
-    size             = 1
-    thin_provisioned = true
-    keep_on_remove   = true
+    label = "disk0"
+    size  = 20
   }
 
-  clone {
-    template_uuid = "${data.vsphere_virtual_machine.template.id}"
-    linked_clone  = "${var.linked_clone != "" ? "true" : "false" }"
-
-    customize {
-      linux_options {
-        host_name = "terraform-test"
-        domain    = "test.internal"
-      }
+  disk {
+    label           = "disk1"
+    controller_type = "sata"
+    unit_number     = 32
+    size            = 10
+  }
 
-      network_interface {
-        ipv4_address = "${var.ipv4_address}"
-        ipv4_netmask = "${var.ipv4_netmask}"
-      }
+  disk {
+    label           = "disk2"
+    controller_type = "nvme"
+    unit_number     = 1
+    size            = 5
+  }
 
-      ipv4_gateway    = "${var.ipv4_gateway}"
-      dns_server_list = ["${var.dns_server}"]
-      dns_suffix_list = ["test.internal"]
-    }
+  disk {
+    label           = "disk3"
+    controller_type = "ide"
+    unit_number     = 2
+    size            = 1
   }
 }
 `,
 		os.Getenv("VSPHERE_DATACENTER"),
 		os.Getenv("VSPHERE_RESOURCE_POOL"),
-		os.Getenv("VSPHERE_NETWORK_LABEL"),
-		os.Getenv("VSPHERE_IPV4_ADDRESS"),
-		os.Getenv("VSPHERE_IPV4_PREFIX"),
-		os.Getenv("VSPHERE_IPV4_GATEWAY"),
-		os.Getenv("VSPHERE_DNS"),
+		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
 		os.Getenv("VSPHERE_DATASTORE"),
-		os.Getenv("VSPHERE_TEMPLATE"),
-		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigBasicESXiOnly() string {
+func testAccResourceVSphereVirtualMachineConfigControllerTypeInsufficientBus() string {
 	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
 variable "network_label" {
   default = "%s"
 }
@@ -4598,14 +5655,19 @@ variable "datastore" {
   default = "%s"
 }
 
-data "vsphere_datacenter" "dc" {}
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
 
 data "vsphere_datastore" "datastore" {
   name          = "${var.datastore}"
   datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
-data "vsphere_resource_pool" "pool" {}
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
 
 data "vsphere_network" "network" {
   name          = "${var.network_label}"
@@ -4629,14 +5691,23 @@ resource "vsphere_virtual_machine" "vm" {
     label = "disk0"
     size  = 20
   }
+
+  disk {
+    label           = "disk1"
+    controller_type = "sata"
+    unit_number     = 1
+    size            = 10
+  }
 }
 `,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
 		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
 		os.Getenv("VSPHERE_DATASTORE"),
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigMultiDevice() string {
+func testAccResourceVSphereVirtualMachineConfigPciPassthrough(devices string, reservation int) string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -4654,6 +5725,10 @@ variable "datastore" {
   default = "%s"
 }
 
+variable "host" {
+  default = "%s"
+}
+
 data "vsphere_datacenter" "dc" {
   name = "${var.datacenter}"
 }
@@ -4673,56 +5748,57 @@ data "vsphere_network" "network" {
   datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
+data "vsphere_host" "host" {
+  name          = "${var.host}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
 resource "vsphere_virtual_machine" "vm" {
   name             = "terraform-test"
   resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
   datastore_id     = "${data.vsphere_datastore.datastore.id}"
+  host_system_id   = "${data.vsphere_host.host.id}"
 
-  num_cpus = 2
-  memory   = 2048
-  guest_id = "other3xLinux64Guest"
-
-  network_interface {
-    network_id            = "${data.vsphere_network.network.id}"
-    bandwidth_share_level = "normal"
-  }
+  num_cpus           = 2
+  memory             = 2048
+  memory_reservation = %d
+  guest_id           = "other3xLinux64Guest"
 
-  network_interface {
-    network_id            = "${data.vsphere_network.network.id}"
-    bandwidth_share_level = "high"
-  }
+  %s
 
   network_interface {
-    network_id            = "${data.vsphere_network.network.id}"
-    bandwidth_share_level = "low"
+    network_id = "${data.vsphere_network.network.id}"
   }
 
   disk {
     label = "disk0"
     size  = 20
   }
-
-  disk {
-    label       = "disk1"
-    unit_number = 1
-    size        = 10
-  }
-
-  disk {
-    label       = "disk2"
-    unit_number = 2
-    size        = 5
-  }
 }
 `,
 		os.Getenv("VSPHERE_DATACENTER"),
 		os.Getenv("VSPHERE_RESOURCE_POOL"),
 		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
 		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_ESXI_HOST"),
+		reservation,
+		devices,
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigMultiHighBusInsufficientBus() string {
+func testAccResourceVSphereVirtualMachineConfigSecurity(extra string) string {
+	return testAccResourceVSphereVirtualMachineConfigSecurityPolicy(extra, "")
+}
+
+// testAccResourceVSphereVirtualMachineConfigSecurityPolicy returns the
+// configuration of testAccResourceVSphereVirtualMachineConfigSecurity with
+// the virtual machine home and disk set to the storage policy with the
+// supplied ID. No storage policy is set if policyID is empty.
+func testAccResourceVSphereVirtualMachineConfigSecurityPolicy(extra, policyID string) string {
+	var policy string
+	if policyID != "" {
+		policy = fmt.Sprintf("storage_policy_id = %q", policyID)
+	}
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -4764,42 +5840,20 @@ resource "vsphere_virtual_machine" "vm" {
   resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
   datastore_id     = "${data.vsphere_datastore.datastore.id}"
 
-  scsi_controller_count = 1
-
   num_cpus = 2
-  memory   = 2048
-  guest_id = "other3xLinux64Guest"
-
-  network_interface {
-    network_id            = "${data.vsphere_network.network.id}"
-    bandwidth_share_level = "normal"
-  }
-
-  network_interface {
-    network_id            = "${data.vsphere_network.network.id}"
-    bandwidth_share_level = "high"
-  }
-
+  memory   = 4096
+  guest_id = "windows9Server64Guest"
+  firmware = "efi"
+  %s
+%s
   network_interface {
-    network_id            = "${data.vsphere_network.network.id}"
-    bandwidth_share_level = "low"
+    network_id = "${data.vsphere_network.network.id}"
   }
 
   disk {
     label = "disk0"
-    size  = 20
-  }
-
-  disk {
-    label       = "disk1"
-    unit_number = 15
-    size        = 10
-  }
-
-  disk {
-    label       = "disk2"
-    unit_number = 31
-    size        = 5
+    size  = 40
+    %s
   }
 }
 `,
@@ -4807,6 +5861,19 @@ resource "vsphere_virtual_machine" "vm" {
 		os.Getenv("VSPHERE_RESOURCE_POOL"),
 		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
 		os.Getenv("VSPHERE_DATASTORE"),
+		policy,
+		extra,
+		policy,
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigEncryption(keyProvider string) string {
+	return fmt.Sprintf(`
+  encryption {
+    key_provider_id = "%s"
+  }
+`,
+		keyProvider,
 	)
 }
 
@@ -6419,6 +7486,145 @@ resource "vsphere_virtual_machine" "vm" {
 	)
 }
 
+func testAccResourceVSphereVirtualMachineConfigCloudInit(cloudInit string) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name             = "terraform-test"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  datastore_id     = "${data.vsphere_datastore.datastore.id}"
+
+  num_cpus = 2
+  memory   = 2048
+  guest_id = "ubuntu64Guest"
+
+  extra_config = {
+    foo = "bar"
+  }
+%s
+  network_interface {
+    network_id = "${data.vsphere_network.network.id}"
+  }
+
+  disk {
+    label = "disk0"
+    size  = 20
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		cloudInit,
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigCloudInitExtraConfigConflict() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name             = "terraform-test"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  datastore_id     = "${data.vsphere_datastore.datastore.id}"
+
+  num_cpus = 2
+  memory   = 2048
+  guest_id = "ubuntu64Guest"
+
+  extra_config = {
+    "guestinfo.userdata" = "foo"
+  }
+
+  cloud_init {
+    userdata = "#cloud-config\n"
+  }
+
+  network_interface {
+    network_id = "${data.vsphere_network.network.id}"
+  }
+
+  disk {
+    label = "disk0"
+    size  = 20
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
+		os.Getenv("VSPHERE_DATASTORE"),
+	)
+}
+
 func testAccResourceVSphereVirtualMachineConfigExistingVmdk() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
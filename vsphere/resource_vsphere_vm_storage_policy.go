@@ -0,0 +1,319 @@
+package vsphere
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/spbm"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/vmware/govmomi/pbm"
+	pbmtypes "github.com/vmware/govmomi/pbm/types"
+	"github.com/vmware/govmomi/vapi/tags"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const (
+	// vmStoragePolicyTagNamespace is the capability namespace used for
+	// tag-based placement rules in storage policies.
+	vmStoragePolicyTagNamespace = "http://www.vmware.com/storage/tag"
+
+	// vmStoragePolicyTagSubProfileName is the name given to the sub-profile that
+	// holds the tag-based placement rules of a storage policy.
+	vmStoragePolicyTagSubProfileName = "Tag based placement"
+
+	// vmStoragePolicyTagOperatorNot is the property operator used to exclude
+	// datastores with the supplied tags.
+	vmStoragePolicyTagOperatorNot = "NOT"
+)
+
+func resourceVSphereVMStoragePolicy() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereVMStoragePolicyCreate,
+		Read:   resourceVSphereVMStoragePolicyRead,
+		Update: resourceVSphereVMStoragePolicyUpdate,
+		Delete: resourceVSphereVMStoragePolicyDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereVMStoragePolicyImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name of the storage policy.",
+				Required:    true,
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Description: "The description of the storage policy.",
+				Optional:    true,
+			},
+			"tag_rules": {
+				Type:        schema.TypeList,
+				Description: "The tag-based placement rules for the storage policy.",
+				Required:    true,
+				MinItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"tag_category_id": {
+							Type:        schema.TypeString,
+							Description: "The ID of the tag category that the tags in this rule belong to.",
+							Required:    true,
+						},
+						"tag_ids": {
+							Type:        schema.TypeList,
+							Description: "The IDs of the tags to match datastores against.",
+							Required:    true,
+							MinItems:    1,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+						"include_datastores_with_tags": {
+							Type:        schema.TypeBool,
+							Description: "Include datastores with the given tags, or exclude them when false.",
+							Optional:    true,
+							Default:     true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func resourceVSphereVMStoragePolicyCreate(d *schema.ResourceData, meta interface{}) error {
+	pc, tm, err := vmStoragePolicyClients(meta)
+	if err != nil {
+		return err
+	}
+	constraints, err := expandVMStoragePolicyConstraints(d, tm)
+	if err != nil {
+		return err
+	}
+	spec := pbmtypes.PbmCapabilityProfileCreateSpec{
+		Name:        d.Get("name").(string),
+		Description: d.Get("description").(string),
+		Category:    string(pbmtypes.PbmProfileCategoryEnumREQUIREMENT),
+		ResourceType: pbmtypes.PbmProfileResourceType{
+			ResourceType: string(pbmtypes.PbmProfileResourceTypeEnumSTORAGE),
+		},
+		Constraints: constraints,
+	}
+	id, err := spbm.Create(pc, spec)
+	if err != nil {
+		return fmt.Errorf("could not create storage policy: %s", err)
+	}
+	if id == "" {
+		return errors.New("no ID was returned")
+	}
+	d.SetId(id)
+	return resourceVSphereVMStoragePolicyRead(d, meta)
+}
+
+func resourceVSphereVMStoragePolicyRead(d *schema.ResourceData, meta interface{}) error {
+	pc, tm, err := vmStoragePolicyClients(meta)
+	if err != nil {
+		return err
+	}
+	id := d.Id()
+	profile, err := spbm.PolicyByID(pc, id)
+	if err != nil {
+		return err
+	}
+	if profile == nil {
+		log.Printf("[DEBUG] Storage policy %s: Resource has been deleted", id)
+		d.SetId("")
+		return nil
+	}
+	d.Set("name", profile.Name)
+	d.Set("description", profile.Description)
+
+	rules, err := flattenVMStoragePolicyConstraints(profile.Constraints, tm)
+	if err != nil {
+		return err
+	}
+	if err := d.Set("tag_rules", rules); err != nil {
+		return fmt.Errorf("could not set tag rule data for storage policy: %s", err)
+	}
+	return nil
+}
+
+func resourceVSphereVMStoragePolicyUpdate(d *schema.ResourceData, meta interface{}) error {
+	pc, tm, err := vmStoragePolicyClients(meta)
+	if err != nil {
+		return err
+	}
+	id := d.Id()
+	spec := pbmtypes.PbmCapabilityProfileUpdateSpec{
+		Name:        d.Get("name").(string),
+		Description: d.Get("description").(string),
+	}
+	if d.HasChange("tag_rules") {
+		constraints, err := expandVMStoragePolicyConstraints(d, tm)
+		if err != nil {
+			return err
+		}
+		spec.Constraints = constraints
+	}
+	if err := spbm.Update(pc, id, spec); err != nil {
+		return fmt.Errorf("could not update storage policy with id %q: %s", id, err)
+	}
+	return resourceVSphereVMStoragePolicyRead(d, meta)
+}
+
+func resourceVSphereVMStoragePolicyDelete(d *schema.ResourceData, meta interface{}) error {
+	pc, _, err := vmStoragePolicyClients(meta)
+	if err != nil {
+		return err
+	}
+	id := d.Id()
+	if err := spbm.Delete(pc, id); err != nil {
+		return fmt.Errorf("could not delete storage policy with id %q: %s", id, err)
+	}
+	return nil
+}
+
+func resourceVSphereVMStoragePolicyImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	pc, _, err := vmStoragePolicyClients(meta)
+	if err != nil {
+		return nil, err
+	}
+	id, err := spbm.PolicyIDByName(pc, d.Id())
+	if err != nil {
+		return nil, err
+	}
+	d.SetId(id)
+	return []*schema.ResourceData{d}, nil
+}
+
+// vmStoragePolicyClients returns the PBM client and tags manager required to
+// manage tag-based storage policies, or an error if either is unavailable on
+// the current connection.
+func vmStoragePolicyClients(meta interface{}) (*pbm.Client, *tags.Manager, error) {
+	client := meta.(*VSphereClient)
+	if client.pbmClient == nil {
+		return nil, nil, errors.New("storage policies require a vCenter connection")
+	}
+	tm, err := client.TagsManager()
+	if err != nil {
+		return nil, nil, err
+	}
+	return client.pbmClient, tm, nil
+}
+
+// expandVMStoragePolicyConstraints reads the tag_rules from the resource data
+// and returns the sub-profile constraints for the storage policy. Tag
+// category and tag IDs are translated to their names, which is what the tag
+// capability namespace expects.
+func expandVMStoragePolicyConstraints(d *schema.ResourceData, tm *tags.Manager) (*pbmtypes.PbmCapabilitySubProfileConstraints, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+
+	var capabilities []pbmtypes.PbmCapabilityInstance
+	for i, r := range d.Get("tag_rules").([]interface{}) {
+		rule := r.(map[string]interface{})
+		category, err := tm.GetCategory(ctx, rule["tag_category_id"].(string))
+		if err != nil {
+			return nil, fmt.Errorf("tag_rules.%d: could not get tag category: %s", i, err)
+		}
+		var values []types.AnyType
+		for _, id := range structure.SliceInterfacesToStrings(rule["tag_ids"].([]interface{})) {
+			tag, err := tm.GetTag(ctx, id)
+			if err != nil {
+				return nil, fmt.Errorf("tag_rules.%d: could not get tag %q: %s", i, id, err)
+			}
+			values = append(values, tag.Name)
+		}
+		property := pbmtypes.PbmCapabilityPropertyInstance{
+			Id:    vmStoragePolicyTagPropertyID(category.Name),
+			Value: pbmtypes.PbmCapabilityDiscreteSet{Values: values},
+		}
+		if !rule["include_datastores_with_tags"].(bool) {
+			property.Operator = vmStoragePolicyTagOperatorNot
+		}
+		capabilities = append(capabilities, pbmtypes.PbmCapabilityInstance{
+			Id: pbmtypes.PbmCapabilityMetadataUniqueId{
+				Namespace: vmStoragePolicyTagNamespace,
+				Id:        category.Name,
+			},
+			Constraint: []pbmtypes.PbmCapabilityConstraintInstance{
+				{PropertyInstance: []pbmtypes.PbmCapabilityPropertyInstance{property}},
+			},
+		})
+	}
+	return &pbmtypes.PbmCapabilitySubProfileConstraints{
+		SubProfiles: []pbmtypes.PbmCapabilitySubProfile{
+			{
+				Name:       vmStoragePolicyTagSubProfileName,
+				Capability: capabilities,
+			},
+		},
+	}, nil
+}
+
+// flattenVMStoragePolicyConstraints reads the tag-based placement rules out
+// of a storage policy's constraints and returns them in a form suitable for
+// saving to tag_rules. Tag category and tag names are translated back to
+// their IDs.
+func flattenVMStoragePolicyConstraints(obj pbmtypes.BasePbmCapabilityConstraints, tm *tags.Manager) ([]interface{}, error) {
+	var rules []interface{}
+	constraints, ok := obj.(*pbmtypes.PbmCapabilitySubProfileConstraints)
+	if !ok {
+		return rules, nil
+	}
+	for _, sp := range constraints.SubProfiles {
+		for _, capability := range sp.Capability {
+			if capability.Id.Namespace != vmStoragePolicyTagNamespace {
+				continue
+			}
+			categoryID, err := tagCategoryByName(tm, capability.Id.Id)
+			if err != nil {
+				return nil, err
+			}
+			for _, constraint := range capability.Constraint {
+				for _, property := range constraint.PropertyInstance {
+					var tagIDs []string
+					for _, name := range vmStoragePolicyDiscreteSetValues(property.Value) {
+						tagID, err := tagByName(tm, name, categoryID)
+						if err != nil {
+							return nil, err
+						}
+						tagIDs = append(tagIDs, tagID)
+					}
+					rules = append(rules, map[string]interface{}{
+						"tag_category_id":              categoryID,
+						"tag_ids":                      tagIDs,
+						"include_datastores_with_tags": property.Operator != vmStoragePolicyTagOperatorNot,
+					})
+				}
+			}
+		}
+	}
+	return rules, nil
+}
+
+// vmStoragePolicyTagPropertyID returns the property ID used for the tags of
+// a specific category in the tag capability namespace.
+func vmStoragePolicyTagPropertyID(categoryName string) string {
+	return fmt.Sprintf("com.vmware.storage.tag.%s.property", categoryName)
+}
+
+// vmStoragePolicyDiscreteSetValues returns the string values of a
+// PbmCapabilityDiscreteSet property value.
+func vmStoragePolicyDiscreteSetValues(v types.AnyType) []string {
+	var values []types.AnyType
+	switch set := v.(type) {
+	case pbmtypes.PbmCapabilityDiscreteSet:
+		values = set.Values
+	case *pbmtypes.PbmCapabilityDiscreteSet:
+		values = set.Values
+	}
+	var result []string
+	for _, value := range values {
+		if s, ok := value.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
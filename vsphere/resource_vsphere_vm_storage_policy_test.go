@@ -0,0 +1,193 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/spbm"
+	pbmtypes "github.com/vmware/govmomi/pbm/types"
+)
+
+func TestAccResourceVSphereVMStoragePolicy_basic(t *testing.T) {
+	testAccResourceTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccSkipIfEsxi(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVMStoragePolicyExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVMStoragePolicyConfig("Managed by Terraform", true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVMStoragePolicyExists(true),
+					testAccResourceVSphereVMStoragePolicyHasDescription("Managed by Terraform"),
+					resource.TestCheckResourceAttr("vsphere_vm_storage_policy.policy", "tag_rules.#", "1"),
+					resource.TestCheckResourceAttr("vsphere_vm_storage_policy.policy", "tag_rules.0.tag_ids.#", "1"),
+					resource.TestCheckResourceAttrPair(
+						"vsphere_vm_storage_policy.policy", "tag_rules.0.tag_category_id",
+						"vsphere_tag_category.category", "id",
+					),
+				),
+			},
+			{
+				ResourceName:      "vsphere_vm_storage_policy.policy",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					return "terraform-test-policy", nil
+				},
+				Config: testAccResourceVSphereVMStoragePolicyConfig("Managed by Terraform", true),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVMStoragePolicy_update(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccSkipIfEsxi(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVMStoragePolicyExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVMStoragePolicyConfig("Managed by Terraform", true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVMStoragePolicyExists(true),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVMStoragePolicyConfig("Updated by Terraform", false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVMStoragePolicyExists(true),
+					testAccResourceVSphereVMStoragePolicyHasDescription("Updated by Terraform"),
+					resource.TestCheckResourceAttr("vsphere_vm_storage_policy.policy", "tag_rules.0.include_datastores_with_tags", "false"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVMStoragePolicy_deletedOutOfBand(t *testing.T) {
+	testAccResourceTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccSkipIfEsxi(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVMStoragePolicyExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVMStoragePolicyConfig("Managed by Terraform", true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVMStoragePolicyExists(true),
+					testAccResourceVSphereVMStoragePolicyDelete,
+				),
+				// The refresh after the policy is deleted removes it from state,
+				// so that the next plan creates it again.
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func testAccResourceVSphereVMStoragePolicyExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		profile, err := testGetVMStoragePolicy(s, "policy")
+		if err != nil {
+			if !expected {
+				// Expected missing
+				return nil
+			}
+			return err
+		}
+		if !expected && profile != nil {
+			return errors.New("expected storage policy to be missing")
+		}
+		if expected && profile == nil {
+			return errors.New("storage policy not found")
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereVMStoragePolicyHasDescription(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		profile, err := testGetVMStoragePolicy(s, "policy")
+		if err != nil {
+			return err
+		}
+		if profile == nil {
+			return errors.New("storage policy not found")
+		}
+		if expected != profile.Description {
+			return fmt.Errorf("expected description to be %q, got %q", expected, profile.Description)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVMStoragePolicyDelete deletes the storage policy
+// outside of Terraform.
+func testAccResourceVSphereVMStoragePolicyDelete(s *terraform.State) error {
+	tVars, err := testClientVariablesForResource(s, "vsphere_vm_storage_policy.policy")
+	if err != nil {
+		return err
+	}
+	return spbm.Delete(testAccProvider.Meta().(*VSphereClient).pbmClient, tVars.resourceID)
+}
+
+// testGetVMStoragePolicy gets the storage policy for the vsphere_vm_storage_policy
+// resource with the supplied name.
+func testGetVMStoragePolicy(s *terraform.State, resourceName string) (*pbmtypes.PbmCapabilityProfile, error) {
+	tVars, err := testClientVariablesForResource(s, fmt.Sprintf("vsphere_vm_storage_policy.%s", resourceName))
+	if err != nil {
+		return nil, err
+	}
+	return spbm.PolicyByID(testAccProvider.Meta().(*VSphereClient).pbmClient, tVars.resourceID)
+}
+
+func testAccResourceVSphereVMStoragePolicyConfig(description string, include bool) string {
+	return fmt.Sprintf(`
+variable "description" {
+  default = "%s"
+}
+
+variable "include" {
+  default = "%t"
+}
+
+resource "vsphere_tag_category" "category" {
+  name        = "terraform-test-policy-category"
+  cardinality = "MULTIPLE"
+
+  associable_types = [
+    "Datastore",
+  ]
+}
+
+resource "vsphere_tag" "tag" {
+  name        = "terraform-test-policy-tag"
+  category_id = "${vsphere_tag_category.category.id}"
+}
+
+resource "vsphere_vm_storage_policy" "policy" {
+  name        = "terraform-test-policy"
+  description = "${var.description}"
+
+  tag_rules {
+    tag_category_id              = "${vsphere_tag_category.category.id}"
+    tag_ids                      = ["${vsphere_tag.tag.id}"]
+    include_datastores_with_tags = "${var.include}"
+  }
+}
+`,
+		description,
+		include,
+	)
+}
@@ -0,0 +1,176 @@
+package vsphere
+
+import (
+	"crypto/tls"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	pbmsimulator "github.com/vmware/govmomi/pbm/simulator"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/simulator/vpx"
+	vapisimulator "github.com/vmware/govmomi/vapi/simulator"
+)
+
+// testSimulatorTests is the list of acceptance tests that are run against the
+// vCenter simulator by TestSimulator. Only add tests here that pass against the
+// simulator's inventory model and API coverage, and that run their test case
+// with testAccResourceTest.
+var testSimulatorTests = []struct {
+	name string
+	f    func(*testing.T)
+}{
+	{"TestAccDataSourceVSphereContentLibrary_basic", TestAccDataSourceVSphereContentLibrary_basic},
+	{"TestAccDataSourceVSphereCustomAttribute_basic", TestAccDataSourceVSphereCustomAttribute_basic},
+	{"TestAccDataSourceVSphereDatacenter_basic", TestAccDataSourceVSphereDatacenter_basic},
+	{"TestAccDataSourceVSphereDatacenter_defaultDatacenter", TestAccDataSourceVSphereDatacenter_defaultDatacenter},
+	{"TestAccDataSourceVSphereHost_basic", TestAccDataSourceVSphereHost_basic},
+	{"TestAccDataSourceVSphereResourcePool_emptyNameOnVCenterShouldError", TestAccDataSourceVSphereResourcePool_emptyNameOnVCenterShouldError},
+	{"TestAccDataSourceVSphereTagCategory_basic", TestAccDataSourceVSphereTagCategory_basic},
+	{"TestAccDataSourceVSphereTag_basic", TestAccDataSourceVSphereTag_basic},
+	{"TestAccResourceVSphereContentLibrary_basic", TestAccResourceVSphereContentLibrary_basic},
+	{"TestAccResourceVSphereCustomAttribute_basic", TestAccResourceVSphereCustomAttribute_basic},
+	{"TestAccResourceVSphereCustomAttribute_changeType", TestAccResourceVSphereCustomAttribute_changeType},
+	{"TestAccResourceVSphereCustomAttribute_rename", TestAccResourceVSphereCustomAttribute_rename},
+	{"TestAccResourceVSphereCustomAttribute_withType", TestAccResourceVSphereCustomAttribute_withType},
+	{"TestAccResourceVSphereDatacenter_createOnRootFolder", TestAccResourceVSphereDatacenter_createOnRootFolder},
+	{"TestAccResourceVSphereDatacenter_createOnSubfolder", TestAccResourceVSphereDatacenter_createOnSubfolder},
+	{"TestAccResourceVSphereDatacenter_modifyCustomAttribute", TestAccResourceVSphereDatacenter_modifyCustomAttribute},
+	{"TestAccResourceVSphereDatacenter_modifyTags", TestAccResourceVSphereDatacenter_modifyTags},
+	{"TestAccResourceVSphereDatacenter_singleCustomAttribute", TestAccResourceVSphereDatacenter_singleCustomAttribute},
+	{"TestAccResourceVSphereDatacenter_singleTag", TestAccResourceVSphereDatacenter_singleTag},
+	{"TestAccResourceVSphereFile_basic", TestAccResourceVSphereFile_basic},
+	{"TestAccResourceVSphereFile_basicUploadAndCopy", TestAccResourceVSphereFile_basicUploadAndCopy},
+	{"TestAccResourceVSphereFile_renamePostCreation", TestAccResourceVSphereFile_renamePostCreation},
+	{"TestAccResourceVSphereFile_uploadAndCopyAndUpdate", TestAccResourceVSphereFile_uploadAndCopyAndUpdate},
+	{"TestAccResourceVSphereFolder_customAttributes", TestAccResourceVSphereFolder_customAttributes},
+	{"TestAccResourceVSphereFolder_datacenterFolder", TestAccResourceVSphereFolder_datacenterFolder},
+	{"TestAccResourceVSphereFolder_datastoreFolder", TestAccResourceVSphereFolder_datastoreFolder},
+	{"TestAccResourceVSphereFolder_hostFolder", TestAccResourceVSphereFolder_hostFolder},
+	{"TestAccResourceVSphereFolder_modifyCustomAttributes", TestAccResourceVSphereFolder_modifyCustomAttributes},
+	{"TestAccResourceVSphereFolder_modifyTags", TestAccResourceVSphereFolder_modifyTags},
+	{"TestAccResourceVSphereFolder_modifyTagsMultiStage", TestAccResourceVSphereFolder_modifyTagsMultiStage},
+	{"TestAccResourceVSphereFolder_moveToSubfolder", TestAccResourceVSphereFolder_moveToSubfolder},
+	{"TestAccResourceVSphereFolder_networkFolder", TestAccResourceVSphereFolder_networkFolder},
+	{"TestAccResourceVSphereFolder_preventDeleteIfNotEmpty", TestAccResourceVSphereFolder_preventDeleteIfNotEmpty},
+	{"TestAccResourceVSphereFolder_rename", TestAccResourceVSphereFolder_rename},
+	{"TestAccResourceVSphereFolder_subfolder", TestAccResourceVSphereFolder_subfolder},
+	{"TestAccResourceVSphereFolder_tags", TestAccResourceVSphereFolder_tags},
+	{"TestAccResourceVSphereFolder_vmFolder", TestAccResourceVSphereFolder_vmFolder},
+	{"TestAccResourceVSphereResourcePool_basic", TestAccResourceVSphereResourcePool_basic},
+	{"TestAccResourceVSphereResourcePool_updateRename", TestAccResourceVSphereResourcePool_updateRename},
+	{"TestAccResourceVSphereVMStoragePolicy_basic", TestAccResourceVSphereVMStoragePolicy_basic},
+	{"TestAccResourceVSphereVMStoragePolicy_deletedOutOfBand", TestAccResourceVSphereVMStoragePolicy_deletedOutOfBand},
+}
+
+// testSimulatorRunning is true while TestSimulator runs a test against the
+// simulator.
+var testSimulatorRunning bool
+
+// TestSimulator runs the acceptance tests in testSimulatorTests against an
+// in-process vCenter simulator, so that they can be run with a plain go test,
+// without a lab. Each test gets a fresh simulator. The test is skipped when
+// TF_ACC is set, as the acceptance tests then run against the environment
+// configured through the usual VSPHERE_* variables instead.
+func TestSimulator(t *testing.T) {
+	if os.Getenv(resource.TestEnvVar) != "" {
+		t.Skipf("%s is set; skipping simulator tests", resource.TestEnvVar)
+	}
+	for _, tc := range testSimulatorTests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			defer testSimulatorStart(t)()
+			tc.f(t)
+		})
+	}
+}
+
+// testAccResourceTest runs the supplied acceptance test case. Against the
+// simulator, the case is run as a unit test, which the test harness runs
+// without TF_ACC or the -v flag. Otherwise, it is run as a regular acceptance
+// test.
+func testAccResourceTest(t *testing.T, c resource.TestCase) {
+	if testSimulatorRunning {
+		resource.UnitTest(t, c)
+		return
+	}
+	resource.Test(t, c)
+}
+
+// testSimulatorStart starts a vCenter simulator, modelling a datacenter with a
+// cluster of hosts, a standalone host, a datastore, and a distributed virtual
+// switch with a port group. The vAPI (tags and content library) and storage
+// policy endpoints are served as well.
+//
+// The VSPHERE_* variables read by the provider and by the acceptance tests are
+// pointed at the simulator's inventory, and testSimulatorRunning is set so
+// that the test cases run as unit tests. The returned function stops the
+// simulator and restores the environment.
+func testSimulatorStart(t *testing.T) func() {
+	t.Helper()
+	model := simulator.VPX()
+	model.Host = 1
+	model.Cluster = 1
+	model.ClusterHost = 3
+	model.Datastore = 1
+	model.Portgroup = 1
+	model.Machine = 0
+	if err := model.Create(); err != nil {
+		t.Fatalf("error creating simulator model: %s", err)
+	}
+	model.Service.TLS = new(tls.Config)
+	server := model.Service.NewServer()
+
+	path, handler := vapisimulator.New(server.URL, vpx.Setting)
+	model.Service.Handle(path, handler)
+	model.Service.RegisterSDK(pbmsimulator.New())
+
+	password, _ := server.URL.User.Password()
+	restore := testSimulatorSetenv(map[string]string{
+		"VSPHERE_SERVER":               server.URL.Host,
+		"VSPHERE_USER":                 server.URL.User.Username(),
+		"VSPHERE_PASSWORD":             password,
+		"VSPHERE_ALLOW_UNVERIFIED_SSL": "true",
+		"VSPHERE_PERSIST_SESSION":      "false",
+		"VSPHERE_TEST_ESXI":            "",
+		"VSPHERE_DATACENTER":           "DC0",
+		"VSPHERE_CLUSTER":              "DC0_C0",
+		"VSPHERE_ESXI_HOST":            "DC0_H0",
+		"VSPHERE_ESXI_HOST5":           "DC0_H0",
+		"VSPHERE_DATASTORE":            "LocalDS_0",
+		"VSPHERE_RESOURCE_POOL":        "DC0_C0/Resources",
+		"VSPHERE_NETWORK_LABEL":        "VM Network",
+		"VSPHERE_NETWORK_LABEL_PXE":    "DC0_DVPG0",
+	})
+
+	testSimulatorRunning = true
+	return func() {
+		testSimulatorRunning = false
+		restore()
+		server.Close()
+		model.Remove()
+	}
+}
+
+// testSimulatorSetenv sets the supplied environment variables, and returns a
+// function that restores their previous values.
+func testSimulatorSetenv(env map[string]string) func() {
+	old := make(map[string]*string)
+	for k, v := range env {
+		if ov, ok := os.LookupEnv(k); ok {
+			old[k] = &ov
+		} else {
+			old[k] = nil
+		}
+		os.Setenv(k, v)
+	}
+	return func() {
+		for k, v := range old {
+			if v == nil {
+				os.Unsetenv(k)
+				continue
+			}
+			os.Setenv(k, *v)
+		}
+	}
+}
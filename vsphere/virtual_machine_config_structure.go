@@ -1,13 +1,19 @@
 package vsphere
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"log"
 	"reflect"
+	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/hashicorp/terraform/terraform"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/spbm"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/virtualmachine"
@@ -47,6 +53,25 @@ var virtualMachineLatencySensitivityAllowedValues = []string{
 	string(types.LatencySensitivitySensitivityLevelHigh),
 }
 
+const (
+	cloudInitEncoding       = "gzip+base64"
+	cloudInitMetadataKey    = "guestinfo.metadata"
+	cloudInitMetadataEncKey = "guestinfo.metadata.encoding"
+	cloudInitUserdataKey    = "guestinfo.userdata"
+	cloudInitUserdataEncKey = "guestinfo.userdata.encoding"
+	cloudInitNetworkKey     = "network"
+	cloudInitNetworkEncKey  = "network.encoding"
+)
+
+// cloudInitExtraConfigKeys are the extra_config keys that are managed by the
+// cloud_init sub-resource.
+var cloudInitExtraConfigKeys = []string{
+	cloudInitMetadataKey,
+	cloudInitMetadataEncKey,
+	cloudInitUserdataKey,
+	cloudInitUserdataEncKey,
+}
+
 // getWithRestart fetches the resoruce data specified at key. If the value has
 // changed, a reboot is flagged in the virtual machine by setting
 // reboot_required to true.
@@ -202,6 +227,16 @@ func schemaVirtualMachineConfigSpec() map[string]*schema.Schema {
 			Optional:    true,
 			Description: "Enable nested hardware virtualization on this virtual machine, facilitating nested virtualization in the guest.",
 		},
+		"vbs_enabled": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Description: "Enable virtualization-based security on this virtual machine. Requires efi firmware with secure boot, vvtd_enabled, and nested_hv_enabled.",
+		},
+		"vvtd_enabled": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Description: "Expose the Intel virtualization technology for directed I/O (IOMMU) to the guest.",
+		},
 		"cpu_performance_counters_enabled": {
 			Type:        schema.TypeBool,
 			Optional:    true,
@@ -254,6 +289,13 @@ func schemaVirtualMachineConfigSpec() map[string]*schema.Schema {
 			Description: "Extra configuration data for this virtual machine. Can be used to supply advanced parameters not normally in configuration, such as instance metadata, or configuration data for OVF images.",
 			Elem:        &schema.Schema{Type: schema.TypeString},
 		},
+		"cloud_init": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "Cloud-init metadata and userdata for this virtual machine, supplied through the guestinfo keys read by the cloud-init VMware datasource.",
+			MaxItems:    1,
+			Elem:        &schema.Resource{Schema: cloudInitSubresourceSchema()},
+		},
 		"vapp": {
 			Type:        schema.TypeList,
 			Optional:    true,
@@ -297,6 +339,28 @@ func vAppSubresourceSchema() map[string]*schema.Schema {
 	}
 }
 
+// cloudInitSubresourceSchema represents the schema for the cloud_init
+// sub-resource.
+func cloudInitSubresourceSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"metadata": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The cloud-init metadata, in YAML or JSON format.",
+		},
+		"network_config": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The cloud-init network configuration, in YAML or JSON format. This is embedded in the metadata.",
+		},
+		"userdata": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The cloud-init userdata, such as a cloud-config document or a script.",
+		},
+	}
+}
+
 // expandVirtualMachineBootOptions reads certain ResourceData keys and
 // returns a VirtualMachineBootOptions.
 func expandVirtualMachineBootOptions(d *schema.ResourceData, client *govmomi.Client) *types.VirtualMachineBootOptions {
@@ -331,6 +395,8 @@ func expandVirtualMachineFlagInfo(d *schema.ResourceData) *types.VirtualMachineF
 		VirtualExecUsage: getWithRestart(d, "hv_mode").(string),
 		VirtualMmuUsage:  getWithRestart(d, "ept_rvi_mode").(string),
 		EnableLogging:    getBoolWithRestart(d, "enable_logging"),
+		VbsEnabled:       getBoolWithRestart(d, "vbs_enabled"),
+		VvtdEnabled:      getBoolWithRestart(d, "vvtd_enabled"),
 	}
 	return obj
 }
@@ -342,6 +408,8 @@ func flattenVirtualMachineFlagInfo(d *schema.ResourceData, obj *types.VirtualMac
 	d.Set("hv_mode", obj.VirtualExecUsage)
 	d.Set("ept_rvi_mode", obj.VirtualMmuUsage)
 	d.Set("enable_logging", obj.EnableLogging)
+	d.Set("vbs_enabled", obj.VbsEnabled)
+	d.Set("vvtd_enabled", obj.VvtdEnabled)
 	return nil
 }
 
@@ -562,6 +630,10 @@ func flattenExtraConfig(d *schema.ResourceData, opts []types.BaseOptionValue) er
 	ec := make(map[string]interface{})
 	for _, v := range opts {
 		ov := v.GetOptionValue()
+		if len(d.Get("cloud_init").([]interface{})) > 0 && isCloudInitExtraConfigKey(ov.Key) {
+			// Managed by cloud_init
+			continue
+		}
 		for k := range d.Get("extra_config").(map[string]interface{}) {
 			if ov.Key == k {
 				ec[ov.Key] = ov.Value
@@ -571,6 +643,142 @@ func flattenExtraConfig(d *schema.ResourceData, opts []types.BaseOptionValue) er
 	return d.Set("extra_config", ec)
 }
 
+// expandCloudInitConfig renders the cloud_init sub-resource into the
+// guestinfo extraConfig keys read by the cloud-init VMware datasource. The
+// metadata and userdata are gzipped and base64 encoded, and the network
+// configuration is embedded in the metadata in the same fashion.
+//
+// If the sub-resource has been removed, the keys are added with an empty
+// value to remove them from extraConfig, similar to expandExtraConfig.
+func expandCloudInitConfig(d *schema.ResourceData) ([]types.BaseOptionValue, error) {
+	if !d.HasChange("cloud_init") {
+		return nil, nil
+	}
+	values := make(map[string]string)
+	for _, k := range cloudInitExtraConfigKeys {
+		values[k] = ""
+	}
+	if ci := d.Get("cloud_init").([]interface{}); len(ci) > 0 && ci[0] != nil {
+		m := ci[0].(map[string]interface{})
+		metadata, err := cloudInitMetadata(m["metadata"].(string), m["network_config"].(string))
+		if err != nil {
+			return nil, err
+		}
+		if metadata != "" {
+			if values[cloudInitMetadataKey], err = cloudInitEncode(metadata); err != nil {
+				return nil, err
+			}
+			values[cloudInitMetadataEncKey] = cloudInitEncoding
+		}
+		if userdata := m["userdata"].(string); userdata != "" {
+			if values[cloudInitUserdataKey], err = cloudInitEncode(userdata); err != nil {
+				return nil, err
+			}
+			values[cloudInitUserdataEncKey] = cloudInitEncoding
+		}
+	}
+
+	// Only nil out keys that we could have set previously.
+	o, _ := d.GetChange("cloud_init")
+	hadOld := len(o.([]interface{})) > 0
+	var opts []types.BaseOptionValue
+	for _, k := range cloudInitExtraConfigKeys {
+		if values[k] == "" && !hadOld {
+			continue
+		}
+		opts = append(opts, &types.OptionValue{
+			Key:   k,
+			Value: values[k],
+		})
+	}
+	return opts, nil
+}
+
+// cloudInitMetadata embeds the supplied network configuration into the
+// supplied metadata, if any. Metadata in JSON format is modified as JSON,
+// otherwise any network keys already in the metadata are removed and the
+// network keys are appended to the metadata as YAML.
+func cloudInitMetadata(metadata, network string) (string, error) {
+	if network == "" {
+		return metadata, nil
+	}
+	enc, err := cloudInitEncode(network)
+	if err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(strings.TrimSpace(metadata), "{") {
+		m := make(map[string]interface{})
+		if err := json.Unmarshal([]byte(metadata), &m); err != nil {
+			return "", fmt.Errorf("error parsing cloud_init metadata as JSON: %s", err)
+		}
+		m[cloudInitNetworkKey] = enc
+		m[cloudInitNetworkEncKey] = cloudInitEncoding
+		b, err := json.Marshal(m)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+	metadata = cloudInitRemoveYAMLKeys(metadata, cloudInitNetworkKey, cloudInitNetworkEncKey)
+	if metadata != "" && !strings.HasSuffix(metadata, "\n") {
+		metadata += "\n"
+	}
+	return fmt.Sprintf("%s%s: %s\n%s: %s\n", metadata, cloudInitNetworkKey, enc, cloudInitNetworkEncKey, cloudInitEncoding), nil
+}
+
+// cloudInitRemoveYAMLKeys removes the supplied top-level keys from YAML
+// metadata, along with their values. A value is made up of the rest of the
+// line of the key and any following lines that are blank, indented, or items
+// of a sequence at the same level as the key.
+func cloudInitRemoveYAMLKeys(metadata string, keys ...string) string {
+	isKey := func(line string) bool {
+		for _, k := range keys {
+			for _, q := range []string{k, `"` + k + `"`, "'" + k + "'"} {
+				if strings.HasPrefix(line, q) && strings.HasPrefix(strings.TrimLeft(line[len(q):], " \t"), ":") {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	var out []string
+	var skip bool
+	for _, line := range strings.SplitAfter(metadata, "\n") {
+		if skip && (strings.TrimSpace(line) == "" || strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "- ") || strings.TrimSpace(line) == "-") {
+			continue
+		}
+		skip = isKey(line)
+		if !skip {
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "")
+}
+
+// cloudInitEncode gzips and base64 encodes the supplied string.
+func cloudInitEncode(s string) (string, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write([]byte(s)); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// isCloudInitExtraConfigKey returns true if the supplied extraConfig key is
+// managed by the cloud_init sub-resource.
+func isCloudInitExtraConfigKey(key string) bool {
+	for _, k := range cloudInitExtraConfigKeys {
+		if key == k {
+			return true
+		}
+	}
+	return false
+}
+
 // expandVAppConfig reads in all the vapp key/value pairs and returns
 // the appropriate VmConfigSpec.
 //
@@ -607,7 +815,7 @@ func expandVAppConfig(d *schema.ResourceData, client *govmomi.Client) (*types.Vm
 		// workflow, so if there are any defined, return an error indicating such.
 		// Return with a no-op otherwise.
 		if len(newMap) > 0 {
-			return nil, fmt.Errorf("vApp properties can only be set on cloned virtual machines")
+			return nil, fmt.Errorf("vApp properties can only be set on cloned or OVF deployed virtual machines")
 		}
 		return nil, nil
 	}
@@ -760,6 +968,10 @@ func expandVirtualMachineConfigSpec(d *schema.ResourceData, client *govmomi.Clie
 	if err != nil {
 		return types.VirtualMachineConfigSpec{}, err
 	}
+	cloudInitConfig, err := expandCloudInitConfig(d)
+	if err != nil {
+		return types.VirtualMachineConfigSpec{}, err
+	}
 
 	obj := types.VirtualMachineConfigSpec{
 		Name:                         d.Get("name").(string),
@@ -777,7 +989,7 @@ func expandVirtualMachineConfigSpec(d *schema.ResourceData, client *govmomi.Clie
 		CpuAllocation:                expandVirtualMachineResourceAllocation(d, "cpu"),
 		MemoryAllocation:             expandVirtualMachineResourceAllocation(d, "memory"),
 		MemoryReservationLockedToMax: getMemoryReservationLockedToMax(d),
-		ExtraConfig:                  expandExtraConfig(d),
+		ExtraConfig:                  append(expandExtraConfig(d), cloudInitConfig...),
 		SwapPlacement:                getWithRestart(d, "swap_placement_policy").(string),
 		BootOptions:                  expandVirtualMachineBootOptions(d, client),
 		VAppConfig:                   vappConfig,
@@ -785,11 +997,22 @@ func expandVirtualMachineConfigSpec(d *schema.ResourceData, client *govmomi.Clie
 		NestedHVEnabled:              getBoolWithRestart(d, "nested_hv_enabled"),
 		VPMCEnabled:                  getBoolWithRestart(d, "cpu_performance_counters_enabled"),
 		LatencySensitivity:           expandLatencySensitivity(d),
+		VmProfile:                    expandVirtualMachineProfileSpec(d),
 	}
 
 	return obj, nil
 }
 
+// expandVirtualMachineProfileSpec returns the storage policy spec for the
+// virtual machine home. The spec is only sent when storage_policy_id has
+// changed, as the policy is read back through SPBM and not the config info.
+func expandVirtualMachineProfileSpec(d *schema.ResourceData) []types.BaseVirtualMachineProfileSpec {
+	if policyID := d.Get("storage_policy_id").(string); d.HasChange("storage_policy_id") && policyID != "" {
+		return spbm.PolicySpecByID(policyID)
+	}
+	return nil
+}
+
 // flattenVirtualMachineConfigInfo reads various fields from a
 // VirtualMachineConfigInfo into the passed in ResourceData.
 //
@@ -0,0 +1,176 @@
+package vsphere
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func testCloudInitDecode(t *testing.T, s string) string {
+	t.Helper()
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		t.Fatalf("error decoding base64: %s", err)
+	}
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("error opening gzip stream: %s", err)
+	}
+	out, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("error reading gzip stream: %s", err)
+	}
+	return string(out)
+}
+
+func testCloudInitEncode(t *testing.T, s string) string {
+	t.Helper()
+	enc, err := cloudInitEncode(s)
+	if err != nil {
+		t.Fatalf("error encoding %q: %s", s, err)
+	}
+	return enc
+}
+
+func TestCloudInitEncode(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  "empty",
+			input: "",
+		},
+		{
+			name:  "single line",
+			input: "local-hostname: foo",
+		},
+		{
+			name:  "cloud-config",
+			input: "#cloud-config\nusers:\n  - name: foo\n    ssh_authorized_keys:\n      - ssh-rsa AAAA\n",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := testCloudInitDecode(t, testCloudInitEncode(t, tc.input))
+			if tc.input != actual {
+				t.Fatalf("expected %q, got %q", tc.input, actual)
+			}
+		})
+	}
+}
+
+func TestCloudInitMetadata(t *testing.T) {
+	network := "version: 2\nethernets:\n  ens192:\n    dhcp4: true\n"
+	suffix := "network: " + testCloudInitEncode(t, network) + "\nnetwork.encoding: gzip+base64\n"
+	cases := []struct {
+		name     string
+		metadata string
+		network  string
+		expected string
+	}{
+		{
+			name:     "no network config",
+			metadata: "local-hostname: foo\n",
+			expected: "local-hostname: foo\n",
+		},
+		{
+			name:     "network config only",
+			network:  network,
+			expected: suffix,
+		},
+		{
+			name:     "no trailing newline",
+			metadata: "local-hostname: foo",
+			network:  network,
+			expected: "local-hostname: foo\n" + suffix,
+		},
+		{
+			name:     "existing network block",
+			metadata: "network:\n  version: 2\n  ethernets:\n    ens192:\n      dhcp4: false\n\nlocal-hostname: foo\n",
+			network:  network,
+			expected: "local-hostname: foo\n" + suffix,
+		},
+		{
+			name:     "existing encoded network keys",
+			metadata: "instance-id: bar\n\"network\": abc\nnetwork.encoding : base64\nlocal-hostname: foo\n",
+			network:  network,
+			expected: "instance-id: bar\nlocal-hostname: foo\n" + suffix,
+		},
+		{
+			name:     "existing network sequence",
+			metadata: "network:\n- a\n- b\nlocal-hostname: foo\n",
+			network:  network,
+			expected: "local-hostname: foo\n" + suffix,
+		},
+		{
+			name:     "similar keys kept",
+			metadata: "networks: foo\n  network: bar\n",
+			network:  network,
+			expected: "networks: foo\n  network: bar\n" + suffix,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := cloudInitMetadata(tc.metadata, tc.network)
+			if err != nil {
+				t.Fatalf("bad: %s", err)
+			}
+			if tc.expected != actual {
+				t.Fatalf("expected %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCloudInitMetadataJSON(t *testing.T) {
+	network := `{"version": 2}`
+	cases := []struct {
+		name     string
+		metadata string
+		expected map[string]interface{}
+	}{
+		{
+			name:     "no network keys",
+			metadata: `{"local-hostname": "foo"}`,
+			expected: map[string]interface{}{
+				"local-hostname":   "foo",
+				"network":          testCloudInitEncode(t, network),
+				"network.encoding": "gzip+base64",
+			},
+		},
+		{
+			name:     "existing network keys",
+			metadata: ` {"local-hostname": "foo", "network": {"version": 1}, "network.encoding": "base64"}`,
+			expected: map[string]interface{}{
+				"local-hostname":   "foo",
+				"network":          testCloudInitEncode(t, network),
+				"network.encoding": "gzip+base64",
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := cloudInitMetadata(tc.metadata, network)
+			if err != nil {
+				t.Fatalf("bad: %s", err)
+			}
+			actual := make(map[string]interface{})
+			if err := json.Unmarshal([]byte(out), &actual); err != nil {
+				t.Fatalf("error parsing %q: %s", out, err)
+			}
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Fatalf("expected %#v, got %#v", tc.expected, actual)
+			}
+		})
+	}
+	t.Run("invalid", func(t *testing.T) {
+		if _, err := cloudInitMetadata(`{"local-hostname": `, network); err == nil {
+			t.Fatal("expected error, got none")
+		}
+	})
+}
@@ -0,0 +1,226 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/cryptomanager"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// schemaVirtualMachineEncryption returns the schema for the encryption
+// sub-resource of vsphere_virtual_machine and vsphere_virtual_disk. When
+// forceNew is set, changing the key provider forces a new resource.
+func schemaVirtualMachineEncryption(forceNew bool) map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"key_provider_id": {
+			Type:        schema.TypeString,
+			Required:    true,
+			ForceNew:    forceNew,
+			Description: "The ID of the key provider to generate the encryption key with.",
+		},
+		"key_id": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "The ID of the key that the object is encrypted with.",
+		},
+	}
+}
+
+// encryptionKeyProviderID returns the ID of the key provider in the
+// encryption sub-resource, or an empty string if encryption is not configured.
+func encryptionKeyProviderID(d *schema.ResourceData) string {
+	if e := d.Get("encryption").([]interface{}); len(e) > 0 && e[0] != nil {
+		return e[0].(map[string]interface{})["key_provider_id"].(string)
+	}
+	return ""
+}
+
+// flattenCryptoKeyID flattens a CryptoKeyId into the encryption sub-resource.
+// A nil key ID results in an empty sub-resource.
+func flattenCryptoKeyID(keyID *types.CryptoKeyId) []interface{} {
+	if keyID == nil || keyID.ProviderId == nil {
+		return nil
+	}
+	return []interface{}{
+		map[string]interface{}{
+			"key_provider_id": keyID.ProviderId.Id,
+			"key_id":          keyID.KeyId,
+		},
+	}
+}
+
+// expandVirtualMachineCryptoSpec compares the encryption sub-resource to the
+// key that the virtual machine is currently encrypted with, and returns the
+// crypto spec to apply to the virtual machine home and the desired encryption
+// of its disks. A nil virtual machine spec means that the encryption of the
+// home does not need to change. The disk spec is either a CryptoSpecEncrypt
+// with the key that the disks should be encrypted with, a CryptoSpecDecrypt,
+// or nil when the disks are to be left alone. Whether an individual disk
+// actually needs to change is decided by virtualDiskCrypto.
+//
+// Encrypting or decrypting a virtual machine requires it to be powered off,
+// so reboot_required is set in those cases. Changing the key provider of an
+// encrypted virtual machine is done with a shallow rekey, which can be done
+// while the virtual machine is powered on.
+func expandVirtualMachineCryptoSpec(d *schema.ResourceData, client *govmomi.Client, current *types.CryptoKeyId) (types.BaseCryptoSpec, types.BaseCryptoSpec, error) {
+	want := encryptionKeyProviderID(d)
+	switch {
+	case current == nil && want == "":
+		return nil, nil, nil
+	case current == nil:
+		key, err := cryptomanager.GenerateKey(client, want)
+		if err != nil {
+			return nil, nil, err
+		}
+		log.Printf("[DEBUG] %s: Encrypting virtual machine with key %q", resourceVSphereVirtualMachineIDString(d), key.KeyId)
+		d.Set("reboot_required", true)
+		spec := &types.CryptoSpecEncrypt{CryptoKeyId: *key}
+		return spec, spec, nil
+	case want == "":
+		// The virtual machine home is encrypted with the default key provider
+		// when a virtual TPM is added, so it is left alone when the encryption
+		// sub-resource is only absent because a virtual TPM is in use.
+		if len(d.Get("vtpm").([]interface{})) > 0 {
+			return nil, nil, nil
+		}
+		log.Printf("[DEBUG] %s: Decrypting virtual machine", resourceVSphereVirtualMachineIDString(d))
+		d.Set("reboot_required", true)
+		spec := &types.CryptoSpecDecrypt{}
+		return spec, spec, nil
+	case current.ProviderId == nil || current.ProviderId.Id != want:
+		key, err := cryptomanager.GenerateKey(client, want)
+		if err != nil {
+			return nil, nil, err
+		}
+		log.Printf("[DEBUG] %s: Rekeying virtual machine with key %q", resourceVSphereVirtualMachineIDString(d), key.KeyId)
+		return &types.CryptoSpecShallowRecrypt{NewKeyId: *key}, &types.CryptoSpecEncrypt{CryptoKeyId: *key}, nil
+	}
+	return nil, &types.CryptoSpecEncrypt{CryptoKeyId: *current}, nil
+}
+
+// applyVirtualMachineDiskCrypto applies the disk crypto spec returned by
+// expandVirtualMachineCryptoSpec to the disks of a virtual machine. New disks
+// are encrypted whenever the virtual machine is encrypted. Existing disks, in
+// the supplied device list, are checked against the current key of each disk,
+// and an edit operation is added for any disk that needs to change and does
+// not have one in the device change spec already. This catches disks that
+// are not encrypted even though the virtual machine home is, such as disks
+// that were added outside of Terraform.
+//
+// The returned bool is true if an existing disk is encrypted or decrypted,
+// which requires the virtual machine to be powered off.
+func applyVirtualMachineDiskCrypto(
+	l object.VirtualDeviceList,
+	spec []types.BaseVirtualDeviceConfigSpec,
+	diskCrypto types.BaseCryptoSpec,
+) ([]types.BaseVirtualDeviceConfigSpec, bool, error) {
+	if diskCrypto == nil {
+		return spec, false, nil
+	}
+	newDiskCrypto, _ := diskCrypto.(*types.CryptoSpecEncrypt)
+
+	var powerOff bool
+	seen := make(map[int32]bool)
+	for _, v := range spec {
+		cspec := v.GetVirtualDeviceConfigSpec()
+		disk, ok := cspec.Device.(*types.VirtualDisk)
+		if !ok {
+			continue
+		}
+		seen[disk.Key] = true
+		switch {
+		case cspec.Operation == types.VirtualDeviceConfigSpecOperationAdd && cspec.FileOperation == types.VirtualDeviceConfigSpecFileOperationCreate:
+			if newDiskCrypto != nil {
+				cspec.Backing = &types.VirtualDeviceConfigSpecBackingSpec{Crypto: newDiskCrypto}
+			}
+		case cspec.Operation == types.VirtualDeviceConfigSpecOperationEdit:
+			if c := virtualDiskCrypto(disk, diskCrypto); c != nil {
+				cspec.Backing = &types.VirtualDeviceConfigSpecBackingSpec{Crypto: c}
+				powerOff = powerOff || virtualDiskCryptoRequiresPowerOff(c)
+			}
+		}
+	}
+
+	for _, dev := range l.SelectByType((*types.VirtualDisk)(nil)) {
+		disk := dev.(*types.VirtualDisk)
+		if seen[disk.Key] {
+			continue
+		}
+		c := virtualDiskCrypto(disk, diskCrypto)
+		if c == nil {
+			continue
+		}
+		dspec, err := object.VirtualDeviceList{disk}.ConfigSpec(types.VirtualDeviceConfigSpecOperationEdit)
+		if err != nil {
+			return nil, false, err
+		}
+		dspec[0].GetVirtualDeviceConfigSpec().Backing = &types.VirtualDeviceConfigSpecBackingSpec{Crypto: c}
+		log.Printf("[DEBUG] applyVirtualMachineDiskCrypto: Changing encryption of disk %s", l.Name(disk))
+		powerOff = powerOff || virtualDiskCryptoRequiresPowerOff(c)
+		spec = append(spec, dspec...)
+	}
+	return spec, powerOff, nil
+}
+
+// virtualDiskCrypto returns the crypto spec to apply to an existing disk,
+// based on the key that the disk is currently encrypted with. For a
+// CryptoSpecEncrypt target, unencrypted disks are encrypted with the target
+// key, and disks that are encrypted through a different key provider are
+// rekeyed with a shallow recrypt. For a CryptoSpecDecrypt target, encrypted
+// disks are decrypted. nil is returned if the disk does not need to change.
+func virtualDiskCrypto(disk *types.VirtualDisk, target types.BaseCryptoSpec) types.BaseCryptoSpec {
+	var keyID *types.CryptoKeyId
+	switch backing := disk.Backing.(type) {
+	case *types.VirtualDiskFlatVer2BackingInfo:
+		keyID = backing.KeyId
+	case *types.VirtualDiskSeSparseBackingInfo:
+		keyID = backing.KeyId
+	default:
+		// Other backings, such as raw device mappings, cannot be encrypted.
+		return nil
+	}
+	switch t := target.(type) {
+	case *types.CryptoSpecEncrypt:
+		switch {
+		case keyID == nil:
+			return t
+		case keyID.ProviderId == nil || t.CryptoKeyId.ProviderId == nil || keyID.ProviderId.Id != t.CryptoKeyId.ProviderId.Id:
+			return &types.CryptoSpecShallowRecrypt{NewKeyId: t.CryptoKeyId}
+		}
+	case *types.CryptoSpecDecrypt:
+		if keyID != nil {
+			return t
+		}
+	}
+	return nil
+}
+
+// virtualDiskCryptoRequiresPowerOff returns true if the supplied disk crypto
+// spec can only be applied while the virtual machine is powered off.
+func virtualDiskCryptoRequiresPowerOff(c types.BaseCryptoSpec) bool {
+	switch c.(type) {
+	case *types.CryptoSpecEncrypt, *types.CryptoSpecDecrypt:
+		return true
+	}
+	return false
+}
+
+// flattenVirtualMachineCryptoInfo sets the encryption sub-resource and
+// crypto_state from the key that the virtual machine is encrypted with and its
+// runtime crypto state.
+func flattenVirtualMachineCryptoInfo(d *schema.ResourceData, keyID *types.CryptoKeyId, state string) error {
+	d.Set("crypto_state", state)
+	// A virtual machine home that is only encrypted because a virtual TPM is in
+	// use is not tracked by the encryption sub-resource.
+	if len(d.Get("vtpm").([]interface{})) > 0 && encryptionKeyProviderID(d) == "" {
+		return nil
+	}
+	if err := d.Set("encryption", flattenCryptoKeyID(keyID)); err != nil {
+		return fmt.Errorf("error setting encryption: %s", err)
+	}
+	return nil
+}